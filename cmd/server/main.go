@@ -2,10 +2,19 @@ package main
 
 import (
 	"context"
+	"crypto/rand"
 	"log/slog"
+	"moveshare/internal/blobstore"
 	"moveshare/internal/config"
 	"moveshare/internal/db"
+	"moveshare/internal/events"
+	"moveshare/internal/feed"
+	"moveshare/internal/geo"
+	"moveshare/internal/mailer"
+	"moveshare/internal/payments"
+	"moveshare/internal/repository"
 	"moveshare/internal/routes"
+	"moveshare/internal/scheduler"
 	"moveshare/internal/services"
 	"net/http"
 	"os"
@@ -56,13 +65,149 @@ func main() {
 
 	defer database.Close()
 
-	jwtService, err := services.NewJWTService("internal/keys/private.pem")
+	jwtCfg, err := config.LoadJWTSettings()
 	if err != nil {
-		slog.Error("Failed to load JWT private key", slog.String("error", err.Error()))
+		slog.Error("Failed to load JWT settings", slog.String("error", err.Error()))
 		os.Exit(1)
 	}
+	jwtService, err := services.NewJWTService(jwtCfg.KeysDir, jwtCfg.ActiveKey)
+	if err != nil {
+		slog.Error("Failed to load JWT signing keys", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
+	geoCfg, err := config.LoadGeoSettings()
+	if err != nil {
+		slog.Error("Failed to load geo settings", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	geocoder := geo.NewZIPGeocoder()
+	if geoCfg.ZIPCentroidsPath != "" {
+		geocoder, err = geo.LoadZIPGeocoder(geoCfg.ZIPCentroidsPath)
+		if err != nil {
+			slog.Error("Failed to load ZIP centroids", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+	}
+
+	mailCfg, err := config.LoadMailSettings()
+	if err != nil {
+		slog.Error("Failed to load mail settings", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	m, err := mailer.New(mailCfg)
+	if err != nil {
+		slog.Error("Failed to create mailer", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
+	uploadCfg, err := config.LoadUploadSettings()
+	if err != nil {
+		slog.Error("Failed to load upload settings", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	carrierCfg, err := config.LoadCarrierSettings()
+	if err != nil {
+		slog.Error("Failed to load carrier settings", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
+	carrierService := services.NewCarrierService(repository.NewCarrierRepository(database), uploadCfg.Dir, uploadCfg.MaxBytes)
+	stopInsuranceCheck := scheduler.Every("insurance expiry", carrierCfg.InsuranceCheckInterval, carrierService.ExpireInsurance)
+	defer stopInsuranceCheck()
+
+	webhookCfg, err := config.LoadWebhookSettings()
+	if err != nil {
+		slog.Error("Failed to load webhook settings", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	webhookService := services.NewWebhookService(repository.NewWebhookRepository(database), repository.NewCompanyRepository(database), webhookCfg.Timeout, webhookCfg.MaxAttempts)
+	stopWebhookDelivery := scheduler.Every("webhook delivery", webhookCfg.PollInterval, webhookService.DeliverDue)
+	defer stopWebhookDelivery()
+
+	feedCfg, err := config.LoadFeedSettings()
+	if err != nil {
+		slog.Error("Failed to load feed settings", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	feedCtx, stopFeed := context.WithCancel(context.Background())
+	defer stopFeed()
+	jobRepo, reviewRepo := repository.NewJobRepository(database), repository.NewReviewRepository(database)
+	var broker feed.Broker
+	switch feedCfg.Broker {
+	case "local":
+		broker = feed.NewLocalBroker(jobRepo, reviewRepo)
+	case "postgres":
+		broker = feed.NewPostgresBroker(feedCtx, cfg.DatabaseURL(), database, jobRepo, reviewRepo)
+	default:
+		slog.Error("Unknown feed broker", slog.String("broker", feedCfg.Broker))
+		os.Exit(1)
+	}
+
+	outboxCfg, err := config.LoadOutboxSettings()
+	if err != nil {
+		slog.Error("Failed to load outbox settings", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	bus := events.NewBus(repository.NewOutboxRepository(database), outboxCfg.MaxAttempts)
+
+	paymentCfg, err := config.LoadPaymentSettings()
+	if err != nil {
+		slog.Error("Failed to load payment settings", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	paymentProvider, err := payments.New(paymentCfg)
+	if err != nil {
+		slog.Error("Failed to create payment provider", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
+	blobCfg, err := config.LoadBlobSettings()
+	if err != nil {
+		slog.Error("Failed to load blob storage settings", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	blobs, err := blobstore.New(blobCfg)
+	if err != nil {
+		slog.Error("Failed to create blob store", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	attachmentCfg, err := config.LoadAttachmentSettings()
+	if err != nil {
+		slog.Error("Failed to load attachment settings", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	attachmentURLSecret := []byte(attachmentCfg.URLSecret)
+	if len(attachmentURLSecret) == 0 {
+		slog.Warn("ATTACHMENT_URL_SECRET is not set, download links will stop working after restart")
+		attachmentURLSecret = make([]byte, 32)
+		if _, err := rand.Read(attachmentURLSecret); err != nil {
+			slog.Error("Failed to generate attachment URL secret", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+	}
 
-	r := routes.NewRouter(database, jwtService)
+	r := routes.NewRouter(routes.Dependencies{
+		DB:                   database,
+		JWTService:           jwtService,
+		Geocoder:             geocoder,
+		Mailer:               m,
+		AppBaseURL:           mailCfg.AppBaseURL,
+		UploadsDir:           uploadCfg.Dir,
+		MaxUploadBytes:       uploadCfg.MaxBytes,
+		Broker:               broker,
+		Webhooks:             webhookService,
+		Events:               bus,
+		Payments:             paymentProvider,
+		Blobs:                blobs,
+		AttachmentURLSecret:  attachmentURLSecret,
+		AttachmentURLTTL:     attachmentCfg.URLTTL,
+		MaxAttachmentsPerJob: attachmentCfg.MaxPerJob,
+		ThumbnailSize:        attachmentCfg.ThumbnailSize,
+	})
+	// Рассылка стартует после того, как NewRouter подписал обработчики
+	stopOutbox := scheduler.Every("outbox dispatch", outboxCfg.PollInterval, bus.DispatchPending)
+	defer stopOutbox()
 	r.PathPrefix("/swagger/").Handler(httpSwagger.WrapHandler)
 	slog.Info("🌟 Server started", slog.String("address", ":8080"))
 	http.ListenAndServe(":8080", r)
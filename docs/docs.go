@@ -15,14 +15,34 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/jobs": {
+        "/.well-known/jwks.json": {
             "get": {
+                "description": "Набор ключей, которыми подписаны действующие токены. Заголовок kid токена указывает на ключ из набора",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Публичные ключи подписи (JWKS)",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.JWKS"
+                        }
+                    }
+                }
+            }
+        },
+        "/2fa/confirm": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Получить jobs по фильтрам: кол-во комнат/офис, даты, размер грузовика, диапазон оплаты, пагинация",
+                "description": "Проверяет первый код из приложения, включает 2FA и возвращает резервные коды. Коды показываются один раз",
                 "consumes": [
                     "application/json"
                 ],
@@ -30,166 +50,157 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "jobs"
+                    "2fa"
                 ],
-                "summary": "Получить список работ (Jobs) с фильтрами и пагинацией",
+                "summary": "Подтвердить подключение 2FA",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Количество комнат или office",
-                        "name": "relocation_size",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Дата начала (ISO8601)",
-                        "name": "date_start",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Дата конца (ISO8601)",
-                        "name": "date_end",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Размер грузовика (small, medium, large)",
-                        "name": "truck_size",
-                        "in": "query"
-                    },
-                    {
-                        "type": "number",
-                        "description": "Минимальная оплата",
-                        "name": "payout_min",
-                        "in": "query"
-                    },
-                    {
-                        "type": "number",
-                        "description": "Максимальная оплата",
-                        "name": "payout_max",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Лимит (по умолчанию 10)",
-                        "name": "limit",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Смещение (по умолчанию 0)",
-                        "name": "offset",
-                        "in": "query"
+                        "description": "Код из приложения",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.TOTPCodeRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/moveshare_internal_models.JobListResponse"
+                            "$ref": "#/definitions/moveshare_internal_models.RecoveryCodesResponse"
                         }
                     },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "409": {
+                        "description": "Conflict"
+                    },
                     "500": {
-                        "description": "failed to fetch jobs",
-                        "schema": {
-                            "type": "string"
-                        }
+                        "description": "Internal Server Error"
                     }
                 }
-            },
+            }
+        },
+        "/2fa/disable": {
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Создать новую работу (Job) с параметрами перевозки",
+                "description": "Отключает 2FA. Требуется действующий код из приложения",
                 "consumes": [
                     "application/json"
                 ],
-                "produces": [
-                    "application/json"
-                ],
                 "tags": [
-                    "jobs"
+                    "2fa"
                 ],
-                "summary": "Создание новой работы (Job)",
+                "summary": "Отключить 2FA",
                 "parameters": [
                     {
-                        "description": "Данные для новой работы",
+                        "description": "Код из приложения",
                         "name": "input",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/moveshare_internal_models.CreateJobRequest"
+                            "$ref": "#/definitions/moveshare_internal_models.TOTPCodeRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
-                        "schema": {
-                            "$ref": "#/definitions/moveshare_internal_models.Job"
-                        }
+                    "204": {
+                        "description": "No Content"
                     },
                     "400": {
-                        "description": "invalid request",
-                        "schema": {
-                            "type": "string"
-                        }
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "409": {
+                        "description": "Conflict"
                     },
                     "500": {
-                        "description": "failed to create job",
-                        "schema": {
-                            "type": "string"
-                        }
+                        "description": "Internal Server Error"
                     }
                 }
             }
         },
-        "/jobs/{id}": {
-            "delete": {
+        "/2fa/enroll": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Удаляет работу (Job) по её id",
+                "description": "Генерирует TOTP-секрет и otpauth:// ссылку для приложения-аутентификатора. 2FA включится после POST /2fa/confirm",
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "jobs"
+                    "2fa"
                 ],
-                "summary": "Удалить работу (Job) по id",
-                "parameters": [
+                "summary": "Начать подключение 2FA",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.TOTPEnrollResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "409": {
+                        "description": "Conflict"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/admin/carrier-reviews": {
+            "get": {
+                "security": [
                     {
-                        "type": "string",
-                        "description": "ID работы",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "BearerAuth": []
                     }
                 ],
+                "description": "Профили и страховые сертификаты, ожидающие решения администратора",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Очередь проверки перевозчиков",
                 "responses": {
-                    "204": {
-                        "description": "deleted",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/moveshare_internal_models.CarrierReviewQueue"
                         }
                     },
-                    "400": {
-                        "description": "invalid id",
+                    "401": {
+                        "description": "unauthorized",
                         "schema": {
                             "type": "string"
                         }
                     },
-                    "404": {
-                        "description": "job not found",
+                    "403": {
+                        "description": "forbidden",
                         "schema": {
                             "type": "string"
                         }
                     },
                     "500": {
-                        "description": "failed to delete job",
+                        "description": "internal server error",
                         "schema": {
                             "type": "string"
                         }
@@ -197,9 +208,13 @@ const docTemplate = `{
                 }
             }
         },
-        "/login": {
+        "/admin/carriers/{id}/review": {
             "post": {
-                "description": "Логин по email и password, возвращает JWT access_token",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "consumes": [
                     "application/json"
                 ],
@@ -207,17 +222,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "admin"
                 ],
-                "summary": "Авторизация пользователя",
+                "summary": "Проверить профиль перевозчика",
                 "parameters": [
                     {
-                        "description": "Login data",
+                        "type": "integer",
+                        "description": "ID перевозчика",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Решение (approve или reject)",
                         "name": "input",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/moveshare_internal_models.LoginRequest"
+                            "$ref": "#/definitions/moveshare_internal_models.ReviewRequest"
                         }
                     }
                 ],
@@ -225,21 +247,100 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/moveshare_internal_models.LoginResponse"
+                            "$ref": "#/definitions/moveshare_internal_models.CarrierProfile"
                         }
                     },
                     "400": {
-                        "description": "Bad Request"
+                        "description": "invalid request",
+                        "schema": {
+                            "type": "string"
+                        }
                     },
                     "401": {
-                        "description": "Unauthorized"
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "carrier profile not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "already reviewed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
                     }
                 }
             }
         },
-        "/sign-up": {
+        "/admin/fee-rules": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Все версии, новые первыми. Действует последняя версия, у которой наступил active_from",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Версии правил удержаний",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/moveshare_internal_models.FeeRuleSet"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
             "post": {
-                "description": "Создание нового пользователя с email, username и password",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Правила: percentage (bps от оплаты, 100 bps = 1%), flat (фиксированная сумма), tiered (ступени up_to по оплате; у последней up_to не указывается), promotion (скидка на компонент, можно по promo_code). sizes ограничивает правило размерами работ, min/max — его сумму. Версии не редактируются; работы, закреплённые раньше, сохраняют свой расчёт",
                 "consumes": [
                     "application/json"
                 ],
@@ -247,17 +348,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "admin"
                 ],
-                "summary": "Регистрация пользователя",
+                "summary": "Новая версия правил удержаний",
                 "parameters": [
                     {
-                        "description": "User registration data",
+                        "description": "Название, правила и начало действия",
                         "name": "input",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/moveshare_internal_models.SignUpRequest"
+                            "$ref": "#/definitions/moveshare_internal_models.CreateFeeRuleSetRequest"
                         }
                     }
                 ],
@@ -265,152 +366,7329 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/moveshare_internal_models.User"
+                            "$ref": "#/definitions/moveshare_internal_models.FeeRuleSet"
                         }
                     },
                     "400": {
-                        "description": "Bad Request"
+                        "description": "invalid fee rules",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/insurance/{id}/file": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Скачать файл страхового сертификата",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID сертификата",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "insurance certificate not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/insurance/{id}/review": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Одобрение действующего сертификата снимает приостановку с перевозчика",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Проверить страховой сертификат",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID сертификата",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Решение (approve или reject)",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.ReviewRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.InsuranceCertificate"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "insurance certificate not found",
+                        "schema": {
+                            "type": "string"
+                        }
                     },
                     "409": {
-                        "description": "Conflict"
+                        "description": "already reviewed",
+                        "schema": {
+                            "type": "string"
+                        }
                     },
                     "500": {
-                        "description": "Internal Server Error"
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/jobs/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Удаляет открытую или отменённую работу независимо от владельца. Работу в исполнении сначала отменяют с возвратом оплаты",
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Удалить любую работу (модерация)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID работы",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "deleted",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "job not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "job is in progress, cancel it first",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "failed to delete job",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/jobs/{id}/documents/regenerate": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Заново собирает PDF уже выданных счёта и квитанции по текущим данным (например, после смены названия компании). Номера документов не меняются",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Пересобрать документы работы",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID работы",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/moveshare_internal_models.JobDocument"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "job not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "job was deleted, documents can not be regenerated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/ledger/accounts": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Все счета выбранного типа с остатками: carrier_payable — долги перевозчикам, poster_balance — деньги заказчиков, platform_revenue — выручка, escrow — деньги на счёте площадки",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Счета для сверки",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Тип счёта",
+                        "name": "type",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/moveshare_internal_models.LedgerAccount"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "invalid account type",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Список пользователей",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Лимит (по умолчанию 50)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Смещение (по умолчанию 0)",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.UserListResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "failed to fetch users",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users/{id}/role": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Меняет роль (shipper, carrier, admin). Каждое изменение записывается в журнал; сессии пользователя завершаются, и ему нужно войти заново",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Сменить роль пользователя",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Новая роль",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.ChangeRoleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.RoleChange"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid role",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "user not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "failed to change role",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users/{id}/role-changes": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Журнал смены ролей пользователя",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/moveshare_internal_models.RoleChange"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "invalid id",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "failed to fetch role changes",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/attachments/{id}/content": {
+            "get": {
+                "description": "Отдаёт файл или его миниатюру (variant=thumbnail, JPEG). Токен не нужен: доступ даёт подпись из ссылки, полученной в списке файлов работы",
+                "produces": [
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "attachments"
+                ],
+                "summary": "Скачать файл по подписанной ссылке",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID файла",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "original или thumbnail",
+                        "name": "variant",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Срок действия ссылки (unix)",
+                        "name": "expires",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Подпись ссылки",
+                        "name": "signature",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "403": {
+                        "description": "invalid or expired link",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "attachment not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/carrier/insurance": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "carrier"
+                ],
+                "summary": "Мои страховые сертификаты",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/moveshare_internal_models.InsuranceCertificate"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Загружает сертификат страхования (COI) в формате PDF, JPEG или PNG. Сертификат действует после одобрения администратором",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "carrier"
+                ],
+                "summary": "Загрузить страховой сертификат",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "Файл сертификата",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Сумма покрытия десятичной строкой: 1000000.00",
+                        "name": "coverage_amount",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Валюта покрытия (по умолчанию USD)",
+                        "name": "coverage_currency",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Дата окончания (YYYY-MM-DD)",
+                        "name": "expires_at",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.InsuranceCertificate"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request or unsupported file type",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "carrier profile not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "413": {
+                        "description": "file is too large",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/carrier/profile": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Профиль с USDOT/MC, статусом проверки и загруженными страховыми сертификатами",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "carrier"
+                ],
+                "summary": "Профиль перевозчика",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.CarrierProfile"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "carrier profile not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Создаёт или обновляет профиль. Смена USDOT или MC номера отправляет профиль на повторную проверку",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "carrier"
+                ],
+                "summary": "Сохранить профиль перевозчика",
+                "parameters": [
+                    {
+                        "description": "Данные перевозчика",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.CarrierProfileRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.CarrierProfile"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "dot number already registered",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/companies": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Компании, в которых состоит пользователь, и его роль в каждой",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "companies"
+                ],
+                "summary": "Мои компании",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/moveshare_internal_models.Company"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Создаёт компанию, автор становится её владельцем. Если активной компании ещё нет, новая становится активной со следующего токена",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "companies"
+                ],
+                "summary": "Создать компанию",
+                "parameters": [
+                    {
+                        "description": "Название компании",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.CreateCompanyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.Company"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/companies/invitations/accept": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Принимает приглашение по токену из письма. Пользователь должен войти под тем email, на который пришло приглашение",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "companies"
+                ],
+                "summary": "Принять приглашение",
+                "parameters": [
+                    {
+                        "description": "Токен из ссылки",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.AcceptInvitationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.CompanyMember"
+                        }
+                    },
+                    "400": {
+                        "description": "invitation is invalid, used or expired",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "invitation was sent to another email",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/companies/switch": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Выдаёт новую пару токенов, в которой указана выбранная компания. company_id = null или 0 — работать от своего имени",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "companies"
+                ],
+                "summary": "Сменить активную компанию",
+                "parameters": [
+                    {
+                        "description": "Компания",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.SwitchCompanyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.LoginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "company not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/companies/{id}/invitations": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "companies"
+                ],
+                "summary": "Неотвеченные приглашения компании",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID компании",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/moveshare_internal_models.CompanyInvitation"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "invalid id",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "not allowed to manage this company",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "company not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Отправляет приглашение на email со ссылкой для принятия. Владелец приглашает с любой ролью, диспетчер — только водителей",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "companies"
+                ],
+                "summary": "Пригласить в компанию",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID компании",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Email и роль",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.InviteMemberRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.CompanyInvitation"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "not allowed to manage this company",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "company not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/companies/{id}/members": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "companies"
+                ],
+                "summary": "Сотрудники компании",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID компании",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/moveshare_internal_models.CompanyMember"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "invalid id",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "company not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/companies/{id}/members/{userID}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Только для владельца компании. Последнего владельца понизить нельзя",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "companies"
+                ],
+                "summary": "Сменить роль сотрудника",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID компании",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID сотрудника",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Новая роль (owner, dispatcher, driver)",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.UpdateMemberRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.CompanyMember"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid role",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "not allowed to manage this company",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "company or member not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "company must keep at least one owner",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Владелец исключает сотрудника, либо сотрудник покидает компанию сам (userID = свой ID)",
+                "tags": [
+                    "companies"
+                ],
+                "summary": "Исключить сотрудника",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID компании",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID сотрудника",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "invalid id",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "not allowed to manage this company",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "company or member not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "company must keep at least one owner",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/companies/{id}/profile": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Средняя оценка по отзывам на работы компании, число отзывов и завершённых работ",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reviews"
+                ],
+                "summary": "Публичный профиль компании",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID компании",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.CompanyProfile"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid id",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "company not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Получить jobs по фильтрам: кол-во комнат/офис, даты, размер грузовика, диапазон оплаты, пагинация",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "jobs"
+                ],
+                "summary": "Получить список работ (Jobs) с фильтрами и пагинацией",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Количество комнат или office",
+                        "name": "relocation_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Дата начала (ISO8601)",
+                        "name": "date_start",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Дата конца (ISO8601)",
+                        "name": "date_end",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Размер грузовика (small, medium, large)",
+                        "name": "truck_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Минимальная оплата, десятичной строкой (1250.50)",
+                        "name": "payout_min",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Максимальная оплата, десятичной строкой",
+                        "name": "payout_max",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Валюта границ оплаты (по умолчанию USD); работы в других валютах не попадают",
+                        "name": "payout_currency",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Статус (open, claimed, in_transit, delivered, completed, cancelled)",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Только работы компании",
+                        "name": "company_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Минимальная средняя оценка заказчика (1-5)",
+                        "name": "min_poster_rating",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Широта точки для поиска по месту погрузки",
+                        "name": "origin_lat",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Долгота точки для поиска по месту погрузки",
+                        "name": "origin_lng",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ZIP-код точки для поиска по месту погрузки (вместо координат)",
+                        "name": "origin_zip",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Радиус поиска по месту погрузки, мили",
+                        "name": "origin_radius",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Широта точки для поиска по месту доставки",
+                        "name": "dest_lat",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Долгота точки для поиска по месту доставки",
+                        "name": "dest_lng",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ZIP-код точки для поиска по месту доставки (вместо координат)",
+                        "name": "dest_zip",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Радиус поиска по месту доставки, мили",
+                        "name": "dest_radius",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Лимит (по умолчанию 10)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Смещение (по умолчанию 0)",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.JobListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "address could not be located",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "failed to fetch jobs",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Создать новую работу (Job) с параметрами перевозки. Если в токене выбрана активная компания, работа принадлежит ей",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "jobs"
+                ],
+                "summary": "Создание новой работы (Job)",
+                "parameters": [
+                    {
+                        "description": "Данные для новой работы",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.CreateJobRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.Job"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request, invalid amount, fees exceed payment or address could not be located",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "email is not verified or not allowed to post for this company",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "failed to create job",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/quote": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Считает комиссию площадки, долю брокера и выплату перевозчику по действующим правилам. Ничего не сохраняет: у работы расчёт фиксируется при закреплении за перевозчиком",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "fees"
+                ],
+                "summary": "Предварительный расчёт удержаний",
+                "parameters": [
+                    {
+                        "description": "Оплата, размер работы и промокод",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.FeeQuoteRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.FeeBreakdown"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request, invalid amount or fees exceed payment",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/stream": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Server-Sent Events: job.created, job.claimed, job.cancelled. Принимает те же фильтры, что GET /jobs; в событие попадают только подходящие работы. Данные события — работа целиком (models.JobEvent). Браузерный EventSource не умеет задавать заголовки, поэтому токен можно передать параметром access_token. Соединение закрывается, когда истекает access-токен — клиент переподключается с новым",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "jobs"
+                ],
+                "summary": "Лента работ в реальном времени (SSE)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Access-токен, если нельзя передать заголовок Authorization",
+                        "name": "access_token",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Размер переезда",
+                        "name": "relocation_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Дата начала (RFC3339)",
+                        "name": "date_start",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Дата окончания (RFC3339)",
+                        "name": "date_end",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Размер грузовика",
+                        "name": "truck_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Мин. оплата",
+                        "name": "payout_min",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Макс. оплата",
+                        "name": "payout_max",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Статус работы",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Минимальная средняя оценка заказчика",
+                        "name": "min_poster_rating",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Только работы компании",
+                        "name": "company_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Широта точки погрузки",
+                        "name": "origin_lat",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Долгота точки погрузки",
+                        "name": "origin_lng",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ZIP точки погрузки",
+                        "name": "origin_zip",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Радиус от точки погрузки, мили",
+                        "name": "origin_radius",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Широта точки доставки",
+                        "name": "dest_lat",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Долгота точки доставки",
+                        "name": "dest_lng",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ZIP точки доставки",
+                        "name": "dest_zip",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Радиус от точки доставки, мили",
+                        "name": "dest_radius",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.JobEvent"
+                        }
+                    },
+                    "400": {
+                        "description": "address could not be located",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Полностью обновляет работу (Job). Доступно только пользователю, который её опубликовал",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "jobs"
+                ],
+                "summary": "Обновить работу (Job) по id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID работы",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Новые данные работы",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.CreateJobRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.Job"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request, invalid amount, fees exceed payment or address could not be located",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "job not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "job is no longer open",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "failed to update job",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Удаляет работу (Job) по её id. Доступно только пользователю, который её опубликовал",
+                "tags": [
+                    "jobs"
+                ],
+                "summary": "Удалить работу (Job) по id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID работы",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "deleted",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid id",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "job not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "job is no longer open",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "failed to delete job",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/{id}/attachments": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Файлы с подписанными ссылками на скачивание (url, thumbnail_url), которые действуют до url_expires_at и не требуют токена. Участники работы видят все файлы, остальные — только опись (inventory) открытой работы",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "attachments"
+                ],
+                "summary": "Файлы работы",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID работы",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/moveshare_internal_models.Attachment"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "job not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Загружает файл: JPEG, PNG, GIF, WebP или PDF (тип определяется по содержимому). kind: inventory — фото описи (те, кто управляет работой), proof_of_delivery — фото доставки (назначенный перевозчик, когда работа в in_transit или delivered), other — любой участник работы. Для JPEG, PNG и GIF строится миниатюра. В ответе — подписанные ссылки на скачивание",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "attachments"
+                ],
+                "summary": "Загрузить файл работы",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID работы",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Файл",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "inventory, proof_of_delivery или other (по умолчанию)",
+                        "name": "kind",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.Attachment"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid attachment or unsupported file type",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "job not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "too many attachments or job is not in transit or delivered",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "413": {
+                        "description": "file is too large",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/{id}/attachments/{attachmentID}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Удаляет файл и его миниатюру; можно загрузившему и тем, кто управляет работой",
+                "tags": [
+                    "attachments"
+                ],
+                "summary": "Удалить файл работы",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID работы",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID файла",
+                        "name": "attachmentID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "deleted",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "attachment not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/{id}/backhauls": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Открытые работы, место погрузки которых рядом с местом доставки указанной работы, а погрузка — не раньше её доставки. Отсортированы по порожнему пробегу, затем по оплате",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "jobs"
+                ],
+                "summary": "Подобрать обратные грузы",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID работы",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "description": "Радиус поиска, мили (по умолчанию 100)",
+                        "name": "radius",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Лимит (по умолчанию 10)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Смещение (по умолчанию 0)",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.BackhaulListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "job has no delivery location",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "job not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "failed to fetch backhauls",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/{id}/bids": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Заказчик видит все ставки, перевозчик — только свои",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "bids"
+                ],
+                "summary": "Список ставок по работе",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID работы",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/moveshare_internal_models.Bid"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "job not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "failed to fetch bids",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Перевозчик предлагает свою цену за открытую работу",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "bids"
+                ],
+                "summary": "Сделать ставку на работу",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID работы",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Ставка",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.CreateBidRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.Bid"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid bid",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "cannot claim own job or carrier is not verified",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "job not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "job is no longer open",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "failed to create bid",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/{id}/bids/{bidID}/accept": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Заказчик принимает ставку (или перевозчик — встречное предложение). Работа закрепляется за перевозчиком по согласованной цене, остальные активные ставки отклоняются (по каждой — событие bid.rejected)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "bids"
+                ],
+                "summary": "Принять ставку",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID работы",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID ставки",
+                        "name": "bidID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.Job"
+                        }
+                    },
+                    "400": {
+                        "description": "fees exceed payment",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "forbidden or carrier is not verified",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "bid not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "bid is no longer active",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "failed to accept bid",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/{id}/bids/{bidID}/counter": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Заказчик предлагает перевозчику свою цену в ответ на ставку",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "bids"
+                ],
+                "summary": "Встречное предложение",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID работы",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID ставки",
+                        "name": "bidID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Встречное предложение",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.CounterBidRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.Bid"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid bid",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "bid not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "bid is no longer active",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "failed to counter bid",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/{id}/bids/{bidID}/reject": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Заказчик отклоняет ставку (или перевозчик — встречное предложение)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "bids"
+                ],
+                "summary": "Отклонить ставку",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID работы",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID ставки",
+                        "name": "bidID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.Bid"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "bid not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "bid is no longer active",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "failed to reject bid",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/{id}/bol": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Накладная (bill of lading) выдаётся, когда перевозчик забрал груз (работа в in_transit): данные работы и сторон фиксируются на этот момент. Ответ — подписи и SHA-256 последней версии PDF (он же bol_hash у работы). Доступно тем, кто управляет работой, и назначенному перевозчику",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "bol"
+                ],
+                "summary": "Транспортная накладная",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID работы",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.BillOfLading"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "job not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "bill of lading is not issued yet",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/{id}/bol/pdf": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Последняя версия PDF накладной с поставленными подписями. Заголовок X-Document-SHA256 — её хеш; он совпадает с SHA-256 тела ответа",
+                "produces": [
+                    "application/pdf"
+                ],
+                "tags": [
+                    "bol"
+                ],
+                "summary": "Транспортная накладная (PDF)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID работы",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "job not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "bill of lading is not issued yet",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/{id}/bol/signatures/{role}": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Принимает подпись отправителя (shipper, при погрузке) или получателя (consignee, когда работа в delivered; только после отправителя): изображение подписи PNG или JPEG до 1 МБ и 2000×2000 пикселей и имя подписавшего. Время и IP фиксирует сервер. Каждая роль подписывает один раз; подпись даёт новую версию PDF, и её хеш записывается в накладную и у работы. Отправитель может подписать, пока работа в in_transit или delivered, получатель — только в delivered",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "bol"
+                ],
+                "summary": "Подписать накладную",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID работы",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Роль: shipper или consignee",
+                        "name": "role",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Изображение подписи",
+                        "name": "signature",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Имя подписавшего",
+                        "name": "name",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.BillOfLading"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid signature or unsupported file type",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "job not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "already signed, shipper has not signed yet, job is not delivered yet or bill of lading can not be signed now",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "413": {
+                        "description": "file is too large",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/{id}/bol/verify": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Сверяет сохранённый PDF последней версии и изображения подписей с хешами, записанными при подписании, а хеш накладной — с bol_hash работы. mismatches перечисляет несовпадения: document или роль подписи",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "bol"
+                ],
+                "summary": "Проверить целостность накладной",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID работы",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.BOLVerification"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "job not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "bill of lading is not issued yet",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/{id}/cancel": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Заказчик отменяет работу, пока груз ещё не забран (open/claimed -\u003e cancelled)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "jobs"
+                ],
+                "summary": "Отменить работу",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID работы",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.Job"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "job not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "invalid job status transition",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "failed to update job status",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/{id}/claim": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Перевозчик закрепляет за собой открытую работу. Доступно только проверенным перевозчикам с действующей страховкой. Из одновременных запросов выигрывает только один. Удержания из оплаты считаются по действующим правилам и фиксируются за работой (GET /jobs/{id}/fees)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "jobs"
+                ],
+                "summary": "Взять работу (Job)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID работы",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.Job"
+                        }
+                    },
+                    "400": {
+                        "description": "fees exceed payment",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "cannot claim own job, not a carrier or carrier is not verified",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "job not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "job is no longer open",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "failed to update job status",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/{id}/complete": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Заказчик подтверждает доставку (delivered -\u003e completed). Пока оплата по эскроу не списана, работу завершить нельзя",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "jobs"
+                ],
+                "summary": "Завершить работу",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID работы",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.Job"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "job not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "invalid job status transition or escrow is not funded yet",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "failed to update job status",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/{id}/deliver": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Перевозчик отмечает, что груз доставлен (in_transit -\u003e delivered)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "jobs"
+                ],
+                "summary": "Отметить доставку",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID работы",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.Job"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "job not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "invalid job status transition",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "failed to update job status",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/{id}/escrow": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Эскроу работы: pending (ждёт списания), funded (оплата удерживается площадкой), released (выплачено перевозчику и брокеру), refunded (возвращено заказчику), cancelled (отменено до списания). ledger — все движения денег по работе. Доступно тем, кто управляет работой, и назначенному перевозчику",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payments"
+                ],
+                "summary": "Оплата работы",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID работы",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.Escrow"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "job not found or escrow not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/{id}/fees": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Расчёт, зафиксированный при закреплении работы: версия правил, строки по каждому правилу и итоговые суммы. Доступно тем, кто управляет работой, и назначенному перевозчику",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "fees"
+                ],
+                "summary": "Удержания по работе",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID работы",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.FeeBreakdown"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "job not found or job is not booked yet",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/{id}/invoice": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Счёт заказчику за завершённую работу: стороны, адреса, даты, оплата и расчёт удержаний. Номер (INV-000042) идёт по порядку внутри компании работы (у работ без компании — внутри её автора) и не меняется при повторной генерации. Доступно тем, кто управляет работой, и назначенному перевозчику",
+                "produces": [
+                    "application/pdf"
+                ],
+                "tags": [
+                    "documents"
+                ],
+                "summary": "Счёт по работе (PDF)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID работы",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "job not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "job is not completed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/{id}/receipt": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Квитанция о полученной от заказчика оплате завершённой работы. Номер (RCT-000042) идёт по порядку так же, как у счетов. Доступно тем, кто управляет работой, и назначенному перевозчику",
+                "produces": [
+                    "application/pdf"
+                ],
+                "tags": [
+                    "documents"
+                ],
+                "summary": "Квитанция об оплате (PDF)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID работы",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "job not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "job is not completed or payment not received",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/{id}/reviews": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reviews"
+                ],
+                "summary": "Отзывы по работе",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID работы",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/moveshare_internal_models.Review"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "job not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Только для завершённых работ. Перевозчик оценивает заказчика, заказчик — перевозчика; по одному отзыву от каждой стороны. Отзыв можно исправить в течение 7 дней",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reviews"
+                ],
+                "summary": "Оставить отзыв по работе",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID работы",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Оценка от 1 до 5 и комментарий",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.CreateReviewRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.Review"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid review",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "job not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "job is not completed or review already left",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/{id}/start": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Перевозчик отмечает, что груз забран и находится в пути (claimed -\u003e in_transit)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "jobs"
+                ],
+                "summary": "Начать перевозку",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID работы",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.Job"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "job not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "invalid job status transition",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "failed to update job status",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/{id}/threads": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Заказчик видит переписки со всеми перевозчиками, перевозчик — только свою. unread_count — непрочитанные сообщения от другой стороны",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "messages"
+                ],
+                "summary": "Переписки по работе",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID работы",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/moveshare_internal_models.MessageThread"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "job not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/{id}/threads/{carrierID}/messages": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Сообщения переписки по работе с перевозчиком carrierID, новые первыми. read_at — когда сообщение прочитала другая сторона",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "messages"
+                ],
+                "summary": "Сообщения переписки",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID работы",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID перевозчика",
+                        "name": "carrierID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Лимит (по умолчанию 50)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Смещение (по умолчанию 0)",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.MessageListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid carrier id",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "not a participant of this thread",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "job not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Переписка с перевозчиком открывается после того, как он сделал ставку на работу или взял её",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "messages"
+                ],
+                "summary": "Отправить сообщение",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID работы",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID перевозчика",
+                        "name": "carrierID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Текст сообщения",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.SendMessageRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.Message"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid message",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "not a participant of this thread",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "job not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "thread is not open yet",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/{id}/threads/{carrierID}/read": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Отмечает прочитанными все сообщения другой стороны",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "messages"
+                ],
+                "summary": "Отметить переписку прочитанной",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID работы",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID перевозчика",
+                        "name": "carrierID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.MarkReadResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid carrier id",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "not a participant of this thread",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "job not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/ledger/balances": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Личные счета с остатками: poster_balance — деньги заказчика у площадки (внесённая оплата и доля брокера до выплаты), carrier_payable — сколько площадка должна перевозчику. Положительный остаток — долг площадки пользователю",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ledger"
+                ],
+                "summary": "Мои балансы",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/moveshare_internal_models.LedgerAccount"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/ledger/statement": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Остаток на начало периода, движения за период [from, to) с остатком после каждого и остаток на конец. Даты — YYYY-MM-DD или RFC3339; по умолчанию с начала текущего месяца по текущий момент",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ledger"
+                ],
+                "summary": "Выписка по счетам",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Начало периода",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Конец периода (не включается)",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.Statement"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid date range",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/login": {
+            "post": {
+                "description": "Логин по email и password, возвращает JWT access_token и refresh_token.\nЕсли включена двухфакторная аутентификация, вместо них возвращается challenge_token для POST /login/2fa",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Авторизация пользователя",
+                "parameters": [
+                    {
+                        "description": "Login data",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.LoginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.LoginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    }
+                }
+            }
+        },
+        "/login/2fa": {
+            "post": {
+                "description": "Обменивает challenge_token из /login и код из приложения (или резервный код) на пару токенов. Неверный код делает challenge_token недействительным",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Второй шаг входа (2FA)",
+                "parameters": [
+                    {
+                        "description": "Challenge token и код",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.TwoFactorLoginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.LoginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/logout": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Отзывает текущий access_token и сессию переданного refresh_token",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Выход",
+                "parameters": [
+                    {
+                        "description": "Refresh token",
+                        "name": "input",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.RefreshRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/notifications": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Уведомления в приложении, новые первыми. unread — общее число непрочитанных",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "Мои уведомления",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Только непрочитанные",
+                        "name": "unread",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Лимит (по умолчанию 20)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Смещение (по умолчанию 0)",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.NotificationListResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/notifications/read": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "Отметить уведомления прочитанными",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.MarkReadResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/password/forgot": {
+            "post": {
+                "description": "Отправляет на email ссылку для сброса пароля. Всегда отвечает 202",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Запрос сброса пароля",
+                "parameters": [
+                    {
+                        "description": "Email",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.EmailRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    }
+                }
+            }
+        },
+        "/password/reset": {
+            "post": {
+                "description": "Устанавливает новый пароль по одноразовому токену из письма и завершает все сессии пользователя; выданные раньше access-токены перестают приниматься",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Сброс пароля",
+                "parameters": [
+                    {
+                        "description": "Токен и новый пароль",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.ResetPasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/reviews/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Автор может изменить оценку и комментарий в течение 7 дней после публикации, затем отзыв фиксируется",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reviews"
+                ],
+                "summary": "Исправить отзыв",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID отзыва",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Оценка от 1 до 5 и комментарий",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.CreateReviewRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.Review"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid review",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "review not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "review can no longer be edited",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/saved-searches": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "saved-searches"
+                ],
+                "summary": "Мои сохранённые поиски",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/moveshare_internal_models.SavedSearch"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Сохраняет фильтр работ (поля как у параметров GET /jobs; origin/destination — {lat, lng} или {zip} и radius в милях). О каждой новой подходящей работе приходит уведомление по каналам channels: email, in_app (по умолчанию in_app)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "saved-searches"
+                ],
+                "summary": "Сохранить поиск",
+                "parameters": [
+                    {
+                        "description": "Название, фильтр и каналы уведомлений",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.SavedSearchRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.SavedSearch"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid saved search",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/saved-searches/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Полностью заменяет название, фильтр и каналы уведомлений",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "saved-searches"
+                ],
+                "summary": "Изменить сохранённый поиск",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID сохранённого поиска",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Название, фильтр и каналы уведомлений",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.SavedSearchRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.SavedSearch"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid saved search",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "saved search not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "tags": [
+                    "saved-searches"
+                ],
+                "summary": "Удалить сохранённый поиск",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID сохранённого поиска",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "deleted",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "saved search not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/saved-searches/{id}/pause": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Поиск сохраняется, но уведомления о новых работах не приходят до POST /saved-searches/{id}/resume",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "saved-searches"
+                ],
+                "summary": "Приостановить уведомления",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID сохранённого поиска",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.SavedSearch"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "saved search not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/saved-searches/{id}/resume": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "saved-searches"
+                ],
+                "summary": "Возобновить уведомления",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID сохранённого поиска",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.SavedSearch"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "saved search not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/sign-up": {
+            "post": {
+                "description": "Создание нового пользователя с email, username и password. На email отправляется ссылка для подтверждения",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Регистрация пользователя",
+                "parameters": [
+                    {
+                        "description": "User registration data",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.SignUpRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "409": {
+                        "description": "Conflict"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/token/refresh": {
+            "post": {
+                "description": "Обменивает refresh_token на новую пару токенов. Старый refresh_token становится недействительным; его повторное использование отзывает всю сессию",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Обновление токенов",
+                "parameters": [
+                    {
+                        "description": "Refresh token",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.RefreshRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.LoginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/users/{id}/profile": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Средняя оценка, число отзывов и завершённых работ",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reviews"
+                ],
+                "summary": "Публичный профиль пользователя",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.UserProfile"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid id",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "user not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/reviews": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reviews"
+                ],
+                "summary": "Отзывы о пользователе",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Лимит (по умолчанию 20)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Смещение (по умолчанию 0)",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.ReviewListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid id",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/verify-email": {
+            "post": {
+                "description": "Подтверждает email по одноразовому токену из письма",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Подтверждение email",
+                "parameters": [
+                    {
+                        "description": "Токен из письма",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.VerifyEmailRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/verify-email/resend": {
+            "post": {
+                "description": "Всегда отвечает 202, чтобы по ответу нельзя было узнать, зарегистрирован ли адрес",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Повторная отправка письма с подтверждением",
+                "parameters": [
+                    {
+                        "description": "Email",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.EmailRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    }
+                }
+            }
+        },
+        "/webhooks": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Личные вебхуки и вебхуки компаний, где пользователь владелец или диспетчер",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Мои вебхуки",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/moveshare_internal_models.Webhook"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Создаёт вебхук: события event_types (job.created, job.claimed, job.started, job.delivered, job.completed, job.cancelled, job.deleted, bid.received, bid.countered, bid.accepted, bid.rejected, saved_search.match) отправляются POST-запросом на url. Тело подписано: заголовок X-MoveShare-Signature \"t=\u003cunix\u003e,v1=\u003chex HMAC-SHA256 от \"\u003ct\u003e.\u003cтело\u003e\"\u003e\" с ключом secret. Секрет возвращается только здесь. С company_id — подписка компании (для владельцев и диспетчеров)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Подписаться на события",
+                "parameters": [
+                    {
+                        "description": "Адрес, события и компания",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.CreateWebhookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.Webhook"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid webhook",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "not allowed to manage this company",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Меняет адрес и события; active=false приостанавливает доставку (события копятся в очереди)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Изменить вебхук",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID вебхука",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Адрес, события, активность",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.UpdateWebhookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.Webhook"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid webhook",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "webhook not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Удаляет подписку вместе с журналом доставок",
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Удалить вебхук",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID вебхука",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "deleted",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "webhook not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}/deliveries": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Доставки, новые первыми: статус (pending, succeeded, failed), число попыток, код и текст последнего ответа",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Журнал доставок вебхука",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID вебхука",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Лимит (по умолчанию 20)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Смещение (по умолчанию 0)",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.WebhookDeliveryListResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "webhook not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}/deliveries/{deliveryID}/redeliver": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Ставит в очередь новую доставку с тем же событием (тот же event id в теле); исходная запись журнала не меняется",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Отправить событие повторно",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID вебхука",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID доставки",
+                        "name": "deliveryID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/moveshare_internal_models.WebhookDelivery"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "webhook delivery not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "moveshare_internal_models.AcceptInvitationRequest": {
+            "type": "object",
+            "properties": {
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "moveshare_internal_models.AccountStatement": {
+            "type": "object",
+            "properties": {
+                "account": {
+                    "$ref": "#/definitions/moveshare_internal_models.LedgerAccount"
+                },
+                "closing_balance": {
+                    "$ref": "#/definitions/moveshare_internal_models.Money"
+                },
+                "lines": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/moveshare_internal_models.StatementLine"
+                    }
+                },
+                "opening_balance": {
+                    "$ref": "#/definitions/moveshare_internal_models.Money"
+                }
+            }
+        },
+        "moveshare_internal_models.AccountType": {
+            "type": "string",
+            "enum": [
+                "poster_balance",
+                "carrier_payable",
+                "platform_revenue",
+                "escrow"
+            ],
+            "x-enum-varnames": [
+                "AccountPosterBalance",
+                "AccountCarrierPayable",
+                "AccountPlatformRevenue",
+                "AccountEscrow"
+            ]
+        },
+        "moveshare_internal_models.Address": {
+            "type": "object",
+            "properties": {
+                "city": {
+                    "type": "string"
+                },
+                "latitude": {
+                    "type": "number"
+                },
+                "longitude": {
+                    "type": "number"
+                },
+                "state": {
+                    "type": "string"
+                },
+                "street": {
+                    "type": "string"
+                },
+                "zip": {
+                    "type": "string"
+                }
+            }
+        },
+        "moveshare_internal_models.Attachment": {
+            "type": "object",
+            "properties": {
+                "content_type": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "file_name": {
+                    "type": "string"
+                },
+                "height": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "job_id": {
+                    "type": "string"
+                },
+                "kind": {
+                    "$ref": "#/definitions/moveshare_internal_models.AttachmentKind"
+                },
+                "sha256": {
+                    "type": "string"
+                },
+                "size": {
+                    "type": "integer"
+                },
+                "thumbnail_url": {
+                    "type": "string"
+                },
+                "uploaded_by": {
+                    "type": "integer"
+                },
+                "url": {
+                    "type": "string"
+                },
+                "url_expires_at": {
+                    "type": "string"
+                },
+                "width": {
+                    "description": "Width и Height — размеры изображения в пикселях; у остальных файлов не заполняются",
+                    "type": "integer"
+                }
+            }
+        },
+        "moveshare_internal_models.AttachmentKind": {
+            "type": "string",
+            "enum": [
+                "inventory",
+                "proof_of_delivery",
+                "other"
+            ],
+            "x-enum-varnames": [
+                "AttachmentInventory",
+                "AttachmentProofOfDelivery",
+                "AttachmentOther"
+            ]
+        },
+        "moveshare_internal_models.BOLParty": {
+            "type": "object",
+            "properties": {
+                "details": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "moveshare_internal_models.BOLSignature": {
+            "type": "object",
+            "properties": {
+                "captured_by": {
+                    "description": "CapturedBy — пользователь, с чьего аккаунта снята подпись",
+                    "type": "integer"
+                },
+                "document_hash": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "image_sha256": {
+                    "type": "string"
+                },
+                "ip": {
+                    "type": "string"
+                },
+                "role": {
+                    "$ref": "#/definitions/moveshare_internal_models.SignatureRole"
+                },
+                "signed_at": {
+                    "type": "string"
+                },
+                "signer_name": {
+                    "type": "string"
+                }
+            }
+        },
+        "moveshare_internal_models.BOLSnapshot": {
+            "type": "object",
+            "properties": {
+                "additional_services": {
+                    "type": "string"
+                },
+                "carrier": {
+                    "$ref": "#/definitions/moveshare_internal_models.BOLParty"
+                },
+                "charges": {
+                    "$ref": "#/definitions/moveshare_internal_models.Money"
+                },
+                "delivery_address": {
+                    "$ref": "#/definitions/moveshare_internal_models.Address"
+                },
+                "delivery_datetime": {
+                    "type": "string"
+                },
+                "description_additional_services": {
+                    "type": "string"
+                },
+                "distance_miles": {
+                    "type": "number"
+                },
+                "job_title": {
+                    "type": "string"
+                },
+                "number_of_bedrooms": {
+                    "$ref": "#/definitions/moveshare_internal_models.NumberOfBedrooms"
+                },
+                "pickup_address": {
+                    "$ref": "#/definitions/moveshare_internal_models.Address"
+                },
+                "pickup_datetime": {
+                    "type": "string"
+                },
+                "shipper": {
+                    "$ref": "#/definitions/moveshare_internal_models.BOLParty"
+                },
+                "truck_size": {
+                    "$ref": "#/definitions/moveshare_internal_models.TruckSize"
+                }
+            }
+        },
+        "moveshare_internal_models.BOLVerification": {
+            "type": "object",
+            "properties": {
+                "document_hash": {
+                    "type": "string"
+                },
+                "file_hash": {
+                    "type": "string"
+                },
+                "mismatches": {
+                    "description": "Mismatches — что не совпало: \"document\" или роль подписи",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "valid": {
+                    "description": "Valid — файл и все изображения подписей совпадают с записанными хешами",
+                    "type": "boolean"
+                }
+            }
+        },
+        "moveshare_internal_models.Backhaul": {
+            "type": "object",
+            "properties": {
+                "deadhead_miles": {
+                    "type": "number"
+                },
+                "job": {
+                    "$ref": "#/definitions/moveshare_internal_models.Job"
+                }
+            }
+        },
+        "moveshare_internal_models.BackhaulListResponse": {
+            "type": "object",
+            "properties": {
+                "backhauls": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/moveshare_internal_models.Backhaul"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "moveshare_internal_models.Bid": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "$ref": "#/definitions/moveshare_internal_models.Money"
+                },
+                "carrier_id": {
+                    "type": "integer"
+                },
+                "counter_amount": {
+                    "$ref": "#/definitions/moveshare_internal_models.Money"
+                },
+                "counter_message": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "job_id": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/moveshare_internal_models.BidStatus"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "moveshare_internal_models.BidStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "countered",
+                "accepted",
+                "rejected"
+            ],
+            "x-enum-varnames": [
+                "BidStatusPending",
+                "BidStatusCountered",
+                "BidStatusAccepted",
+                "BidStatusRejected"
+            ]
+        },
+        "moveshare_internal_models.BillOfLading": {
+            "type": "object",
+            "properties": {
+                "document_hash": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "issued_at": {
+                    "type": "string"
+                },
+                "job_id": {
+                    "type": "string"
+                },
+                "number": {
+                    "type": "string"
+                },
+                "signatures": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/moveshare_internal_models.BOLSignature"
+                    }
+                },
+                "snapshot": {
+                    "$ref": "#/definitions/moveshare_internal_models.BOLSnapshot"
+                }
+            }
+        },
+        "moveshare_internal_models.CarrierProfile": {
+            "type": "object",
+            "properties": {
+                "certificates": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/moveshare_internal_models.InsuranceCertificate"
+                    }
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "dot_number": {
+                    "type": "string"
+                },
+                "legal_name": {
+                    "type": "string"
+                },
+                "mc_number": {
+                    "type": "string"
+                },
+                "review_note": {
+                    "type": "string"
+                },
+                "reviewed_at": {
+                    "type": "string"
+                },
+                "reviewed_by": {
+                    "type": "integer"
+                },
+                "status": {
+                    "$ref": "#/definitions/moveshare_internal_models.CarrierStatus"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                },
+                "verified": {
+                    "description": "Verified — профиль одобрен и есть действующая одобренная страховка",
+                    "type": "boolean"
+                }
+            }
+        },
+        "moveshare_internal_models.CarrierProfileRequest": {
+            "type": "object",
+            "properties": {
+                "dot_number": {
+                    "type": "string"
+                },
+                "legal_name": {
+                    "type": "string"
+                },
+                "mc_number": {
+                    "type": "string"
+                }
+            }
+        },
+        "moveshare_internal_models.CarrierReviewQueue": {
+            "type": "object",
+            "properties": {
+                "certificates": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/moveshare_internal_models.InsuranceCertificate"
+                    }
+                },
+                "profiles": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/moveshare_internal_models.CarrierProfile"
+                    }
+                }
+            }
+        },
+        "moveshare_internal_models.CarrierStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "approved",
+                "rejected",
+                "suspended"
+            ],
+            "x-enum-varnames": [
+                "CarrierPending",
+                "CarrierApproved",
+                "CarrierRejected",
+                "CarrierSuspended"
+            ]
+        },
+        "moveshare_internal_models.ChangeRoleRequest": {
+            "type": "object",
+            "properties": {
+                "reason": {
+                    "type": "string"
+                },
+                "role": {
+                    "$ref": "#/definitions/moveshare_internal_models.UserRole"
+                }
+            }
+        },
+        "moveshare_internal_models.Company": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "my_role": {
+                    "description": "Роль текущего пользователя в компании (в списке \"мои компании\")",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/moveshare_internal_models.CompanyRole"
+                        }
+                    ]
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "moveshare_internal_models.CompanyInvitation": {
+            "type": "object",
+            "properties": {
+                "accepted_at": {
+                    "type": "string"
+                },
+                "company_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "invited_by": {
+                    "type": "integer"
+                },
+                "role": {
+                    "$ref": "#/definitions/moveshare_internal_models.CompanyRole"
+                }
+            }
+        },
+        "moveshare_internal_models.CompanyMember": {
+            "type": "object",
+            "properties": {
+                "company_id": {
+                    "type": "integer"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "joined_at": {
+                    "type": "string"
+                },
+                "role": {
+                    "$ref": "#/definitions/moveshare_internal_models.CompanyRole"
+                },
+                "user_id": {
+                    "type": "integer"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "moveshare_internal_models.CompanyProfile": {
+            "type": "object",
+            "properties": {
+                "completed_jobs": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "rating": {
+                    "description": "средняя оценка, 0 — отзывов нет",
+                    "type": "number"
+                },
+                "review_count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "moveshare_internal_models.CompanyRole": {
+            "type": "string",
+            "enum": [
+                "owner",
+                "dispatcher",
+                "driver"
+            ],
+            "x-enum-varnames": [
+                "CompanyOwner",
+                "CompanyDispatcher",
+                "CompanyDriver"
+            ]
+        },
+        "moveshare_internal_models.CounterBidRequest": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "$ref": "#/definitions/moveshare_internal_models.Money"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "moveshare_internal_models.CreateBidRequest": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "$ref": "#/definitions/moveshare_internal_models.Money"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "moveshare_internal_models.CreateCompanyRequest": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "moveshare_internal_models.CreateFeeRuleSetRequest": {
+            "type": "object",
+            "properties": {
+                "active_from": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "rules": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/moveshare_internal_models.FeeRule"
+                    }
+                }
+            }
+        },
+        "moveshare_internal_models.CreateJobRequest": {
+            "type": "object",
+            "properties": {
+                "additional_services": {
+                    "type": "string"
+                },
+                "delivery_address": {
+                    "$ref": "#/definitions/moveshare_internal_models.Address"
+                },
+                "delivery_datetime": {
+                    "type": "string"
+                },
+                "description_additional_services": {
+                    "type": "string"
+                },
+                "number_of_bedrooms": {
+                    "$ref": "#/definitions/moveshare_internal_models.NumberOfBedrooms"
+                },
+                "payment_amount": {
+                    "$ref": "#/definitions/moveshare_internal_models.Money"
+                },
+                "pickup_address": {
+                    "$ref": "#/definitions/moveshare_internal_models.Address"
+                },
+                "pickup_datetime": {
+                    "type": "string"
+                },
+                "promo_code": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "truck_size": {
+                    "$ref": "#/definitions/moveshare_internal_models.TruckSize"
+                }
+            }
+        },
+        "moveshare_internal_models.CreateReviewRequest": {
+            "type": "object",
+            "properties": {
+                "comment": {
+                    "type": "string"
+                },
+                "rating": {
+                    "description": "от 1 до 5",
+                    "type": "integer"
+                }
+            }
+        },
+        "moveshare_internal_models.CreateWebhookRequest": {
+            "type": "object",
+            "properties": {
+                "company_id": {
+                    "description": "CompanyID — подписка компании; доступно владельцам и диспетчерам",
+                    "type": "integer"
+                },
+                "event_types": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/moveshare_internal_models.EventType"
+                    }
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "moveshare_internal_models.DocumentKind": {
+            "type": "string",
+            "enum": [
+                "invoice",
+                "receipt"
+            ],
+            "x-enum-varnames": [
+                "DocumentInvoice",
+                "DocumentReceipt"
+            ]
+        },
+        "moveshare_internal_models.EmailRequest": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                }
+            }
+        },
+        "moveshare_internal_models.Escrow": {
+            "type": "object",
+            "properties": {
+                "broker_cut": {
+                    "$ref": "#/definitions/moveshare_internal_models.Money"
+                },
+                "carrier_net": {
+                    "$ref": "#/definitions/moveshare_internal_models.Money"
+                },
+                "charge_ref": {
+                    "description": "ChargeRef — списание у платёжного провайдера, по нему делается возврат",
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "job_id": {
+                    "type": "string"
+                },
+                "ledger": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/moveshare_internal_models.LedgerEntry"
+                    }
+                },
+                "payee_id": {
+                    "type": "integer"
+                },
+                "payer_id": {
+                    "type": "integer"
+                },
+                "payment": {
+                    "$ref": "#/definitions/moveshare_internal_models.Money"
+                },
+                "platform_fee": {
+                    "$ref": "#/definitions/moveshare_internal_models.Money"
+                },
+                "status": {
+                    "$ref": "#/definitions/moveshare_internal_models.EscrowStatus"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "moveshare_internal_models.EscrowStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "funded",
+                "released",
+                "refunded",
+                "cancelled"
+            ],
+            "x-enum-varnames": [
+                "EscrowPending",
+                "EscrowFunded",
+                "EscrowReleased",
+                "EscrowRefunded",
+                "EscrowCancelled"
+            ]
+        },
+        "moveshare_internal_models.EventType": {
+            "type": "string",
+            "enum": [
+                "job.created",
+                "job.claimed",
+                "job.started",
+                "job.delivered",
+                "job.completed",
+                "job.cancelled",
+                "job.deleted",
+                "bid.received",
+                "bid.countered",
+                "bid.accepted",
+                "bid.rejected",
+                "saved_search.match"
+            ],
+            "x-enum-varnames": [
+                "EventJobCreated",
+                "EventJobClaimed",
+                "EventJobStarted",
+                "EventJobDelivered",
+                "EventJobCompleted",
+                "EventJobCancelled",
+                "EventJobDeleted",
+                "EventBidReceived",
+                "EventBidCountered",
+                "EventBidAccepted",
+                "EventBidRejected",
+                "EventSavedSearchMatch"
+            ]
+        },
+        "moveshare_internal_models.FeeBreakdown": {
+            "type": "object",
+            "properties": {
+                "broker_cut": {
+                    "$ref": "#/definitions/moveshare_internal_models.Money"
+                },
+                "calculated_at": {
+                    "description": "CalculatedAt — когда сделан расчёт; у работы — момент закрепления за перевозчиком",
+                    "type": "string"
+                },
+                "carrier_net": {
+                    "$ref": "#/definitions/moveshare_internal_models.Money"
+                },
+                "lines": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/moveshare_internal_models.FeeLine"
+                    }
+                },
+                "payment": {
+                    "$ref": "#/definitions/moveshare_internal_models.Money"
+                },
+                "platform_fee": {
+                    "$ref": "#/definitions/moveshare_internal_models.Money"
+                },
+                "promo_code": {
+                    "type": "string"
+                },
+                "rule_set_id": {
+                    "type": "string"
+                },
+                "rule_set_version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "moveshare_internal_models.FeeComponent": {
+            "type": "string",
+            "enum": [
+                "platform_fee",
+                "broker_cut"
+            ],
+            "x-enum-varnames": [
+                "FeePlatform",
+                "FeeBrokerCut"
+            ]
+        },
+        "moveshare_internal_models.FeeLine": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "$ref": "#/definitions/moveshare_internal_models.Money"
+                },
+                "component": {
+                    "$ref": "#/definitions/moveshare_internal_models.FeeComponent"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "kind": {
+                    "$ref": "#/definitions/moveshare_internal_models.FeeRuleKind"
+                }
+            }
+        },
+        "moveshare_internal_models.FeeQuoteRequest": {
+            "type": "object",
+            "properties": {
+                "number_of_bedrooms": {
+                    "$ref": "#/definitions/moveshare_internal_models.NumberOfBedrooms"
+                },
+                "payment_amount": {
+                    "$ref": "#/definitions/moveshare_internal_models.Money"
+                },
+                "promo_code": {
+                    "type": "string"
+                }
+            }
+        },
+        "moveshare_internal_models.FeeRule": {
+            "type": "object",
+            "properties": {
+                "bps": {
+                    "type": "integer"
+                },
+                "component": {
+                    "$ref": "#/definitions/moveshare_internal_models.FeeComponent"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "flat": {
+                    "$ref": "#/definitions/moveshare_internal_models.Money"
+                },
+                "kind": {
+                    "$ref": "#/definitions/moveshare_internal_models.FeeRuleKind"
+                },
+                "max": {
+                    "$ref": "#/definitions/moveshare_internal_models.Money"
+                },
+                "min": {
+                    "$ref": "#/definitions/moveshare_internal_models.Money"
+                },
+                "promo_code": {
+                    "description": "PromoCode — акция действует только по коду; пусто — для всех",
+                    "type": "string"
+                },
+                "sizes": {
+                    "description": "Sizes — правило действует только для работ такого размера; пусто — для всех",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/moveshare_internal_models.NumberOfBedrooms"
+                    }
+                },
+                "tiers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/moveshare_internal_models.FeeTier"
+                    }
+                },
+                "valid_from": {
+                    "type": "string"
+                },
+                "valid_until": {
+                    "type": "string"
+                }
+            }
+        },
+        "moveshare_internal_models.FeeRuleKind": {
+            "type": "string",
+            "enum": [
+                "percentage",
+                "flat",
+                "tiered",
+                "promotion"
+            ],
+            "x-enum-varnames": [
+                "FeePercentage",
+                "FeeFlat",
+                "FeeTiered",
+                "FeePromotion"
+            ]
+        },
+        "moveshare_internal_models.FeeRuleSet": {
+            "type": "object",
+            "properties": {
+                "active_from": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "rules": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/moveshare_internal_models.FeeRule"
+                    }
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "moveshare_internal_models.FeeTier": {
+            "type": "object",
+            "properties": {
+                "bps": {
+                    "type": "integer"
+                },
+                "flat": {
+                    "$ref": "#/definitions/moveshare_internal_models.Money"
+                },
+                "up_to": {
+                    "$ref": "#/definitions/moveshare_internal_models.Money"
+                }
+            }
+        },
+        "moveshare_internal_models.GeoRadius": {
+            "type": "object",
+            "properties": {
+                "lat": {
+                    "type": "number"
+                },
+                "lng": {
+                    "type": "number"
+                },
+                "radius": {
+                    "type": "number"
+                },
+                "zip": {
+                    "type": "string"
+                }
+            }
+        },
+        "moveshare_internal_models.InsuranceCertificate": {
+            "type": "object",
+            "properties": {
+                "carrier_id": {
+                    "type": "integer"
+                },
+                "content_type": {
+                    "type": "string"
+                },
+                "coverage_amount": {
+                    "$ref": "#/definitions/moveshare_internal_models.Money"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "file_name": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "review_note": {
+                    "type": "string"
+                },
+                "reviewed_at": {
+                    "type": "string"
+                },
+                "reviewed_by": {
+                    "type": "integer"
+                },
+                "status": {
+                    "$ref": "#/definitions/moveshare_internal_models.InsuranceStatus"
+                }
+            }
+        },
+        "moveshare_internal_models.InsuranceStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "approved",
+                "rejected",
+                "expired"
+            ],
+            "x-enum-varnames": [
+                "InsurancePending",
+                "InsuranceApproved",
+                "InsuranceRejected",
+                "InsuranceExpired"
+            ]
+        },
+        "moveshare_internal_models.InviteMemberRequest": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "role": {
+                    "$ref": "#/definitions/moveshare_internal_models.CompanyRole"
+                }
+            }
+        },
+        "moveshare_internal_models.JWK": {
+            "type": "object",
+            "properties": {
+                "alg": {
+                    "type": "string"
+                },
+                "e": {
+                    "type": "string"
+                },
+                "kid": {
+                    "type": "string"
+                },
+                "kty": {
+                    "type": "string"
+                },
+                "n": {
+                    "type": "string"
+                },
+                "use": {
+                    "type": "string"
+                }
+            }
+        },
+        "moveshare_internal_models.JWKS": {
+            "type": "object",
+            "properties": {
+                "keys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/moveshare_internal_models.JWK"
+                    }
+                }
+            }
+        },
+        "moveshare_internal_models.Job": {
+            "type": "object",
+            "properties": {
+                "additional_services": {
+                    "type": "string"
+                },
+                "bol_hash": {
+                    "description": "BOLHash — SHA-256 последней версии транспортной накладной (GET /jobs/{id}/bol)",
+                    "type": "string"
+                },
+                "carrier_id": {
+                    "type": "integer"
+                },
+                "company_id": {
+                    "type": "integer"
+                },
+                "cut_amount": {
+                    "$ref": "#/definitions/moveshare_internal_models.Money"
+                },
+                "delivery_address": {
+                    "$ref": "#/definitions/moveshare_internal_models.Address"
+                },
+                "delivery_datetime": {
+                    "type": "string"
+                },
+                "description_additional_services": {
+                    "type": "string"
+                },
+                "distance_miles": {
+                    "type": "number"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "number_of_bedrooms": {
+                    "$ref": "#/definitions/moveshare_internal_models.NumberOfBedrooms"
+                },
+                "payment_amount": {
+                    "$ref": "#/definitions/moveshare_internal_models.Money"
+                },
+                "pickup_address": {
+                    "$ref": "#/definitions/moveshare_internal_models.Address"
+                },
+                "pickup_datetime": {
+                    "type": "string"
+                },
+                "poster_id": {
+                    "type": "integer"
+                },
+                "promo_code": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/moveshare_internal_models.JobStatus"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "truck_size": {
+                    "$ref": "#/definitions/moveshare_internal_models.TruckSize"
+                }
+            }
+        },
+        "moveshare_internal_models.JobDocument": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "issued_at": {
+                    "type": "string"
+                },
+                "issuer": {
+                    "type": "string"
+                },
+                "job_id": {
+                    "type": "string"
+                },
+                "kind": {
+                    "$ref": "#/definitions/moveshare_internal_models.DocumentKind"
+                },
+                "number": {
+                    "type": "integer"
+                }
+            }
+        },
+        "moveshare_internal_models.JobEvent": {
+            "type": "object",
+            "properties": {
+                "at": {
+                    "type": "string"
+                },
+                "job": {
+                    "$ref": "#/definitions/moveshare_internal_models.Job"
+                },
+                "type": {
+                    "$ref": "#/definitions/moveshare_internal_models.JobEventType"
+                }
+            }
+        },
+        "moveshare_internal_models.JobEventType": {
+            "type": "string",
+            "enum": [
+                "job.created",
+                "job.claimed",
+                "job.cancelled"
+            ],
+            "x-enum-varnames": [
+                "JobEventCreated",
+                "JobEventClaimed",
+                "JobEventCancelled"
+            ]
+        },
+        "moveshare_internal_models.JobFilter": {
+            "type": "object",
+            "properties": {
+                "company_id": {
+                    "description": "работы компании; 0 — без фильтра",
+                    "type": "integer"
+                },
+                "date_end": {
+                    "description": "\u003c=",
+                    "type": "string"
+                },
+                "date_start": {
+                    "description": "\u003e=",
+                    "type": "string"
+                },
+                "destination": {
+                    "description": "адрес доставки в радиусе",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/moveshare_internal_models.GeoRadius"
+                        }
+                    ]
+                },
+                "min_poster_rating": {
+                    "description": "средняя оценка заказчика \u003e=; заказчики без отзывов не попадают",
+                    "type": "number"
+                },
+                "origin": {
+                    "description": "адрес погрузки в радиусе",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/moveshare_internal_models.GeoRadius"
+                        }
+                    ]
+                },
+                "payout_max": {
+                    "description": "\u003c=, только работы в той же валюте",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/moveshare_internal_models.Money"
+                        }
+                    ]
+                },
+                "payout_min": {
+                    "description": "\u003e=, только работы в той же валюте",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/moveshare_internal_models.Money"
+                        }
+                    ]
+                },
+                "relocation_size": {
+                    "description": "\"1\", \"2\", \"office\" и т.д.",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "\"open\", \"claimed\" и т.д.",
+                    "type": "string"
+                },
+                "truck_size": {
+                    "description": "\"small\", \"medium\", \"large\"",
+                    "type": "string"
+                }
+            }
+        },
+        "moveshare_internal_models.JobListResponse": {
+            "type": "object",
+            "properties": {
+                "jobs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/moveshare_internal_models.Job"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "moveshare_internal_models.JobStatus": {
+            "type": "string",
+            "enum": [
+                "open",
+                "claimed",
+                "in_transit",
+                "delivered",
+                "completed",
+                "cancelled"
+            ],
+            "x-enum-varnames": [
+                "JobStatusOpen",
+                "JobStatusClaimed",
+                "JobStatusInTransit",
+                "JobStatusDelivered",
+                "JobStatusCompleted",
+                "JobStatusCancelled"
+            ]
+        },
+        "moveshare_internal_models.JournalEntryType": {
+            "type": "string",
+            "enum": [
+                "escrow_funded",
+                "job_completed",
+                "carrier_payout",
+                "broker_payout",
+                "escrow_refunded"
+            ],
+            "x-enum-varnames": [
+                "JournalEscrowFunded",
+                "JournalJobCompleted",
+                "JournalCarrierPayout",
+                "JournalBrokerPayout",
+                "JournalEscrowRefunded"
+            ]
+        },
+        "moveshare_internal_models.LedgerAccount": {
+            "type": "object",
+            "properties": {
+                "balance": {
+                    "$ref": "#/definitions/moveshare_internal_models.Money"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "type": {
+                    "$ref": "#/definitions/moveshare_internal_models.AccountType"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "moveshare_internal_models.LedgerEntry": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "$ref": "#/definitions/moveshare_internal_models.Money"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "escrow_id": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "job_id": {
+                    "type": "string"
+                },
+                "provider": {
+                    "type": "string"
+                },
+                "provider_ref": {
+                    "type": "string"
+                },
+                "type": {
+                    "$ref": "#/definitions/moveshare_internal_models.LedgerEntryType"
+                },
+                "user_id": {
+                    "description": "UserID — кто заплатил или получил деньги; у комиссии площадки не указывается",
+                    "type": "integer"
+                }
+            }
+        },
+        "moveshare_internal_models.LedgerEntryType": {
+            "type": "string",
+            "enum": [
+                "escrow_funded",
+                "carrier_payout",
+                "broker_payout",
+                "platform_fee",
+                "escrow_refunded"
+            ],
+            "x-enum-varnames": [
+                "LedgerEscrowFunded",
+                "LedgerCarrierPayout",
+                "LedgerBrokerPayout",
+                "LedgerPlatformFee",
+                "LedgerEscrowRefunded"
+            ]
+        },
+        "moveshare_internal_models.LoginRequest": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "moveshare_internal_models.LoginResponse": {
+            "type": "object",
+            "properties": {
+                "access_token": {
+                    "type": "string"
+                },
+                "challenge_token": {
+                    "type": "string"
+                },
+                "expires_in": {
+                    "description": "время жизни access_token в секундах",
+                    "type": "integer"
+                },
+                "refresh_token": {
+                    "type": "string"
+                },
+                "two_factor_required": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "moveshare_internal_models.MarkReadResponse": {
+            "type": "object",
+            "properties": {
+                "marked": {
+                    "type": "integer"
+                }
+            }
+        },
+        "moveshare_internal_models.Message": {
+            "type": "object",
+            "properties": {
+                "body": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "from_carrier": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "read_at": {
+                    "description": "ReadAt — когда сообщение прочитала другая сторона",
+                    "type": "string"
+                },
+                "sender_id": {
+                    "type": "integer"
+                },
+                "thread_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "moveshare_internal_models.MessageListResponse": {
+            "type": "object",
+            "properties": {
+                "messages": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/moveshare_internal_models.Message"
                     }
+                },
+                "total": {
+                    "type": "integer"
                 }
             }
-        }
-    },
-    "definitions": {
-        "moveshare_internal_models.CreateJobRequest": {
+        },
+        "moveshare_internal_models.MessageThread": {
             "type": "object",
             "properties": {
-                "additional_services": {
+                "carrier_id": {
+                    "type": "integer"
+                },
+                "created_at": {
                     "type": "string"
                 },
-                "cut_amount": {
-                    "type": "number"
+                "id": {
+                    "type": "string"
                 },
-                "delivery_datetime": {
+                "job_id": {
                     "type": "string"
                 },
-                "description_additional_services": {
+                "last_message_at": {
                     "type": "string"
                 },
-                "number_of_bedrooms": {
-                    "$ref": "#/definitions/moveshare_internal_models.NumberOfBedrooms"
+                "unread_count": {
+                    "description": "UnreadCount — непрочитанные сообщения от другой стороны",
+                    "type": "integer"
+                }
+            }
+        },
+        "moveshare_internal_models.Money": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "string",
+                    "example": "1250.50"
                 },
-                "payment_amount": {
-                    "type": "number"
+                "currency": {
+                    "type": "string",
+                    "example": "USD"
+                }
+            }
+        },
+        "moveshare_internal_models.Notification": {
+            "type": "object",
+            "properties": {
+                "body": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "job_id": {
+                    "type": "string"
+                },
+                "read_at": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "type": {
+                    "$ref": "#/definitions/moveshare_internal_models.NotificationType"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "moveshare_internal_models.NotificationChannel": {
+            "type": "string",
+            "enum": [
+                "email",
+                "in_app",
+                "webhook"
+            ],
+            "x-enum-varnames": [
+                "NotificationChannelEmail",
+                "NotificationChannelInApp",
+                "NotificationChannelWebhook"
+            ]
+        },
+        "moveshare_internal_models.NotificationListResponse": {
+            "type": "object",
+            "properties": {
+                "notifications": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/moveshare_internal_models.Notification"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                },
+                "unread": {
+                    "type": "integer"
+                }
+            }
+        },
+        "moveshare_internal_models.NotificationType": {
+            "type": "string",
+            "enum": [
+                "saved_search.match"
+            ],
+            "x-enum-varnames": [
+                "NotificationSavedSearchMatch"
+            ]
+        },
+        "moveshare_internal_models.NumberOfBedrooms": {
+            "type": "string",
+            "enum": [
+                "1",
+                "2",
+                "3",
+                "4",
+                "5+",
+                "office"
+            ],
+            "x-enum-varnames": [
+                "OneBedroom",
+                "TwoBedrooms",
+                "ThreeBedrooms",
+                "FourBedrooms",
+                "FivePlus",
+                "OfficeBedroom"
+            ]
+        },
+        "moveshare_internal_models.RecoveryCodesResponse": {
+            "type": "object",
+            "properties": {
+                "recovery_codes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "moveshare_internal_models.RefreshRequest": {
+            "type": "object",
+            "properties": {
+                "refresh_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "moveshare_internal_models.ResetPasswordRequest": {
+            "type": "object",
+            "properties": {
+                "password": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "moveshare_internal_models.Review": {
+            "type": "object",
+            "properties": {
+                "comment": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "editable_until": {
+                    "description": "EditableUntil — после этого момента отзыв нельзя изменить",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "job_id": {
+                    "type": "string"
+                },
+                "rating": {
+                    "type": "integer"
+                },
+                "reviewee_company_id": {
+                    "type": "integer"
+                },
+                "reviewee_id": {
+                    "type": "integer"
+                },
+                "reviewer_id": {
+                    "type": "integer"
+                },
+                "reviewer_side": {
+                    "$ref": "#/definitions/moveshare_internal_models.ReviewSide"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "moveshare_internal_models.ReviewDecision": {
+            "type": "string",
+            "enum": [
+                "approve",
+                "reject"
+            ],
+            "x-enum-varnames": [
+                "ReviewApprove",
+                "ReviewReject"
+            ]
+        },
+        "moveshare_internal_models.ReviewListResponse": {
+            "type": "object",
+            "properties": {
+                "reviews": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/moveshare_internal_models.Review"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "moveshare_internal_models.ReviewRequest": {
+            "type": "object",
+            "properties": {
+                "decision": {
+                    "description": "approve или reject",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/moveshare_internal_models.ReviewDecision"
+                        }
+                    ]
+                },
+                "note": {
+                    "type": "string"
+                }
+            }
+        },
+        "moveshare_internal_models.ReviewSide": {
+            "type": "string",
+            "enum": [
+                "poster",
+                "carrier"
+            ],
+            "x-enum-varnames": [
+                "ReviewByPoster",
+                "ReviewByCarrier"
+            ]
+        },
+        "moveshare_internal_models.RoleChange": {
+            "type": "object",
+            "properties": {
+                "changed_by": {
+                    "type": "integer"
                 },
-                "pickup_datetime": {
+                "created_at": {
                     "type": "string"
                 },
-                "title": {
+                "id": {
+                    "type": "integer"
+                },
+                "new_role": {
+                    "$ref": "#/definitions/moveshare_internal_models.UserRole"
+                },
+                "old_role": {
+                    "$ref": "#/definitions/moveshare_internal_models.UserRole"
+                },
+                "reason": {
                     "type": "string"
                 },
-                "truck_size": {
-                    "$ref": "#/definitions/moveshare_internal_models.TruckSize"
+                "user_id": {
+                    "type": "integer"
                 }
             }
         },
-        "moveshare_internal_models.Job": {
+        "moveshare_internal_models.SavedSearch": {
             "type": "object",
             "properties": {
-                "additional_services": {
-                    "type": "string"
-                },
-                "cut_amount": {
-                    "type": "number"
+                "channels": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/moveshare_internal_models.NotificationChannel"
+                    }
                 },
-                "delivery_datetime": {
+                "created_at": {
                     "type": "string"
                 },
-                "description_additional_services": {
-                    "type": "string"
+                "filter": {
+                    "$ref": "#/definitions/moveshare_internal_models.JobFilter"
                 },
                 "id": {
                     "type": "string"
                 },
-                "number_of_bedrooms": {
-                    "$ref": "#/definitions/moveshare_internal_models.NumberOfBedrooms"
-                },
-                "payment_amount": {
-                    "type": "number"
+                "last_notified_at": {
+                    "type": "string"
                 },
-                "pickup_datetime": {
+                "name": {
                     "type": "string"
                 },
-                "title": {
+                "paused": {
+                    "description": "Paused — уведомления приостановлены, поиск сохранён",
+                    "type": "boolean"
+                },
+                "updated_at": {
                     "type": "string"
                 },
-                "truck_size": {
-                    "$ref": "#/definitions/moveshare_internal_models.TruckSize"
+                "user_id": {
+                    "type": "integer"
                 }
             }
         },
-        "moveshare_internal_models.JobListResponse": {
+        "moveshare_internal_models.SavedSearchRequest": {
             "type": "object",
             "properties": {
-                "jobs": {
+                "channels": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/moveshare_internal_models.Job"
+                        "$ref": "#/definitions/moveshare_internal_models.NotificationChannel"
                     }
                 },
-                "total": {
-                    "type": "integer"
+                "filter": {
+                    "$ref": "#/definitions/moveshare_internal_models.JobFilter"
+                },
+                "name": {
+                    "type": "string"
                 }
             }
         },
-        "moveshare_internal_models.LoginRequest": {
+        "moveshare_internal_models.SendMessageRequest": {
             "type": "object",
             "properties": {
-                "email": {
-                    "type": "string"
-                },
-                "password": {
+                "body": {
                     "type": "string"
                 }
             }
         },
-        "moveshare_internal_models.LoginResponse": {
+        "moveshare_internal_models.SignUpRequest": {
             "type": "object",
             "properties": {
-                "access_token": {
+                "email": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                },
+                "role": {
+                    "description": "shipper (по умолчанию) или carrier",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/moveshare_internal_models.UserRole"
+                        }
+                    ]
+                },
+                "username": {
                     "type": "string"
                 }
             }
         },
-        "moveshare_internal_models.NumberOfBedrooms": {
+        "moveshare_internal_models.SignatureRole": {
             "type": "string",
             "enum": [
-                "1",
-                "2",
-                "3",
-                "4",
-                "5+",
-                "office"
+                "shipper",
+                "consignee"
             ],
             "x-enum-varnames": [
-                "OneBedroom",
-                "TwoBedrooms",
-                "ThreeBedrooms",
-                "FourBedrooms",
-                "FivePlus",
-                "OfficeBedroom"
+                "SignatureShipper",
+                "SignatureConsignee"
             ]
         },
-        "moveshare_internal_models.SignUpRequest": {
+        "moveshare_internal_models.Statement": {
             "type": "object",
             "properties": {
-                "email": {
+                "accounts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/moveshare_internal_models.AccountStatement"
+                    }
+                },
+                "from": {
                     "type": "string"
                 },
-                "password": {
+                "to": {
                     "type": "string"
+                }
+            }
+        },
+        "moveshare_internal_models.StatementLine": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "$ref": "#/definitions/moveshare_internal_models.Money"
                 },
-                "username": {
+                "balance": {
+                    "$ref": "#/definitions/moveshare_internal_models.Money"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "entry_id": {
+                    "type": "string"
+                },
+                "job_id": {
+                    "type": "string"
+                },
+                "posted_at": {
+                    "type": "string"
+                },
+                "type": {
+                    "$ref": "#/definitions/moveshare_internal_models.JournalEntryType"
+                }
+            }
+        },
+        "moveshare_internal_models.SwitchCompanyRequest": {
+            "type": "object",
+            "properties": {
+                "company_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "moveshare_internal_models.TOTPCodeRequest": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                }
+            }
+        },
+        "moveshare_internal_models.TOTPEnrollResponse": {
+            "type": "object",
+            "properties": {
+                "otpauth_uri": {
+                    "type": "string"
+                },
+                "secret": {
                     "type": "string"
                 }
             }
@@ -428,22 +7706,240 @@ const docTemplate = `{
                 "LargeTruck"
             ]
         },
+        "moveshare_internal_models.TwoFactorLoginRequest": {
+            "type": "object",
+            "properties": {
+                "challenge_token": {
+                    "type": "string"
+                },
+                "code": {
+                    "type": "string"
+                },
+                "recovery_code": {
+                    "type": "string"
+                }
+            }
+        },
+        "moveshare_internal_models.UpdateMemberRequest": {
+            "type": "object",
+            "properties": {
+                "role": {
+                    "$ref": "#/definitions/moveshare_internal_models.CompanyRole"
+                }
+            }
+        },
+        "moveshare_internal_models.UpdateWebhookRequest": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "event_types": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/moveshare_internal_models.EventType"
+                    }
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
         "moveshare_internal_models.User": {
             "type": "object",
             "properties": {
+                "active_company_id": {
+                    "description": "Компания, от имени которой пользователь сейчас работает (попадает в JWT)",
+                    "type": "integer"
+                },
                 "created_at": {
                     "type": "string"
                 },
                 "email": {
                     "type": "string"
                 },
+                "email_verified": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "role": {
+                    "$ref": "#/definitions/moveshare_internal_models.UserRole"
+                },
+                "two_factor_enabled": {
+                    "type": "boolean"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "moveshare_internal_models.UserListResponse": {
+            "type": "object",
+            "properties": {
+                "total": {
+                    "type": "integer"
+                },
+                "users": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/moveshare_internal_models.User"
+                    }
+                }
+            }
+        },
+        "moveshare_internal_models.UserProfile": {
+            "type": "object",
+            "properties": {
+                "completed_jobs": {
+                    "type": "integer"
+                },
                 "id": {
                     "type": "integer"
                 },
+                "rating": {
+                    "description": "средняя оценка, 0 — отзывов нет",
+                    "type": "number"
+                },
+                "review_count": {
+                    "type": "integer"
+                },
+                "role": {
+                    "$ref": "#/definitions/moveshare_internal_models.UserRole"
+                },
                 "username": {
                     "type": "string"
                 }
             }
+        },
+        "moveshare_internal_models.UserRole": {
+            "type": "string",
+            "enum": [
+                "shipper",
+                "carrier",
+                "admin"
+            ],
+            "x-enum-varnames": [
+                "RoleShipper",
+                "RoleCarrier",
+                "RoleAdmin"
+            ]
+        },
+        "moveshare_internal_models.VerifyEmailRequest": {
+            "type": "object",
+            "properties": {
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "moveshare_internal_models.Webhook": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "company_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "event_types": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/moveshare_internal_models.EventType"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "secret": {
+                    "description": "Secret возвращается только при создании",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "moveshare_internal_models.WebhookDelivery": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "delivered_at": {
+                    "type": "string"
+                },
+                "event_id": {
+                    "type": "string"
+                },
+                "event_type": {
+                    "$ref": "#/definitions/moveshare_internal_models.EventType"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "last_attempt_at": {
+                    "type": "string"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "last_status_code": {
+                    "type": "integer"
+                },
+                "next_attempt_at": {
+                    "type": "string"
+                },
+                "payload": {
+                    "type": "object"
+                },
+                "status": {
+                    "$ref": "#/definitions/moveshare_internal_models.WebhookDeliveryStatus"
+                },
+                "webhook_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "moveshare_internal_models.WebhookDeliveryListResponse": {
+            "type": "object",
+            "properties": {
+                "deliveries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/moveshare_internal_models.WebhookDelivery"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "moveshare_internal_models.WebhookDeliveryStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "succeeded",
+                "failed"
+            ],
+            "x-enum-varnames": [
+                "WebhookDeliveryPending",
+                "WebhookDeliverySucceeded",
+                "WebhookDeliveryFailed"
+            ]
         }
     },
     "securityDefinitions": {
@@ -0,0 +1,65 @@
+// Package blobstore хранит файлы по ключам: в локальном каталоге или в
+// S3-совместимом хранилище
+package blobstore
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"moveshare/internal/config"
+	"strings"
+)
+
+var (
+	ErrNotFound   = errors.New("blob not found")
+	ErrInvalidKey = errors.New("invalid blob key")
+)
+
+// BlobStore хранит файлы по ключам вида "jobs/<id>/<file>". Запись по
+// существующему ключу заменяет файл
+type BlobStore interface {
+	// Put сохраняет size байт из r с типом contentType
+	Put(key string, r io.Reader, size int64, contentType string) error
+	// Get открывает файл; закрыть его должен вызывающий
+	Get(key string) (io.ReadCloser, error)
+	// Delete удаляет файл; удаление отсутствующего — не ошибка
+	Delete(key string) error
+}
+
+// New создаёт BlobStore по настройкам: local (по умолчанию) или s3
+func New(cfg *config.BlobSettings) (BlobStore, error) {
+	switch cfg.Driver {
+	case "", "local":
+		return NewLocalStore(cfg.LocalDir)
+	case "s3":
+		return NewS3Store(S3Options{
+			Endpoint:  cfg.S3Endpoint,
+			Region:    cfg.S3Region,
+			Bucket:    cfg.S3Bucket,
+			AccessKey: cfg.S3AccessKey,
+			SecretKey: cfg.S3SecretKey,
+			PathStyle: cfg.S3PathStyle,
+		})
+	default:
+		return nil, fmt.Errorf("unknown blob driver %q", cfg.Driver)
+	}
+}
+
+// validKey — ключ из непустых частей через "/", без "." и ".." и без
+// символов, которые по-разному понимают файловая система и S3
+func validKey(key string) bool {
+	if key == "" || len(key) > 512 {
+		return false
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return false
+		}
+		for _, c := range part {
+			if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_' || c == '.') {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,86 @@
+package blobstore
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLocalStoreRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewLocalStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content := []byte("%PDF-1.4 invoice")
+	if err := store.Put("jobs/7/invoice.pdf", bytes.NewReader(content), int64(len(content)), "application/pdf"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "jobs", "7", "invoice.pdf")); err != nil {
+		t.Errorf("file is not stored under the store directory: %v", err)
+	}
+
+	f, err := store.Get("jobs/7/invoice.pdf")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	got, _ := io.ReadAll(f)
+	f.Close()
+	if !bytes.Equal(got, content) {
+		t.Errorf("Get = %q, want %q", got, content)
+	}
+
+	if err := store.Delete("jobs/7/invoice.pdf"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := store.Get("jobs/7/invoice.pdf"); err != ErrNotFound {
+		t.Errorf("Get after Delete: err = %v, want ErrNotFound", err)
+	}
+	if err := store.Delete("jobs/7/invoice.pdf"); err != nil {
+		t.Errorf("Delete of missing file: %v", err)
+	}
+}
+
+func TestLocalStoreShortWriteLeavesNoFile(t *testing.T) {
+	dir := t.TempDir()
+	store, _ := NewLocalStore(dir)
+	if err := store.Put("jobs/1/a.jpg", strings.NewReader("abc"), 10, "image/jpeg"); err == nil {
+		t.Fatal("Put with wrong size succeeded")
+	}
+	entries, _ := os.ReadDir(filepath.Join(dir, "jobs", "1"))
+	if len(entries) != 0 {
+		t.Errorf("leftover files after failed Put: %v", entries)
+	}
+}
+
+func TestLocalStoreRejectsEscapingKeys(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "blobs")
+	store, err := NewLocalStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret := filepath.Join(root, "secret.txt")
+	if err := os.WriteFile(secret, []byte("secret"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range []string{"../secret.txt", "jobs/../../secret.txt", "/etc/passwd", `..\secret.txt`, ".."} {
+		if err := store.Put(key, strings.NewReader("x"), 1, "text/plain"); err != ErrInvalidKey {
+			t.Errorf("Put(%q): err = %v, want ErrInvalidKey", key, err)
+		}
+		if _, err := store.Get(key); err != ErrInvalidKey {
+			t.Errorf("Get(%q): err = %v, want ErrInvalidKey", key, err)
+		}
+		if err := store.Delete(key); err != ErrInvalidKey {
+			t.Errorf("Delete(%q): err = %v, want ErrInvalidKey", key, err)
+		}
+	}
+	if got, err := os.ReadFile(secret); err != nil || string(got) != "secret" {
+		t.Errorf("file outside the store changed: %q, %v", got, err)
+	}
+}
@@ -0,0 +1,27 @@
+package config
+
+import (
+	"time"
+
+	"github.com/caarlos0/env/v10"
+	"github.com/joho/godotenv"
+)
+
+type AttachmentSettings struct {
+	// Ключ подписи ссылок на скачивание. Если не задан, при запуске создаётся
+	// случайный, и выданные ссылки перестают работать после перезапуска
+	URLSecret string        `env:"ATTACHMENT_URL_SECRET"`
+	URLTTL    time.Duration `env:"ATTACHMENT_URL_TTL" envDefault:"15m"`
+	MaxPerJob int           `env:"ATTACHMENT_MAX_PER_JOB" envDefault:"50"`
+	// Наибольшая сторона миниатюры изображения в пикселях
+	ThumbnailSize int `env:"ATTACHMENT_THUMBNAIL_SIZE" envDefault:"320"`
+}
+
+func LoadAttachmentSettings() (*AttachmentSettings, error) {
+	_ = godotenv.Load()
+	var cfg AttachmentSettings
+	if err := env.Parse(&cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
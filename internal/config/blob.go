@@ -0,0 +1,29 @@
+package config
+
+import (
+	"github.com/caarlos0/env/v10"
+	"github.com/joho/godotenv"
+)
+
+type BlobSettings struct {
+	// "local" — файлы в каталоге LocalDir, "s3" — в бакете S3-совместимого хранилища (AWS S3, MinIO)
+	Driver   string `env:"BLOB_DRIVER" envDefault:"local"`
+	LocalDir string `env:"BLOB_LOCAL_DIR" envDefault:"uploads/blobs"`
+	// Адрес хранилища со схемой, например http://localhost:9000 для MinIO
+	S3Endpoint  string `env:"S3_ENDPOINT" envDefault:"https://s3.amazonaws.com"`
+	S3Region    string `env:"S3_REGION" envDefault:"us-east-1"`
+	S3Bucket    string `env:"S3_BUCKET"`
+	S3AccessKey string `env:"S3_ACCESS_KEY_ID"`
+	S3SecretKey string `env:"S3_SECRET_ACCESS_KEY"`
+	// Бакет в пути (http://host/bucket/key), а не в имени хоста — так работает MinIO
+	S3PathStyle bool `env:"S3_PATH_STYLE" envDefault:"true"`
+}
+
+func LoadBlobSettings() (*BlobSettings, error) {
+	_ = godotenv.Load()
+	var cfg BlobSettings
+	if err := env.Parse(&cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
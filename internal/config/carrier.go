@@ -0,0 +1,22 @@
+package config
+
+import (
+	"time"
+
+	"github.com/caarlos0/env/v10"
+	"github.com/joho/godotenv"
+)
+
+type CarrierSettings struct {
+	// Как часто проверять сроки страховок перевозчиков
+	InsuranceCheckInterval time.Duration `env:"INSURANCE_CHECK_INTERVAL" envDefault:"1h"`
+}
+
+func LoadCarrierSettings() (*CarrierSettings, error) {
+	_ = godotenv.Load()
+	var cfg CarrierSettings
+	if err := env.Parse(&cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
@@ -0,0 +1,21 @@
+package config
+
+import (
+	"github.com/caarlos0/env/v10"
+	"github.com/joho/godotenv"
+)
+
+type FeedSettings struct {
+	// postgres — события через LISTEN/NOTIFY, доходят до всех экземпляров сервера;
+	// local — только внутри процесса, для запуска в одном экземпляре
+	Broker string `env:"FEED_BROKER" envDefault:"postgres"`
+}
+
+func LoadFeedSettings() (*FeedSettings, error) {
+	_ = godotenv.Load()
+	var cfg FeedSettings
+	if err := env.Parse(&cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
@@ -0,0 +1,20 @@
+package config
+
+import (
+	"github.com/caarlos0/env/v10"
+	"github.com/joho/godotenv"
+)
+
+type GeoSettings struct {
+	// Путь к CSV с центроидами ZIP-кодов. Если не задан, используется встроенная таблица
+	ZIPCentroidsPath string `env:"ZIP_CENTROIDS_PATH"`
+}
+
+func LoadGeoSettings() (*GeoSettings, error) {
+	_ = godotenv.Load()
+	var cfg GeoSettings
+	if err := env.Parse(&cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
@@ -0,0 +1,23 @@
+package config
+
+import (
+	"github.com/caarlos0/env/v10"
+	"github.com/joho/godotenv"
+)
+
+type JWTSettings struct {
+	// Каталог с приватными RSA-ключами в PEM (*.pem)
+	KeysDir string `env:"JWT_KEYS_DIR" envDefault:"internal/keys"`
+	// Имя файла ключа, которым подписываются новые токены.
+	// По умолчанию — последний по алфавиту файл в KeysDir
+	ActiveKey string `env:"JWT_ACTIVE_KEY"`
+}
+
+func LoadJWTSettings() (*JWTSettings, error) {
+	_ = godotenv.Load()
+	var cfg JWTSettings
+	if err := env.Parse(&cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
@@ -0,0 +1,26 @@
+package config
+
+import (
+	"github.com/caarlos0/env/v10"
+	"github.com/joho/godotenv"
+)
+
+type MailSettings struct {
+	// "file" — письма складываются в OutboxDir, "smtp" — отправляются через SMTP-сервер
+	Driver    string `env:"MAIL_DRIVER" envDefault:"file"`
+	From      string `env:"MAIL_FROM" envDefault:"MoveShare <no-reply@moveshare.local>"`
+	OutboxDir string `env:"MAIL_OUTBOX_DIR" envDefault:"outbox"`
+	SMTPHost  string `env:"SMTP_HOST" envDefault:"localhost"`
+	SMTPPort  int    `env:"SMTP_PORT" envDefault:"1025"`
+	// Адрес фронтенда, на который ведут ссылки из писем
+	AppBaseURL string `env:"APP_BASE_URL" envDefault:"http://localhost:3000"`
+}
+
+func LoadMailSettings() (*MailSettings, error) {
+	_ = godotenv.Load()
+	var cfg MailSettings
+	if err := env.Parse(&cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
@@ -0,0 +1,24 @@
+package config
+
+import (
+	"time"
+
+	"github.com/caarlos0/env/v10"
+	"github.com/joho/godotenv"
+)
+
+type OutboxSettings struct {
+	// Как часто проверять outbox на неразосланные события
+	PollInterval time.Duration `env:"OUTBOX_POLL_INTERVAL" envDefault:"1s"`
+	// После стольких неудачных рассылок событие помечается failed
+	MaxAttempts int `env:"OUTBOX_MAX_ATTEMPTS" envDefault:"20"`
+}
+
+func LoadOutboxSettings() (*OutboxSettings, error) {
+	_ = godotenv.Load()
+	var cfg OutboxSettings
+	if err := env.Parse(&cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
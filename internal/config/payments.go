@@ -0,0 +1,20 @@
+package config
+
+import (
+	"github.com/caarlos0/env/v10"
+	"github.com/joho/godotenv"
+)
+
+type PaymentSettings struct {
+	// "fake" — платежи проводятся в памяти процесса, без реальных денег (для разработки)
+	Provider string `env:"PAYMENTS_PROVIDER" envDefault:"fake"`
+}
+
+func LoadPaymentSettings() (*PaymentSettings, error) {
+	_ = godotenv.Load()
+	var cfg PaymentSettings
+	if err := env.Parse(&cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
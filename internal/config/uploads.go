@@ -0,0 +1,21 @@
+package config
+
+import (
+	"github.com/caarlos0/env/v10"
+	"github.com/joho/godotenv"
+)
+
+type UploadSettings struct {
+	// Каталог, куда сохраняются загруженные документы
+	Dir      string `env:"UPLOADS_DIR" envDefault:"uploads"`
+	MaxBytes int64  `env:"UPLOAD_MAX_BYTES" envDefault:"10485760"`
+}
+
+func LoadUploadSettings() (*UploadSettings, error) {
+	_ = godotenv.Load()
+	var cfg UploadSettings
+	if err := env.Parse(&cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
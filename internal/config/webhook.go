@@ -0,0 +1,26 @@
+package config
+
+import (
+	"time"
+
+	"github.com/caarlos0/env/v10"
+	"github.com/joho/godotenv"
+)
+
+type WebhookSettings struct {
+	// Как часто проверять очередь доставок
+	PollInterval time.Duration `env:"WEBHOOK_POLL_INTERVAL" envDefault:"5s"`
+	// Сколько ждать ответа подписчика
+	Timeout time.Duration `env:"WEBHOOK_TIMEOUT" envDefault:"10s"`
+	// После стольких неудачных попыток доставка помечается failed
+	MaxAttempts int `env:"WEBHOOK_MAX_ATTEMPTS" envDefault:"10"`
+}
+
+func LoadWebhookSettings() (*WebhookSettings, error) {
+	_ = godotenv.Load()
+	var cfg WebhookSettings
+	if err := env.Parse(&cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
@@ -0,0 +1,114 @@
+package documents
+
+import (
+	"fmt"
+	"image"
+	"moveshare/internal/models"
+	"moveshare/internal/pdf"
+)
+
+const (
+	signatureWidth  = 200.0
+	signatureHeight = 60.0
+	signedAtFmt     = "Jan 2, 2006 15:04:05 UTC"
+)
+
+// RenderBOL собирает PDF транспортной накладной. images — изображения
+// подписей по ролям; у каждой подписи из bol.Signatures должно быть своё
+func RenderBOL(bol *models.BillOfLading, images map[models.SignatureRole]image.Image) []byte {
+	s := bol.Snapshot
+	doc := pdf.New("BILL OF LADING "+bol.Number, bol.IssuedAt)
+	doc.AddPage()
+
+	y := 738.0
+	doc.Text(left, y, 18, true, "MoveShare")
+	doc.TextRight(right, y, 20, true, "BILL OF LADING")
+	y -= 20
+	doc.TextRight(right, y, bodySize, false, "No. "+bol.Number)
+	y -= 14
+	doc.TextRight(right, y, bodySize, false, "Date: "+bol.IssuedAt.UTC().Format(dateFmt))
+	y -= 14
+	doc.Line(left, y, right, y, 0.75)
+
+	y -= 22
+	y = parties(doc, y, "Shipper", Party(s.Shipper), "Carrier", Party(s.Carrier))
+
+	y -= 10
+	doc.Text(left, y, 11, true, "Shipment")
+	y -= 16
+	rows := [][2]string{
+		{"Job", s.JobTitle},
+		{"Job ID", bol.JobID},
+		{"Pickup", joinNonEmpty(formatAddress(s.PickupAddress), s.PickupDateTime.UTC().Format(dateFmt))},
+		{"Delivery", joinNonEmpty(formatAddress(s.DeliveryAddress), s.DeliveryDateTime.UTC().Format(dateFmt))},
+		{"Distance", fmt.Sprintf("%.1f mi", s.DistanceMiles)},
+		{"Load", joinNonEmpty(string(s.NumberOfBedrooms)+" household goods", "truck "+string(s.TruckSize))},
+	}
+	if s.AdditionalServices != "" {
+		rows = append(rows, [2]string{"Services", joinNonEmpty(s.AdditionalServices, s.DescriptionAdditionalServices)})
+	}
+	for _, row := range rows {
+		doc.Text(left, y, bodySize, true, row[0])
+		doc.Text(left+70, y, bodySize, false, fit(row[1], right-left-70, bodySize, false))
+		y -= 14
+	}
+
+	y -= 14
+	doc.Line(left, y+10, right, y+10, 0.5)
+	amountRow(doc, y-6, "Agreed charges", s.Charges, true)
+	y -= 40
+
+	for _, line := range []string{
+		"Received by the carrier the household goods described above in apparent good order, except as noted,",
+		"for transportation to the delivery address. The shipper signs at pickup and the consignee at delivery;",
+		"the consignee's signature confirms receipt of the goods.",
+	} {
+		doc.Text(left, y, 9, false, line)
+		y -= 12
+	}
+
+	y -= 20
+	signatureBlock(doc, left, y, "Shipper (at pickup)", bol.Signature(models.SignatureShipper), images[models.SignatureShipper])
+	signatureBlock(doc, column, y, "Consignee (at delivery)", bol.Signature(models.SignatureConsignee), images[models.SignatureConsignee])
+
+	doc.Line(left, 60, right, 60, 0.5)
+	doc.Text(left, 46, 8, false, "Issued by MoveShare for job "+bol.JobID+". Signatures are captured electronically;")
+	doc.Text(left, 36, 8, false, "each one produces a new version of this document whose SHA-256 is recorded with the job.")
+	return doc.Bytes()
+}
+
+// signatureBlock — подпись стороны: изображение над чертой, под ней имя,
+// время и адрес, с которого подписано
+func signatureBlock(doc *pdf.Document, x, y float64, label string, sig *models.BOLSignature, img image.Image) {
+	doc.Text(x, y, 9, true, label)
+	line := y - 14 - signatureHeight
+	if sig != nil && img != nil {
+		w, h := fitImage(img, signatureWidth, signatureHeight)
+		doc.Image(x, line+2, w, h, img)
+	}
+	doc.Line(x, line, x+signatureWidth, line, 0.5)
+	y = line - 13
+	if sig == nil {
+		doc.Text(x, y, 9, false, "Not signed")
+		return
+	}
+	doc.Text(x, y, bodySize, true, fit(sig.SignerName, signatureWidth+40, bodySize, true))
+	y -= 12
+	doc.Text(x, y, 8, false, "Signed "+sig.SignedAt.UTC().Format(signedAtFmt))
+	y -= 10
+	doc.Text(x, y, 8, false, "IP "+sig.IP)
+}
+
+// fitImage — размер изображения, вписанного в w×h с сохранением пропорций
+func fitImage(img image.Image, w, h float64) (float64, float64) {
+	bounds := img.Bounds()
+	iw, ih := float64(bounds.Dx()), float64(bounds.Dy())
+	if iw == 0 || ih == 0 {
+		return 0, 0
+	}
+	scale := w / iw
+	if ih*scale > h {
+		scale = h / ih
+	}
+	return iw * scale, ih * scale
+}
@@ -0,0 +1,191 @@
+// Package documents собирает PDF счетов, квитанций и транспортных накладных по работам
+package documents
+
+import (
+	"fmt"
+	"moveshare/internal/models"
+	"moveshare/internal/pdf"
+	"strings"
+	"time"
+)
+
+// Party — сторона в документе: название и строки с реквизитами
+type Party struct {
+	Name    string
+	Details []string
+}
+
+// Data — всё, что попадает в документ. Одинаковые данные дают одинаковый файл
+type Data struct {
+	Document *models.JobDocument
+	Job      *models.Job
+	BillTo   Party
+	Carrier  Party
+	// Fees — расчёт удержаний, зафиксированный при закреплении работы; может отсутствовать
+	Fees *models.FeeBreakdown
+	// PaidAt и PaymentRef — когда и какой операцией провайдера получена оплата (для квитанции)
+	PaidAt     *time.Time
+	PaymentRef string
+}
+
+const (
+	left     = 54.0
+	right    = pdf.PageWidth - 54
+	column   = 320.0
+	dateFmt  = "Jan 2, 2006"
+	bodySize = 10.0
+)
+
+var titles = map[models.DocumentKind]string{
+	models.DocumentInvoice: "INVOICE",
+	models.DocumentReceipt: "RECEIPT",
+}
+
+// Render собирает PDF документа
+func Render(d Data) []byte {
+	number := d.Document.DisplayNumber()
+	doc := pdf.New(titles[d.Document.Kind]+" "+number, d.Document.IssuedAt)
+	doc.AddPage()
+
+	y := 738.0
+	doc.Text(left, y, 18, true, "MoveShare")
+	doc.TextRight(right, y, 20, true, titles[d.Document.Kind])
+	y -= 20
+	doc.TextRight(right, y, bodySize, false, "No. "+number)
+	y -= 14
+	doc.TextRight(right, y, bodySize, false, "Date: "+d.Document.IssuedAt.UTC().Format(dateFmt))
+	y -= 14
+	doc.Line(left, y, right, y, 0.75)
+
+	y -= 22
+	billToLabel := "Bill to"
+	if d.Document.Kind == models.DocumentReceipt {
+		billToLabel = "Received from"
+	}
+	y = parties(doc, y, billToLabel, d.BillTo, "Carrier", d.Carrier)
+
+	y -= 10
+	doc.Text(left, y, 11, true, "Job")
+	y -= 16
+	job := d.Job
+	for _, row := range [][2]string{
+		{"Title", job.JobTitle},
+		{"Job ID", job.ID},
+		{"Pickup", joinNonEmpty(formatAddress(job.PickupAddress), job.PickupDateTime.UTC().Format(dateFmt))},
+		{"Delivery", joinNonEmpty(formatAddress(job.DeliveryAddress), job.DeliveryDateTime.UTC().Format(dateFmt))},
+		{"Distance", fmt.Sprintf("%.1f mi", job.DistanceMiles)},
+	} {
+		doc.Text(left, y, bodySize, true, row[0])
+		doc.Text(left+70, y, bodySize, false, fit(row[1], right-left-70, bodySize, false))
+		y -= 14
+	}
+
+	y -= 14
+	doc.Text(left, y, bodySize, true, "Description")
+	doc.TextRight(right, y, bodySize, true, "Amount")
+	y -= 6
+	doc.Line(left, y, right, y, 0.5)
+	y -= 16
+
+	payment := job.PaymentAmount
+	if d.Fees != nil {
+		payment = d.Fees.Payment
+	}
+	switch d.Document.Kind {
+	case models.DocumentInvoice:
+		y = amountRow(doc, y, "Moving services: "+job.JobTitle, payment, false)
+		if d.Fees != nil {
+			y -= 6
+			doc.Text(left, y, 9, true, fmt.Sprintf("Included in the amount above (fee rules v%d):", d.Fees.RuleSetVersion))
+			y -= 14
+			y = amountRow(doc, y, "    Platform fee", d.Fees.PlatformFee, false)
+			y = amountRow(doc, y, "    Broker cut", d.Fees.BrokerCut, false)
+			y = amountRow(doc, y, "    Carrier payout", d.Fees.CarrierNet, false)
+			if d.Fees.PromoCode != "" {
+				doc.Text(left, y, 9, false, "    Promo code: "+d.Fees.PromoCode)
+				y -= 14
+			}
+		}
+		doc.Line(left, y+4, right, y+4, 0.5)
+		y -= 10
+		amountRow(doc, y, "Total due", payment, true)
+	case models.DocumentReceipt:
+		y = amountRow(doc, y, "Payment for moving services: "+job.JobTitle, payment, false)
+		if d.PaidAt != nil {
+			doc.Text(left, y, 9, false, "Paid on "+d.PaidAt.UTC().Format(dateFmt))
+			y -= 14
+		}
+		if d.PaymentRef != "" {
+			doc.Text(left, y, 9, false, "Payment reference: "+d.PaymentRef)
+			y -= 14
+		}
+		doc.Line(left, y+4, right, y+4, 0.5)
+		y -= 10
+		amountRow(doc, y, "Total paid", payment, true)
+	}
+
+	doc.Line(left, 60, right, 60, 0.5)
+	doc.Text(left, 46, 8, false, "Issued by MoveShare for job "+job.ID+". "+number+" is permanent and is kept on regeneration.")
+	return doc.Bytes()
+}
+
+// parties выводит две стороны в две колонки и возвращает y под ними
+func parties(doc *pdf.Document, y float64, leftLabel string, l Party, rightLabel string, r Party) float64 {
+	doc.Text(left, y, 9, true, strings.ToUpper(leftLabel))
+	doc.Text(column, y, 9, true, strings.ToUpper(rightLabel))
+	y -= 16
+	bottom := y
+	for i, p := range []Party{l, r} {
+		x := []float64{left, column}[i]
+		py := y
+		doc.Text(x, py, 11, true, fit(p.Name, column-left-10, 11, true))
+		py -= 14
+		for _, line := range p.Details {
+			doc.Text(x, py, bodySize, false, fit(line, column-left-10, bodySize, false))
+			py -= 13
+		}
+		if py < bottom {
+			bottom = py
+		}
+	}
+	return bottom - 8
+}
+
+// amountRow — строка таблицы: описание слева, сумма справа
+func amountRow(doc *pdf.Document, y float64, label string, amount models.Money, bold bool) float64 {
+	size := bodySize
+	if bold {
+		size = 12
+	}
+	value := amount.String()
+	doc.Text(left, y, size, bold, fit(label, right-left-pdf.TextWidth(value, size, bold)-20, size, bold))
+	doc.TextRight(right, y, size, bold, value)
+	return y - 16
+}
+
+func formatAddress(a models.Address) string {
+	return joinNonEmpty(a.Street, a.City, a.State+" "+a.ZIP)
+}
+
+// joinNonEmpty соединяет через запятую непустые части
+func joinNonEmpty(parts ...string) string {
+	nonEmpty := []string{}
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); p != "" {
+			nonEmpty = append(nonEmpty, p)
+		}
+	}
+	return strings.Join(nonEmpty, ", ")
+}
+
+// fit обрезает строку до ширины width, добавляя многоточие
+func fit(s string, width, size float64, bold bool) string {
+	if pdf.TextWidth(s, size, bold) <= width {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && pdf.TextWidth(string(runes)+"...", size, bold) > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "..."
+}
@@ -0,0 +1,161 @@
+package events
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"moveshare/internal/models"
+	"moveshare/internal/repository"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// batchSize — сколько событий забирать из outbox за раз
+	batchSize = 100
+	// lease — на сколько событие скрыто от других экземпляров, пока его рассылают
+	lease = 5 * time.Minute
+	// baseBackoff — пауза перед второй попыткой; дальше она удваивается
+	baseBackoff = 5 * time.Second
+	maxBackoff  = time.Hour
+)
+
+// Handler обрабатывает событие. Ошибка — событие будет передано этому
+// подписчику ещё раз, поэтому обработчик должен выдерживать повторы
+type Handler func(event *models.Event) error
+
+// Bus раздаёт события из outbox подписчикам этого процесса. Доставка
+// «хотя бы один раз»: подписчик, который уже обработал событие, отмечается
+// в outbox_consumers и при повторной рассылке пропускается
+type Bus interface {
+	// Subscribe подписывает обработчик на события types (без types — на все).
+	// name сохраняется в базе, поэтому должно оставаться неизменным между релизами
+	Subscribe(name string, handler Handler, types ...models.EventType)
+	// DispatchPending рассылает все события, срок которых подошёл
+	DispatchPending() error
+}
+
+type subscription struct {
+	name    string
+	handler Handler
+	types   map[models.EventType]bool
+}
+
+func (s subscription) wants(eventType models.EventType) bool {
+	return len(s.types) == 0 || s.types[eventType]
+}
+
+type bus struct {
+	repo          repository.OutboxRepository
+	maxAttempts   int
+	mu            sync.RWMutex
+	subscriptions []subscription
+}
+
+func NewBus(repo repository.OutboxRepository, maxAttempts int) Bus {
+	return &bus{repo: repo, maxAttempts: maxAttempts}
+}
+
+func (b *bus) Subscribe(name string, handler Handler, types ...models.EventType) {
+	sub := subscription{name: name, handler: handler, types: map[models.EventType]bool{}}
+	for _, t := range types {
+		sub.types[t] = true
+	}
+	b.mu.Lock()
+	b.subscriptions = append(b.subscriptions, sub)
+	b.mu.Unlock()
+}
+
+func (b *bus) DispatchPending() error {
+	for {
+		pending, err := b.repo.ClaimPending(batchSize, lease)
+		if err != nil {
+			return err
+		}
+		for _, p := range pending {
+			if err := b.dispatch(p); err != nil {
+				// Аренда истечёт, и событие разошлют снова
+				slog.Error("Failed to dispatch outbox event",
+					slog.String("event_id", p.Event.ID),
+					slog.String("error", err.Error()))
+			}
+		}
+		if len(pending) < batchSize {
+			return nil
+		}
+	}
+}
+
+// dispatch передаёт событие всем подписчикам, которые его ещё не обработали.
+// Ошибка — только если не удалось записать результат
+func (b *bus) dispatch(p *repository.PendingEvent) error {
+	event := p.Event
+	consumed, err := b.repo.GetConsumers(event.ID)
+	if err != nil {
+		return err
+	}
+
+	b.mu.RLock()
+	subscriptions := b.subscriptions
+	b.mu.RUnlock()
+
+	var failures []string
+	for _, sub := range subscriptions {
+		if !sub.wants(event.Type) || consumed[sub.name] {
+			continue
+		}
+		if err := call(sub.handler, event); err != nil {
+			failures = append(failures, sub.name+": "+err.Error())
+			continue
+		}
+		if err := b.repo.MarkConsumed(event.ID, sub.name); err != nil {
+			return err
+		}
+	}
+	if len(failures) == 0 {
+		return b.repo.MarkPublished(event.ID)
+	}
+
+	errMsg := strings.Join(failures, "; ")
+	slog.Warn("Outbox event subscribers failed",
+		slog.String("event_id", event.ID),
+		slog.String("event_type", string(event.Type)),
+		slog.String("error", errMsg))
+	attempt := p.Attempts + 1
+	if attempt >= b.maxAttempts {
+		return b.repo.RecordFailure(event.ID, errMsg, nil)
+	}
+	next := time.Now().Add(backoff(attempt))
+	return b.repo.RecordFailure(event.ID, errMsg, &next)
+}
+
+// call вызывает обработчик; паника подписчика считается его ошибкой и не роняет рассылку
+func call(handler Handler, event *models.Event) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return handler(event)
+}
+
+// backoff — пауза после attempt-й неудачной попытки: 5s, 10s, 20s... но не больше часа
+func backoff(attempt int) time.Duration {
+	d := time.Duration(float64(baseBackoff) * math.Pow(2, float64(attempt-1)))
+	if d <= 0 || d > maxBackoff {
+		return maxBackoff
+	}
+	return d
+}
+
+// JobHandler — обработчик событий, в которых данные — снимок работы
+func JobHandler(handle func(job *models.Job) error) Handler {
+	return func(event *models.Event) error {
+		var job models.Job
+		if err := event.Decode(&job); err != nil {
+			return err
+		}
+		return handle(&job)
+	}
+}
@@ -0,0 +1,202 @@
+package events
+
+import (
+	"errors"
+	"moveshare/internal/models"
+	"moveshare/internal/repository"
+	"slices"
+	"testing"
+	"time"
+)
+
+// fakeOutbox хранит события в памяти. ClaimPending отдаёт события, которые
+// ещё не разосланы и не отложены
+type fakeOutbox struct {
+	events    []*repository.PendingEvent
+	consumers map[string]map[string]bool
+	published map[string]bool
+	failures  map[string]*time.Time
+}
+
+func newFakeOutbox(events ...*models.Event) *fakeOutbox {
+	o := &fakeOutbox{consumers: map[string]map[string]bool{}, published: map[string]bool{}, failures: map[string]*time.Time{}}
+	for _, event := range events {
+		o.events = append(o.events, &repository.PendingEvent{Event: event})
+	}
+	return o
+}
+
+func (o *fakeOutbox) ClaimPending(limit int, lease time.Duration) ([]*repository.PendingEvent, error) {
+	var pending []*repository.PendingEvent
+	for _, p := range o.events {
+		if _, failed := o.failures[p.Event.ID]; !o.published[p.Event.ID] && !failed && len(pending) < limit {
+			pending = append(pending, p)
+		}
+	}
+	return pending, nil
+}
+
+func (o *fakeOutbox) GetConsumers(eventID string) (map[string]bool, error) {
+	consumed := map[string]bool{}
+	for name := range o.consumers[eventID] {
+		consumed[name] = true
+	}
+	return consumed, nil
+}
+
+func (o *fakeOutbox) MarkConsumed(eventID, subscriber string) error {
+	if o.consumers[eventID] == nil {
+		o.consumers[eventID] = map[string]bool{}
+	}
+	o.consumers[eventID][subscriber] = true
+	return nil
+}
+
+func (o *fakeOutbox) MarkPublished(eventID string) error {
+	o.published[eventID] = true
+	return nil
+}
+
+func (o *fakeOutbox) RecordFailure(eventID string, errMsg string, nextAttemptAt *time.Time) error {
+	o.failures[eventID] = nextAttemptAt
+	for _, p := range o.events {
+		if p.Event.ID == eventID {
+			p.Attempts++
+		}
+	}
+	return nil
+}
+
+// retry снимает отсрочку, как будто подошло время следующей попытки
+func (o *fakeOutbox) retry() {
+	for id := range o.failures {
+		delete(o.failures, id)
+	}
+}
+
+func jobCreated() *models.Event {
+	return models.NewEvent(models.EventJobCreated, &models.Job{ID: "job", JobTitle: "2 bedroom move"}, models.EventAudience{}, "")
+}
+
+func TestDispatchByType(t *testing.T) {
+	created, bid := jobCreated(), models.NewEvent(models.EventBidReceived, &models.Bid{ID: "bid"}, models.EventAudience{}, "")
+	outbox := newFakeOutbox(created, bid)
+	b := NewBus(outbox, 5)
+	var jobs, all []models.EventType
+	b.Subscribe("jobs", func(e *models.Event) error { jobs = append(jobs, e.Type); return nil }, models.EventJobCreated, models.EventJobClaimed)
+	b.Subscribe("all", func(e *models.Event) error { all = append(all, e.Type); return nil })
+
+	if err := b.DispatchPending(); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(jobs, []models.EventType{models.EventJobCreated}) {
+		t.Errorf("jobs subscriber got %v", jobs)
+	}
+	if !slices.Equal(all, []models.EventType{models.EventJobCreated, models.EventBidReceived}) {
+		t.Errorf("catch-all subscriber got %v", all)
+	}
+	if !outbox.published[created.ID] || !outbox.published[bid.ID] {
+		t.Errorf("published = %v", outbox.published)
+	}
+	if err := b.DispatchPending(); err != nil || len(all) != 2 {
+		t.Errorf("published events dispatched again: %v, %v", all, err)
+	}
+}
+
+// Подписчик, который уже обработал событие, при повторной рассылке пропускается;
+// упавший получает его снова
+func TestDispatchRetriesOnlyFailedSubscribers(t *testing.T) {
+	event := jobCreated()
+	outbox := newFakeOutbox(event)
+	b := NewBus(outbox, 5)
+	calls := map[string]int{}
+	fail := true
+	b.Subscribe("feed", func(e *models.Event) error { calls["feed"]++; return nil })
+	b.Subscribe("webhooks", func(e *models.Event) error {
+		calls["webhooks"]++
+		if fail {
+			return errors.New("endpoint is down")
+		}
+		return nil
+	})
+	b.Subscribe("panicking", func(e *models.Event) error {
+		calls["panicking"]++
+		if fail {
+			panic("nil map")
+		}
+		return nil
+	})
+
+	before := time.Now()
+	if err := b.DispatchPending(); err != nil {
+		t.Fatal(err)
+	}
+	next := outbox.failures[event.ID]
+	if outbox.published[event.ID] || next == nil || next.Sub(before) < baseBackoff {
+		t.Fatalf("after a failure: published = %v, next attempt = %v", outbox.published[event.ID], next)
+	}
+
+	fail = false
+	outbox.retry()
+	if err := b.DispatchPending(); err != nil {
+		t.Fatal(err)
+	}
+	if calls["feed"] != 1 || calls["webhooks"] != 2 || calls["panicking"] != 2 {
+		t.Errorf("calls = %v, want feed once and the failed subscribers twice", calls)
+	}
+	if !outbox.published[event.ID] {
+		t.Error("event not published after all subscribers succeeded")
+	}
+}
+
+func TestDispatchGivesUpAfterMaxAttempts(t *testing.T) {
+	event := jobCreated()
+	outbox := newFakeOutbox(event)
+	b := NewBus(outbox, 3)
+	calls := 0
+	b.Subscribe("broken", func(e *models.Event) error { calls++; return errors.New("always fails") })
+
+	for i := 0; i < 5; i++ {
+		b.DispatchPending()
+		if i < 2 {
+			if outbox.failures[event.ID] == nil {
+				t.Fatalf("attempt %d: no retry scheduled", i+1)
+			}
+			outbox.retry()
+		}
+	}
+	if calls != 3 {
+		t.Errorf("handler called %d times, want 3", calls)
+	}
+	if next, failed := outbox.failures[event.ID]; !failed || next != nil {
+		t.Errorf("after the last attempt: next attempt = %v, failed = %v; want the event parked", next, failed)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{1, 5 * time.Second},
+		{2, 10 * time.Second},
+		{3, 20 * time.Second},
+		{10, 5 * time.Second << 9},
+		{11, maxBackoff},
+		{100, maxBackoff},
+		{10000, maxBackoff},
+	}
+	for _, tt := range tests {
+		if got := backoff(tt.attempt); got != tt.want {
+			t.Errorf("backoff(%d) = %v, want %v", tt.attempt, got, tt.want)
+		}
+	}
+}
+
+func TestJobHandler(t *testing.T) {
+	var got *models.Job
+	handler := JobHandler(func(job *models.Job) error { got = job; return nil })
+	if err := handler(jobCreated()); err != nil || got == nil || got.ID != "job" || got.JobTitle != "2 bedroom move" {
+		t.Errorf("decoded job = %+v, %v", got, err)
+	}
+}
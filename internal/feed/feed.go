@@ -0,0 +1,120 @@
+package feed
+
+import (
+	"log/slog"
+	"moveshare/internal/models"
+	"moveshare/internal/repository"
+	"sync"
+	"time"
+)
+
+// subscriberBuffer — сколько событий может накопиться у медленного подписчика,
+// прежде чем новые начнут для него отбрасываться
+const subscriberBuffer = 64
+
+// Publisher сообщает ленте об изменении работы. Публикация не должна
+// мешать основной операции, поэтому ошибки только логируются
+type Publisher interface {
+	Publish(eventType models.JobEventType, jobID string)
+}
+
+// Broker доставляет события ленты всем подписчикам этого экземпляра сервера
+type Broker interface {
+	Publisher
+	Subscribe() (events <-chan models.JobEvent, cancel func())
+}
+
+// hub раздаёт события локальным подписчикам
+type hub struct {
+	mu          sync.Mutex
+	subscribers map[chan models.JobEvent]struct{}
+	jobRepo     repository.JobRepository
+	reviewRepo  repository.ReviewRepository
+}
+
+func newHub(jobRepo repository.JobRepository, reviewRepo repository.ReviewRepository) *hub {
+	return &hub{
+		subscribers: make(map[chan models.JobEvent]struct{}),
+		jobRepo:     jobRepo,
+		reviewRepo:  reviewRepo,
+	}
+}
+
+func (h *hub) Subscribe() (<-chan models.JobEvent, func()) {
+	ch := make(chan models.JobEvent, subscriberBuffer)
+	h.mu.Lock()
+	h.subscribers[ch] = struct{}{}
+	h.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			h.mu.Lock()
+			delete(h.subscribers, ch)
+			h.mu.Unlock()
+			close(ch)
+		})
+	}
+}
+
+// deliver загружает актуальное состояние работы и раздаёт событие подписчикам
+func (h *hub) deliver(eventType models.JobEventType, jobID string) {
+	job, err := h.jobRepo.GetJobByID(jobID)
+	if err != nil {
+		slog.Error("Feed: failed to load job",
+			slog.String("job_id", jobID),
+			slog.String("error", err.Error()))
+		return
+	}
+	event := models.JobEvent{Type: eventType, Job: job, At: time.Now()}
+	if rating, err := h.reviewRepo.GetUserRating(job.PosterID); err == nil {
+		event.PosterRating = rating.Rating
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.subscribers {
+		select {
+		case ch <- event:
+		default:
+			slog.Warn("Feed: subscriber is too slow, event dropped", slog.String("job_id", jobID))
+		}
+	}
+}
+
+type localBroker struct {
+	*hub
+}
+
+// NewLocalBroker — брокер внутри одного процесса. Подходит, если сервер запущен
+// в одном экземпляре; иначе нужен NewPostgresBroker
+func NewLocalBroker(jobRepo repository.JobRepository, reviewRepo repository.ReviewRepository) Broker {
+	return &localBroker{hub: newHub(jobRepo, reviewRepo)}
+}
+
+func (b *localBroker) Publish(eventType models.JobEventType, jobID string) {
+	go b.deliver(eventType, jobID)
+}
+
+// feedEvents — доменные события, которые попадают в ленту
+var feedEvents = map[models.EventType]models.JobEventType{
+	models.EventJobCreated:   models.JobEventCreated,
+	models.EventJobClaimed:   models.JobEventClaimed,
+	models.EventJobCancelled: models.JobEventCancelled,
+}
+
+// Subscriber пересылает доменные события работ из outbox в ленту
+func Subscriber(publisher Publisher) func(event *models.Event) error {
+	return func(event *models.Event) error {
+		eventType, ok := feedEvents[event.Type]
+		if !ok {
+			return nil
+		}
+		var job models.Job
+		if err := event.Decode(&job); err != nil {
+			return err
+		}
+		publisher.Publish(eventType, job.ID)
+		return nil
+	}
+}
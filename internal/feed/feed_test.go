@@ -0,0 +1,183 @@
+package feed
+
+import (
+	"moveshare/internal/models"
+	"moveshare/internal/repository"
+	"testing"
+	"time"
+)
+
+func feedJob() *models.Job {
+	companyID := 10
+	return &models.Job{
+		ID:               "job",
+		PosterID:         1,
+		CompanyID:        &companyID,
+		NumberOfBedrooms: models.TwoBedrooms,
+		TruckSize:        models.MediumTruck,
+		Status:           models.JobStatusOpen,
+		PickupDateTime:   time.Date(2026, 11, 2, 9, 0, 0, 0, time.UTC),
+		DeliveryDateTime: time.Date(2026, 11, 3, 17, 0, 0, 0, time.UTC),
+		PaymentAmount:    models.NewMoney(150000, "USD"),
+		PickupAddress:    models.Address{ZIP: "10001", Latitude: 40.7506, Longitude: -73.9972},
+		DeliveryAddress:  models.Address{ZIP: "19103", Latitude: 39.9525, Longitude: -75.1740},
+	}
+}
+
+func TestMatches(t *testing.T) {
+	at := func(day, hour int) *time.Time {
+		v := time.Date(2026, 11, day, hour, 0, 0, 0, time.UTC)
+		return &v
+	}
+	money := func(amount int64, currency string) *models.Money {
+		v := models.NewMoney(amount, currency)
+		return &v
+	}
+	rating := func(v float64) *float64 { return &v }
+	tests := []struct {
+		name   string
+		filter models.JobFilter
+		want   bool
+	}{
+		{"no filter", models.JobFilter{}, true},
+		{"bedrooms", models.JobFilter{NumberOfBedrooms: "2"}, true},
+		{"other bedrooms", models.JobFilter{NumberOfBedrooms: "office"}, false},
+		{"truck size", models.JobFilter{TruckSize: "large"}, false},
+		{"pickup after date_start", models.JobFilter{DateStart: at(2, 9)}, true},
+		{"pickup before date_start", models.JobFilter{DateStart: at(2, 10)}, false},
+		{"delivery before date_end", models.JobFilter{DateEnd: at(3, 17)}, true},
+		{"delivery after date_end", models.JobFilter{DateEnd: at(3, 16)}, false},
+		{"payout in range", models.JobFilter{PayoutMin: money(150000, "USD"), PayoutMax: money(150000, "USD")}, true},
+		{"payout below min", models.JobFilter{PayoutMin: money(150001, "USD")}, false},
+		{"payout above max", models.JobFilter{PayoutMax: money(149999, "USD")}, false},
+		{"payout in another currency", models.JobFilter{PayoutMin: money(100, "CAD")}, false},
+		{"status", models.JobFilter{Status: "claimed"}, false},
+		{"company", models.JobFilter{CompanyID: 10}, true},
+		{"other company", models.JobFilter{CompanyID: 11}, false},
+		{"poster rating", models.JobFilter{MinPosterRating: rating(4.5)}, true},
+		{"poster rating too low", models.JobFilter{MinPosterRating: rating(4.9)}, false},
+		{"origin radius", models.JobFilter{Origin: &models.GeoRadius{Latitude: 40.7128, Longitude: -74.0060, Miles: 10}}, true},
+		{"origin too far", models.JobFilter{Origin: &models.GeoRadius{Latitude: 41.8858, Longitude: -87.6181, Miles: 100}}, false},
+		{"destination radius", models.JobFilter{Destination: &models.GeoRadius{Latitude: 40.7506, Longitude: -73.9972, Miles: 90}}, true},
+		{"destination too far", models.JobFilter{Destination: &models.GeoRadius{Latitude: 40.7506, Longitude: -73.9972, Miles: 50}}, false},
+	}
+	for _, tt := range tests {
+		event := models.JobEvent{Type: models.JobEventCreated, Job: feedJob(), PosterRating: 4.7}
+		if got := Matches(tt.filter, event); got != tt.want {
+			t.Errorf("%s: Matches = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	// Заказчики без отзывов под фильтр по рейтингу не попадают, как и в SQL
+	unrated := models.JobEvent{Job: feedJob()}
+	if Matches(models.JobFilter{MinPosterRating: rating(0)}, unrated) {
+		t.Error("poster without reviews matched min_poster_rating")
+	}
+	noCoordinates := models.JobEvent{Job: feedJob()}
+	noCoordinates.Job.PickupAddress = models.Address{ZIP: "10001"}
+	if Matches(models.JobFilter{Origin: &models.GeoRadius{Latitude: 40.7506, Longitude: -73.9972, Miles: 10}}, noCoordinates) {
+		t.Error("job without coordinates matched a radius filter")
+	}
+}
+
+type feedJobRepo struct {
+	repository.JobRepository
+}
+
+func (feedJobRepo) GetJobByID(id string) (*models.Job, error) {
+	if id != "job" {
+		return nil, repository.ErrJobNotFound
+	}
+	return feedJob(), nil
+}
+
+type feedReviewRepo struct {
+	repository.ReviewRepository
+}
+
+func (feedReviewRepo) GetUserRating(userID int) (*models.RatingSummary, error) {
+	return &models.RatingSummary{Rating: 4.2, ReviewCount: 5}, nil
+}
+
+func receive(t *testing.T, events <-chan models.JobEvent) models.JobEvent {
+	t.Helper()
+	select {
+	case event := <-events:
+		return event
+	case <-time.After(time.Second):
+		t.Fatal("no event delivered")
+		return models.JobEvent{}
+	}
+}
+
+func TestLocalBroker(t *testing.T) {
+	broker := NewLocalBroker(feedJobRepo{}, feedReviewRepo{})
+	first, cancelFirst := broker.Subscribe()
+	second, cancelSecond := broker.Subscribe()
+	defer cancelSecond()
+
+	broker.Publish(models.JobEventCreated, "job")
+	for _, events := range []<-chan models.JobEvent{first, second} {
+		event := receive(t, events)
+		if event.Type != models.JobEventCreated || event.Job.ID != "job" || event.PosterRating != 4.2 {
+			t.Errorf("event = %+v", event)
+		}
+	}
+
+	cancelFirst()
+	cancelFirst()
+	if _, open := <-first; open {
+		t.Error("channel of a cancelled subscription is still open")
+	}
+	broker.Publish(models.JobEventClaimed, "job")
+	if event := receive(t, second); event.Type != models.JobEventClaimed {
+		t.Errorf("event after another subscriber left = %+v", event)
+	}
+}
+
+// Медленный подписчик теряет события сверх буфера, но не задерживает рассылку
+func TestSlowSubscriberDropsEvents(t *testing.T) {
+	h := newHub(feedJobRepo{}, feedReviewRepo{})
+	events, cancel := h.Subscribe()
+	defer cancel()
+	for i := 0; i < subscriberBuffer+10; i++ {
+		h.deliver(models.JobEventCreated, "job")
+	}
+	if len(events) != subscriberBuffer {
+		t.Errorf("buffered %d events, want %d", len(events), subscriberBuffer)
+	}
+	h.deliver(models.JobEventCreated, "missing")
+	if len(events) != subscriberBuffer {
+		t.Error("event for a missing job delivered")
+	}
+}
+
+type recordingPublisher struct {
+	published []models.JobEventType
+}
+
+func (p *recordingPublisher) Publish(eventType models.JobEventType, jobID string) {
+	p.published = append(p.published, eventType)
+}
+
+func TestSubscriber(t *testing.T) {
+	tests := []struct {
+		event models.EventType
+		want  models.JobEventType
+	}{
+		{models.EventJobCreated, models.JobEventCreated},
+		{models.EventJobClaimed, models.JobEventClaimed},
+		{models.EventJobCancelled, models.JobEventCancelled},
+		{models.EventJobCompleted, ""},
+		{models.EventBidReceived, ""},
+	}
+	for _, tt := range tests {
+		publisher := &recordingPublisher{}
+		if err := Subscriber(publisher)(models.NewEvent(tt.event, feedJob(), models.EventAudience{}, "")); err != nil {
+			t.Fatal(err)
+		}
+		if tt.want == "" && len(publisher.published) != 0 || tt.want != "" && (len(publisher.published) != 1 || publisher.published[0] != tt.want) {
+			t.Errorf("%s: published %v, want %q", tt.event, publisher.published, tt.want)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package feed
+
+import (
+	"moveshare/internal/geo"
+	"moveshare/internal/models"
+)
+
+// Matches проверяет событие теми же условиями, что GET /jobs применяет в SQL.
+// Координаты GeoRadius к этому моменту должны быть определены (ZIP уже геокодирован)
+func Matches(filter models.JobFilter, event models.JobEvent) bool {
+	job := event.Job
+	if filter.NumberOfBedrooms != "" && string(job.NumberOfBedrooms) != filter.NumberOfBedrooms {
+		return false
+	}
+	if filter.TruckSize != "" && string(job.TruckSize) != filter.TruckSize {
+		return false
+	}
+	if filter.DateStart != nil && job.PickupDateTime.Before(*filter.DateStart) {
+		return false
+	}
+	if filter.DateEnd != nil && job.DeliveryDateTime.After(*filter.DateEnd) {
+		return false
+	}
+	if filter.PayoutMin != nil && (!job.PaymentAmount.SameCurrency(*filter.PayoutMin) || job.PaymentAmount.Amount < filter.PayoutMin.Amount) {
+		return false
+	}
+	if filter.PayoutMax != nil && (!job.PaymentAmount.SameCurrency(*filter.PayoutMax) || job.PaymentAmount.Amount > filter.PayoutMax.Amount) {
+		return false
+	}
+	if filter.Status != "" && string(job.Status) != filter.Status {
+		return false
+	}
+	if filter.CompanyID != 0 && (job.CompanyID == nil || *job.CompanyID != filter.CompanyID) {
+		return false
+	}
+	if filter.MinPosterRating != nil && (event.PosterRating == 0 || event.PosterRating < *filter.MinPosterRating) {
+		return false
+	}
+	if !withinRadius(filter.Origin, job.PickupAddress) {
+		return false
+	}
+	if !withinRadius(filter.Destination, job.DeliveryAddress) {
+		return false
+	}
+	return true
+}
+
+func withinRadius(radius *models.GeoRadius, addr models.Address) bool {
+	if radius == nil {
+		return true
+	}
+	if !addr.HasCoordinates() {
+		return false
+	}
+	return geo.HaversineMiles(radius.Latitude, radius.Longitude, addr.Latitude, addr.Longitude) <= radius.Miles
+}
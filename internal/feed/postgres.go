@@ -0,0 +1,99 @@
+package feed
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log/slog"
+	"moveshare/internal/models"
+	"moveshare/internal/repository"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// notifyChannel — канал LISTEN/NOTIFY для событий ленты
+const notifyChannel = "job_events"
+
+// notification — полезная нагрузка NOTIFY. Саму работу не передаём:
+// NOTIFY ограничен 8000 байтами, а каждый экземпляр всё равно читает её из БД
+type notification struct {
+	Type  models.JobEventType `json:"type"`
+	JobID string              `json:"job_id"`
+}
+
+type postgresBroker struct {
+	*hub
+	db *sql.DB
+}
+
+// NewPostgresBroker — брокер на Postgres LISTEN/NOTIFY: событие, опубликованное
+// любым экземпляром сервера, получают подписчики всех экземпляров.
+// Слушает канал на отдельном соединении, пока не отменён ctx
+func NewPostgresBroker(ctx context.Context, databaseURL string, db *sql.DB, jobRepo repository.JobRepository, reviewRepo repository.ReviewRepository) Broker {
+	b := &postgresBroker{hub: newHub(jobRepo, reviewRepo), db: db}
+	go b.listen(ctx, databaseURL)
+	return b
+}
+
+func (b *postgresBroker) Publish(eventType models.JobEventType, jobID string) {
+	payload, err := json.Marshal(notification{Type: eventType, JobID: jobID})
+	if err != nil {
+		slog.Error("Feed: failed to encode notification", slog.String("error", err.Error()))
+		return
+	}
+	if _, err := b.db.Exec(`SELECT pg_notify($1, $2)`, notifyChannel, string(payload)); err != nil {
+		slog.Error("Feed: failed to publish event",
+			slog.String("job_id", jobID),
+			slog.String("error", err.Error()))
+	}
+}
+
+// listen держит соединение с LISTEN и переподключается при обрыве
+func (b *postgresBroker) listen(ctx context.Context, databaseURL string) {
+	backoff := time.Second
+	for {
+		err := b.listenOnce(ctx, databaseURL, func() { backoff = time.Second })
+		if ctx.Err() != nil {
+			return
+		}
+		slog.Error("Feed: listener disconnected",
+			slog.String("error", err.Error()),
+			slog.Duration("retry_in", backoff))
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		if backoff < 30*time.Second {
+			backoff *= 2
+		}
+	}
+}
+
+func (b *postgresBroker) listenOnce(ctx context.Context, databaseURL string, connected func()) error {
+	conn, err := pgx.Connect(ctx, databaseURL)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+notifyChannel); err != nil {
+		return err
+	}
+	connected()
+	slog.Info("Feed: listening for job events", slog.String("channel", notifyChannel))
+
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		var msg notification
+		if err := json.Unmarshal([]byte(n.Payload), &msg); err != nil {
+			slog.Error("Feed: malformed notification", slog.String("payload", n.Payload))
+			continue
+		}
+		b.deliver(msg.Type, msg.JobID)
+	}
+}
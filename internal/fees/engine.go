@@ -0,0 +1,245 @@
+package fees
+
+import (
+	"errors"
+	"moveshare/internal/models"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidRules      = errors.New("invalid fee rules")
+	ErrFeesExceedPayment = errors.New("fees exceed payment")
+)
+
+// bpsDenominator — 10000 базисных пунктов = 100%
+const bpsDenominator = 10000
+
+// Input — то, от чего зависит расчёт
+type Input struct {
+	Payment   models.Money
+	Size      models.NumberOfBedrooms
+	PromoCode string
+	// At — момент расчёта; по нему проверяются сроки действия правил
+	At time.Time
+}
+
+// Calculate считает удержания из оплаты по набору правил. Сначала складываются
+// обычные правила каждого компонента, затем применяются акции; остаток оплаты —
+// выплата перевозчику. Правила в другой валюте, чем оплата, не применяются
+func Calculate(set *models.FeeRuleSet, in Input) (*models.FeeBreakdown, error) {
+	payment := in.Payment.WithDefaultCurrency()
+	if payment.IsNegative() || !payment.Valid() {
+		return nil, models.ErrInvalidMoney
+	}
+	breakdown := &models.FeeBreakdown{
+		RuleSetID:      set.ID,
+		RuleSetVersion: set.Version,
+		Payment:        payment,
+		Lines:          []models.FeeLine{},
+		CalculatedAt:   in.At,
+	}
+
+	totals := map[models.FeeComponent]int64{}
+	for _, promotions := range []bool{false, true} {
+		for _, rule := range set.Rules {
+			if (rule.Kind == models.FeePromotion) != promotions || !applies(rule, in, payment) {
+				continue
+			}
+			amount := ruleAmount(rule, payment.Amount, totals[rule.Component])
+			if amount == 0 {
+				continue
+			}
+			totals[rule.Component] += amount
+			breakdown.Lines = append(breakdown.Lines, models.FeeLine{
+				Component:   rule.Component,
+				Kind:        rule.Kind,
+				Description: rule.Description,
+				Amount:      models.NewMoney(amount, payment.Currency),
+			})
+			if rule.PromoCode != "" {
+				breakdown.PromoCode = rule.PromoCode
+			}
+		}
+	}
+
+	platform, broker := totals[models.FeePlatform], totals[models.FeeBrokerCut]
+	net := payment.Amount - platform - broker
+	if net < 0 {
+		return nil, ErrFeesExceedPayment
+	}
+	breakdown.PlatformFee = models.NewMoney(platform, payment.Currency)
+	breakdown.BrokerCut = models.NewMoney(broker, payment.Currency)
+	breakdown.CarrierNet = models.NewMoney(net, payment.Currency)
+	return breakdown, nil
+}
+
+// ruleAmount — вклад правила. Для акции — отрицательная скидка, не больше
+// уже посчитанной суммы компонента current
+func ruleAmount(rule models.FeeRule, payment, current int64) int64 {
+	var amount int64
+	switch rule.Kind {
+	case models.FeePercentage:
+		amount = applyBPS(payment, rule.BPS)
+	case models.FeeFlat:
+		amount = flatAmount(rule.Flat)
+	case models.FeeTiered:
+		for _, tier := range rule.Tiers {
+			if tier.UpTo == nil || payment <= tier.UpTo.Amount {
+				amount = applyBPS(payment, tier.BPS) + flatAmount(tier.Flat)
+				break
+			}
+		}
+	case models.FeePromotion:
+		amount = applyBPS(current, rule.BPS) + flatAmount(rule.Flat)
+	}
+	if rule.Min != nil && amount < rule.Min.Amount {
+		amount = rule.Min.Amount
+	}
+	if rule.Max != nil && amount > rule.Max.Amount {
+		amount = rule.Max.Amount
+	}
+	if rule.Kind == models.FeePromotion {
+		if amount > current {
+			amount = current
+		}
+		return -amount
+	}
+	return amount
+}
+
+// applies — правило действует для этой работы в момент расчёта
+func applies(rule models.FeeRule, in Input, payment models.Money) bool {
+	if rule.ValidFrom != nil && in.At.Before(*rule.ValidFrom) {
+		return false
+	}
+	if rule.ValidUntil != nil && !in.At.Before(*rule.ValidUntil) {
+		return false
+	}
+	if rule.PromoCode != "" && !strings.EqualFold(rule.PromoCode, strings.TrimSpace(in.PromoCode)) {
+		return false
+	}
+	if len(rule.Sizes) > 0 && !containsSize(rule.Sizes, in.Size) {
+		return false
+	}
+	for _, m := range ruleAmounts(rule) {
+		if !m.SameCurrency(payment) {
+			return false
+		}
+	}
+	return true
+}
+
+// Validate проверяет набор правил перед сохранением
+func Validate(rules []models.FeeRule) error {
+	if len(rules) == 0 {
+		return ErrInvalidRules
+	}
+	for _, rule := range rules {
+		if rule.Component != models.FeePlatform && rule.Component != models.FeeBrokerCut {
+			return ErrInvalidRules
+		}
+		if rule.BPS < 0 || rule.BPS > bpsDenominator {
+			return ErrInvalidRules
+		}
+		for _, m := range ruleAmounts(rule) {
+			if m.IsNegative() || !m.Valid() {
+				return ErrInvalidRules
+			}
+		}
+		if rule.Min != nil && rule.Max != nil && rule.Min.Amount > rule.Max.Amount {
+			return ErrInvalidRules
+		}
+		if rule.ValidFrom != nil && rule.ValidUntil != nil && !rule.ValidFrom.Before(*rule.ValidUntil) {
+			return ErrInvalidRules
+		}
+		switch rule.Kind {
+		case models.FeePercentage:
+			if rule.BPS == 0 {
+				return ErrInvalidRules
+			}
+		case models.FeeFlat:
+			if rule.Flat == nil {
+				return ErrInvalidRules
+			}
+		case models.FeeTiered:
+			if err := validateTiers(rule.Tiers); err != nil {
+				return err
+			}
+		case models.FeePromotion:
+			if rule.BPS == 0 && rule.Flat == nil {
+				return ErrInvalidRules
+			}
+		default:
+			return ErrInvalidRules
+		}
+	}
+	return nil
+}
+
+// validateTiers — ступени идут по возрастанию up_to, последняя — без up_to,
+// чтобы любая оплата попадала в какую-то ступень
+func validateTiers(tiers []models.FeeTier) error {
+	if len(tiers) == 0 || tiers[len(tiers)-1].UpTo != nil {
+		return ErrInvalidRules
+	}
+	var prev *models.Money
+	for i, tier := range tiers {
+		if tier.BPS < 0 || tier.BPS > bpsDenominator {
+			return ErrInvalidRules
+		}
+		if tier.UpTo == nil {
+			if i != len(tiers)-1 {
+				return ErrInvalidRules
+			}
+			continue
+		}
+		if prev != nil && tier.UpTo.Amount <= prev.Amount {
+			return ErrInvalidRules
+		}
+		prev = tier.UpTo
+	}
+	return nil
+}
+
+// ruleAmounts — все суммы правила; они должны быть в валюте оплаты
+func ruleAmounts(rule models.FeeRule) []models.Money {
+	var amounts []models.Money
+	for _, m := range []*models.Money{rule.Flat, rule.Min, rule.Max} {
+		if m != nil {
+			amounts = append(amounts, *m)
+		}
+	}
+	for _, tier := range rule.Tiers {
+		for _, m := range []*models.Money{tier.UpTo, tier.Flat} {
+			if m != nil {
+				amounts = append(amounts, *m)
+			}
+		}
+	}
+	return amounts
+}
+
+// applyBPS — bps базисных пунктов от amount с округлением половины вверх
+func applyBPS(amount, bps int64) int64 {
+	if amount <= 0 || bps == 0 {
+		return 0
+	}
+	return (amount*bps + bpsDenominator/2) / bpsDenominator
+}
+
+func flatAmount(m *models.Money) int64 {
+	if m == nil {
+		return 0
+	}
+	return m.Amount
+}
+
+func containsSize(sizes []models.NumberOfBedrooms, size models.NumberOfBedrooms) bool {
+	for _, s := range sizes {
+		if s == size {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,198 @@
+package fees
+
+import (
+	"moveshare/internal/models"
+	"testing"
+	"time"
+)
+
+func usd(amount int64) *models.Money {
+	m := models.NewMoney(amount, "USD")
+	return &m
+}
+
+var at = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+// tieredPlatform — 10% до 500.00, 8% + 5.00 до 2000.00, дальше 6%
+var tieredPlatform = models.FeeRule{
+	Component: models.FeePlatform,
+	Kind:      models.FeeTiered,
+	Tiers: []models.FeeTier{
+		{UpTo: usd(50000), BPS: 1000},
+		{UpTo: usd(200000), BPS: 800, Flat: usd(500)},
+		{BPS: 600},
+	},
+}
+
+func TestCalculate(t *testing.T) {
+	tests := []struct {
+		name     string
+		rules    []models.FeeRule
+		payment  int64
+		size     models.NumberOfBedrooms
+		promo    string
+		platform int64
+		broker   int64
+	}{
+		{
+			name:     "percentage rounds half up",
+			rules:    []models.FeeRule{{Component: models.FeePlatform, Kind: models.FeePercentage, BPS: 250}},
+			payment:  1020, // 2.5% от 10.20 = 0.255
+			platform: 26,
+		},
+		{
+			name:     "percentage rounds down below half",
+			rules:    []models.FeeRule{{Component: models.FeePlatform, Kind: models.FeePercentage, BPS: 250}},
+			payment:  1019, // 0.25475
+			platform: 25,
+		},
+		{
+			name:     "first tier includes its bound",
+			rules:    []models.FeeRule{tieredPlatform},
+			payment:  50000,
+			platform: 5000,
+		},
+		{
+			name:     "second tier adds flat",
+			rules:    []models.FeeRule{tieredPlatform},
+			payment:  50001,
+			platform: 4000 + 500,
+		},
+		{
+			name:     "open-ended last tier",
+			rules:    []models.FeeRule{tieredPlatform},
+			payment:  1000000,
+			platform: 60000,
+		},
+		{
+			name: "min and max clamp the rule",
+			rules: []models.FeeRule{
+				{Component: models.FeePlatform, Kind: models.FeePercentage, BPS: 100, Min: usd(1000)},
+				{Component: models.FeeBrokerCut, Kind: models.FeePercentage, BPS: 5000, Max: usd(2500)},
+			},
+			payment:  20000,
+			platform: 1000,
+			broker:   2500,
+		},
+		{
+			name: "promotion applies after regular rules",
+			rules: []models.FeeRule{
+				{Component: models.FeePlatform, Kind: models.FeePromotion, BPS: 5000, PromoCode: "HALF"},
+				{Component: models.FeePlatform, Kind: models.FeePercentage, BPS: 1000},
+			},
+			payment:  100000,
+			promo:    " half ",
+			platform: 5000,
+		},
+		{
+			name: "promotion without its code",
+			rules: []models.FeeRule{
+				{Component: models.FeePlatform, Kind: models.FeePercentage, BPS: 1000},
+				{Component: models.FeePlatform, Kind: models.FeePromotion, BPS: 5000, PromoCode: "HALF"},
+			},
+			payment:  100000,
+			platform: 10000,
+		},
+		{
+			name: "promotion does not go below zero",
+			rules: []models.FeeRule{
+				{Component: models.FeePlatform, Kind: models.FeeFlat, Flat: usd(300)},
+				{Component: models.FeePlatform, Kind: models.FeePromotion, Flat: usd(1000)},
+			},
+			payment:  100000,
+			platform: 0,
+		},
+		{
+			name: "size-specific rule",
+			rules: []models.FeeRule{
+				{Component: models.FeePlatform, Kind: models.FeePercentage, BPS: 1000},
+				{Component: models.FeeBrokerCut, Kind: models.FeeFlat, Flat: usd(2000), Sizes: []models.NumberOfBedrooms{models.FivePlus}},
+			},
+			payment:  100000,
+			size:     models.TwoBedrooms,
+			platform: 10000,
+		},
+		{
+			name: "rule in another currency is skipped",
+			rules: []models.FeeRule{
+				{Component: models.FeePlatform, Kind: models.FeePercentage, BPS: 1000},
+				{Component: models.FeeBrokerCut, Kind: models.FeeFlat, Flat: &models.Money{Amount: 2000, Currency: "EUR"}},
+			},
+			payment:  100000,
+			platform: 10000,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Validate(tt.rules); err != nil {
+				t.Fatalf("Validate: %v", err)
+			}
+			set := &models.FeeRuleSet{ID: "set", Version: 1, Rules: tt.rules}
+			got, err := Calculate(set, Input{Payment: *usd(tt.payment), Size: tt.size, PromoCode: tt.promo, At: at})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.PlatformFee.Amount != tt.platform || got.BrokerCut.Amount != tt.broker {
+				t.Errorf("platform = %d, broker = %d; want %d, %d", got.PlatformFee.Amount, got.BrokerCut.Amount, tt.platform, tt.broker)
+			}
+			if net := tt.payment - tt.platform - tt.broker; got.CarrierNet.Amount != net {
+				t.Errorf("carrier net = %d, want %d", got.CarrierNet.Amount, net)
+			}
+			var lines int64
+			for _, line := range got.Lines {
+				lines += line.Amount.Amount
+			}
+			if lines != tt.platform+tt.broker {
+				t.Errorf("lines sum to %d, want %d", lines, tt.platform+tt.broker)
+			}
+		})
+	}
+}
+
+func TestCalculateValidityWindow(t *testing.T) {
+	from, until := at.Add(-time.Hour), at
+	set := &models.FeeRuleSet{Rules: []models.FeeRule{
+		{Component: models.FeePlatform, Kind: models.FeePercentage, BPS: 1000, ValidFrom: &from, ValidUntil: &until},
+	}}
+	got, _ := Calculate(set, Input{Payment: *usd(10000), At: at.Add(-time.Minute)})
+	if got.PlatformFee.Amount != 1000 {
+		t.Errorf("inside the window: platform = %d, want 1000", got.PlatformFee.Amount)
+	}
+	// ValidUntil не включается
+	got, _ = Calculate(set, Input{Payment: *usd(10000), At: at})
+	if got.PlatformFee.Amount != 0 {
+		t.Errorf("at valid_until: platform = %d, want 0", got.PlatformFee.Amount)
+	}
+}
+
+func TestCalculateRejects(t *testing.T) {
+	set := &models.FeeRuleSet{Rules: []models.FeeRule{{Component: models.FeePlatform, Kind: models.FeeFlat, Flat: usd(5000)}}}
+	if _, err := Calculate(set, Input{Payment: *usd(4999), At: at}); err != ErrFeesExceedPayment {
+		t.Errorf("fees above payment: err = %v, want ErrFeesExceedPayment", err)
+	}
+	if _, err := Calculate(set, Input{Payment: *usd(-1), At: at}); err != models.ErrInvalidMoney {
+		t.Errorf("negative payment: err = %v, want ErrInvalidMoney", err)
+	}
+}
+
+func TestValidateTiers(t *testing.T) {
+	tests := []struct {
+		name  string
+		tiers []models.FeeTier
+		valid bool
+	}{
+		{"ascending with open last tier", tieredPlatform.Tiers, true},
+		{"single open tier", []models.FeeTier{{BPS: 500}}, true},
+		{"empty", nil, false},
+		{"every tier bounded", []models.FeeTier{{UpTo: usd(50000), BPS: 1000}, {UpTo: usd(200000), BPS: 800}}, false},
+		{"open tier in the middle", []models.FeeTier{{BPS: 1000}, {UpTo: usd(200000), BPS: 800}, {BPS: 600}}, false},
+		{"bounds not ascending", []models.FeeTier{{UpTo: usd(50000), BPS: 1000}, {UpTo: usd(50000), BPS: 800}, {BPS: 600}}, false},
+		{"bps above 100%", []models.FeeTier{{BPS: 10001}}, false},
+	}
+	for _, tt := range tests {
+		rules := []models.FeeRule{{Component: models.FeePlatform, Kind: models.FeeTiered, Tiers: tt.tiers}}
+		if err := Validate(rules); (err == nil) != tt.valid {
+			t.Errorf("%s: Validate err = %v, want valid %v", tt.name, err, tt.valid)
+		}
+	}
+}
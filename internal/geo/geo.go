@@ -0,0 +1,31 @@
+package geo
+
+import (
+	"errors"
+	"math"
+	"moveshare/internal/models"
+)
+
+// EarthRadiusMiles — средний радиус Земли в милях
+const EarthRadiusMiles = 3958.8
+
+var ErrAddressNotFound = errors.New("address not found")
+
+// Geocoder определяет координаты адреса
+type Geocoder interface {
+	Geocode(addr models.Address) (lat, lng float64, err error)
+}
+
+// HaversineMiles возвращает расстояние по дуге большого круга между двумя точками
+func HaversineMiles(lat1, lng1, lat2, lng2 float64) float64 {
+	dLat := toRadians(lat2 - lat1)
+	dLng := toRadians(lng2 - lng1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRadians(lat1))*math.Cos(toRadians(lat2))*math.Sin(dLng/2)*math.Sin(dLng/2)
+	// Для почти противоположных точек погрешность может дать a чуть больше 1
+	return 2 * EarthRadiusMiles * math.Asin(math.Sqrt(math.Min(1, a)))
+}
+
+func toRadians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
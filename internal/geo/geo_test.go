@@ -0,0 +1,81 @@
+package geo
+
+import (
+	"errors"
+	"math"
+	"moveshare/internal/models"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHaversineMiles(t *testing.T) {
+	tests := []struct {
+		name                   string
+		lat1, lng1, lat2, lng2 float64
+		want, tolerance        float64
+	}{
+		{"same point", 40.7506, -73.9972, 40.7506, -73.9972, 0, 0},
+		{"New York to Philadelphia", 40.7506, -73.9972, 39.9522, -75.1741, 81, 2},
+		{"New York to Los Angeles", 40.7128, -74.0060, 34.0522, -118.2437, 2445, 5},
+		{"one degree of latitude", 0, 0, 1, 0, 69.09, 0.01},
+		{"antipodes", 0, 0, 0, 180, math.Pi * EarthRadiusMiles, 0.001},
+	}
+	for _, tt := range tests {
+		got := HaversineMiles(tt.lat1, tt.lng1, tt.lat2, tt.lng2)
+		if math.Abs(got-tt.want) > tt.tolerance {
+			t.Errorf("%s: %.2f miles, want %.2f ± %.2f", tt.name, got, tt.want, tt.tolerance)
+		}
+		if back := HaversineMiles(tt.lat2, tt.lng2, tt.lat1, tt.lng1); math.Abs(back-got) > 1e-9 {
+			t.Errorf("%s: distance is not symmetric: %.6f vs %.6f", tt.name, got, back)
+		}
+	}
+}
+
+func TestZIPGeocoder(t *testing.T) {
+	g := NewZIPGeocoder()
+	tests := []struct {
+		zip      string
+		lat, lng float64
+		err      error
+	}{
+		{"10001", 40.7506, -73.9972, nil},
+		{" 10001 ", 40.7506, -73.9972, nil},
+		{"10001-1234", 40.7506, -73.9972, nil},
+		{"99999", 0, 0, ErrAddressNotFound},
+		{"", 0, 0, ErrAddressNotFound},
+	}
+	for _, tt := range tests {
+		lat, lng, err := g.Geocode(models.Address{ZIP: tt.zip})
+		if !errors.Is(err, tt.err) || lat != tt.lat || lng != tt.lng {
+			t.Errorf("Geocode(%q) = %v, %v, %v; want %v, %v, %v", tt.zip, lat, lng, err, tt.lat, tt.lng, tt.err)
+		}
+	}
+}
+
+func TestLoadZIPGeocoder(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "zips.csv")
+	os.WriteFile(path, []byte("zip,latitude,longitude\n12345,42.81,-73.94\n"), 0o600)
+	g, err := LoadZIPGeocoder(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lat, lng, err := g.Geocode(models.Address{ZIP: "12345"}); err != nil || lat != 42.81 || lng != -73.94 {
+		t.Errorf("Geocode = %v, %v, %v", lat, lng, err)
+	}
+
+	for name, content := range map[string]string{
+		"too few columns": "12345,42.81\n",
+		"bad latitude":    "12345,north,-73.94\n",
+		"bad longitude":   "12345,42.81,west\n",
+	} {
+		if _, err := parseZIPCentroids(strings.NewReader(content)); err == nil {
+			t.Errorf("%s: table accepted", name)
+		}
+	}
+	if _, err := LoadZIPGeocoder(filepath.Join(dir, "missing.csv")); err == nil {
+		t.Error("missing file accepted")
+	}
+}
@@ -0,0 +1,88 @@
+package geo
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"moveshare/internal/models"
+	"os"
+	"strconv"
+	"strings"
+)
+
+//go:embed zip_centroids.csv
+var defaultZIPCentroids []byte
+
+type point struct {
+	lat, lng float64
+}
+
+// zipGeocoder — офлайн-геокодер по таблице центроидов почтовых индексов.
+// Точность — уровень ZIP-кода, чего достаточно для поиска грузов по радиусу.
+type zipGeocoder struct {
+	centroids map[string]point
+}
+
+// NewZIPGeocoder создаёт геокодер по встроенной таблице центроидов
+func NewZIPGeocoder() Geocoder {
+	g, err := parseZIPCentroids(bytes.NewReader(defaultZIPCentroids))
+	if err != nil {
+		panic(fmt.Sprintf("geo: broken embedded zip table: %v", err))
+	}
+	return g
+}
+
+// LoadZIPGeocoder загружает таблицу центроидов из CSV-файла
+// с колонками zip,latitude,longitude (остальные колонки игнорируются)
+func LoadZIPGeocoder(path string) (Geocoder, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return parseZIPCentroids(f)
+}
+
+func parseZIPCentroids(r io.Reader) (*zipGeocoder, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	g := &zipGeocoder{centroids: make(map[string]point, len(records))}
+	for i, rec := range records {
+		if i == 0 && rec[0] == "zip" {
+			continue
+		}
+		if len(rec) < 3 {
+			return nil, fmt.Errorf("line %d: expected zip,latitude,longitude", i+1)
+		}
+		lat, err := strconv.ParseFloat(rec[1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		lng, err := strconv.ParseFloat(rec[2], 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		g.centroids[rec[0]] = point{lat: lat, lng: lng}
+	}
+	return g, nil
+}
+
+func (g *zipGeocoder) Geocode(addr models.Address) (float64, float64, error) {
+	zip := strings.TrimSpace(addr.ZIP)
+	// ZIP+4 -> ZIP5
+	if len(zip) > 5 {
+		zip = zip[:5]
+	}
+	p, ok := g.centroids[zip]
+	if !ok {
+		return 0, 0, ErrAddressNotFound
+	}
+	return p.lat, p.lng, nil
+}
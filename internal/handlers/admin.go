@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"moveshare/internal/middleware"
+	"moveshare/internal/models"
+	"moveshare/internal/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// AdminHandler отвечает за эндпоинты модерации
+type AdminHandler struct {
+	AdminService services.AdminService
+}
+
+func NewAdminHandler(adminService services.AdminService) *AdminHandler {
+	return &AdminHandler{AdminService: adminService}
+}
+
+// ListUsers godoc
+// @Summary Список пользователей
+// @Tags admin
+// @Produce  json
+// @Param limit query int false "Лимит (по умолчанию 50)"
+// @Param offset query int false "Смещение (по умолчанию 0)"
+// @Success 200 {object} models.UserListResponse
+// @Failure 401 {string} string "unauthorized"
+// @Failure 403 {string} string "forbidden"
+// @Failure 500 {string} string "failed to fetch users"
+// @Security BearerAuth
+// @Router /admin/users [get]
+func (h *AdminHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	limit := 50
+	offset := 0
+	if v := q.Get("limit"); v != "" {
+		if i, err := strconv.Atoi(v); err == nil {
+			limit = i
+		}
+	}
+	if v := q.Get("offset"); v != "" {
+		if i, err := strconv.Atoi(v); err == nil {
+			offset = i
+		}
+	}
+
+	users, total, err := h.AdminService.ListUsers(limit, offset)
+	if err != nil {
+		http.Error(w, "failed to fetch users", http.StatusInternalServerError)
+		return
+	}
+	if users == nil {
+		users = []*models.User{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.UserListResponse{Users: users, Total: total})
+}
+
+// ChangeRole godoc
+// @Summary Сменить роль пользователя
+// @Description Меняет роль (shipper, carrier, admin). Каждое изменение записывается в журнал; сессии пользователя завершаются, и ему нужно войти заново
+// @Tags admin
+// @Accept  json
+// @Produce  json
+// @Param id path int true "ID пользователя"
+// @Param input body models.ChangeRoleRequest true "Новая роль"
+// @Success 200 {object} models.RoleChange
+// @Failure 400 {string} string "invalid role"
+// @Failure 401 {string} string "unauthorized"
+// @Failure 403 {string} string "forbidden"
+// @Failure 404 {string} string "user not found"
+// @Failure 500 {string} string "failed to change role"
+// @Security BearerAuth
+// @Router /admin/users/{id}/role [put]
+func (h *AdminHandler) ChangeRole(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	userID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+	var req models.ChangeRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+
+	change, err := h.AdminService.ChangeRole(adminID, userID, req)
+	if err != nil {
+		switch err {
+		case services.ErrInvalidRole:
+			http.Error(w, "invalid role", http.StatusBadRequest)
+		case services.ErrCannotChangeOwnRole:
+			http.Error(w, "admins cannot change their own role", http.StatusBadRequest)
+		case services.ErrUserNotFound:
+			http.Error(w, "user not found", http.StatusNotFound)
+		default:
+			http.Error(w, "failed to change role", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	slog.Info("User role changed",
+		slog.Int("user_id", change.UserID),
+		slog.String("old_role", string(change.OldRole)),
+		slog.String("new_role", string(change.NewRole)),
+		slog.Int("changed_by", change.ChangedBy))
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(change)
+}
+
+// GetRoleChanges godoc
+// @Summary Журнал смены ролей пользователя
+// @Tags admin
+// @Produce  json
+// @Param id path int true "ID пользователя"
+// @Success 200 {array} models.RoleChange
+// @Failure 400 {string} string "invalid id"
+// @Failure 401 {string} string "unauthorized"
+// @Failure 403 {string} string "forbidden"
+// @Failure 500 {string} string "failed to fetch role changes"
+// @Security BearerAuth
+// @Router /admin/users/{id}/role-changes [get]
+func (h *AdminHandler) GetRoleChanges(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+	changes, err := h.AdminService.GetRoleChanges(userID)
+	if err != nil {
+		http.Error(w, "failed to fetch role changes", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(changes)
+}
+
+// DeleteJob godoc
+// @Summary Удалить любую работу (модерация)
+// @Description Удаляет открытую или отменённую работу независимо от владельца. Работу в исполнении сначала отменяют с возвратом оплаты
+// @Tags admin
+// @Param id path string true "ID работы"
+// @Success 204 {string} string "deleted"
+// @Failure 401 {string} string "unauthorized"
+// @Failure 403 {string} string "forbidden"
+// @Failure 404 {string} string "job not found"
+// @Failure 409 {string} string "job is in progress, cancel it first"
+// @Failure 500 {string} string "failed to delete job"
+// @Security BearerAuth
+// @Router /admin/jobs/{id} [delete]
+func (h *AdminHandler) DeleteJob(w http.ResponseWriter, r *http.Request) {
+	adminID, _ := middleware.UserIDFromContext(r.Context())
+	id := mux.Vars(r)["id"]
+	if err := h.AdminService.DeleteJob(id); err != nil {
+		switch err {
+		case services.ErrJobNotFound:
+			http.Error(w, "job not found", http.StatusNotFound)
+		case services.ErrJobInProgress:
+			http.Error(w, "job is in progress, cancel it first", http.StatusConflict)
+		default:
+			http.Error(w, "failed to delete job", http.StatusInternalServerError)
+		}
+		return
+	}
+	slog.Info("Job removed by moderator", slog.String("job_id", id), slog.Int("admin_id", adminID))
+	w.WriteHeader(http.StatusNoContent)
+}
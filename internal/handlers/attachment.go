@@ -0,0 +1,205 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"moveshare/internal/middleware"
+	"moveshare/internal/models"
+	"moveshare/internal/services"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// AttachmentHandler отвечает за файлы работ
+type AttachmentHandler struct {
+	AttachmentService services.AttachmentService
+	MaxUploadBytes    int64
+}
+
+func NewAttachmentHandler(attachmentService services.AttachmentService, maxUploadBytes int64) *AttachmentHandler {
+	return &AttachmentHandler{AttachmentService: attachmentService, MaxUploadBytes: maxUploadBytes}
+}
+
+// UploadAttachment godoc
+// @Summary Загрузить файл работы
+// @Description Загружает файл: JPEG, PNG, GIF, WebP или PDF (тип определяется по содержимому). kind: inventory — фото описи (те, кто управляет работой), proof_of_delivery — фото доставки (назначенный перевозчик, когда работа в in_transit или delivered), other — любой участник работы. Для JPEG, PNG и GIF строится миниатюра. В ответе — подписанные ссылки на скачивание
+// @Tags attachments
+// @Accept  multipart/form-data
+// @Produce  json
+// @Param id path string true "ID работы"
+// @Param file formData file true "Файл"
+// @Param kind formData string false "inventory, proof_of_delivery или other (по умолчанию)"
+// @Success 201 {object} models.Attachment
+// @Failure 400 {string} string "invalid attachment or unsupported file type"
+// @Failure 401 {string} string "unauthorized"
+// @Failure 403 {string} string "forbidden"
+// @Failure 404 {string} string "job not found"
+// @Failure 409 {string} string "too many attachments or job is not in transit or delivered"
+// @Failure 413 {string} string "file is too large"
+// @Failure 500 {string} string "internal server error"
+// @Security BearerAuth
+// @Router /jobs/{id}/attachments [post]
+func (h *AttachmentHandler) UploadAttachment(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	// Запас на поля формы и заголовки multipart
+	r.Body = http.MaxBytesReader(w, r.Body, h.MaxUploadBytes+1<<20)
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			http.Error(w, "file is too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	kind := models.AttachmentKind(r.FormValue("kind"))
+	attachment, err := h.AttachmentService.Upload(mux.Vars(r)["id"], userID, kind, header.Filename, file)
+	if err != nil {
+		writeAttachmentError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(attachment)
+}
+
+// GetAttachments godoc
+// @Summary Файлы работы
+// @Description Файлы с подписанными ссылками на скачивание (url, thumbnail_url), которые действуют до url_expires_at и не требуют токена. Участники работы видят все файлы, остальные — только опись (inventory) открытой работы
+// @Tags attachments
+// @Produce  json
+// @Param id path string true "ID работы"
+// @Success 200 {array} models.Attachment
+// @Failure 401 {string} string "unauthorized"
+// @Failure 403 {string} string "forbidden"
+// @Failure 404 {string} string "job not found"
+// @Failure 500 {string} string "internal server error"
+// @Security BearerAuth
+// @Router /jobs/{id}/attachments [get]
+func (h *AttachmentHandler) GetAttachments(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	attachments, err := h.AttachmentService.GetAttachments(mux.Vars(r)["id"], userID)
+	if err != nil {
+		writeAttachmentError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(attachments)
+}
+
+// DeleteAttachment godoc
+// @Summary Удалить файл работы
+// @Description Удаляет файл и его миниатюру; можно загрузившему и тем, кто управляет работой
+// @Tags attachments
+// @Param id path string true "ID работы"
+// @Param attachmentID path string true "ID файла"
+// @Success 204 {string} string "deleted"
+// @Failure 401 {string} string "unauthorized"
+// @Failure 403 {string} string "forbidden"
+// @Failure 404 {string} string "attachment not found"
+// @Failure 500 {string} string "internal server error"
+// @Security BearerAuth
+// @Router /jobs/{id}/attachments/{attachmentID} [delete]
+func (h *AttachmentHandler) DeleteAttachment(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	vars := mux.Vars(r)
+	if err := h.AttachmentService.DeleteAttachment(vars["id"], vars["attachmentID"], userID); err != nil {
+		writeAttachmentError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DownloadAttachment godoc
+// @Summary Скачать файл по подписанной ссылке
+// @Description Отдаёт файл или его миниатюру (variant=thumbnail, JPEG). Токен не нужен: доступ даёт подпись из ссылки, полученной в списке файлов работы
+// @Tags attachments
+// @Produce  octet-stream
+// @Param id path string true "ID файла"
+// @Param variant query string true "original или thumbnail"
+// @Param expires query int true "Срок действия ссылки (unix)"
+// @Param signature query string true "Подпись ссылки"
+// @Success 200 {file} file
+// @Failure 403 {string} string "invalid or expired link"
+// @Failure 404 {string} string "attachment not found"
+// @Failure 500 {string} string "internal server error"
+// @Router /attachments/{id}/content [get]
+func (h *AttachmentHandler) DownloadAttachment(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	expires, err := strconv.ParseInt(q.Get("expires"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid or expired link", http.StatusForbidden)
+		return
+	}
+	variant := q.Get("variant")
+	attachment, f, err := h.AttachmentService.Open(mux.Vars(r)["id"], variant, expires, q.Get("signature"))
+	if err != nil {
+		writeAttachmentError(w, err)
+		return
+	}
+	defer f.Close()
+
+	// Изображения открываются в браузере, остальное скачивается
+	disposition := "attachment"
+	if strings.HasPrefix(attachment.ContentType, "image/") {
+		disposition = "inline"
+	}
+	w.Header().Set("Content-Type", attachment.ContentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("%s; filename=%q", disposition, attachment.FileName))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Content-Security-Policy", "sandbox")
+	w.Header().Set("Cache-Control", "private, max-age=300")
+	if attachment.Size > 0 {
+		w.Header().Set("Content-Length", strconv.FormatInt(attachment.Size, 10))
+	}
+	if _, err := io.Copy(w, f); err != nil {
+		slog.Error("Failed to send attachment", slog.String("error", err.Error()))
+	}
+}
+
+func writeAttachmentError(w http.ResponseWriter, err error) {
+	switch err {
+	case services.ErrJobNotFound:
+		http.Error(w, "job not found", http.StatusNotFound)
+	case services.ErrJobForbidden:
+		http.Error(w, "forbidden", http.StatusForbidden)
+	case services.ErrAttachmentNotFound:
+		http.Error(w, "attachment not found", http.StatusNotFound)
+	case services.ErrInvalidAttachment:
+		http.Error(w, "invalid attachment", http.StatusBadRequest)
+	case services.ErrUnsupportedFileType:
+		http.Error(w, "unsupported file type", http.StatusBadRequest)
+	case services.ErrFileTooLarge:
+		http.Error(w, "file is too large", http.StatusRequestEntityTooLarge)
+	case services.ErrAttachmentLimit:
+		http.Error(w, "too many attachments", http.StatusConflict)
+	case services.ErrDeliveryNotStarted:
+		http.Error(w, "job is not in transit or delivered", http.StatusConflict)
+	case services.ErrInvalidLink:
+		http.Error(w, "invalid or expired link", http.StatusForbidden)
+	default:
+		slog.Error("Attachment operation failed", slog.String("error", err.Error()))
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+	}
+}
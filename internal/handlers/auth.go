@@ -3,19 +3,21 @@ package handlers
 import (
 	"encoding/json"
 	"log/slog"
+	"moveshare/internal/middleware"
 	"moveshare/internal/models"
 	"moveshare/internal/services"
 	"net/http"
 )
 
 type AuthHandler struct {
-	AuthService services.AuthService
-	JWTService  services.JWTService
+	AuthService      services.AuthService
+	TokenService     services.TokenService
+	TwoFactorService services.TwoFactorService
 }
 
 // SignUp godoc
 // @Summary Регистрация пользователя
-// @Description Создание нового пользователя с email, username и password
+// @Description Создание нового пользователя с email, username и password. На email отправляется ссылка для подтверждения
 // @Tags auth
 // @Accept  json
 // @Produce  json
@@ -73,7 +75,8 @@ func (h *AuthHandler) SignUp(w http.ResponseWriter, r *http.Request) {
 
 // Login godoc
 // @Summary Авторизация пользователя
-// @Description Логин по email и password, возвращает JWT access_token
+// @Description Логин по email и password, возвращает JWT access_token и refresh_token.
+// @Description Если включена двухфакторная аутентификация, вместо них возвращается challenge_token для POST /login/2fa
 // @Tags auth
 // @Accept  json
 // @Produce  json
@@ -95,13 +98,194 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	token, err := h.JWTService.GenerateToken(user.ID, user.Email)
+	var resp *models.LoginResponse
+	if user.TOTPEnabled {
+		resp, err = h.TwoFactorService.Challenge(user)
+	} else {
+		resp, err = h.TokenService.IssueTokens(user)
+	}
 	if err != nil {
 		http.Error(w, "failed to generate token", http.StatusInternalServerError)
 		return
 	}
 
-	resp := models.LoginResponse{AccessToken: token}
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(resp)
 }
+
+// Refresh godoc
+// @Summary Обновление токенов
+// @Description Обменивает refresh_token на новую пару токенов. Старый refresh_token становится недействительным; его повторное использование отзывает всю сессию
+// @Tags auth
+// @Accept  json
+// @Produce  json
+// @Param input body models.RefreshRequest true "Refresh token"
+// @Success 200 {object} models.LoginResponse
+// @Failure 400
+// @Failure 401
+// @Failure 500
+// @Router /token/refresh [post]
+func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	var req models.RefreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.RefreshToken == "" {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := h.TokenService.Refresh(req.RefreshToken)
+	if err != nil {
+		switch err {
+		case services.ErrRefreshTokenReused:
+			slog.Warn("Refresh token reuse detected, session revoked",
+				slog.String("remote_addr", r.RemoteAddr))
+			http.Error(w, "invalid refresh token", http.StatusUnauthorized)
+		case services.ErrInvalidRefreshToken:
+			http.Error(w, "invalid refresh token", http.StatusUnauthorized)
+		default:
+			slog.Error("Failed to refresh token", slog.String("error", err.Error()))
+			http.Error(w, "internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// Logout godoc
+// @Summary Выход
+// @Description Отзывает текущий access_token и сессию переданного refresh_token
+// @Tags auth
+// @Accept  json
+// @Param input body models.RefreshRequest false "Refresh token"
+// @Success 204
+// @Failure 401
+// @Failure 500
+// @Security BearerAuth
+// @Router /logout [post]
+func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.ClaimsFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req models.RefreshRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid request", http.StatusBadRequest)
+			return
+		}
+	}
+
+	if err := h.TokenService.Logout(claims, req.RefreshToken); err != nil {
+		slog.Error("Failed to logout", slog.String("error", err.Error()))
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// VerifyEmail godoc
+// @Summary Подтверждение email
+// @Description Подтверждает email по одноразовому токену из письма
+// @Tags auth
+// @Accept  json
+// @Param input body models.VerifyEmailRequest true "Токен из письма"
+// @Success 204
+// @Failure 400
+// @Failure 500
+// @Router /verify-email [post]
+func (h *AuthHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	var req models.VerifyEmailRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Token == "" {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.AuthService.VerifyEmail(req.Token); err != nil {
+		if err == services.ErrInvalidUserToken {
+			http.Error(w, "token is invalid, used or expired", http.StatusBadRequest)
+			return
+		}
+		slog.Error("Failed to verify email", slog.String("error", err.Error()))
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ResendVerification godoc
+// @Summary Повторная отправка письма с подтверждением
+// @Description Всегда отвечает 202, чтобы по ответу нельзя было узнать, зарегистрирован ли адрес
+// @Tags auth
+// @Accept  json
+// @Param input body models.EmailRequest true "Email"
+// @Success 202
+// @Failure 400
+// @Router /verify-email/resend [post]
+func (h *AuthHandler) ResendVerification(w http.ResponseWriter, r *http.Request) {
+	var req models.EmailRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Email == "" {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.AuthService.ResendVerification(req.Email); err != nil {
+		slog.Error("Failed to resend verification email", slog.String("error", err.Error()))
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// ForgotPassword godoc
+// @Summary Запрос сброса пароля
+// @Description Отправляет на email ссылку для сброса пароля. Всегда отвечает 202
+// @Tags auth
+// @Accept  json
+// @Param input body models.EmailRequest true "Email"
+// @Success 202
+// @Failure 400
+// @Router /password/forgot [post]
+func (h *AuthHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	var req models.EmailRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Email == "" {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.AuthService.RequestPasswordReset(req.Email); err != nil {
+		slog.Error("Failed to send password reset email", slog.String("error", err.Error()))
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// ResetPassword godoc
+// @Summary Сброс пароля
+// @Description Устанавливает новый пароль по одноразовому токену из письма и завершает все сессии пользователя; выданные раньше access-токены перестают приниматься
+// @Tags auth
+// @Accept  json
+// @Param input body models.ResetPasswordRequest true "Токен и новый пароль"
+// @Success 204
+// @Failure 400
+// @Failure 500
+// @Router /password/reset [post]
+func (h *AuthHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	var req models.ResetPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Token == "" {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.AuthService.ResetPassword(req); err != nil {
+		switch err {
+		case services.ErrInvalidInput:
+			http.Error(w, "invalid input data", http.StatusBadRequest)
+		case services.ErrInvalidUserToken:
+			http.Error(w, "token is invalid, used or expired", http.StatusBadRequest)
+		default:
+			slog.Error("Failed to reset password", slog.String("error", err.Error()))
+			http.Error(w, "internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,215 @@
+package handlers
+
+import (
+	"encoding/json"
+	"moveshare/internal/middleware"
+	"moveshare/internal/models"
+	"moveshare/internal/services"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// BidHandler отвечает за ставки и встречные предложения по работам
+type BidHandler struct {
+	BidService services.BidService
+}
+
+func NewBidHandler(bidService services.BidService) *BidHandler {
+	return &BidHandler{BidService: bidService}
+}
+
+// CreateBid godoc
+// @Summary Сделать ставку на работу
+// @Description Перевозчик предлагает свою цену за открытую работу
+// @Tags bids
+// @Accept  json
+// @Produce  json
+// @Param id path string true "ID работы"
+// @Param input body models.CreateBidRequest true "Ставка"
+// @Success 201 {object} models.Bid
+// @Failure 400 {string} string "invalid bid"
+// @Failure 401 {string} string "unauthorized"
+// @Failure 403 {string} string "cannot claim own job or carrier is not verified"
+// @Failure 404 {string} string "job not found"
+// @Failure 409 {string} string "job is no longer open"
+// @Failure 500 {string} string "failed to create bid"
+// @Security BearerAuth
+// @Router /jobs/{id}/bids [post]
+func (h *BidHandler) CreateBid(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req models.CreateBidRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+	bid, err := h.BidService.CreateBid(mux.Vars(r)["id"], userID, req)
+	if err != nil {
+		writeBidError(w, err, "failed to create bid")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(bid)
+}
+
+// GetBids godoc
+// @Summary Список ставок по работе
+// @Description Заказчик видит все ставки, перевозчик — только свои
+// @Tags bids
+// @Produce  json
+// @Param id path string true "ID работы"
+// @Success 200 {array} models.Bid
+// @Failure 401 {string} string "unauthorized"
+// @Failure 404 {string} string "job not found"
+// @Failure 500 {string} string "failed to fetch bids"
+// @Security BearerAuth
+// @Router /jobs/{id}/bids [get]
+func (h *BidHandler) GetBids(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	bids, err := h.BidService.GetBids(mux.Vars(r)["id"], userID)
+	if err != nil {
+		writeBidError(w, err, "failed to fetch bids")
+		return
+	}
+	if bids == nil {
+		bids = []*models.Bid{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(bids)
+}
+
+// AcceptBid godoc
+// @Summary Принять ставку
+// @Description Заказчик принимает ставку (или перевозчик — встречное предложение). Работа закрепляется за перевозчиком по согласованной цене, остальные активные ставки отклоняются (по каждой — событие bid.rejected)
+// @Tags bids
+// @Produce  json
+// @Param id path string true "ID работы"
+// @Param bidID path string true "ID ставки"
+// @Success 200 {object} models.Job
+// @Failure 400 {string} string "fees exceed payment"
+// @Failure 401 {string} string "unauthorized"
+// @Failure 403 {string} string "forbidden or carrier is not verified"
+// @Failure 404 {string} string "bid not found"
+// @Failure 409 {string} string "bid is no longer active"
+// @Failure 500 {string} string "failed to accept bid"
+// @Security BearerAuth
+// @Router /jobs/{id}/bids/{bidID}/accept [post]
+func (h *BidHandler) AcceptBid(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	vars := mux.Vars(r)
+	job, err := h.BidService.AcceptBid(vars["id"], vars["bidID"], userID)
+	if err != nil {
+		writeBidError(w, err, "failed to accept bid")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(job)
+}
+
+// RejectBid godoc
+// @Summary Отклонить ставку
+// @Description Заказчик отклоняет ставку (или перевозчик — встречное предложение)
+// @Tags bids
+// @Produce  json
+// @Param id path string true "ID работы"
+// @Param bidID path string true "ID ставки"
+// @Success 200 {object} models.Bid
+// @Failure 401 {string} string "unauthorized"
+// @Failure 403 {string} string "forbidden"
+// @Failure 404 {string} string "bid not found"
+// @Failure 409 {string} string "bid is no longer active"
+// @Failure 500 {string} string "failed to reject bid"
+// @Security BearerAuth
+// @Router /jobs/{id}/bids/{bidID}/reject [post]
+func (h *BidHandler) RejectBid(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	vars := mux.Vars(r)
+	bid, err := h.BidService.RejectBid(vars["id"], vars["bidID"], userID)
+	if err != nil {
+		writeBidError(w, err, "failed to reject bid")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(bid)
+}
+
+// CounterBid godoc
+// @Summary Встречное предложение
+// @Description Заказчик предлагает перевозчику свою цену в ответ на ставку
+// @Tags bids
+// @Accept  json
+// @Produce  json
+// @Param id path string true "ID работы"
+// @Param bidID path string true "ID ставки"
+// @Param input body models.CounterBidRequest true "Встречное предложение"
+// @Success 200 {object} models.Bid
+// @Failure 400 {string} string "invalid bid"
+// @Failure 401 {string} string "unauthorized"
+// @Failure 403 {string} string "forbidden"
+// @Failure 404 {string} string "bid not found"
+// @Failure 409 {string} string "bid is no longer active"
+// @Failure 500 {string} string "failed to counter bid"
+// @Security BearerAuth
+// @Router /jobs/{id}/bids/{bidID}/counter [post]
+func (h *BidHandler) CounterBid(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req models.CounterBidRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+	vars := mux.Vars(r)
+	bid, err := h.BidService.CounterBid(vars["id"], vars["bidID"], userID, req)
+	if err != nil {
+		writeBidError(w, err, "failed to counter bid")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(bid)
+}
+
+func writeBidError(w http.ResponseWriter, err error, fallback string) {
+	switch err {
+	case services.ErrInvalidBid:
+		http.Error(w, "invalid bid", http.StatusBadRequest)
+	case services.ErrFeesExceedPayment:
+		http.Error(w, "fees exceed payment", http.StatusBadRequest)
+	case services.ErrJobNotFound:
+		http.Error(w, "job not found", http.StatusNotFound)
+	case services.ErrBidNotFound:
+		http.Error(w, "bid not found", http.StatusNotFound)
+	case services.ErrBidForbidden:
+		http.Error(w, "forbidden", http.StatusForbidden)
+	case services.ErrCannotClaimOwnJob:
+		http.Error(w, "cannot claim own job", http.StatusForbidden)
+	case services.ErrCarrierNotVerified:
+		http.Error(w, "carrier is not verified", http.StatusForbidden)
+	case services.ErrJobNotOpen:
+		http.Error(w, "job is no longer open", http.StatusConflict)
+	case services.ErrBidNotActive:
+		http.Error(w, "bid is no longer active", http.StatusConflict)
+	default:
+		http.Error(w, fallback, http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"moveshare/internal/middleware"
+	"moveshare/internal/models"
+	"moveshare/internal/services"
+	"net"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// BOLHandler отвечает за транспортные накладные и их подписи
+type BOLHandler struct {
+	BOLService services.BOLService
+}
+
+func NewBOLHandler(bolService services.BOLService) *BOLHandler {
+	return &BOLHandler{BOLService: bolService}
+}
+
+// GetBOL godoc
+// @Summary Транспортная накладная
+// @Description Накладная (bill of lading) выдаётся, когда перевозчик забрал груз (работа в in_transit): данные работы и сторон фиксируются на этот момент. Ответ — подписи и SHA-256 последней версии PDF (он же bol_hash у работы). Доступно тем, кто управляет работой, и назначенному перевозчику
+// @Tags bol
+// @Produce  json
+// @Param id path string true "ID работы"
+// @Success 200 {object} models.BillOfLading
+// @Failure 401 {string} string "unauthorized"
+// @Failure 403 {string} string "forbidden"
+// @Failure 404 {string} string "job not found"
+// @Failure 409 {string} string "bill of lading is not issued yet"
+// @Failure 500 {string} string "internal server error"
+// @Security BearerAuth
+// @Router /jobs/{id}/bol [get]
+func (h *BOLHandler) GetBOL(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	bol, err := h.BOLService.GetBOL(mux.Vars(r)["id"], userID)
+	if err != nil {
+		writeBOLError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(bol)
+}
+
+// GetBOLFile godoc
+// @Summary Транспортная накладная (PDF)
+// @Description Последняя версия PDF накладной с поставленными подписями. Заголовок X-Document-SHA256 — её хеш; он совпадает с SHA-256 тела ответа
+// @Tags bol
+// @Produce  application/pdf
+// @Param id path string true "ID работы"
+// @Success 200 {file} file
+// @Failure 401 {string} string "unauthorized"
+// @Failure 403 {string} string "forbidden"
+// @Failure 404 {string} string "job not found"
+// @Failure 409 {string} string "bill of lading is not issued yet"
+// @Failure 500 {string} string "internal server error"
+// @Security BearerAuth
+// @Router /jobs/{id}/bol/pdf [get]
+func (h *BOLHandler) GetBOLFile(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	bol, content, err := h.BOLService.GetBOLFile(mux.Vars(r)["id"], userID)
+	if err != nil {
+		writeBOLError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", bol.Number+".pdf"))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("X-Document-SHA256", bol.DocumentHash)
+	if _, err := w.Write(content); err != nil {
+		slog.Error("Failed to send bill of lading", slog.String("error", err.Error()))
+	}
+}
+
+// SignBOL godoc
+// @Summary Подписать накладную
+// @Description Принимает подпись отправителя (shipper, при погрузке) или получателя (consignee, когда работа в delivered; только после отправителя): изображение подписи PNG или JPEG до 1 МБ и 2000×2000 пикселей и имя подписавшего. Время и IP фиксирует сервер. Каждая роль подписывает один раз; подпись даёт новую версию PDF, и её хеш записывается в накладную и у работы. Отправитель может подписать, пока работа в in_transit или delivered, получатель — только в delivered
+// @Tags bol
+// @Accept  multipart/form-data
+// @Produce  json
+// @Param id path string true "ID работы"
+// @Param role path string true "Роль: shipper или consignee"
+// @Param signature formData file true "Изображение подписи"
+// @Param name formData string true "Имя подписавшего"
+// @Success 201 {object} models.BillOfLading
+// @Failure 400 {string} string "invalid signature or unsupported file type"
+// @Failure 401 {string} string "unauthorized"
+// @Failure 403 {string} string "forbidden"
+// @Failure 404 {string} string "job not found"
+// @Failure 409 {string} string "already signed, shipper has not signed yet, job is not delivered yet or bill of lading can not be signed now"
+// @Failure 413 {string} string "file is too large"
+// @Failure 500 {string} string "internal server error"
+// @Security BearerAuth
+// @Router /jobs/{id}/bol/signatures/{role} [post]
+func (h *BOLHandler) SignBOL(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	// Запас на поля формы и заголовки multipart
+	r.Body = http.MaxBytesReader(w, r.Body, services.MaxSignatureBytes+1<<20)
+	file, _, err := r.FormFile("signature")
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			http.Error(w, "file is too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	vars := mux.Vars(r)
+	bol, err := h.BOLService.Sign(vars["id"], userID, models.SignatureRole(vars["role"]), r.FormValue("name"), file, clientIP(r))
+	if err != nil {
+		writeBOLError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(bol)
+}
+
+// VerifyBOL godoc
+// @Summary Проверить целостность накладной
+// @Description Сверяет сохранённый PDF последней версии и изображения подписей с хешами, записанными при подписании, а хеш накладной — с bol_hash работы. mismatches перечисляет несовпадения: document или роль подписи
+// @Tags bol
+// @Produce  json
+// @Param id path string true "ID работы"
+// @Success 200 {object} models.BOLVerification
+// @Failure 401 {string} string "unauthorized"
+// @Failure 403 {string} string "forbidden"
+// @Failure 404 {string} string "job not found"
+// @Failure 409 {string} string "bill of lading is not issued yet"
+// @Failure 500 {string} string "internal server error"
+// @Security BearerAuth
+// @Router /jobs/{id}/bol/verify [get]
+func (h *BOLHandler) VerifyBOL(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	result, err := h.BOLService.Verify(mux.Vars(r)["id"], userID)
+	if err != nil {
+		writeBOLError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// clientIP — адрес клиента из соединения, без порта
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func writeBOLError(w http.ResponseWriter, err error) {
+	switch err {
+	case services.ErrJobNotFound:
+		http.Error(w, "job not found", http.StatusNotFound)
+	case services.ErrJobForbidden:
+		http.Error(w, "forbidden", http.StatusForbidden)
+	case services.ErrInvalidSignature:
+		http.Error(w, "invalid signature", http.StatusBadRequest)
+	case services.ErrUnsupportedFileType:
+		http.Error(w, "unsupported file type", http.StatusBadRequest)
+	case services.ErrFileTooLarge:
+		http.Error(w, "file is too large", http.StatusRequestEntityTooLarge)
+	case services.ErrBOLNotIssued:
+		http.Error(w, "bill of lading is not issued yet", http.StatusConflict)
+	case services.ErrBOLAlreadySigned:
+		http.Error(w, "already signed", http.StatusConflict)
+	case services.ErrShipperNotSigned:
+		http.Error(w, "shipper has not signed yet", http.StatusConflict)
+	case services.ErrNotDelivered:
+		http.Error(w, "job is not delivered yet", http.StatusConflict)
+	case services.ErrBOLNotSignable:
+		http.Error(w, "bill of lading can not be signed now", http.StatusConflict)
+	case services.ErrBOLConflict:
+		http.Error(w, "bill of lading changed, try again", http.StatusConflict)
+	default:
+		slog.Error("Bill of lading operation failed", slog.String("error", err.Error()))
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+	}
+}
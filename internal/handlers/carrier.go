@@ -0,0 +1,334 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"moveshare/internal/middleware"
+	"moveshare/internal/models"
+	"moveshare/internal/services"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// CarrierHandler отвечает за профиль перевозчика, страховые сертификаты
+// и их проверку администратором
+type CarrierHandler struct {
+	CarrierService services.CarrierService
+	MaxUploadBytes int64
+}
+
+func NewCarrierHandler(carrierService services.CarrierService, maxUploadBytes int64) *CarrierHandler {
+	return &CarrierHandler{CarrierService: carrierService, MaxUploadBytes: maxUploadBytes}
+}
+
+// GetProfile godoc
+// @Summary Профиль перевозчика
+// @Description Профиль с USDOT/MC, статусом проверки и загруженными страховыми сертификатами
+// @Tags carrier
+// @Produce  json
+// @Success 200 {object} models.CarrierProfile
+// @Failure 401 {string} string "unauthorized"
+// @Failure 403 {string} string "forbidden"
+// @Failure 404 {string} string "carrier profile not found"
+// @Failure 500 {string} string "internal server error"
+// @Security BearerAuth
+// @Router /carrier/profile [get]
+func (h *CarrierHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	profile, err := h.CarrierService.GetProfile(userID)
+	if err != nil {
+		writeCarrierError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(profile)
+}
+
+// SaveProfile godoc
+// @Summary Сохранить профиль перевозчика
+// @Description Создаёт или обновляет профиль. Смена USDOT или MC номера отправляет профиль на повторную проверку
+// @Tags carrier
+// @Accept  json
+// @Produce  json
+// @Param input body models.CarrierProfileRequest true "Данные перевозчика"
+// @Success 200 {object} models.CarrierProfile
+// @Failure 400 {string} string "invalid request"
+// @Failure 401 {string} string "unauthorized"
+// @Failure 403 {string} string "forbidden"
+// @Failure 409 {string} string "dot number already registered"
+// @Failure 500 {string} string "internal server error"
+// @Security BearerAuth
+// @Router /carrier/profile [put]
+func (h *CarrierHandler) SaveProfile(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req models.CarrierProfileRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+	profile, err := h.CarrierService.SaveProfile(userID, req)
+	if err != nil {
+		writeCarrierError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(profile)
+}
+
+// UploadCertificate godoc
+// @Summary Загрузить страховой сертификат
+// @Description Загружает сертификат страхования (COI) в формате PDF, JPEG или PNG. Сертификат действует после одобрения администратором
+// @Tags carrier
+// @Accept  multipart/form-data
+// @Produce  json
+// @Param file formData file true "Файл сертификата"
+// @Param coverage_amount formData string true "Сумма покрытия десятичной строкой: 1000000.00"
+// @Param coverage_currency formData string false "Валюта покрытия (по умолчанию USD)"
+// @Param expires_at formData string true "Дата окончания (YYYY-MM-DD)"
+// @Success 201 {object} models.InsuranceCertificate
+// @Failure 400 {string} string "invalid request or unsupported file type"
+// @Failure 401 {string} string "unauthorized"
+// @Failure 403 {string} string "forbidden"
+// @Failure 404 {string} string "carrier profile not found"
+// @Failure 413 {string} string "file is too large"
+// @Failure 500 {string} string "internal server error"
+// @Security BearerAuth
+// @Router /carrier/insurance [post]
+func (h *CarrierHandler) UploadCertificate(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	// Запас на поля формы и заголовки multipart
+	r.Body = http.MaxBytesReader(w, r.Body, h.MaxUploadBytes+1<<20)
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			http.Error(w, "file is too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	coverage, err := models.ParseMoney(r.FormValue("coverage_amount"), r.FormValue("coverage_currency"))
+	if err != nil {
+		http.Error(w, "invalid coverage_amount", http.StatusBadRequest)
+		return
+	}
+	expiresAt, err := time.Parse("2006-01-02", r.FormValue("expires_at"))
+	if err != nil {
+		http.Error(w, "invalid expires_at", http.StatusBadRequest)
+		return
+	}
+	// Полис действует до конца указанного дня
+	expiresAt = expiresAt.Add(24*time.Hour - time.Second)
+
+	cert, err := h.CarrierService.UploadCertificate(userID, file, header.Filename, coverage, expiresAt)
+	if err != nil {
+		writeCarrierError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(cert)
+}
+
+// GetCertificates godoc
+// @Summary Мои страховые сертификаты
+// @Tags carrier
+// @Produce  json
+// @Success 200 {array} models.InsuranceCertificate
+// @Failure 401 {string} string "unauthorized"
+// @Failure 403 {string} string "forbidden"
+// @Failure 500 {string} string "internal server error"
+// @Security BearerAuth
+// @Router /carrier/insurance [get]
+func (h *CarrierHandler) GetCertificates(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	certs, err := h.CarrierService.GetCertificates(userID)
+	if err != nil {
+		writeCarrierError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(certs)
+}
+
+// ReviewQueue godoc
+// @Summary Очередь проверки перевозчиков
+// @Description Профили и страховые сертификаты, ожидающие решения администратора
+// @Tags admin
+// @Produce  json
+// @Success 200 {object} models.CarrierReviewQueue
+// @Failure 401 {string} string "unauthorized"
+// @Failure 403 {string} string "forbidden"
+// @Failure 500 {string} string "internal server error"
+// @Security BearerAuth
+// @Router /admin/carrier-reviews [get]
+func (h *CarrierHandler) ReviewQueue(w http.ResponseWriter, r *http.Request) {
+	queue, err := h.CarrierService.ReviewQueue()
+	if err != nil {
+		writeCarrierError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(queue)
+}
+
+// ReviewProfile godoc
+// @Summary Проверить профиль перевозчика
+// @Tags admin
+// @Accept  json
+// @Produce  json
+// @Param id path int true "ID перевозчика"
+// @Param input body models.ReviewRequest true "Решение (approve или reject)"
+// @Success 200 {object} models.CarrierProfile
+// @Failure 400 {string} string "invalid request"
+// @Failure 401 {string} string "unauthorized"
+// @Failure 403 {string} string "forbidden"
+// @Failure 404 {string} string "carrier profile not found"
+// @Failure 409 {string} string "already reviewed"
+// @Failure 500 {string} string "internal server error"
+// @Security BearerAuth
+// @Router /admin/carriers/{id}/review [post]
+func (h *CarrierHandler) ReviewProfile(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	userID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+	var req models.ReviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+	profile, err := h.CarrierService.ReviewProfile(adminID, userID, req)
+	if err != nil {
+		writeCarrierError(w, err)
+		return
+	}
+	slog.Info("Carrier profile reviewed",
+		slog.Int("carrier_id", profile.UserID),
+		slog.String("status", string(profile.Status)),
+		slog.Int("reviewed_by", adminID))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(profile)
+}
+
+// ReviewCertificate godoc
+// @Summary Проверить страховой сертификат
+// @Description Одобрение действующего сертификата снимает приостановку с перевозчика
+// @Tags admin
+// @Accept  json
+// @Produce  json
+// @Param id path string true "ID сертификата"
+// @Param input body models.ReviewRequest true "Решение (approve или reject)"
+// @Success 200 {object} models.InsuranceCertificate
+// @Failure 400 {string} string "invalid request"
+// @Failure 401 {string} string "unauthorized"
+// @Failure 403 {string} string "forbidden"
+// @Failure 404 {string} string "insurance certificate not found"
+// @Failure 409 {string} string "already reviewed"
+// @Failure 500 {string} string "internal server error"
+// @Security BearerAuth
+// @Router /admin/insurance/{id}/review [post]
+func (h *CarrierHandler) ReviewCertificate(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req models.ReviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+	cert, err := h.CarrierService.ReviewCertificate(adminID, mux.Vars(r)["id"], req)
+	if err != nil {
+		writeCarrierError(w, err)
+		return
+	}
+	slog.Info("Insurance certificate reviewed",
+		slog.String("certificate_id", cert.ID),
+		slog.Int("carrier_id", cert.CarrierID),
+		slog.String("status", string(cert.Status)),
+		slog.Int("reviewed_by", adminID))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(cert)
+}
+
+// DownloadCertificate godoc
+// @Summary Скачать файл страхового сертификата
+// @Tags admin
+// @Produce  application/octet-stream
+// @Param id path string true "ID сертификата"
+// @Success 200 {file} file
+// @Failure 401 {string} string "unauthorized"
+// @Failure 403 {string} string "forbidden"
+// @Failure 404 {string} string "insurance certificate not found"
+// @Failure 500 {string} string "internal server error"
+// @Security BearerAuth
+// @Router /admin/insurance/{id}/file [get]
+func (h *CarrierHandler) DownloadCertificate(w http.ResponseWriter, r *http.Request) {
+	cert, f, err := h.CarrierService.OpenCertificate(mux.Vars(r)["id"])
+	if err != nil {
+		writeCarrierError(w, err)
+		return
+	}
+	defer f.Close()
+	w.Header().Set("Content-Type", cert.ContentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", cert.FileName))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	if _, err := io.Copy(w, f); err != nil {
+		slog.Error("Failed to send certificate", slog.String("error", err.Error()))
+	}
+}
+
+func writeCarrierError(w http.ResponseWriter, err error) {
+	switch err {
+	case services.ErrInvalidInput:
+		http.Error(w, "invalid request", http.StatusBadRequest)
+	case services.ErrUnsupportedFileType:
+		http.Error(w, "unsupported file type", http.StatusBadRequest)
+	case services.ErrFileTooLarge:
+		http.Error(w, "file is too large", http.StatusRequestEntityTooLarge)
+	case services.ErrCarrierProfileNotFound:
+		http.Error(w, "carrier profile not found", http.StatusNotFound)
+	case services.ErrCertificateNotFound:
+		http.Error(w, "insurance certificate not found", http.StatusNotFound)
+	case services.ErrDOTNumberTaken:
+		http.Error(w, "dot number already registered", http.StatusConflict)
+	case services.ErrAlreadyReviewed:
+		http.Error(w, "already reviewed", http.StatusConflict)
+	default:
+		slog.Error("Carrier operation failed", slog.String("error", err.Error()))
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,369 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"moveshare/internal/middleware"
+	"moveshare/internal/models"
+	"moveshare/internal/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// CompanyHandler отвечает за компании, их сотрудников и приглашения
+type CompanyHandler struct {
+	CompanyService services.CompanyService
+}
+
+func NewCompanyHandler(companyService services.CompanyService) *CompanyHandler {
+	return &CompanyHandler{CompanyService: companyService}
+}
+
+// CreateCompany godoc
+// @Summary Создать компанию
+// @Description Создаёт компанию, автор становится её владельцем. Если активной компании ещё нет, новая становится активной со следующего токена
+// @Tags companies
+// @Accept  json
+// @Produce  json
+// @Param input body models.CreateCompanyRequest true "Название компании"
+// @Success 201 {object} models.Company
+// @Failure 400 {string} string "invalid request"
+// @Failure 401 {string} string "unauthorized"
+// @Failure 500 {string} string "internal server error"
+// @Security BearerAuth
+// @Router /companies [post]
+func (h *CompanyHandler) CreateCompany(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req models.CreateCompanyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+	company, err := h.CompanyService.CreateCompany(userID, req)
+	if err != nil {
+		writeCompanyError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(company)
+}
+
+// GetMyCompanies godoc
+// @Summary Мои компании
+// @Description Компании, в которых состоит пользователь, и его роль в каждой
+// @Tags companies
+// @Produce  json
+// @Success 200 {array} models.Company
+// @Failure 401 {string} string "unauthorized"
+// @Failure 500 {string} string "internal server error"
+// @Security BearerAuth
+// @Router /companies [get]
+func (h *CompanyHandler) GetMyCompanies(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	companies, err := h.CompanyService.GetMyCompanies(userID)
+	if err != nil {
+		writeCompanyError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(companies)
+}
+
+// SwitchCompany godoc
+// @Summary Сменить активную компанию
+// @Description Выдаёт новую пару токенов, в которой указана выбранная компания. company_id = null или 0 — работать от своего имени
+// @Tags companies
+// @Accept  json
+// @Produce  json
+// @Param input body models.SwitchCompanyRequest true "Компания"
+// @Success 200 {object} models.LoginResponse
+// @Failure 400 {string} string "invalid request"
+// @Failure 401 {string} string "unauthorized"
+// @Failure 404 {string} string "company not found"
+// @Failure 500 {string} string "internal server error"
+// @Security BearerAuth
+// @Router /companies/switch [post]
+func (h *CompanyHandler) SwitchCompany(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req models.SwitchCompanyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+	resp, err := h.CompanyService.SwitchCompany(userID, req.CompanyID)
+	if err != nil {
+		writeCompanyError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// GetMembers godoc
+// @Summary Сотрудники компании
+// @Tags companies
+// @Produce  json
+// @Param id path int true "ID компании"
+// @Success 200 {array} models.CompanyMember
+// @Failure 400 {string} string "invalid id"
+// @Failure 401 {string} string "unauthorized"
+// @Failure 404 {string} string "company not found"
+// @Failure 500 {string} string "internal server error"
+// @Security BearerAuth
+// @Router /companies/{id}/members [get]
+func (h *CompanyHandler) GetMembers(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	companyID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+	members, err := h.CompanyService.GetMembers(companyID, userID)
+	if err != nil {
+		writeCompanyError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(members)
+}
+
+// UpdateMember godoc
+// @Summary Сменить роль сотрудника
+// @Description Только для владельца компании. Последнего владельца понизить нельзя
+// @Tags companies
+// @Accept  json
+// @Produce  json
+// @Param id path int true "ID компании"
+// @Param userID path int true "ID сотрудника"
+// @Param input body models.UpdateMemberRequest true "Новая роль (owner, dispatcher, driver)"
+// @Success 200 {object} models.CompanyMember
+// @Failure 400 {string} string "invalid role"
+// @Failure 401 {string} string "unauthorized"
+// @Failure 403 {string} string "not allowed to manage this company"
+// @Failure 404 {string} string "company or member not found"
+// @Failure 409 {string} string "company must keep at least one owner"
+// @Failure 500 {string} string "internal server error"
+// @Security BearerAuth
+// @Router /companies/{id}/members/{userID} [put]
+func (h *CompanyHandler) UpdateMember(w http.ResponseWriter, r *http.Request) {
+	actorID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	companyID, memberID, ok := memberPath(w, r)
+	if !ok {
+		return
+	}
+	var req models.UpdateMemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+	member, err := h.CompanyService.UpdateMemberRole(companyID, actorID, memberID, req.Role)
+	if err != nil {
+		writeCompanyError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(member)
+}
+
+// RemoveMember godoc
+// @Summary Исключить сотрудника
+// @Description Владелец исключает сотрудника, либо сотрудник покидает компанию сам (userID = свой ID)
+// @Tags companies
+// @Param id path int true "ID компании"
+// @Param userID path int true "ID сотрудника"
+// @Success 204
+// @Failure 400 {string} string "invalid id"
+// @Failure 401 {string} string "unauthorized"
+// @Failure 403 {string} string "not allowed to manage this company"
+// @Failure 404 {string} string "company or member not found"
+// @Failure 409 {string} string "company must keep at least one owner"
+// @Failure 500 {string} string "internal server error"
+// @Security BearerAuth
+// @Router /companies/{id}/members/{userID} [delete]
+func (h *CompanyHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	actorID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	companyID, memberID, ok := memberPath(w, r)
+	if !ok {
+		return
+	}
+	if err := h.CompanyService.RemoveMember(companyID, actorID, memberID); err != nil {
+		writeCompanyError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Invite godoc
+// @Summary Пригласить в компанию
+// @Description Отправляет приглашение на email со ссылкой для принятия. Владелец приглашает с любой ролью, диспетчер — только водителей
+// @Tags companies
+// @Accept  json
+// @Produce  json
+// @Param id path int true "ID компании"
+// @Param input body models.InviteMemberRequest true "Email и роль"
+// @Success 201 {object} models.CompanyInvitation
+// @Failure 400 {string} string "invalid request"
+// @Failure 401 {string} string "unauthorized"
+// @Failure 403 {string} string "not allowed to manage this company"
+// @Failure 404 {string} string "company not found"
+// @Failure 500 {string} string "internal server error"
+// @Security BearerAuth
+// @Router /companies/{id}/invitations [post]
+func (h *CompanyHandler) Invite(w http.ResponseWriter, r *http.Request) {
+	inviterID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	companyID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+	var req models.InviteMemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+	inv, err := h.CompanyService.Invite(companyID, inviterID, req)
+	if err != nil {
+		writeCompanyError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(inv)
+}
+
+// GetInvitations godoc
+// @Summary Неотвеченные приглашения компании
+// @Tags companies
+// @Produce  json
+// @Param id path int true "ID компании"
+// @Success 200 {array} models.CompanyInvitation
+// @Failure 400 {string} string "invalid id"
+// @Failure 401 {string} string "unauthorized"
+// @Failure 403 {string} string "not allowed to manage this company"
+// @Failure 404 {string} string "company not found"
+// @Failure 500 {string} string "internal server error"
+// @Security BearerAuth
+// @Router /companies/{id}/invitations [get]
+func (h *CompanyHandler) GetInvitations(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	companyID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+	invitations, err := h.CompanyService.GetInvitations(companyID, userID)
+	if err != nil {
+		writeCompanyError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(invitations)
+}
+
+// AcceptInvitation godoc
+// @Summary Принять приглашение
+// @Description Принимает приглашение по токену из письма. Пользователь должен войти под тем email, на который пришло приглашение
+// @Tags companies
+// @Accept  json
+// @Produce  json
+// @Param input body models.AcceptInvitationRequest true "Токен из ссылки"
+// @Success 200 {object} models.CompanyMember
+// @Failure 400 {string} string "invitation is invalid, used or expired"
+// @Failure 401 {string} string "unauthorized"
+// @Failure 403 {string} string "invitation was sent to another email"
+// @Failure 500 {string} string "internal server error"
+// @Security BearerAuth
+// @Router /companies/invitations/accept [post]
+func (h *CompanyHandler) AcceptInvitation(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req models.AcceptInvitationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Token == "" {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+	member, err := h.CompanyService.AcceptInvitation(userID, req.Token)
+	if err != nil {
+		writeCompanyError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(member)
+}
+
+func memberPath(w http.ResponseWriter, r *http.Request) (int, int, bool) {
+	vars := mux.Vars(r)
+	companyID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return 0, 0, false
+	}
+	memberID, err := strconv.Atoi(vars["userID"])
+	if err != nil {
+		http.Error(w, "invalid user id", http.StatusBadRequest)
+		return 0, 0, false
+	}
+	return companyID, memberID, true
+}
+
+func writeCompanyError(w http.ResponseWriter, err error) {
+	switch err {
+	case services.ErrInvalidInput:
+		http.Error(w, "invalid request", http.StatusBadRequest)
+	case services.ErrInvalidRole:
+		http.Error(w, "invalid role", http.StatusBadRequest)
+	case services.ErrInvalidInvitation:
+		http.Error(w, "invitation is invalid, used or expired", http.StatusBadRequest)
+	case services.ErrInvitationEmailMismatch:
+		http.Error(w, "invitation was sent to another email", http.StatusForbidden)
+	case services.ErrCompanyForbidden:
+		http.Error(w, "not allowed to manage this company", http.StatusForbidden)
+	case services.ErrCompanyNotFound:
+		http.Error(w, "company not found", http.StatusNotFound)
+	case services.ErrMemberNotFound:
+		http.Error(w, "member not found", http.StatusNotFound)
+	case services.ErrLastOwner:
+		http.Error(w, "company must keep at least one owner", http.StatusConflict)
+	default:
+		slog.Error("Company operation failed", slog.String("error", err.Error()))
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"moveshare/internal/middleware"
+	"moveshare/internal/models"
+	"moveshare/internal/services"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// DocumentHandler отвечает за счета и квитанции по работам
+type DocumentHandler struct {
+	DocumentService services.DocumentService
+}
+
+func NewDocumentHandler(documentService services.DocumentService) *DocumentHandler {
+	return &DocumentHandler{DocumentService: documentService}
+}
+
+// GetInvoice godoc
+// @Summary Счёт по работе (PDF)
+// @Description Счёт заказчику за завершённую работу: стороны, адреса, даты, оплата и расчёт удержаний. Номер (INV-000042) идёт по порядку внутри компании работы (у работ без компании — внутри её автора) и не меняется при повторной генерации. Доступно тем, кто управляет работой, и назначенному перевозчику
+// @Tags documents
+// @Produce  application/pdf
+// @Param id path string true "ID работы"
+// @Success 200 {file} file
+// @Failure 401 {string} string "unauthorized"
+// @Failure 403 {string} string "forbidden"
+// @Failure 404 {string} string "job not found"
+// @Failure 409 {string} string "job is not completed"
+// @Failure 500 {string} string "internal server error"
+// @Security BearerAuth
+// @Router /jobs/{id}/invoice [get]
+func (h *DocumentHandler) GetInvoice(w http.ResponseWriter, r *http.Request) {
+	h.serve(w, r, models.DocumentInvoice)
+}
+
+// GetReceipt godoc
+// @Summary Квитанция об оплате (PDF)
+// @Description Квитанция о полученной от заказчика оплате завершённой работы. Номер (RCT-000042) идёт по порядку так же, как у счетов. Доступно тем, кто управляет работой, и назначенному перевозчику
+// @Tags documents
+// @Produce  application/pdf
+// @Param id path string true "ID работы"
+// @Success 200 {file} file
+// @Failure 401 {string} string "unauthorized"
+// @Failure 403 {string} string "forbidden"
+// @Failure 404 {string} string "job not found"
+// @Failure 409 {string} string "job is not completed or payment not received"
+// @Failure 500 {string} string "internal server error"
+// @Security BearerAuth
+// @Router /jobs/{id}/receipt [get]
+func (h *DocumentHandler) GetReceipt(w http.ResponseWriter, r *http.Request) {
+	h.serve(w, r, models.DocumentReceipt)
+}
+
+func (h *DocumentHandler) serve(w http.ResponseWriter, r *http.Request, kind models.DocumentKind) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	doc, content, err := h.DocumentService.GetDocument(mux.Vars(r)["id"], userID, kind)
+	if err != nil {
+		writeDocumentError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", doc.DisplayNumber()+".pdf"))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	if _, err := w.Write(content); err != nil {
+		slog.Error("Failed to send document", slog.String("error", err.Error()))
+	}
+}
+
+// RegenerateDocuments godoc
+// @Summary Пересобрать документы работы
+// @Description Заново собирает PDF уже выданных счёта и квитанции по текущим данным (например, после смены названия компании). Номера документов не меняются
+// @Tags admin
+// @Produce  json
+// @Param id path string true "ID работы"
+// @Success 200 {array} models.JobDocument
+// @Failure 401 {string} string "unauthorized"
+// @Failure 403 {string} string "forbidden"
+// @Failure 404 {string} string "job not found"
+// @Failure 409 {string} string "job was deleted, documents can not be regenerated"
+// @Failure 500 {string} string "internal server error"
+// @Security BearerAuth
+// @Router /admin/jobs/{id}/documents/regenerate [post]
+func (h *DocumentHandler) RegenerateDocuments(w http.ResponseWriter, r *http.Request) {
+	docs, err := h.DocumentService.Regenerate(mux.Vars(r)["id"])
+	if err != nil {
+		writeDocumentError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(docs)
+}
+
+func writeDocumentError(w http.ResponseWriter, err error) {
+	switch err {
+	case services.ErrJobNotFound:
+		http.Error(w, "job not found", http.StatusNotFound)
+	case services.ErrJobForbidden:
+		http.Error(w, "forbidden", http.StatusForbidden)
+	case services.ErrJobNotCompleted:
+		http.Error(w, "job is not completed", http.StatusConflict)
+	case services.ErrPaymentNotReceived:
+		http.Error(w, "payment not received", http.StatusConflict)
+	case services.ErrJobDeleted:
+		http.Error(w, "job was deleted, documents can not be regenerated", http.StatusConflict)
+	default:
+		slog.Error("Document operation failed", slog.String("error", err.Error()))
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"moveshare/internal/middleware"
+	"moveshare/internal/models"
+	"moveshare/internal/services"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// FeeHandler отвечает за расчёт удержаний из оплаты и правила для него
+type FeeHandler struct {
+	FeeService services.FeeService
+}
+
+func NewFeeHandler(feeService services.FeeService) *FeeHandler {
+	return &FeeHandler{FeeService: feeService}
+}
+
+// Quote godoc
+// @Summary Предварительный расчёт удержаний
+// @Description Считает комиссию площадки, долю брокера и выплату перевозчику по действующим правилам. Ничего не сохраняет: у работы расчёт фиксируется при закреплении за перевозчиком
+// @Tags fees
+// @Accept  json
+// @Produce  json
+// @Param input body models.FeeQuoteRequest true "Оплата, размер работы и промокод"
+// @Success 200 {object} models.FeeBreakdown
+// @Failure 400 {string} string "invalid request, invalid amount or fees exceed payment"
+// @Failure 401 {string} string "unauthorized"
+// @Failure 500 {string} string "internal server error"
+// @Security BearerAuth
+// @Router /jobs/quote [post]
+func (h *FeeHandler) Quote(w http.ResponseWriter, r *http.Request) {
+	var req models.FeeQuoteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+	quote, err := h.FeeService.Quote(req)
+	if err != nil {
+		writeFeeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(quote)
+}
+
+// GetJobFees godoc
+// @Summary Удержания по работе
+// @Description Расчёт, зафиксированный при закреплении работы: версия правил, строки по каждому правилу и итоговые суммы. Доступно тем, кто управляет работой, и назначенному перевозчику
+// @Tags fees
+// @Produce  json
+// @Param id path string true "ID работы"
+// @Success 200 {object} models.FeeBreakdown
+// @Failure 401 {string} string "unauthorized"
+// @Failure 403 {string} string "forbidden"
+// @Failure 404 {string} string "job not found or job is not booked yet"
+// @Failure 500 {string} string "internal server error"
+// @Security BearerAuth
+// @Router /jobs/{id}/fees [get]
+func (h *FeeHandler) GetJobFees(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	breakdown, err := h.FeeService.GetJobFees(mux.Vars(r)["id"], userID)
+	if err != nil {
+		writeFeeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(breakdown)
+}
+
+// GetRuleSets godoc
+// @Summary Версии правил удержаний
+// @Description Все версии, новые первыми. Действует последняя версия, у которой наступил active_from
+// @Tags admin
+// @Produce  json
+// @Success 200 {array} models.FeeRuleSet
+// @Failure 401 {string} string "unauthorized"
+// @Failure 403 {string} string "forbidden"
+// @Failure 500 {string} string "internal server error"
+// @Security BearerAuth
+// @Router /admin/fee-rules [get]
+func (h *FeeHandler) GetRuleSets(w http.ResponseWriter, r *http.Request) {
+	sets, err := h.FeeService.GetRuleSets()
+	if err != nil {
+		writeFeeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sets)
+}
+
+// CreateRuleSet godoc
+// @Summary Новая версия правил удержаний
+// @Description Правила: percentage (bps от оплаты, 100 bps = 1%), flat (фиксированная сумма), tiered (ступени up_to по оплате; у последней up_to не указывается), promotion (скидка на компонент, можно по promo_code). sizes ограничивает правило размерами работ, min/max — его сумму. Версии не редактируются; работы, закреплённые раньше, сохраняют свой расчёт
+// @Tags admin
+// @Accept  json
+// @Produce  json
+// @Param input body models.CreateFeeRuleSetRequest true "Название, правила и начало действия"
+// @Success 201 {object} models.FeeRuleSet
+// @Failure 400 {string} string "invalid fee rules"
+// @Failure 401 {string} string "unauthorized"
+// @Failure 403 {string} string "forbidden"
+// @Failure 500 {string} string "internal server error"
+// @Security BearerAuth
+// @Router /admin/fee-rules [post]
+func (h *FeeHandler) CreateRuleSet(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req models.CreateFeeRuleSetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+	set, err := h.FeeService.CreateRuleSet(adminID, req)
+	if err != nil {
+		writeFeeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(set)
+}
+
+func writeFeeError(w http.ResponseWriter, err error) {
+	switch err {
+	case services.ErrInvalidAmount:
+		http.Error(w, "invalid amount", http.StatusBadRequest)
+	case services.ErrFeesExceedPayment:
+		http.Error(w, "fees exceed payment", http.StatusBadRequest)
+	case services.ErrInvalidFeeRules:
+		http.Error(w, "invalid fee rules", http.StatusBadRequest)
+	case services.ErrJobNotFound:
+		http.Error(w, "job not found", http.StatusNotFound)
+	case services.ErrJobFeesNotFound:
+		http.Error(w, "job is not booked yet", http.StatusNotFound)
+	case services.ErrJobForbidden:
+		http.Error(w, "forbidden", http.StatusForbidden)
+	default:
+		slog.Error("Fee operation failed", slog.String("error", err.Error()))
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+	}
+}
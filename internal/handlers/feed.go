@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"moveshare/internal/feed"
+	"moveshare/internal/middleware"
+	"moveshare/internal/models"
+	"moveshare/internal/services"
+	"net/http"
+	"time"
+)
+
+// feedHeartbeat — как часто слать комментарий-пинг, чтобы прокси не закрывали тихое соединение
+const feedHeartbeat = 25 * time.Second
+
+// FeedHandler отдаёт ленту событий по работам в реальном времени
+type FeedHandler struct {
+	Broker     feed.Broker
+	JobService services.JobService
+}
+
+func NewFeedHandler(broker feed.Broker, jobService services.JobService) *FeedHandler {
+	return &FeedHandler{Broker: broker, JobService: jobService}
+}
+
+// Stream godoc
+// @Summary Лента работ в реальном времени (SSE)
+// @Description Server-Sent Events: job.created, job.claimed, job.cancelled. Принимает те же фильтры, что GET /jobs; в событие попадают только подходящие работы. Данные события — работа целиком (models.JobEvent). Браузерный EventSource не умеет задавать заголовки, поэтому токен можно передать параметром access_token. Соединение закрывается, когда истекает access-токен — клиент переподключается с новым
+// @Tags jobs
+// @Produce  text/event-stream
+// @Param access_token query string false "Access-токен, если нельзя передать заголовок Authorization"
+// @Param relocation_size query string false "Размер переезда"
+// @Param date_start query string false "Дата начала (RFC3339)"
+// @Param date_end query string false "Дата окончания (RFC3339)"
+// @Param truck_size query string false "Размер грузовика"
+// @Param payout_min query number false "Мин. оплата"
+// @Param payout_max query number false "Макс. оплата"
+// @Param status query string false "Статус работы"
+// @Param min_poster_rating query number false "Минимальная средняя оценка заказчика"
+// @Param company_id query int false "Только работы компании"
+// @Param origin_lat query number false "Широта точки погрузки"
+// @Param origin_lng query number false "Долгота точки погрузки"
+// @Param origin_zip query string false "ZIP точки погрузки"
+// @Param origin_radius query number false "Радиус от точки погрузки, мили"
+// @Param dest_lat query number false "Широта точки доставки"
+// @Param dest_lng query number false "Долгота точки доставки"
+// @Param dest_zip query string false "ZIP точки доставки"
+// @Param dest_radius query number false "Радиус от точки доставки, мили"
+// @Success 200 {object} models.JobEvent
+// @Failure 400 {string} string "address could not be located"
+// @Failure 401 {string} string "unauthorized"
+// @Failure 500 {string} string "internal server error"
+// @Security BearerAuth
+// @Router /jobs/stream [get]
+func (h *FeedHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.ClaimsFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	filter := parseJobFilter(r.URL.Query())
+	if err := h.JobService.ResolveFilter(&filter); err != nil {
+		if err == services.ErrInvalidAddress {
+			http.Error(w, "address could not be located", http.StatusBadRequest)
+			return
+		}
+		slog.Error("Failed to resolve feed filter", slog.String("error", err.Error()))
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	events, cancel := h.Broker.Subscribe()
+	defer cancel()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	if err := rc.Flush(); err != nil {
+		slog.Error("Streaming unsupported", slog.String("error", err.Error()))
+		return
+	}
+
+	heartbeat := time.NewTicker(feedHeartbeat)
+	defer heartbeat.Stop()
+	expired := time.NewTimer(time.Until(claims.ExpiresAt))
+	defer expired.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-expired.C:
+			fmt.Fprint(w, "event: token.expired\ndata: {}\n\n")
+			rc.Flush()
+			return
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			if !feed.Matches(filter, event) {
+				continue
+			}
+			if err := writeFeedEvent(w, event); err != nil {
+				return
+			}
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// writeFeedEvent пишет событие в формате SSE: имя события и JSON в data
+func writeFeedEvent(w http.ResponseWriter, event models.JobEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		slog.Error("Failed to encode feed event", slog.String("error", err.Error()))
+		return nil
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data)
+	return err
+}
@@ -2,9 +2,11 @@ package handlers
 
 import (
 	"encoding/json"
+	"moveshare/internal/middleware"
 	"moveshare/internal/models"
 	"moveshare/internal/services"
 	"net/http"
+	"net/url"
 	"strconv"
 	"time"
 
@@ -22,25 +24,45 @@ func NewJobHandler(jobService services.JobService) *JobHandler {
 
 // CreateJob godoc
 // @Summary Создание новой работы (Job)
-// @Description Создать новую работу (Job) с параметрами перевозки
+// @Description Создать новую работу (Job) с параметрами перевозки. Если в токене выбрана активная компания, работа принадлежит ей
 // @Tags jobs
 // @Accept  json
 // @Produce  json
 // @Param input body models.CreateJobRequest true "Данные для новой работы"
 // @Success 201 {object} models.Job
-// @Failure 400 {string} string "invalid request"
+// @Failure 400 {string} string "invalid request, invalid amount, fees exceed payment or address could not be located"
+// @Failure 401 {string} string "unauthorized"
+// @Failure 403 {string} string "email is not verified or not allowed to post for this company"
 // @Failure 500 {string} string "failed to create job"
 // @Router /jobs [post]
 // @Security BearerAuth
 func (h *JobHandler) CreateJob(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.ClaimsFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
 	var req models.CreateJobRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "invalid request", http.StatusBadRequest)
 		return
 	}
-	job, err := h.JobService.CreateJob(req)
+	job, err := h.JobService.CreateJob(claims.UserID, claims.CompanyID, req)
 	if err != nil {
-		http.Error(w, "failed to create job", http.StatusInternalServerError)
+		switch err {
+		case services.ErrInvalidAddress:
+			http.Error(w, "address could not be located", http.StatusBadRequest)
+		case services.ErrInvalidAmount:
+			http.Error(w, "invalid amount", http.StatusBadRequest)
+		case services.ErrFeesExceedPayment:
+			http.Error(w, "fees exceed payment", http.StatusBadRequest)
+		case services.ErrEmailNotVerified:
+			http.Error(w, "email is not verified", http.StatusForbidden)
+		case services.ErrCompanyForbidden:
+			http.Error(w, "not allowed to post for this company", http.StatusForbidden)
+		default:
+			http.Error(w, "failed to create job", http.StatusInternalServerError)
+		}
 		return
 	}
 	w.WriteHeader(http.StatusCreated)
@@ -57,16 +79,62 @@ func (h *JobHandler) CreateJob(w http.ResponseWriter, r *http.Request) {
 // @Param date_start query string false "Дата начала (ISO8601)"
 // @Param date_end query string false "Дата конца (ISO8601)"
 // @Param truck_size query string false "Размер грузовика (small, medium, large)"
-// @Param payout_min query number false "Минимальная оплата"
-// @Param payout_max query number false "Максимальная оплата"
+// @Param payout_min query string false "Минимальная оплата, десятичной строкой (1250.50)"
+// @Param payout_max query string false "Максимальная оплата, десятичной строкой"
+// @Param payout_currency query string false "Валюта границ оплаты (по умолчанию USD); работы в других валютах не попадают"
+// @Param status query string false "Статус (open, claimed, in_transit, delivered, completed, cancelled)"
+// @Param company_id query int false "Только работы компании"
+// @Param min_poster_rating query number false "Минимальная средняя оценка заказчика (1-5)"
+// @Param origin_lat query number false "Широта точки для поиска по месту погрузки"
+// @Param origin_lng query number false "Долгота точки для поиска по месту погрузки"
+// @Param origin_zip query string false "ZIP-код точки для поиска по месту погрузки (вместо координат)"
+// @Param origin_radius query number false "Радиус поиска по месту погрузки, мили"
+// @Param dest_lat query number false "Широта точки для поиска по месту доставки"
+// @Param dest_lng query number false "Долгота точки для поиска по месту доставки"
+// @Param dest_zip query string false "ZIP-код точки для поиска по месту доставки (вместо координат)"
+// @Param dest_radius query number false "Радиус поиска по месту доставки, мили"
 // @Param limit query int false "Лимит (по умолчанию 10)"
 // @Param offset query int false "Смещение (по умолчанию 0)"
 // @Success 200 {object} models.JobListResponse
+// @Failure 400 {string} string "address could not be located"
 // @Failure 500 {string} string "failed to fetch jobs"
 // @Router /jobs [get]
 // @Security BearerAuth
 func (h *JobHandler) GetJobs(w http.ResponseWriter, r *http.Request) {
 	q := r.URL.Query()
+	filter := parseJobFilter(q)
+	limit := 10
+	offset := 0
+	if v := q.Get("limit"); v != "" {
+		if i, err := strconv.Atoi(v); err == nil {
+			limit = i
+		}
+	}
+	if v := q.Get("offset"); v != "" {
+		if i, err := strconv.Atoi(v); err == nil {
+			offset = i
+		}
+	}
+
+	jobs, total, err := h.JobService.GetJobs(filter, limit, offset)
+	if err != nil {
+		if err == services.ErrInvalidAddress {
+			http.Error(w, "address could not be located", http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "failed to fetch jobs", http.StatusInternalServerError)
+		return
+	}
+	resp := models.JobListResponse{
+		Jobs:  jobs,
+		Total: total,
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// parseJobFilter читает параметры фильтра GET /jobs; их же принимает лента /jobs/stream
+func parseJobFilter(q url.Values) models.JobFilter {
 	filter := models.JobFilter{}
 
 	if v := q.Get("relocation_size"); v != "" {
@@ -86,67 +154,336 @@ func (h *JobHandler) GetJobs(w http.ResponseWriter, r *http.Request) {
 		filter.TruckSize = v
 	}
 	if v := q.Get("payout_min"); v != "" {
-		if f, err := strconv.ParseFloat(v, 64); err == nil {
-			filter.PayoutMin = &f
+		if m, err := models.ParseMoney(v, q.Get("payout_currency")); err == nil {
+			filter.PayoutMin = &m
 		}
 	}
 	if v := q.Get("payout_max"); v != "" {
-		if f, err := strconv.ParseFloat(v, 64); err == nil {
-			filter.PayoutMax = &f
+		if m, err := models.ParseMoney(v, q.Get("payout_currency")); err == nil {
+			filter.PayoutMax = &m
 		}
 	}
-	limit := 10
-	offset := 0
-	if v := q.Get("limit"); v != "" {
-		if i, err := strconv.Atoi(v); err == nil {
-			limit = i
+	if v := q.Get("status"); v != "" {
+		filter.Status = v
+	}
+	if v := q.Get("min_poster_rating"); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			filter.MinPosterRating = &f
 		}
 	}
-	if v := q.Get("offset"); v != "" {
+	if v := q.Get("company_id"); v != "" {
 		if i, err := strconv.Atoi(v); err == nil {
-			offset = i
+			filter.CompanyID = i
 		}
 	}
+	filter.Origin = parseGeoRadius(q, "origin")
+	filter.Destination = parseGeoRadius(q, "dest")
+	return filter
+}
 
-	jobs, total, err := h.JobService.GetJobs(filter, limit, offset)
-	if err != nil {
-		http.Error(w, "failed to fetch jobs", http.StatusInternalServerError)
+// parseGeoRadius читает параметры <prefix>_lat, <prefix>_lng, <prefix>_zip и <prefix>_radius.
+// Без радиуса или без точки фильтр не применяется
+func parseGeoRadius(q url.Values, prefix string) *models.GeoRadius {
+	miles, err := strconv.ParseFloat(q.Get(prefix+"_radius"), 64)
+	if err != nil || miles <= 0 {
+		return nil
+	}
+	radius := &models.GeoRadius{Miles: miles, ZIP: q.Get(prefix + "_zip")}
+	if radius.ZIP != "" {
+		return radius
+	}
+	lat, latErr := strconv.ParseFloat(q.Get(prefix+"_lat"), 64)
+	lng, lngErr := strconv.ParseFloat(q.Get(prefix+"_lng"), 64)
+	if latErr != nil || lngErr != nil {
+		return nil
+	}
+	radius.Latitude, radius.Longitude = lat, lng
+	return radius
+}
+
+// UpdateJob godoc
+// @Summary Обновить работу (Job) по id
+// @Description Полностью обновляет работу (Job). Доступно только пользователю, который её опубликовал
+// @Tags jobs
+// @Accept  json
+// @Produce  json
+// @Param id path string true "ID работы"
+// @Param input body models.CreateJobRequest true "Новые данные работы"
+// @Success 200 {object} models.Job
+// @Failure 400 {string} string "invalid request, invalid amount, fees exceed payment or address could not be located"
+// @Failure 401 {string} string "unauthorized"
+// @Failure 403 {string} string "forbidden"
+// @Failure 404 {string} string "job not found"
+// @Failure 409 {string} string "job is no longer open"
+// @Failure 500 {string} string "failed to update job"
+// @Security BearerAuth
+// @Router /jobs/{id} [put]
+func (h *JobHandler) UpdateJob(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
 		return
 	}
-	resp := models.JobListResponse{
-		Jobs:  jobs,
-		Total: total,
+	id := mux.Vars(r)["id"]
+	if id == "" {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+	var req models.CreateJobRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+	job, err := h.JobService.UpdateJob(id, userID, req)
+	if err != nil {
+		switch err {
+		case services.ErrJobNotFound:
+			http.Error(w, "job not found", http.StatusNotFound)
+		case services.ErrJobForbidden:
+			http.Error(w, "forbidden", http.StatusForbidden)
+		case services.ErrJobNotOpen:
+			http.Error(w, "job is no longer open", http.StatusConflict)
+		case services.ErrInvalidAddress:
+			http.Error(w, "address could not be located", http.StatusBadRequest)
+		case services.ErrInvalidAmount:
+			http.Error(w, "invalid amount", http.StatusBadRequest)
+		case services.ErrFeesExceedPayment:
+			http.Error(w, "fees exceed payment", http.StatusBadRequest)
+		default:
+			http.Error(w, "failed to update job", http.StatusInternalServerError)
+		}
+		return
 	}
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(resp)
+	json.NewEncoder(w).Encode(job)
 }
 
 // DeleteJob godoc
 // @Summary Удалить работу (Job) по id
-// @Description Удаляет работу (Job) по её id
+// @Description Удаляет работу (Job) по её id. Доступно только пользователю, который её опубликовал
 // @Tags jobs
 // @Param id path string true "ID работы"
 // @Success 204 {string} string "deleted"
 // @Failure 400 {string} string "invalid id"
+// @Failure 401 {string} string "unauthorized"
+// @Failure 403 {string} string "forbidden"
 // @Failure 404 {string} string "job not found"
+// @Failure 409 {string} string "job is no longer open"
 // @Failure 500 {string} string "failed to delete job"
 // @Security BearerAuth
 // @Router /jobs/{id} [delete]
 func (h *JobHandler) DeleteJob(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
 	vars := mux.Vars(r)
 	id := vars["id"]
 	if id == "" {
 		http.Error(w, "invalid id", http.StatusBadRequest)
 		return
 	}
-	err := h.JobService.DeleteJob(id)
+	err := h.JobService.DeleteJob(id, userID)
 	if err != nil {
-		if err == services.ErrJobNotFound {
+		switch err {
+		case services.ErrJobNotFound:
 			http.Error(w, "job not found", http.StatusNotFound)
-			return
+		case services.ErrJobForbidden:
+			http.Error(w, "forbidden", http.StatusForbidden)
+		case services.ErrJobNotOpen:
+			http.Error(w, "job is no longer open", http.StatusConflict)
+		default:
+			http.Error(w, "failed to delete job", http.StatusInternalServerError)
 		}
-		http.Error(w, "failed to delete job", http.StatusInternalServerError)
 		return
 	}
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// ClaimJob godoc
+// @Summary Взять работу (Job)
+// @Description Перевозчик закрепляет за собой открытую работу. Доступно только проверенным перевозчикам с действующей страховкой. Из одновременных запросов выигрывает только один. Удержания из оплаты считаются по действующим правилам и фиксируются за работой (GET /jobs/{id}/fees)
+// @Tags jobs
+// @Produce  json
+// @Param id path string true "ID работы"
+// @Success 200 {object} models.Job
+// @Failure 400 {string} string "fees exceed payment"
+// @Failure 401 {string} string "unauthorized"
+// @Failure 403 {string} string "cannot claim own job, not a carrier or carrier is not verified"
+// @Failure 404 {string} string "job not found"
+// @Failure 409 {string} string "job is no longer open"
+// @Failure 500 {string} string "failed to update job status"
+// @Security BearerAuth
+// @Router /jobs/{id}/claim [post]
+func (h *JobHandler) ClaimJob(w http.ResponseWriter, r *http.Request) {
+	h.changeStatus(w, r, h.JobService.ClaimJob)
+}
+
+// StartJob godoc
+// @Summary Начать перевозку
+// @Description Перевозчик отмечает, что груз забран и находится в пути (claimed -> in_transit)
+// @Tags jobs
+// @Produce  json
+// @Param id path string true "ID работы"
+// @Success 200 {object} models.Job
+// @Failure 401 {string} string "unauthorized"
+// @Failure 403 {string} string "forbidden"
+// @Failure 404 {string} string "job not found"
+// @Failure 409 {string} string "invalid job status transition"
+// @Failure 500 {string} string "failed to update job status"
+// @Security BearerAuth
+// @Router /jobs/{id}/start [post]
+func (h *JobHandler) StartJob(w http.ResponseWriter, r *http.Request) {
+	h.changeStatus(w, r, h.JobService.StartJob)
+}
+
+// DeliverJob godoc
+// @Summary Отметить доставку
+// @Description Перевозчик отмечает, что груз доставлен (in_transit -> delivered)
+// @Tags jobs
+// @Produce  json
+// @Param id path string true "ID работы"
+// @Success 200 {object} models.Job
+// @Failure 401 {string} string "unauthorized"
+// @Failure 403 {string} string "forbidden"
+// @Failure 404 {string} string "job not found"
+// @Failure 409 {string} string "invalid job status transition"
+// @Failure 500 {string} string "failed to update job status"
+// @Security BearerAuth
+// @Router /jobs/{id}/deliver [post]
+func (h *JobHandler) DeliverJob(w http.ResponseWriter, r *http.Request) {
+	h.changeStatus(w, r, h.JobService.DeliverJob)
+}
+
+// CompleteJob godoc
+// @Summary Завершить работу
+// @Description Заказчик подтверждает доставку (delivered -> completed). Пока оплата по эскроу не списана, работу завершить нельзя
+// @Tags jobs
+// @Produce  json
+// @Param id path string true "ID работы"
+// @Success 200 {object} models.Job
+// @Failure 401 {string} string "unauthorized"
+// @Failure 403 {string} string "forbidden"
+// @Failure 404 {string} string "job not found"
+// @Failure 409 {string} string "invalid job status transition or escrow is not funded yet"
+// @Failure 500 {string} string "failed to update job status"
+// @Security BearerAuth
+// @Router /jobs/{id}/complete [post]
+func (h *JobHandler) CompleteJob(w http.ResponseWriter, r *http.Request) {
+	h.changeStatus(w, r, h.JobService.CompleteJob)
+}
+
+// CancelJob godoc
+// @Summary Отменить работу
+// @Description Заказчик отменяет работу, пока груз ещё не забран (open/claimed -> cancelled)
+// @Tags jobs
+// @Produce  json
+// @Param id path string true "ID работы"
+// @Success 200 {object} models.Job
+// @Failure 401 {string} string "unauthorized"
+// @Failure 403 {string} string "forbidden"
+// @Failure 404 {string} string "job not found"
+// @Failure 409 {string} string "invalid job status transition"
+// @Failure 500 {string} string "failed to update job status"
+// @Security BearerAuth
+// @Router /jobs/{id}/cancel [post]
+func (h *JobHandler) CancelJob(w http.ResponseWriter, r *http.Request) {
+	h.changeStatus(w, r, h.JobService.CancelJob)
+}
+
+// GetBackhauls godoc
+// @Summary Подобрать обратные грузы
+// @Description Открытые работы, место погрузки которых рядом с местом доставки указанной работы, а погрузка — не раньше её доставки. Отсортированы по порожнему пробегу, затем по оплате
+// @Tags jobs
+// @Produce  json
+// @Param id path string true "ID работы"
+// @Param radius query number false "Радиус поиска, мили (по умолчанию 100)"
+// @Param limit query int false "Лимит (по умолчанию 10)"
+// @Param offset query int false "Смещение (по умолчанию 0)"
+// @Success 200 {object} models.BackhaulListResponse
+// @Failure 400 {string} string "job has no delivery location"
+// @Failure 404 {string} string "job not found"
+// @Failure 500 {string} string "failed to fetch backhauls"
+// @Security BearerAuth
+// @Router /jobs/{id}/backhauls [get]
+func (h *JobHandler) GetBackhauls(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	radius := 100.0
+	if v := q.Get("radius"); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil && f > 0 {
+			radius = f
+		}
+	}
+	limit := 10
+	offset := 0
+	if v := q.Get("limit"); v != "" {
+		if i, err := strconv.Atoi(v); err == nil {
+			limit = i
+		}
+	}
+	if v := q.Get("offset"); v != "" {
+		if i, err := strconv.Atoi(v); err == nil {
+			offset = i
+		}
+	}
+
+	backhauls, total, err := h.JobService.GetBackhauls(mux.Vars(r)["id"], radius, limit, offset)
+	if err != nil {
+		switch err {
+		case services.ErrJobNotFound:
+			http.Error(w, "job not found", http.StatusNotFound)
+		case services.ErrInvalidAddress:
+			http.Error(w, "job has no delivery location", http.StatusBadRequest)
+		default:
+			http.Error(w, "failed to fetch backhauls", http.StatusInternalServerError)
+		}
+		return
+	}
+	resp := models.BackhaulListResponse{
+		Backhauls: backhauls,
+		Total:     total,
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// changeStatus — общая часть эндпоинтов смены статуса работы
+func (h *JobHandler) changeStatus(w http.ResponseWriter, r *http.Request, action func(id string, userID int) (*models.Job, error)) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	id := mux.Vars(r)["id"]
+	if id == "" {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+	job, err := action(id, userID)
+	if err != nil {
+		switch err {
+		case services.ErrJobNotFound:
+			http.Error(w, "job not found", http.StatusNotFound)
+		case services.ErrJobForbidden:
+			http.Error(w, "forbidden", http.StatusForbidden)
+		case services.ErrCannotClaimOwnJob:
+			http.Error(w, "cannot claim own job", http.StatusForbidden)
+		case services.ErrCarrierNotVerified:
+			http.Error(w, "carrier is not verified", http.StatusForbidden)
+		case services.ErrJobNotOpen:
+			http.Error(w, "job is no longer open", http.StatusConflict)
+		case services.ErrInvalidTransition:
+			http.Error(w, "invalid job status transition", http.StatusConflict)
+		case services.ErrFeesExceedPayment:
+			http.Error(w, "fees exceed payment", http.StatusBadRequest)
+		case services.ErrEscrowNotFunded:
+			http.Error(w, "escrow is not funded yet", http.StatusConflict)
+		default:
+			http.Error(w, "failed to update job status", http.StatusInternalServerError)
+		}
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(job)
+}
@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"encoding/json"
+	"moveshare/internal/models"
+	"moveshare/internal/services"
+	"net/http"
+)
+
+// JWKSHandler публикует публичные ключи для проверки токенов другими сервисами
+type JWKSHandler struct {
+	JWTService services.JWTService
+}
+
+func NewJWKSHandler(jwtService services.JWTService) *JWKSHandler {
+	return &JWKSHandler{JWTService: jwtService}
+}
+
+// GetJWKS godoc
+// @Summary Публичные ключи подписи (JWKS)
+// @Description Набор ключей, которыми подписаны действующие токены. Заголовок kid токена указывает на ключ из набора
+// @Tags auth
+// @Produce  json
+// @Success 200 {object} models.JWKS
+// @Router /.well-known/jwks.json [get]
+func (h *JWKSHandler) GetJWKS(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	var jwks models.JWKS = h.JWTService.JWKS()
+	json.NewEncoder(w).Encode(jwks)
+}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"moveshare/internal/middleware"
+	"moveshare/internal/models"
+	"moveshare/internal/services"
+	"net/http"
+	"time"
+)
+
+// LedgerHandler отвечает за балансы и выписки по счетам
+type LedgerHandler struct {
+	LedgerService services.LedgerService
+}
+
+func NewLedgerHandler(ledgerService services.LedgerService) *LedgerHandler {
+	return &LedgerHandler{LedgerService: ledgerService}
+}
+
+// GetBalances godoc
+// @Summary Мои балансы
+// @Description Личные счета с остатками: poster_balance — деньги заказчика у площадки (внесённая оплата и доля брокера до выплаты), carrier_payable — сколько площадка должна перевозчику. Положительный остаток — долг площадки пользователю
+// @Tags ledger
+// @Produce  json
+// @Success 200 {array} models.LedgerAccount
+// @Failure 401 {string} string "unauthorized"
+// @Failure 500 {string} string "internal server error"
+// @Security BearerAuth
+// @Router /ledger/balances [get]
+func (h *LedgerHandler) GetBalances(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	accounts, err := h.LedgerService.GetBalances(userID)
+	if err != nil {
+		writeLedgerError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(accounts)
+}
+
+// GetStatement godoc
+// @Summary Выписка по счетам
+// @Description Остаток на начало периода, движения за период [from, to) с остатком после каждого и остаток на конец. Даты — YYYY-MM-DD или RFC3339; по умолчанию с начала текущего месяца по текущий момент
+// @Tags ledger
+// @Produce  json
+// @Param from query string false "Начало периода"
+// @Param to query string false "Конец периода (не включается)"
+// @Success 200 {object} models.Statement
+// @Failure 400 {string} string "invalid date range"
+// @Failure 401 {string} string "unauthorized"
+// @Failure 500 {string} string "internal server error"
+// @Security BearerAuth
+// @Router /ledger/statement [get]
+func (h *LedgerHandler) GetStatement(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	now := time.Now().UTC()
+	from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	to := now
+	q := r.URL.Query()
+	for _, p := range []struct {
+		name string
+		dst  *time.Time
+	}{{"from", &from}, {"to", &to}} {
+		v := q.Get(p.name)
+		if v == "" {
+			continue
+		}
+		t, err := parseDate(v)
+		if err != nil {
+			http.Error(w, "invalid date range", http.StatusBadRequest)
+			return
+		}
+		*p.dst = t
+	}
+	statement, err := h.LedgerService.GetStatement(userID, from, to)
+	if err != nil {
+		writeLedgerError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(statement)
+}
+
+// GetAccounts godoc
+// @Summary Счета для сверки
+// @Description Все счета выбранного типа с остатками: carrier_payable — долги перевозчикам, poster_balance — деньги заказчиков, platform_revenue — выручка, escrow — деньги на счёте площадки
+// @Tags admin
+// @Produce  json
+// @Param type query string true "Тип счёта"
+// @Success 200 {array} models.LedgerAccount
+// @Failure 400 {string} string "invalid account type"
+// @Failure 401 {string} string "unauthorized"
+// @Failure 403 {string} string "forbidden"
+// @Failure 500 {string} string "internal server error"
+// @Security BearerAuth
+// @Router /admin/ledger/accounts [get]
+func (h *LedgerHandler) GetAccounts(w http.ResponseWriter, r *http.Request) {
+	accounts, err := h.LedgerService.GetAccounts(models.AccountType(r.URL.Query().Get("type")))
+	if err != nil {
+		writeLedgerError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(accounts)
+}
+
+// parseDate принимает дату YYYY-MM-DD (полночь UTC) или момент в RFC3339
+func parseDate(v string) (time.Time, error) {
+	if t, err := time.Parse("2006-01-02", v); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, v)
+}
+
+func writeLedgerError(w http.ResponseWriter, err error) {
+	switch err {
+	case services.ErrInvalidDateRange:
+		http.Error(w, "invalid date range", http.StatusBadRequest)
+	case services.ErrInvalidAccountType:
+		http.Error(w, "invalid account type", http.StatusBadRequest)
+	default:
+		slog.Error("Ledger operation failed", slog.String("error", err.Error()))
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"moveshare/internal/middleware"
+	"moveshare/internal/models"
+	"moveshare/internal/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// MessageHandler отвечает за переписку заказчика и перевозчиков по работе
+type MessageHandler struct {
+	MessageService services.MessageService
+}
+
+func NewMessageHandler(messageService services.MessageService) *MessageHandler {
+	return &MessageHandler{MessageService: messageService}
+}
+
+// GetThreads godoc
+// @Summary Переписки по работе
+// @Description Заказчик видит переписки со всеми перевозчиками, перевозчик — только свою. unread_count — непрочитанные сообщения от другой стороны
+// @Tags messages
+// @Produce  json
+// @Param id path string true "ID работы"
+// @Success 200 {array} models.MessageThread
+// @Failure 401 {string} string "unauthorized"
+// @Failure 404 {string} string "job not found"
+// @Failure 500 {string} string "internal server error"
+// @Security BearerAuth
+// @Router /jobs/{id}/threads [get]
+func (h *MessageHandler) GetThreads(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	threads, err := h.MessageService.GetThreads(mux.Vars(r)["id"], userID)
+	if err != nil {
+		writeMessageError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(threads)
+}
+
+// GetMessages godoc
+// @Summary Сообщения переписки
+// @Description Сообщения переписки по работе с перевозчиком carrierID, новые первыми. read_at — когда сообщение прочитала другая сторона
+// @Tags messages
+// @Produce  json
+// @Param id path string true "ID работы"
+// @Param carrierID path int true "ID перевозчика"
+// @Param limit query int false "Лимит (по умолчанию 50)"
+// @Param offset query int false "Смещение (по умолчанию 0)"
+// @Success 200 {object} models.MessageListResponse
+// @Failure 400 {string} string "invalid carrier id"
+// @Failure 401 {string} string "unauthorized"
+// @Failure 403 {string} string "not a participant of this thread"
+// @Failure 404 {string} string "job not found"
+// @Failure 500 {string} string "internal server error"
+// @Security BearerAuth
+// @Router /jobs/{id}/threads/{carrierID}/messages [get]
+func (h *MessageHandler) GetMessages(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	carrierID, err := strconv.Atoi(mux.Vars(r)["carrierID"])
+	if err != nil {
+		http.Error(w, "invalid carrier id", http.StatusBadRequest)
+		return
+	}
+	q := r.URL.Query()
+	limit := 50
+	offset := 0
+	if v := q.Get("limit"); v != "" {
+		if i, err := strconv.Atoi(v); err == nil {
+			limit = i
+		}
+	}
+	if v := q.Get("offset"); v != "" {
+		if i, err := strconv.Atoi(v); err == nil {
+			offset = i
+		}
+	}
+
+	messages, total, err := h.MessageService.GetMessages(mux.Vars(r)["id"], carrierID, userID, limit, offset)
+	if err != nil {
+		writeMessageError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.MessageListResponse{Messages: messages, Total: total})
+}
+
+// SendMessage godoc
+// @Summary Отправить сообщение
+// @Description Переписка с перевозчиком открывается после того, как он сделал ставку на работу или взял её
+// @Tags messages
+// @Accept  json
+// @Produce  json
+// @Param id path string true "ID работы"
+// @Param carrierID path int true "ID перевозчика"
+// @Param input body models.SendMessageRequest true "Текст сообщения"
+// @Success 201 {object} models.Message
+// @Failure 400 {string} string "invalid message"
+// @Failure 401 {string} string "unauthorized"
+// @Failure 403 {string} string "not a participant of this thread"
+// @Failure 404 {string} string "job not found"
+// @Failure 409 {string} string "thread is not open yet"
+// @Failure 500 {string} string "internal server error"
+// @Security BearerAuth
+// @Router /jobs/{id}/threads/{carrierID}/messages [post]
+func (h *MessageHandler) SendMessage(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	carrierID, err := strconv.Atoi(mux.Vars(r)["carrierID"])
+	if err != nil {
+		http.Error(w, "invalid carrier id", http.StatusBadRequest)
+		return
+	}
+	var req models.SendMessageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+	msg, err := h.MessageService.SendMessage(mux.Vars(r)["id"], carrierID, userID, req)
+	if err != nil {
+		writeMessageError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(msg)
+}
+
+// MarkRead godoc
+// @Summary Отметить переписку прочитанной
+// @Description Отмечает прочитанными все сообщения другой стороны
+// @Tags messages
+// @Produce  json
+// @Param id path string true "ID работы"
+// @Param carrierID path int true "ID перевозчика"
+// @Success 200 {object} models.MarkReadResponse
+// @Failure 400 {string} string "invalid carrier id"
+// @Failure 401 {string} string "unauthorized"
+// @Failure 403 {string} string "not a participant of this thread"
+// @Failure 404 {string} string "job not found"
+// @Failure 500 {string} string "internal server error"
+// @Security BearerAuth
+// @Router /jobs/{id}/threads/{carrierID}/read [post]
+func (h *MessageHandler) MarkRead(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	carrierID, err := strconv.Atoi(mux.Vars(r)["carrierID"])
+	if err != nil {
+		http.Error(w, "invalid carrier id", http.StatusBadRequest)
+		return
+	}
+	marked, err := h.MessageService.MarkRead(mux.Vars(r)["id"], carrierID, userID)
+	if err != nil {
+		writeMessageError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.MarkReadResponse{Marked: marked})
+}
+
+func writeMessageError(w http.ResponseWriter, err error) {
+	switch err {
+	case services.ErrInvalidMessage:
+		http.Error(w, "invalid message", http.StatusBadRequest)
+	case services.ErrThreadForbidden:
+		http.Error(w, "not a participant of this thread", http.StatusForbidden)
+	case services.ErrJobNotFound:
+		http.Error(w, "job not found", http.StatusNotFound)
+	case services.ErrThreadNotOpen:
+		http.Error(w, "thread is not open yet", http.StatusConflict)
+	default:
+		slog.Error("Message operation failed", slog.String("error", err.Error()))
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"moveshare/internal/middleware"
+	"moveshare/internal/models"
+	"moveshare/internal/services"
+	"net/http"
+	"strconv"
+)
+
+// NotificationHandler отдаёт уведомления пользователя в приложении
+type NotificationHandler struct {
+	NotificationService services.NotificationService
+}
+
+func NewNotificationHandler(notificationService services.NotificationService) *NotificationHandler {
+	return &NotificationHandler{NotificationService: notificationService}
+}
+
+// GetNotifications godoc
+// @Summary Мои уведомления
+// @Description Уведомления в приложении, новые первыми. unread — общее число непрочитанных
+// @Tags notifications
+// @Produce  json
+// @Param unread query bool false "Только непрочитанные"
+// @Param limit query int false "Лимит (по умолчанию 20)"
+// @Param offset query int false "Смещение (по умолчанию 0)"
+// @Success 200 {object} models.NotificationListResponse
+// @Failure 401 {string} string "unauthorized"
+// @Failure 500 {string} string "internal server error"
+// @Security BearerAuth
+// @Router /notifications [get]
+func (h *NotificationHandler) GetNotifications(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	q := r.URL.Query()
+	unreadOnly := q.Get("unread") == "true"
+	limit := 20
+	offset := 0
+	if v := q.Get("limit"); v != "" {
+		if i, err := strconv.Atoi(v); err == nil {
+			limit = i
+		}
+	}
+	if v := q.Get("offset"); v != "" {
+		if i, err := strconv.Atoi(v); err == nil {
+			offset = i
+		}
+	}
+
+	resp, err := h.NotificationService.GetNotifications(userID, unreadOnly, limit, offset)
+	if err != nil {
+		slog.Error("Failed to fetch notifications", slog.String("error", err.Error()))
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// MarkNotificationsRead godoc
+// @Summary Отметить уведомления прочитанными
+// @Tags notifications
+// @Produce  json
+// @Success 200 {object} models.MarkReadResponse
+// @Failure 401 {string} string "unauthorized"
+// @Failure 500 {string} string "internal server error"
+// @Security BearerAuth
+// @Router /notifications/read [post]
+func (h *NotificationHandler) MarkNotificationsRead(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	marked, err := h.NotificationService.MarkAllRead(userID)
+	if err != nil {
+		slog.Error("Failed to mark notifications read", slog.String("error", err.Error()))
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.MarkReadResponse{Marked: marked})
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"moveshare/internal/middleware"
+	"moveshare/internal/models"
+	"moveshare/internal/services"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// PaymentHandler отвечает за оплату работ через эскроу
+type PaymentHandler struct {
+	PaymentService services.PaymentService
+}
+
+func NewPaymentHandler(paymentService services.PaymentService) *PaymentHandler {
+	return &PaymentHandler{PaymentService: paymentService}
+}
+
+// GetEscrow godoc
+// @Summary Оплата работы
+// @Description Эскроу работы: pending (ждёт списания), funded (оплата удерживается площадкой), released (выплачено перевозчику и брокеру), refunded (возвращено заказчику), cancelled (отменено до списания). ledger — все движения денег по работе. Доступно тем, кто управляет работой, и назначенному перевозчику
+// @Tags payments
+// @Produce  json
+// @Param id path string true "ID работы"
+// @Success 200 {object} models.Escrow
+// @Failure 401 {string} string "unauthorized"
+// @Failure 403 {string} string "forbidden"
+// @Failure 404 {string} string "job not found or escrow not found"
+// @Failure 500 {string} string "internal server error"
+// @Security BearerAuth
+// @Router /jobs/{id}/escrow [get]
+func (h *PaymentHandler) GetEscrow(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var escrow *models.Escrow
+	escrow, err := h.PaymentService.GetEscrow(mux.Vars(r)["id"], userID)
+	if err != nil {
+		writePaymentError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(escrow)
+}
+
+func writePaymentError(w http.ResponseWriter, err error) {
+	switch err {
+	case services.ErrJobNotFound:
+		http.Error(w, "job not found", http.StatusNotFound)
+	case services.ErrEscrowNotFound:
+		http.Error(w, "escrow not found", http.StatusNotFound)
+	case services.ErrJobForbidden:
+		http.Error(w, "forbidden", http.StatusForbidden)
+	default:
+		slog.Error("Payment operation failed", slog.String("error", err.Error()))
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+	}
+}
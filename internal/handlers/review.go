@@ -0,0 +1,241 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"moveshare/internal/middleware"
+	"moveshare/internal/models"
+	"moveshare/internal/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// ReviewHandler отвечает за отзывы и публичную репутацию пользователей и компаний
+type ReviewHandler struct {
+	ReviewService services.ReviewService
+}
+
+func NewReviewHandler(reviewService services.ReviewService) *ReviewHandler {
+	return &ReviewHandler{ReviewService: reviewService}
+}
+
+// CreateReview godoc
+// @Summary Оставить отзыв по работе
+// @Description Только для завершённых работ. Перевозчик оценивает заказчика, заказчик — перевозчика; по одному отзыву от каждой стороны. Отзыв можно исправить в течение 7 дней
+// @Tags reviews
+// @Accept  json
+// @Produce  json
+// @Param id path string true "ID работы"
+// @Param input body models.CreateReviewRequest true "Оценка от 1 до 5 и комментарий"
+// @Success 201 {object} models.Review
+// @Failure 400 {string} string "invalid review"
+// @Failure 401 {string} string "unauthorized"
+// @Failure 403 {string} string "forbidden"
+// @Failure 404 {string} string "job not found"
+// @Failure 409 {string} string "job is not completed or review already left"
+// @Failure 500 {string} string "internal server error"
+// @Security BearerAuth
+// @Router /jobs/{id}/reviews [post]
+func (h *ReviewHandler) CreateReview(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req models.CreateReviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+	review, err := h.ReviewService.CreateReview(mux.Vars(r)["id"], userID, req)
+	if err != nil {
+		writeReviewError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(review)
+}
+
+// GetJobReviews godoc
+// @Summary Отзывы по работе
+// @Tags reviews
+// @Produce  json
+// @Param id path string true "ID работы"
+// @Success 200 {array} models.Review
+// @Failure 401 {string} string "unauthorized"
+// @Failure 404 {string} string "job not found"
+// @Failure 500 {string} string "internal server error"
+// @Security BearerAuth
+// @Router /jobs/{id}/reviews [get]
+func (h *ReviewHandler) GetJobReviews(w http.ResponseWriter, r *http.Request) {
+	reviews, err := h.ReviewService.GetJobReviews(mux.Vars(r)["id"])
+	if err != nil {
+		writeReviewError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(reviews)
+}
+
+// UpdateReview godoc
+// @Summary Исправить отзыв
+// @Description Автор может изменить оценку и комментарий в течение 7 дней после публикации, затем отзыв фиксируется
+// @Tags reviews
+// @Accept  json
+// @Produce  json
+// @Param id path string true "ID отзыва"
+// @Param input body models.CreateReviewRequest true "Оценка от 1 до 5 и комментарий"
+// @Success 200 {object} models.Review
+// @Failure 400 {string} string "invalid review"
+// @Failure 401 {string} string "unauthorized"
+// @Failure 403 {string} string "forbidden"
+// @Failure 404 {string} string "review not found"
+// @Failure 409 {string} string "review can no longer be edited"
+// @Failure 500 {string} string "internal server error"
+// @Security BearerAuth
+// @Router /reviews/{id} [put]
+func (h *ReviewHandler) UpdateReview(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req models.CreateReviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+	review, err := h.ReviewService.UpdateReview(mux.Vars(r)["id"], userID, req)
+	if err != nil {
+		writeReviewError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(review)
+}
+
+// GetUserProfile godoc
+// @Summary Публичный профиль пользователя
+// @Description Средняя оценка, число отзывов и завершённых работ
+// @Tags reviews
+// @Produce  json
+// @Param id path int true "ID пользователя"
+// @Success 200 {object} models.UserProfile
+// @Failure 400 {string} string "invalid id"
+// @Failure 401 {string} string "unauthorized"
+// @Failure 404 {string} string "user not found"
+// @Failure 500 {string} string "internal server error"
+// @Security BearerAuth
+// @Router /users/{id}/profile [get]
+func (h *ReviewHandler) GetUserProfile(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+	profile, err := h.ReviewService.GetUserProfile(userID)
+	if err != nil {
+		writeReviewError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(profile)
+}
+
+// GetUserReviews godoc
+// @Summary Отзывы о пользователе
+// @Tags reviews
+// @Produce  json
+// @Param id path int true "ID пользователя"
+// @Param limit query int false "Лимит (по умолчанию 20)"
+// @Param offset query int false "Смещение (по умолчанию 0)"
+// @Success 200 {object} models.ReviewListResponse
+// @Failure 400 {string} string "invalid id"
+// @Failure 401 {string} string "unauthorized"
+// @Failure 500 {string} string "internal server error"
+// @Security BearerAuth
+// @Router /users/{id}/reviews [get]
+func (h *ReviewHandler) GetUserReviews(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+	q := r.URL.Query()
+	limit := 20
+	offset := 0
+	if v := q.Get("limit"); v != "" {
+		if i, err := strconv.Atoi(v); err == nil {
+			limit = i
+		}
+	}
+	if v := q.Get("offset"); v != "" {
+		if i, err := strconv.Atoi(v); err == nil {
+			offset = i
+		}
+	}
+	reviews, total, err := h.ReviewService.GetUserReviews(userID, limit, offset)
+	if err != nil {
+		writeReviewError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.ReviewListResponse{Reviews: reviews, Total: total})
+}
+
+// GetCompanyProfile godoc
+// @Summary Публичный профиль компании
+// @Description Средняя оценка по отзывам на работы компании, число отзывов и завершённых работ
+// @Tags reviews
+// @Produce  json
+// @Param id path int true "ID компании"
+// @Success 200 {object} models.CompanyProfile
+// @Failure 400 {string} string "invalid id"
+// @Failure 401 {string} string "unauthorized"
+// @Failure 404 {string} string "company not found"
+// @Failure 500 {string} string "internal server error"
+// @Security BearerAuth
+// @Router /companies/{id}/profile [get]
+func (h *ReviewHandler) GetCompanyProfile(w http.ResponseWriter, r *http.Request) {
+	companyID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+	profile, err := h.ReviewService.GetCompanyProfile(companyID)
+	if err != nil {
+		writeReviewError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(profile)
+}
+
+func writeReviewError(w http.ResponseWriter, err error) {
+	switch err {
+	case services.ErrInvalidReview:
+		http.Error(w, "invalid review", http.StatusBadRequest)
+	case services.ErrJobForbidden, services.ErrReviewForbidden:
+		http.Error(w, "forbidden", http.StatusForbidden)
+	case services.ErrJobNotFound:
+		http.Error(w, "job not found", http.StatusNotFound)
+	case services.ErrReviewNotFound:
+		http.Error(w, "review not found", http.StatusNotFound)
+	case services.ErrUserNotFound:
+		http.Error(w, "user not found", http.StatusNotFound)
+	case services.ErrCompanyNotFound:
+		http.Error(w, "company not found", http.StatusNotFound)
+	case services.ErrJobNotCompleted:
+		http.Error(w, "job is not completed", http.StatusConflict)
+	case services.ErrReviewExists:
+		http.Error(w, "review already left for this job", http.StatusConflict)
+	case services.ErrReviewLocked:
+		http.Error(w, "review can no longer be edited", http.StatusConflict)
+	default:
+		slog.Error("Review operation failed", slog.String("error", err.Error()))
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"moveshare/internal/middleware"
+	"moveshare/internal/models"
+	"moveshare/internal/services"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// SavedSearchHandler отвечает за сохранённые поиски работ
+type SavedSearchHandler struct {
+	SavedSearchService services.SavedSearchService
+}
+
+func NewSavedSearchHandler(savedSearchService services.SavedSearchService) *SavedSearchHandler {
+	return &SavedSearchHandler{SavedSearchService: savedSearchService}
+}
+
+// CreateSavedSearch godoc
+// @Summary Сохранить поиск
+// @Description Сохраняет фильтр работ (поля как у параметров GET /jobs; origin/destination — {lat, lng} или {zip} и radius в милях). О каждой новой подходящей работе приходит уведомление по каналам channels: email, in_app (по умолчанию in_app)
+// @Tags saved-searches
+// @Accept  json
+// @Produce  json
+// @Param input body models.SavedSearchRequest true "Название, фильтр и каналы уведомлений"
+// @Success 201 {object} models.SavedSearch
+// @Failure 400 {string} string "invalid saved search"
+// @Failure 401 {string} string "unauthorized"
+// @Failure 500 {string} string "internal server error"
+// @Security BearerAuth
+// @Router /saved-searches [post]
+func (h *SavedSearchHandler) CreateSavedSearch(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req models.SavedSearchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+	search, err := h.SavedSearchService.CreateSavedSearch(userID, req)
+	if err != nil {
+		writeSavedSearchError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(search)
+}
+
+// GetSavedSearches godoc
+// @Summary Мои сохранённые поиски
+// @Tags saved-searches
+// @Produce  json
+// @Success 200 {array} models.SavedSearch
+// @Failure 401 {string} string "unauthorized"
+// @Failure 500 {string} string "internal server error"
+// @Security BearerAuth
+// @Router /saved-searches [get]
+func (h *SavedSearchHandler) GetSavedSearches(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	searches, err := h.SavedSearchService.GetSavedSearches(userID)
+	if err != nil {
+		writeSavedSearchError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(searches)
+}
+
+// UpdateSavedSearch godoc
+// @Summary Изменить сохранённый поиск
+// @Description Полностью заменяет название, фильтр и каналы уведомлений
+// @Tags saved-searches
+// @Accept  json
+// @Produce  json
+// @Param id path string true "ID сохранённого поиска"
+// @Param input body models.SavedSearchRequest true "Название, фильтр и каналы уведомлений"
+// @Success 200 {object} models.SavedSearch
+// @Failure 400 {string} string "invalid saved search"
+// @Failure 401 {string} string "unauthorized"
+// @Failure 404 {string} string "saved search not found"
+// @Failure 500 {string} string "internal server error"
+// @Security BearerAuth
+// @Router /saved-searches/{id} [put]
+func (h *SavedSearchHandler) UpdateSavedSearch(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req models.SavedSearchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+	search, err := h.SavedSearchService.UpdateSavedSearch(mux.Vars(r)["id"], userID, req)
+	if err != nil {
+		writeSavedSearchError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(search)
+}
+
+// PauseSavedSearch godoc
+// @Summary Приостановить уведомления
+// @Description Поиск сохраняется, но уведомления о новых работах не приходят до POST /saved-searches/{id}/resume
+// @Tags saved-searches
+// @Produce  json
+// @Param id path string true "ID сохранённого поиска"
+// @Success 200 {object} models.SavedSearch
+// @Failure 401 {string} string "unauthorized"
+// @Failure 404 {string} string "saved search not found"
+// @Failure 500 {string} string "internal server error"
+// @Security BearerAuth
+// @Router /saved-searches/{id}/pause [post]
+func (h *SavedSearchHandler) PauseSavedSearch(w http.ResponseWriter, r *http.Request) {
+	h.setPaused(w, r, true)
+}
+
+// ResumeSavedSearch godoc
+// @Summary Возобновить уведомления
+// @Tags saved-searches
+// @Produce  json
+// @Param id path string true "ID сохранённого поиска"
+// @Success 200 {object} models.SavedSearch
+// @Failure 401 {string} string "unauthorized"
+// @Failure 404 {string} string "saved search not found"
+// @Failure 500 {string} string "internal server error"
+// @Security BearerAuth
+// @Router /saved-searches/{id}/resume [post]
+func (h *SavedSearchHandler) ResumeSavedSearch(w http.ResponseWriter, r *http.Request) {
+	h.setPaused(w, r, false)
+}
+
+func (h *SavedSearchHandler) setPaused(w http.ResponseWriter, r *http.Request, paused bool) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	search, err := h.SavedSearchService.SetPaused(mux.Vars(r)["id"], userID, paused)
+	if err != nil {
+		writeSavedSearchError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(search)
+}
+
+// DeleteSavedSearch godoc
+// @Summary Удалить сохранённый поиск
+// @Tags saved-searches
+// @Param id path string true "ID сохранённого поиска"
+// @Success 204 {string} string "deleted"
+// @Failure 401 {string} string "unauthorized"
+// @Failure 404 {string} string "saved search not found"
+// @Failure 500 {string} string "internal server error"
+// @Security BearerAuth
+// @Router /saved-searches/{id} [delete]
+func (h *SavedSearchHandler) DeleteSavedSearch(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if err := h.SavedSearchService.DeleteSavedSearch(mux.Vars(r)["id"], userID); err != nil {
+		writeSavedSearchError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeSavedSearchError(w http.ResponseWriter, err error) {
+	switch err {
+	case services.ErrInvalidSavedSearch:
+		http.Error(w, "invalid saved search", http.StatusBadRequest)
+	case services.ErrInvalidAddress:
+		http.Error(w, "address could not be located", http.StatusBadRequest)
+	case services.ErrSavedSearchNotFound:
+		http.Error(w, "saved search not found", http.StatusNotFound)
+	default:
+		slog.Error("Saved search operation failed", slog.String("error", err.Error()))
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+	}
+}
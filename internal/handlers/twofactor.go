@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"moveshare/internal/middleware"
+	"moveshare/internal/models"
+	"moveshare/internal/services"
+	"net/http"
+)
+
+// TwoFactorHandler отвечает за подключение TOTP и второй шаг входа
+type TwoFactorHandler struct {
+	TwoFactorService services.TwoFactorService
+}
+
+func NewTwoFactorHandler(twoFactorService services.TwoFactorService) *TwoFactorHandler {
+	return &TwoFactorHandler{TwoFactorService: twoFactorService}
+}
+
+// Enroll godoc
+// @Summary Начать подключение 2FA
+// @Description Генерирует TOTP-секрет и otpauth:// ссылку для приложения-аутентификатора. 2FA включится после POST /2fa/confirm
+// @Tags 2fa
+// @Produce  json
+// @Success 200 {object} models.TOTPEnrollResponse
+// @Failure 401
+// @Failure 409
+// @Failure 500
+// @Security BearerAuth
+// @Router /2fa/enroll [post]
+func (h *TwoFactorHandler) Enroll(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	resp, err := h.TwoFactorService.Enroll(userID)
+	if err != nil {
+		writeTwoFactorError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// Confirm godoc
+// @Summary Подтвердить подключение 2FA
+// @Description Проверяет первый код из приложения, включает 2FA и возвращает резервные коды. Коды показываются один раз
+// @Tags 2fa
+// @Accept  json
+// @Produce  json
+// @Param input body models.TOTPCodeRequest true "Код из приложения"
+// @Success 200 {object} models.RecoveryCodesResponse
+// @Failure 400
+// @Failure 401
+// @Failure 409
+// @Failure 500
+// @Security BearerAuth
+// @Router /2fa/confirm [post]
+func (h *TwoFactorHandler) Confirm(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req models.TOTPCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+	codes, err := h.TwoFactorService.Confirm(userID, req.Code)
+	if err != nil {
+		writeTwoFactorError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// Disable godoc
+// @Summary Отключить 2FA
+// @Description Отключает 2FA. Требуется действующий код из приложения
+// @Tags 2fa
+// @Accept  json
+// @Param input body models.TOTPCodeRequest true "Код из приложения"
+// @Success 204
+// @Failure 400
+// @Failure 401
+// @Failure 409
+// @Failure 500
+// @Security BearerAuth
+// @Router /2fa/disable [post]
+func (h *TwoFactorHandler) Disable(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req models.TOTPCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+	if err := h.TwoFactorService.Disable(userID, req.Code); err != nil {
+		writeTwoFactorError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Login godoc
+// @Summary Второй шаг входа (2FA)
+// @Description Обменивает challenge_token из /login и код из приложения (или резервный код) на пару токенов. Неверный код делает challenge_token недействительным
+// @Tags auth
+// @Accept  json
+// @Produce  json
+// @Param input body models.TwoFactorLoginRequest true "Challenge token и код"
+// @Success 200 {object} models.LoginResponse
+// @Failure 400
+// @Failure 401
+// @Failure 500
+// @Router /login/2fa [post]
+func (h *TwoFactorHandler) Login(w http.ResponseWriter, r *http.Request) {
+	var req models.TwoFactorLoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.ChallengeToken == "" {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+	resp, err := h.TwoFactorService.CompleteLogin(req)
+	if err != nil {
+		switch err {
+		case services.ErrInvalidChallenge, services.ErrInvalidTwoFactorCode:
+			http.Error(w, "invalid credentials", http.StatusUnauthorized)
+		default:
+			slog.Error("Failed to complete two-factor login", slog.String("error", err.Error()))
+			http.Error(w, "internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+func writeTwoFactorError(w http.ResponseWriter, err error) {
+	switch err {
+	case services.ErrInvalidTwoFactorCode:
+		http.Error(w, "invalid two-factor code", http.StatusBadRequest)
+	case services.ErrTwoFactorEnabled:
+		http.Error(w, "two-factor authentication already enabled", http.StatusConflict)
+	case services.ErrTwoFactorNotEnabled:
+		http.Error(w, "two-factor authentication is not enabled", http.StatusConflict)
+	case services.ErrTwoFactorNotEnrolled:
+		http.Error(w, "two-factor enrollment not started", http.StatusConflict)
+	default:
+		slog.Error("Two-factor operation failed", slog.String("error", err.Error()))
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+	}
+}
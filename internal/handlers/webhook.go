@@ -0,0 +1,229 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"moveshare/internal/middleware"
+	"moveshare/internal/models"
+	"moveshare/internal/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// WebhookHandler отвечает за подписки на события и журнал их доставки
+type WebhookHandler struct {
+	WebhookService services.WebhookService
+}
+
+func NewWebhookHandler(webhookService services.WebhookService) *WebhookHandler {
+	return &WebhookHandler{WebhookService: webhookService}
+}
+
+// CreateWebhook godoc
+// @Summary Подписаться на события
+// @Description Создаёт вебхук: события event_types (job.created, job.claimed, job.started, job.delivered, job.completed, job.cancelled, job.deleted, bid.received, bid.countered, bid.accepted, bid.rejected, saved_search.match) отправляются POST-запросом на url. Тело подписано: заголовок X-MoveShare-Signature "t=<unix>,v1=<hex HMAC-SHA256 от "<t>.<тело>">" с ключом secret. Секрет возвращается только здесь. С company_id — подписка компании (для владельцев и диспетчеров)
+// @Tags webhooks
+// @Accept  json
+// @Produce  json
+// @Param input body models.CreateWebhookRequest true "Адрес, события и компания"
+// @Success 201 {object} models.Webhook
+// @Failure 400 {string} string "invalid webhook"
+// @Failure 401 {string} string "unauthorized"
+// @Failure 403 {string} string "not allowed to manage this company"
+// @Failure 500 {string} string "internal server error"
+// @Security BearerAuth
+// @Router /webhooks [post]
+func (h *WebhookHandler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req models.CreateWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+	webhook, err := h.WebhookService.CreateWebhook(userID, req)
+	if err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(webhook)
+}
+
+// GetWebhooks godoc
+// @Summary Мои вебхуки
+// @Description Личные вебхуки и вебхуки компаний, где пользователь владелец или диспетчер
+// @Tags webhooks
+// @Produce  json
+// @Success 200 {array} models.Webhook
+// @Failure 401 {string} string "unauthorized"
+// @Failure 500 {string} string "internal server error"
+// @Security BearerAuth
+// @Router /webhooks [get]
+func (h *WebhookHandler) GetWebhooks(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	webhooks, err := h.WebhookService.GetWebhooks(userID)
+	if err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(webhooks)
+}
+
+// UpdateWebhook godoc
+// @Summary Изменить вебхук
+// @Description Меняет адрес и события; active=false приостанавливает доставку (события копятся в очереди)
+// @Tags webhooks
+// @Accept  json
+// @Produce  json
+// @Param id path string true "ID вебхука"
+// @Param input body models.UpdateWebhookRequest true "Адрес, события, активность"
+// @Success 200 {object} models.Webhook
+// @Failure 400 {string} string "invalid webhook"
+// @Failure 401 {string} string "unauthorized"
+// @Failure 404 {string} string "webhook not found"
+// @Failure 500 {string} string "internal server error"
+// @Security BearerAuth
+// @Router /webhooks/{id} [put]
+func (h *WebhookHandler) UpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req models.UpdateWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+	webhook, err := h.WebhookService.UpdateWebhook(mux.Vars(r)["id"], userID, req)
+	if err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(webhook)
+}
+
+// DeleteWebhook godoc
+// @Summary Удалить вебхук
+// @Description Удаляет подписку вместе с журналом доставок
+// @Tags webhooks
+// @Param id path string true "ID вебхука"
+// @Success 204 {string} string "deleted"
+// @Failure 401 {string} string "unauthorized"
+// @Failure 404 {string} string "webhook not found"
+// @Failure 500 {string} string "internal server error"
+// @Security BearerAuth
+// @Router /webhooks/{id} [delete]
+func (h *WebhookHandler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if err := h.WebhookService.DeleteWebhook(mux.Vars(r)["id"], userID); err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetDeliveries godoc
+// @Summary Журнал доставок вебхука
+// @Description Доставки, новые первыми: статус (pending, succeeded, failed), число попыток, код и текст последнего ответа
+// @Tags webhooks
+// @Produce  json
+// @Param id path string true "ID вебхука"
+// @Param limit query int false "Лимит (по умолчанию 20)"
+// @Param offset query int false "Смещение (по умолчанию 0)"
+// @Success 200 {object} models.WebhookDeliveryListResponse
+// @Failure 401 {string} string "unauthorized"
+// @Failure 404 {string} string "webhook not found"
+// @Failure 500 {string} string "internal server error"
+// @Security BearerAuth
+// @Router /webhooks/{id}/deliveries [get]
+func (h *WebhookHandler) GetDeliveries(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	q := r.URL.Query()
+	limit := 20
+	offset := 0
+	if v := q.Get("limit"); v != "" {
+		if i, err := strconv.Atoi(v); err == nil {
+			limit = i
+		}
+	}
+	if v := q.Get("offset"); v != "" {
+		if i, err := strconv.Atoi(v); err == nil {
+			offset = i
+		}
+	}
+	deliveries, total, err := h.WebhookService.GetDeliveries(mux.Vars(r)["id"], userID, limit, offset)
+	if err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.WebhookDeliveryListResponse{Deliveries: deliveries, Total: total})
+}
+
+// Redeliver godoc
+// @Summary Отправить событие повторно
+// @Description Ставит в очередь новую доставку с тем же событием (тот же event id в теле); исходная запись журнала не меняется
+// @Tags webhooks
+// @Produce  json
+// @Param id path string true "ID вебхука"
+// @Param deliveryID path string true "ID доставки"
+// @Success 202 {object} models.WebhookDelivery
+// @Failure 401 {string} string "unauthorized"
+// @Failure 404 {string} string "webhook delivery not found"
+// @Failure 500 {string} string "internal server error"
+// @Security BearerAuth
+// @Router /webhooks/{id}/deliveries/{deliveryID}/redeliver [post]
+func (h *WebhookHandler) Redeliver(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	vars := mux.Vars(r)
+	delivery, err := h.WebhookService.Redeliver(vars["id"], vars["deliveryID"], userID)
+	if err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(delivery)
+}
+
+func writeWebhookError(w http.ResponseWriter, err error) {
+	switch err {
+	case services.ErrInvalidWebhook:
+		http.Error(w, "invalid webhook", http.StatusBadRequest)
+	case services.ErrCompanyForbidden:
+		http.Error(w, "not allowed to manage this company", http.StatusForbidden)
+	case services.ErrWebhookNotFound:
+		http.Error(w, "webhook not found", http.StatusNotFound)
+	case services.ErrDeliveryNotFound:
+		http.Error(w, "webhook delivery not found", http.StatusNotFound)
+	default:
+		slog.Error("Webhook operation failed", slog.String("error", err.Error()))
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,30 @@
+package mailer
+
+import (
+	"fmt"
+	"moveshare/internal/config"
+)
+
+// Message — простое текстовое письмо
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer отправляет письма пользователям
+type Mailer interface {
+	Send(msg Message) error
+}
+
+// New создаёт Mailer по настройкам: file (по умолчанию) или smtp
+func New(cfg *config.MailSettings) (Mailer, error) {
+	switch cfg.Driver {
+	case "", "file":
+		return NewFileMailer(cfg.OutboxDir, cfg.From)
+	case "smtp":
+		return NewSMTPMailer(cfg.SMTPHost, cfg.SMTPPort, cfg.From), nil
+	default:
+		return nil, fmt.Errorf("unknown mail driver %q", cfg.Driver)
+	}
+}
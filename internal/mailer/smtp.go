@@ -0,0 +1,41 @@
+package mailer
+
+import (
+	"bytes"
+	"fmt"
+	"net/mail"
+	"net/smtp"
+	"time"
+)
+
+// smtpMailer отправляет письма через SMTP-сервер без авторизации
+// (локальный relay или MailHog/Mailpit при разработке)
+type smtpMailer struct {
+	addr string
+	from string
+}
+
+func NewSMTPMailer(host string, port int, from string) Mailer {
+	return &smtpMailer{addr: fmt.Sprintf("%s:%d", host, port), from: from}
+}
+
+func (m *smtpMailer) Send(msg Message) error {
+	from, err := mail.ParseAddress(m.from)
+	if err != nil {
+		return err
+	}
+	return smtp.SendMail(m.addr, nil, from.Address, []string{msg.To}, buildMessage(m.from, msg))
+}
+
+func buildMessage(from string, msg Message) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", msg.Subject)
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("\r\n")
+	buf.WriteString(msg.Body)
+	return buf.Bytes()
+}
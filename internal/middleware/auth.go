@@ -2,6 +2,8 @@ package middleware
 
 import (
 	"context"
+	"log/slog"
+	"moveshare/internal/models"
 	"moveshare/internal/services"
 	"net/http"
 	"strings"
@@ -11,9 +13,10 @@ type contextKey string
 
 const (
 	ContextUserIDKey contextKey = "userID"
+	ContextClaimsKey contextKey = "claims"
 )
 
-func AuthMiddleware(jwtService services.JWTService) func(http.Handler) http.Handler {
+func AuthMiddleware(jwtService services.JWTService, tokenService services.TokenService) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			authHeader := r.Header.Get("Authorization")
@@ -22,13 +25,69 @@ func AuthMiddleware(jwtService services.JWTService) func(http.Handler) http.Hand
 				return
 			}
 			token := strings.TrimPrefix(authHeader, "Bearer ")
-			userID, err := jwtService.ValidateToken(token)
+			claims, err := jwtService.ValidateToken(token)
 			if err != nil {
 				http.Error(w, "invalid or expired token", http.StatusUnauthorized)
 				return
 			}
-			ctx := context.WithValue(r.Context(), ContextUserIDKey, userID)
+			revoked, err := tokenService.IsRevoked(claims)
+			if err != nil {
+				slog.Error("Failed to check token revocation", slog.String("error", err.Error()))
+				http.Error(w, "internal server error", http.StatusInternalServerError)
+				return
+			}
+			if revoked {
+				http.Error(w, "invalid or expired token", http.StatusUnauthorized)
+				return
+			}
+			ctx := context.WithValue(r.Context(), ContextUserIDKey, claims.UserID)
+			ctx = context.WithValue(ctx, ContextClaimsKey, claims)
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
 }
+
+// UserIDFromContext возвращает ID пользователя, положенный AuthMiddleware
+func UserIDFromContext(ctx context.Context) (int, bool) {
+	userID, ok := ctx.Value(ContextUserIDKey).(int)
+	return userID, ok
+}
+
+// ClaimsFromContext возвращает данные access-токена, положенные AuthMiddleware
+func ClaimsFromContext(ctx context.Context) (*services.AccessClaims, bool) {
+	claims, ok := ctx.Value(ContextClaimsKey).(*services.AccessClaims)
+	return claims, ok
+}
+
+// RequireRole пропускает только пользователей с одной из перечисленных ролей.
+// Должен стоять после AuthMiddleware
+func RequireRole(roles ...models.UserRole) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, ok := ClaimsFromContext(r.Context())
+			if !ok {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+			for _, role := range roles {
+				if claims.Role == role {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+			http.Error(w, "forbidden", http.StatusForbidden)
+		})
+	}
+}
+
+// TokenFromQuery переносит access-токен из параметра access_token в заголовок
+// Authorization. Нужен для EventSource в браузере, который не умеет задавать
+// заголовки. Должен стоять перед AuthMiddleware
+func TokenFromQuery(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token := r.URL.Query().Get("access_token"); token != "" && r.Header.Get("Authorization") == "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"moveshare/internal/models"
+	"moveshare/internal/services"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// fakeJWT принимает токен "valid" и возвращает для него claims
+type fakeJWT struct {
+	services.JWTService
+	claims *services.AccessClaims
+}
+
+func (j *fakeJWT) ValidateToken(token string) (*services.AccessClaims, error) {
+	if token != "valid" {
+		return nil, errors.New("invalid token")
+	}
+	return j.claims, nil
+}
+
+// fakeTokens отзывает токены, выпущенные до cutoff, и запоминает, что проверялось
+type fakeTokens struct {
+	services.TokenService
+	cutoff  time.Time
+	checked *services.AccessClaims
+}
+
+func (s *fakeTokens) IsRevoked(claims *services.AccessClaims) (bool, error) {
+	s.checked = claims
+	return claims.IssuedAt.Before(s.cutoff), nil
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name     string
+		header   string
+		issuedAt time.Time
+		want     int
+	}{
+		{"valid token", "Bearer valid", now, http.StatusOK},
+		{"no header", "", now, http.StatusUnauthorized},
+		{"not a bearer token", "Basic valid", now, http.StatusUnauthorized},
+		{"invalid token", "Bearer forged", now, http.StatusUnauthorized},
+		{"issued before the password change", "Bearer valid", now.Add(-time.Hour), http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		claims := &services.AccessClaims{UserID: 7, Role: models.RoleCarrier, JTI: "jti", IssuedAt: tt.issuedAt}
+		tokens := &fakeTokens{cutoff: now.Add(-time.Minute)}
+		var gotUserID int
+		handler := AuthMiddleware(&fakeJWT{claims: claims}, tokens)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			gotUserID, _ = UserIDFromContext(r.Context())
+		}))
+
+		req := httptest.NewRequest(http.MethodGet, "/jobs", nil)
+		if tt.header != "" {
+			req.Header.Set("Authorization", tt.header)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.name, rec.Code, tt.want)
+		}
+		if tt.want == http.StatusOK && (gotUserID != 7 || tokens.checked != claims) {
+			t.Errorf("%s: user id in context = %d, checked claims = %+v", tt.name, gotUserID, tokens.checked)
+		}
+	}
+}
+
+func TestRequireRole(t *testing.T) {
+	tests := []struct {
+		name   string
+		claims *services.AccessClaims
+		want   int
+	}{
+		{"admin", &services.AccessClaims{UserID: 1, Role: models.RoleAdmin}, http.StatusOK},
+		{"carrier", &services.AccessClaims{UserID: 2, Role: models.RoleCarrier}, http.StatusForbidden},
+		{"no claims", nil, http.StatusUnauthorized},
+	}
+	handler := RequireRole(models.RoleAdmin)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/admin/users", nil)
+		if tt.claims != nil {
+			req = req.WithContext(context.WithValue(req.Context(), ContextClaimsKey, tt.claims))
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.name, rec.Code, tt.want)
+		}
+	}
+}
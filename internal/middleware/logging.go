@@ -9,6 +9,15 @@ import (
 	"time"
 )
 
+// maxLoggedBody — тела JSON больше этого размера в лог не попадают
+const maxLoggedBody = 64 << 10
+
+// readCloser — прочитанное начало тела и его остаток; Close закрывает исходное тело
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
 type responseWriter struct {
 	http.ResponseWriter
 	statusCode int
@@ -21,19 +30,31 @@ func (rw *responseWriter) WriteHeader(code int) {
 }
 
 func (rw *responseWriter) Write(data []byte) (int, error) {
-	rw.body.Write(data)
+	// Тело нужно только для лога ошибок; потоковые ответы (SSE) не копим в памяти
+	if rw.statusCode >= 400 {
+		rw.body.Write(data)
+	}
 	return rw.ResponseWriter.Write(data)
 }
 
+// Unwrap даёт http.ResponseController доступ к Flush исходного ResponseWriter
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
 func LoggingMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
 
-		// Читаем тело запроса
+		// Читаем тело запроса: только JSON и не больше maxLoggedBody. Остальное
+		// (файлы) не буферизуем, чтобы ограничения размера в обработчиках работали
 		var requestBody []byte
-		if r.Body != nil {
-			requestBody, _ = io.ReadAll(r.Body)
-			r.Body = io.NopCloser(bytes.NewBuffer(requestBody))
+		if r.Body != nil && r.Body != http.NoBody && isJSONContent(r.Header.Get("Content-Type")) {
+			requestBody, _ = io.ReadAll(io.LimitReader(r.Body, maxLoggedBody+1))
+			r.Body = readCloser{io.MultiReader(bytes.NewReader(requestBody), r.Body), r.Body}
+			if len(requestBody) > maxLoggedBody {
+				requestBody = nil
+			}
 		}
 
 		// Оборачиваем ResponseWriter
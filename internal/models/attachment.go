@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// AttachmentKind — назначение файла работы
+type AttachmentKind string
+
+const (
+	// AttachmentInventory — фото и опись вещей; загружают те, кто управляет работой
+	AttachmentInventory AttachmentKind = "inventory"
+	// AttachmentProofOfDelivery — фото доставки; загружает назначенный перевозчик
+	AttachmentProofOfDelivery AttachmentKind = "proof_of_delivery"
+	// AttachmentOther — прочие файлы участников работы
+	AttachmentOther AttachmentKind = "other"
+)
+
+// Attachment — файл работы. URL и ThumbnailURL — подписанные ссылки на
+// скачивание без токена, действуют до URLExpiresAt
+type Attachment struct {
+	ID          string         `json:"id" db:"id"`
+	JobID       string         `json:"job_id" db:"job_id"`
+	UploadedBy  int            `json:"uploaded_by" db:"uploaded_by"`
+	Kind        AttachmentKind `json:"kind" db:"kind"`
+	FileName    string         `json:"file_name" db:"file_name"`
+	ContentType string         `json:"content_type" db:"content_type"`
+	Size        int64          `json:"size" db:"size"`
+	SHA256      string         `json:"sha256" db:"sha256"`
+	// Width и Height — размеры изображения в пикселях; у остальных файлов не заполняются
+	Width        int        `json:"width,omitempty" db:"width"`
+	Height       int        `json:"height,omitempty" db:"height"`
+	BlobKey      string     `json:"-" db:"blob_key"`
+	ThumbnailKey string     `json:"-" db:"thumbnail_key"`
+	URL          string     `json:"url,omitempty"`
+	ThumbnailURL string     `json:"thumbnail_url,omitempty"`
+	URLExpiresAt *time.Time `json:"url_expires_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+type BidStatus string
+
+const (
+	BidStatusPending   BidStatus = "pending"
+	BidStatusCountered BidStatus = "countered"
+	BidStatusAccepted  BidStatus = "accepted"
+	BidStatusRejected  BidStatus = "rejected"
+)
+
+// Bid — предложение перевозчика взять работу за свою цену
+type Bid struct {
+	ID             string    `json:"id" db:"id"`
+	JobID          string    `json:"job_id" db:"job_id"`
+	CarrierID      int       `json:"carrier_id" db:"carrier_id"`
+	Amount         Money     `json:"amount" db:"amount"`
+	Message        string    `json:"message" db:"message"`
+	CounterAmount  *Money    `json:"counter_amount,omitempty" db:"counter_amount"`
+	CounterMessage string    `json:"counter_message,omitempty" db:"counter_message"`
+	Status         BidStatus `json:"status" db:"status"`
+	ExpiresAt      time.Time `json:"expires_at" db:"expires_at"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// CreateBidRequest — ставка перевозчика в валюте работы. Если expires_at не указан, ставка живёт сутки
+type CreateBidRequest struct {
+	Amount    Money     `json:"amount"`
+	Message   string    `json:"message"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// CounterBidRequest — встречное предложение заказчика
+type CounterBidRequest struct {
+	Amount  Money  `json:"amount"`
+	Message string `json:"message"`
+}
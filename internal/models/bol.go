@@ -0,0 +1,87 @@
+package models
+
+import "time"
+
+// SignatureRole — кто подписывает накладную
+type SignatureRole string
+
+const (
+	// SignatureShipper — отправитель, подписывает при погрузке
+	SignatureShipper SignatureRole = "shipper"
+	// SignatureConsignee — получатель, подписывает при доставке
+	SignatureConsignee SignatureRole = "consignee"
+)
+
+// BOLParty — сторона в накладной: название и строки с реквизитами
+type BOLParty struct {
+	Name    string   `json:"name"`
+	Details []string `json:"details,omitempty"`
+}
+
+// BOLSnapshot — данные работы и сторон на момент выдачи накладной. Из них
+// собирается PDF, поэтому последующие изменения профилей документ не меняют
+type BOLSnapshot struct {
+	JobTitle                      string           `json:"job_title"`
+	Shipper                       BOLParty         `json:"shipper"`
+	Carrier                       BOLParty         `json:"carrier"`
+	PickupAddress                 Address          `json:"pickup_address"`
+	DeliveryAddress               Address          `json:"delivery_address"`
+	PickupDateTime                time.Time        `json:"pickup_datetime"`
+	DeliveryDateTime              time.Time        `json:"delivery_datetime"`
+	NumberOfBedrooms              NumberOfBedrooms `json:"number_of_bedrooms"`
+	TruckSize                     TruckSize        `json:"truck_size"`
+	AdditionalServices            string           `json:"additional_services,omitempty"`
+	DescriptionAdditionalServices string           `json:"description_additional_services,omitempty"`
+	DistanceMiles                 float64          `json:"distance_miles"`
+	Charges                       Money            `json:"charges"`
+}
+
+// BOLSignature — подпись накладной. DocumentHash — SHA-256 PDF, получившегося
+// после этой подписи; IP и время фиксирует сервер
+type BOLSignature struct {
+	ID          string        `json:"id" db:"id"`
+	Role        SignatureRole `json:"role" db:"role"`
+	SignerName  string        `json:"signer_name" db:"signer_name"`
+	ImagePath   string        `json:"-" db:"image_path"`
+	ImageSHA256 string        `json:"image_sha256" db:"image_sha256"`
+	IP          string        `json:"ip" db:"ip"`
+	// CapturedBy — пользователь, с чьего аккаунта снята подпись
+	CapturedBy   *int      `json:"captured_by,omitempty" db:"captured_by"`
+	DocumentHash string    `json:"document_hash" db:"document_hash"`
+	SignedAt     time.Time `json:"signed_at" db:"signed_at"`
+}
+
+// BillOfLading — транспортная накладная работы. Выдаётся, когда груз
+// забран; каждая подпись даёт новую версию PDF, и DocumentHash — хеш
+// последней версии (он же хранится у работы в bol_hash)
+type BillOfLading struct {
+	ID           string          `json:"id" db:"id"`
+	JobID        string          `json:"job_id" db:"job_id"`
+	Number       string          `json:"number" db:"number"`
+	Snapshot     BOLSnapshot     `json:"snapshot" db:"snapshot"`
+	Signatures   []*BOLSignature `json:"signatures"`
+	DocumentHash string          `json:"document_hash" db:"document_hash"`
+	FilePath     string          `json:"-" db:"file_path"`
+	IssuedAt     time.Time       `json:"issued_at" db:"issued_at"`
+}
+
+// Signature — подпись роли role или nil
+func (b *BillOfLading) Signature(role SignatureRole) *BOLSignature {
+	for _, sig := range b.Signatures {
+		if sig.Role == role {
+			return sig
+		}
+	}
+	return nil
+}
+
+// BOLVerification — проверка целостности: хеш сохранённого файла и
+// изображений подписей сравниваются с записанными при подписании
+type BOLVerification struct {
+	DocumentHash string `json:"document_hash"`
+	FileHash     string `json:"file_hash"`
+	// Valid — файл и все изображения подписей совпадают с записанными хешами
+	Valid bool `json:"valid"`
+	// Mismatches — что не совпало: "document" или роль подписи
+	Mismatches []string `json:"mismatches,omitempty"`
+}
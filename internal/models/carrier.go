@@ -0,0 +1,80 @@
+package models
+
+import "time"
+
+// CarrierStatus — статус проверки перевозчика
+type CarrierStatus string
+
+const (
+	CarrierPending  CarrierStatus = "pending"
+	CarrierApproved CarrierStatus = "approved"
+	CarrierRejected CarrierStatus = "rejected"
+	// CarrierSuspended — профиль был одобрен, но действующей страховки больше нет
+	CarrierSuspended CarrierStatus = "suspended"
+)
+
+// InsuranceStatus — статус сертификата страхования (COI)
+type InsuranceStatus string
+
+const (
+	InsurancePending  InsuranceStatus = "pending"
+	InsuranceApproved InsuranceStatus = "approved"
+	InsuranceRejected InsuranceStatus = "rejected"
+	InsuranceExpired  InsuranceStatus = "expired"
+)
+
+// ReviewDecision — решение администратора по профилю или сертификату
+type ReviewDecision string
+
+const (
+	ReviewApprove ReviewDecision = "approve"
+	ReviewReject  ReviewDecision = "reject"
+)
+
+type CarrierProfile struct {
+	UserID     int           `json:"user_id" db:"user_id"`
+	LegalName  string        `json:"legal_name" db:"legal_name"`
+	DOTNumber  string        `json:"dot_number" db:"dot_number"`
+	MCNumber   string        `json:"mc_number,omitempty" db:"mc_number"`
+	Status     CarrierStatus `json:"status" db:"status"`
+	ReviewNote string        `json:"review_note,omitempty" db:"review_note"`
+	ReviewedBy *int          `json:"reviewed_by,omitempty" db:"reviewed_by"`
+	ReviewedAt *time.Time    `json:"reviewed_at,omitempty" db:"reviewed_at"`
+	CreatedAt  time.Time     `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time     `json:"updated_at" db:"updated_at"`
+	// Verified — профиль одобрен и есть действующая одобренная страховка
+	Verified     bool                    `json:"verified"`
+	Certificates []*InsuranceCertificate `json:"certificates,omitempty"`
+}
+
+type InsuranceCertificate struct {
+	ID             string          `json:"id" db:"id"`
+	CarrierID      int             `json:"carrier_id" db:"carrier_id"`
+	FilePath       string          `json:"-" db:"file_path"`
+	FileName       string          `json:"file_name" db:"file_name"`
+	ContentType    string          `json:"content_type" db:"content_type"`
+	CoverageAmount Money           `json:"coverage_amount" db:"coverage_amount"`
+	ExpiresAt      time.Time       `json:"expires_at" db:"expires_at"`
+	Status         InsuranceStatus `json:"status" db:"status"`
+	ReviewNote     string          `json:"review_note,omitempty" db:"review_note"`
+	ReviewedBy     *int            `json:"reviewed_by,omitempty" db:"reviewed_by"`
+	ReviewedAt     *time.Time      `json:"reviewed_at,omitempty" db:"reviewed_at"`
+	CreatedAt      time.Time       `json:"created_at" db:"created_at"`
+}
+
+type CarrierProfileRequest struct {
+	LegalName string `json:"legal_name"`
+	DOTNumber string `json:"dot_number"`
+	MCNumber  string `json:"mc_number"`
+}
+
+type ReviewRequest struct {
+	Decision ReviewDecision `json:"decision"` // approve или reject
+	Note     string         `json:"note"`
+}
+
+// CarrierReviewQueue — профили и сертификаты, ожидающие проверки администратором
+type CarrierReviewQueue struct {
+	Profiles     []*CarrierProfile       `json:"profiles"`
+	Certificates []*InsuranceCertificate `json:"certificates"`
+}
@@ -0,0 +1,77 @@
+package models
+
+import "time"
+
+// CompanyRole — роль сотрудника внутри компании
+type CompanyRole string
+
+const (
+	CompanyOwner      CompanyRole = "owner"
+	CompanyDispatcher CompanyRole = "dispatcher"
+	CompanyDriver     CompanyRole = "driver"
+)
+
+func (r CompanyRole) Valid() bool {
+	switch r {
+	case CompanyOwner, CompanyDispatcher, CompanyDriver:
+		return true
+	}
+	return false
+}
+
+// CanManageJobs — владельцы и диспетчеры управляют объявлениями компании
+func (r CompanyRole) CanManageJobs() bool {
+	return r == CompanyOwner || r == CompanyDispatcher
+}
+
+type Company struct {
+	ID        int       `json:"id" db:"id"`
+	Name      string    `json:"name" db:"name"`
+	CreatedBy int       `json:"created_by" db:"created_by"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	// Роль текущего пользователя в компании (в списке "мои компании")
+	MyRole CompanyRole `json:"my_role,omitempty"`
+}
+
+type CompanyMember struct {
+	CompanyID int         `json:"company_id" db:"company_id"`
+	UserID    int         `json:"user_id" db:"user_id"`
+	Email     string      `json:"email"`
+	Username  string      `json:"username"`
+	Role      CompanyRole `json:"role" db:"role"`
+	JoinedAt  time.Time   `json:"joined_at" db:"joined_at"`
+}
+
+type CompanyInvitation struct {
+	ID         string      `json:"id" db:"id"`
+	CompanyID  int         `json:"company_id" db:"company_id"`
+	Email      string      `json:"email" db:"email"`
+	Role       CompanyRole `json:"role" db:"role"`
+	TokenHash  string      `json:"-" db:"token_hash"`
+	InvitedBy  int         `json:"invited_by" db:"invited_by"`
+	ExpiresAt  time.Time   `json:"expires_at" db:"expires_at"`
+	AcceptedAt *time.Time  `json:"accepted_at,omitempty" db:"accepted_at"`
+	CreatedAt  time.Time   `json:"created_at" db:"created_at"`
+}
+
+type CreateCompanyRequest struct {
+	Name string `json:"name"`
+}
+
+type InviteMemberRequest struct {
+	Email string      `json:"email"`
+	Role  CompanyRole `json:"role"`
+}
+
+type AcceptInvitationRequest struct {
+	Token string `json:"token"`
+}
+
+type UpdateMemberRequest struct {
+	Role CompanyRole `json:"role"`
+}
+
+// SwitchCompanyRequest — выбор активной компании. null или 0 — работать от своего имени
+type SwitchCompanyRequest struct {
+	CompanyID *int `json:"company_id"`
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// DocumentKind — вид документа по работе
+type DocumentKind string
+
+const (
+	// DocumentInvoice — счёт заказчику за выполненную работу
+	DocumentInvoice DocumentKind = "invoice"
+	// DocumentReceipt — квитанция о полученной от заказчика оплате
+	DocumentReceipt DocumentKind = "receipt"
+)
+
+// documentPrefixes — префиксы номеров документов
+var documentPrefixes = map[DocumentKind]string{
+	DocumentInvoice: "INV",
+	DocumentReceipt: "RCT",
+}
+
+// JobDocument — выданный документ. Номер выдаётся один раз и не меняется
+// при повторной генерации файла. Нумерация своя у каждого вида документов
+// и каждого выставителя: компании работы, а у работ без компании — её автора
+type JobDocument struct {
+	ID       string       `json:"id" db:"id"`
+	JobID    string       `json:"job_id" db:"job_id"`
+	Kind     DocumentKind `json:"kind" db:"kind"`
+	Issuer   string       `json:"issuer" db:"issuer"`
+	Number   int          `json:"number" db:"number"`
+	FilePath string       `json:"-" db:"file_path"`
+	IssuedAt time.Time    `json:"issued_at" db:"issued_at"`
+}
+
+// DisplayNumber — номер для документа и имени файла: INV-000042
+func (d *JobDocument) DisplayNumber() string {
+	return fmt.Sprintf("%s-%06d", documentPrefixes[d.Kind], d.Number)
+}
@@ -0,0 +1,89 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EventType — тип доменного события, на которое можно подписаться вебхуком
+type EventType string
+
+const (
+	EventJobCreated       EventType = "job.created"
+	EventJobClaimed       EventType = "job.claimed"
+	EventJobStarted       EventType = "job.started"
+	EventJobDelivered     EventType = "job.delivered"
+	EventJobCompleted     EventType = "job.completed"
+	EventJobCancelled     EventType = "job.cancelled"
+	EventJobDeleted       EventType = "job.deleted"
+	EventBidReceived      EventType = "bid.received"
+	EventBidCountered     EventType = "bid.countered"
+	EventBidAccepted      EventType = "bid.accepted"
+	EventBidRejected      EventType = "bid.rejected"
+	EventSavedSearchMatch EventType = "saved_search.match"
+)
+
+// EventTypes — все события, доступные для подписки
+var EventTypes = []EventType{
+	EventJobCreated, EventJobClaimed, EventJobStarted, EventJobDelivered, EventJobCompleted, EventJobCancelled, EventJobDeleted,
+	EventBidReceived, EventBidCountered, EventBidAccepted, EventBidRejected,
+	EventSavedSearchMatch,
+}
+
+func (t EventType) Valid() bool {
+	for _, known := range EventTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Event — доменное событие. Сервисы записывают его в outbox в одной транзакции
+// с изменением данных; в таком же виде (конверт id/type/created_at/data) его
+// получает подписчик вебхука
+type Event struct {
+	ID        string      `json:"id"`
+	Type      EventType   `json:"type"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+	// Audience — кому адресовано событие (для вебхуков)
+	Audience EventAudience `json:"-"`
+	// DedupKey — одно и то же изменение попадает в outbox не больше одного раза
+	DedupKey string `json:"-"`
+}
+
+// NewEvent создаёт событие со снимком data. Пустой dedupKey — без дедупликации
+func NewEvent(eventType EventType, data interface{}, audience EventAudience, dedupKey string) *Event {
+	id := uuid.New().String()
+	if dedupKey == "" {
+		dedupKey = id
+	}
+	return &Event{
+		ID:        id,
+		Type:      eventType,
+		CreatedAt: time.Now().UTC(),
+		Data:      data,
+		Audience:  audience,
+		DedupKey:  dedupKey,
+	}
+}
+
+// Decode раскладывает данные события в v. У событий, прочитанных из outbox,
+// Data — json.RawMessage
+func (e *Event) Decode(v interface{}) error {
+	raw, err := json.Marshal(e.Data)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, v)
+}
+
+// EventAudience — кому адресовано событие: личные подписки пользователей UserIDs
+// и подписки компании CompanyID
+type EventAudience struct {
+	UserIDs   []int `json:"user_ids"`
+	CompanyID *int  `json:"company_id,omitempty"`
+}
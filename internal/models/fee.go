@@ -0,0 +1,102 @@
+package models
+
+import "time"
+
+// FeeComponent — удержание из оплаты работы
+type FeeComponent string
+
+const (
+	// FeePlatform — комиссия площадки
+	FeePlatform FeeComponent = "platform_fee"
+	// FeeBrokerCut — доля брокера, опубликовавшего работу
+	FeeBrokerCut FeeComponent = "broker_cut"
+)
+
+// FeeRuleKind — как правило считает сумму
+type FeeRuleKind string
+
+const (
+	// FeePercentage — bps базисных пунктов от оплаты (100 bps = 1%)
+	FeePercentage FeeRuleKind = "percentage"
+	// FeeFlat — фиксированная сумма flat
+	FeeFlat FeeRuleKind = "flat"
+	// FeeTiered — процент и/или фиксированная сумма ступени, в которую попала оплата
+	FeeTiered FeeRuleKind = "tiered"
+	// FeePromotion — скидка на уже посчитанный компонент: bps от него и/или flat
+	FeePromotion FeeRuleKind = "promotion"
+)
+
+// FeeRule — одно правило набора. Правила одного компонента складываются,
+// затем применяются акции; min/max ограничивают сумму самого правила
+type FeeRule struct {
+	Component   FeeComponent `json:"component"`
+	Kind        FeeRuleKind  `json:"kind"`
+	Description string       `json:"description,omitempty"`
+	BPS         int64        `json:"bps,omitempty"`
+	Flat        *Money       `json:"flat,omitempty"`
+	Tiers       []FeeTier    `json:"tiers,omitempty"`
+	Min         *Money       `json:"min,omitempty"`
+	Max         *Money       `json:"max,omitempty"`
+	// Sizes — правило действует только для работ такого размера; пусто — для всех
+	Sizes []NumberOfBedrooms `json:"sizes,omitempty"`
+	// PromoCode — акция действует только по коду; пусто — для всех
+	PromoCode  string     `json:"promo_code,omitempty"`
+	ValidFrom  *time.Time `json:"valid_from,omitempty"`
+	ValidUntil *time.Time `json:"valid_until,omitempty"`
+}
+
+// FeeTier — ступень тарифа: действует для оплаты не больше up_to
+// (у последней ступени up_to не указывается)
+type FeeTier struct {
+	UpTo *Money `json:"up_to,omitempty"`
+	BPS  int64  `json:"bps,omitempty"`
+	Flat *Money `json:"flat,omitempty"`
+}
+
+// FeeRuleSet — версия правил. Наборы не меняются: новая версия создаётся
+// заново и действует с active_from, а расчёты по старым версиям сохраняются
+type FeeRuleSet struct {
+	ID         string    `json:"id" db:"id"`
+	Version    int       `json:"version" db:"version"`
+	Name       string    `json:"name" db:"name"`
+	Rules      []FeeRule `json:"rules" db:"rules"`
+	ActiveFrom time.Time `json:"active_from" db:"active_from"`
+	CreatedBy  *int      `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
+
+// CreateFeeRuleSetRequest — новая версия правил. Без active_from действует сразу
+type CreateFeeRuleSetRequest struct {
+	Name       string     `json:"name"`
+	Rules      []FeeRule  `json:"rules"`
+	ActiveFrom *time.Time `json:"active_from,omitempty"`
+}
+
+// FeeLine — вклад одного правила в расчёт; у акций сумма отрицательная
+type FeeLine struct {
+	Component   FeeComponent `json:"component"`
+	Kind        FeeRuleKind  `json:"kind"`
+	Description string       `json:"description,omitempty"`
+	Amount      Money        `json:"amount"`
+}
+
+// FeeBreakdown — расчёт удержаний: оплата = комиссия площадки + доля брокера + выплата перевозчику
+type FeeBreakdown struct {
+	RuleSetID      string    `json:"rule_set_id"`
+	RuleSetVersion int       `json:"rule_set_version"`
+	Payment        Money     `json:"payment"`
+	PlatformFee    Money     `json:"platform_fee"`
+	BrokerCut      Money     `json:"broker_cut"`
+	CarrierNet     Money     `json:"carrier_net"`
+	PromoCode      string    `json:"promo_code,omitempty"`
+	Lines          []FeeLine `json:"lines"`
+	// CalculatedAt — когда сделан расчёт; у работы — момент закрепления за перевозчиком
+	CalculatedAt time.Time `json:"calculated_at"`
+}
+
+// FeeQuoteRequest — предварительный расчёт для POST /jobs/quote
+type FeeQuoteRequest struct {
+	PaymentAmount    Money            `json:"payment_amount"`
+	NumberOfBedrooms NumberOfBedrooms `json:"number_of_bedrooms"`
+	PromoCode        string           `json:"promo_code,omitempty"`
+}
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// JobEventType — тип события ленты работ
+type JobEventType string
+
+const (
+	JobEventCreated   JobEventType = "job.created"
+	JobEventClaimed   JobEventType = "job.claimed"
+	JobEventCancelled JobEventType = "job.cancelled"
+)
+
+// JobEvent — событие ленты работ, которое получают подписчики GET /jobs/stream
+type JobEvent struct {
+	Type JobEventType `json:"type"`
+	Job  *Job         `json:"job"`
+	At   time.Time    `json:"at"`
+	// PosterRating нужен только для фильтра min_poster_rating
+	PosterRating float64 `json:"-"`
+}
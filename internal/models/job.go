@@ -21,6 +21,37 @@ const (
 	LargeTruck  TruckSize = "large"
 )
 
+// JobStatus — этап жизненного цикла работы
+type JobStatus string
+
+const (
+	JobStatusOpen      JobStatus = "open"
+	JobStatusClaimed   JobStatus = "claimed"
+	JobStatusInTransit JobStatus = "in_transit"
+	JobStatusDelivered JobStatus = "delivered"
+	JobStatusCompleted JobStatus = "completed"
+	JobStatusCancelled JobStatus = "cancelled"
+)
+
+// Address — адрес погрузки или доставки. Если координаты не переданы,
+// они определяются геокодером по ZIP-коду
+type Address struct {
+	Street    string  `json:"street"`
+	City      string  `json:"city"`
+	State     string  `json:"state"`
+	ZIP       string  `json:"zip"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// HasCoordinates сообщает, заданы ли координаты адреса
+func (a Address) HasCoordinates() bool {
+	return a.Latitude != 0 || a.Longitude != 0
+}
+
+// Job — работа. CutAmount — доля брокера по правилам удержаний: у открытой
+// работы это оценка, после закрепления — зафиксированная сумма (GET /jobs/{id}/fees).
+// PromoCode заказчика учитывается в расчёте удержаний
 type Job struct {
 	ID                            string           `json:"id" db:"id"`
 	JobTitle                      string           `json:"title" db:"title"`
@@ -30,11 +61,23 @@ type Job struct {
 	TruckSize                     TruckSize        `json:"truck_size" db:"truck_size"`
 	PickupDateTime                time.Time        `json:"pickup_datetime" db:"pickup_datetime"`
 	DeliveryDateTime              time.Time        `json:"delivery_datetime" db:"delivery_datetime"`
-	CutAmount                     float64          `json:"cut_amount" db:"cut_amount"`
-	PaymentAmount                 float64          `json:"payment_amount" db:"payment_amount"`
+	CutAmount                     Money            `json:"cut_amount" db:"cut_amount"`
+	PaymentAmount                 Money            `json:"payment_amount" db:"payment_amount"`
+	PosterID                      int              `json:"poster_id" db:"poster_id"`
+	Status                        JobStatus        `json:"status" db:"status"`
+	CarrierID                     *int             `json:"carrier_id,omitempty" db:"carrier_id"`
+	PickupAddress                 Address          `json:"pickup_address"`
+	DeliveryAddress               Address          `json:"delivery_address"`
+	DistanceMiles                 float64          `json:"distance_miles" db:"distance_miles"`
+	CompanyID                     *int             `json:"company_id,omitempty" db:"company_id"`
+	PromoCode                     string           `json:"promo_code,omitempty" db:"promo_code"`
+	// BOLHash — SHA-256 последней версии транспортной накладной (GET /jobs/{id}/bol)
+	BOLHash string `json:"bol_hash,omitempty" db:"bol_hash"`
 }
 
-// CreateJobRequest используется для создания новой Job через API (без ID)
+// CreateJobRequest используется для создания новой Job через API (без ID).
+// Тот же формат принимает PUT /jobs/{id} для полного обновления работы.
+// cut_amount не принимается: долю брокера считают правила удержаний
 type CreateJobRequest struct {
 	JobTitle                      string           `json:"title"`
 	NumberOfBedrooms              NumberOfBedrooms `json:"number_of_bedrooms"`
@@ -43,18 +86,54 @@ type CreateJobRequest struct {
 	TruckSize                     TruckSize        `json:"truck_size"`
 	PickupDateTime                time.Time        `json:"pickup_datetime"`
 	DeliveryDateTime              time.Time        `json:"delivery_datetime"`
-	CutAmount                     float64          `json:"cut_amount"`
-	PaymentAmount                 float64          `json:"payment_amount"`
+	PaymentAmount                 Money            `json:"payment_amount"`
+	PickupAddress                 Address          `json:"pickup_address"`
+	DeliveryAddress               Address          `json:"delivery_address"`
+	PromoCode                     string           `json:"promo_code,omitempty"`
 }
 
 // JobFilter для фильтрации и поиска
 type JobFilter struct {
-	NumberOfBedrooms string     // "1", "2", "office" и т.д.
-	DateStart        *time.Time // >=
-	DateEnd          *time.Time // <=
-	TruckSize        string     // "small", "medium", "large"
-	PayoutMin        *float64   // >=
-	PayoutMax        *float64   // <=
+	NumberOfBedrooms string     `json:"relocation_size,omitempty"`   // "1", "2", "office" и т.д.
+	DateStart        *time.Time `json:"date_start,omitempty"`        // >=
+	DateEnd          *time.Time `json:"date_end,omitempty"`          // <=
+	TruckSize        string     `json:"truck_size,omitempty"`        // "small", "medium", "large"
+	PayoutMin        *Money     `json:"payout_min,omitempty"`        // >=, только работы в той же валюте
+	PayoutMax        *Money     `json:"payout_max,omitempty"`        // <=, только работы в той же валюте
+	Status           string     `json:"status,omitempty"`            // "open", "claimed" и т.д.
+	CompanyID        int        `json:"company_id,omitempty"`        // работы компании; 0 — без фильтра
+	MinPosterRating  *float64   `json:"min_poster_rating,omitempty"` // средняя оценка заказчика >=; заказчики без отзывов не попадают
+	Origin           *GeoRadius `json:"origin,omitempty"`            // адрес погрузки в радиусе
+	Destination      *GeoRadius `json:"destination,omitempty"`       // адрес доставки в радиусе
+	Sort             JobSort    `json:"-"`                           // по умолчанию — pickup_datetime DESC
+}
+
+type JobSort string
+
+const (
+	// JobSortDeadhead — по удалённости места погрузки от точки Origin, затем по оплате
+	JobSortDeadhead JobSort = "deadhead"
+)
+
+// GeoRadius — условие "в пределах Miles миль от точки".
+// Точку можно задать координатами или ZIP-кодом
+type GeoRadius struct {
+	Latitude  float64 `json:"lat,omitempty"`
+	Longitude float64 `json:"lng,omitempty"`
+	ZIP       string  `json:"zip,omitempty"`
+	Miles     float64 `json:"radius"`
+}
+
+// Backhaul — обратный груз: работа и порожний пробег до её места погрузки
+type Backhaul struct {
+	Job           *Job    `json:"job"`
+	DeadheadMiles float64 `json:"deadhead_miles"`
+}
+
+// BackhaulListResponse для ответа на GET /jobs/{id}/backhauls
+type BackhaulListResponse struct {
+	Backhauls []*Backhaul `json:"backhauls"`
+	Total     int         `json:"total"`
 }
 
 // JobListResponse для ответа на GET /jobs
@@ -0,0 +1,16 @@
+package models
+
+// JWK — публичный RSA-ключ в формате RFC 7517
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// JWKS — набор публичных ключей для проверки токенов MoveShare
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
@@ -0,0 +1,97 @@
+package models
+
+import "time"
+
+// AccountType — вид счёта двойной записи
+type AccountType string
+
+const (
+	// AccountPosterBalance — деньги заказчика у площадки: внесённая оплата,
+	// ещё не ушедшая перевозчику, и причитающаяся ему доля брокера
+	AccountPosterBalance AccountType = "poster_balance"
+	// AccountCarrierPayable — сколько площадка должна перевозчику за завершённые работы
+	AccountCarrierPayable AccountType = "carrier_payable"
+	// AccountPlatformRevenue — заработанная комиссия площадки
+	AccountPlatformRevenue AccountType = "platform_revenue"
+	// AccountEscrow — деньги на счёте площадки у платёжного провайдера
+	AccountEscrow AccountType = "escrow"
+)
+
+// DebitNormal — баланс счёта растёт по дебету (активы). Остальные счета —
+// обязательства и доходы, их баланс растёт по кредиту
+func (t AccountType) DebitNormal() bool {
+	return t == AccountEscrow
+}
+
+// Personal — счёт ведётся отдельно для каждого пользователя
+func (t AccountType) Personal() bool {
+	return t == AccountPosterBalance || t == AccountCarrierPayable
+}
+
+// LedgerAccount — счёт в одной валюте. Balance — остаток в нормальной стороне
+// счёта: у poster_balance и carrier_payable положительный остаток — долг площадки пользователю
+type LedgerAccount struct {
+	ID        int         `json:"id" db:"id"`
+	Type      AccountType `json:"type" db:"type"`
+	UserID    *int        `json:"user_id,omitempty" db:"user_id"`
+	Currency  string      `json:"currency" db:"currency"`
+	Balance   Money       `json:"balance"`
+	CreatedAt time.Time   `json:"created_at" db:"created_at"`
+}
+
+// JournalEntryType — хозяйственная операция, по которой сделана проводка
+type JournalEntryType string
+
+const (
+	JournalEscrowFunded   JournalEntryType = "escrow_funded"
+	JournalJobCompleted   JournalEntryType = "job_completed"
+	JournalCarrierPayout  JournalEntryType = "carrier_payout"
+	JournalBrokerPayout   JournalEntryType = "broker_payout"
+	JournalEscrowRefunded JournalEntryType = "escrow_refunded"
+)
+
+// JournalEntry — проводка: набор движений по счетам, сумма которых равна нулю.
+// Повторная проводка с тем же DedupKey не записывается
+type JournalEntry struct {
+	ID          string           `json:"id" db:"id"`
+	Type        JournalEntryType `json:"type" db:"type"`
+	JobID       *string          `json:"job_id,omitempty" db:"job_id"`
+	Description string           `json:"description" db:"description"`
+	DedupKey    string           `json:"-" db:"dedup_key"`
+	PostedAt    time.Time        `json:"posted_at" db:"posted_at"`
+	Postings    []*Posting       `json:"postings"`
+}
+
+// Posting — движение по счёту: положительная сумма — дебет, отрицательная — кредит
+type Posting struct {
+	AccountType AccountType `json:"account_type"`
+	UserID      *int        `json:"user_id,omitempty"`
+	Amount      Money       `json:"amount"`
+}
+
+// StatementLine — строка выписки. Суммы в нормальной стороне счёта:
+// положительная увеличивает остаток
+type StatementLine struct {
+	EntryID     string           `json:"entry_id"`
+	Type        JournalEntryType `json:"type"`
+	JobID       *string          `json:"job_id,omitempty"`
+	Description string           `json:"description"`
+	PostedAt    time.Time        `json:"posted_at"`
+	Amount      Money            `json:"amount"`
+	Balance     Money            `json:"balance"`
+}
+
+// AccountStatement — выписка по одному счёту за период
+type AccountStatement struct {
+	Account        *LedgerAccount   `json:"account"`
+	OpeningBalance Money            `json:"opening_balance"`
+	ClosingBalance Money            `json:"closing_balance"`
+	Lines          []*StatementLine `json:"lines"`
+}
+
+// Statement — выписка по счетам пользователя за [from, to)
+type Statement struct {
+	From     time.Time           `json:"from"`
+	To       time.Time           `json:"to"`
+	Accounts []*AccountStatement `json:"accounts"`
+}
@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// MessageThread — переписка заказчика с одним перевозчиком по работе.
+// Со стороны заказчика в ней участвуют автор работы и диспетчеры его компании.
+type MessageThread struct {
+	ID            string     `json:"id" db:"id"`
+	JobID         string     `json:"job_id" db:"job_id"`
+	CarrierID     int        `json:"carrier_id" db:"carrier_id"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+	LastMessageAt *time.Time `json:"last_message_at,omitempty"`
+	// UnreadCount — непрочитанные сообщения от другой стороны
+	UnreadCount int `json:"unread_count"`
+}
+
+type Message struct {
+	ID          string    `json:"id" db:"id"`
+	ThreadID    string    `json:"thread_id" db:"thread_id"`
+	SenderID    int       `json:"sender_id" db:"sender_id"`
+	FromCarrier bool      `json:"from_carrier" db:"from_carrier"`
+	Body        string    `json:"body" db:"body"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	// ReadAt — когда сообщение прочитала другая сторона
+	ReadAt *time.Time `json:"read_at,omitempty" db:"read_at"`
+}
+
+type SendMessageRequest struct {
+	Body string `json:"body"`
+}
+
+type MessageListResponse struct {
+	Messages []*Message `json:"messages"`
+	Total    int        `json:"total"`
+}
+
+type MarkReadResponse struct {
+	Marked int `json:"marked"`
+}
@@ -0,0 +1,202 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// DefaultCurrency — валюта сумм, для которых она не указана
+const DefaultCurrency = "USD"
+
+var (
+	ErrInvalidMoney     = errors.New("invalid money amount")
+	ErrCurrencyMismatch = errors.New("currency mismatch")
+)
+
+// currencyExponents — число знаков после запятой у валют, где оно не 2 (ISO 4217)
+var currencyExponents = map[string]int{
+	"JPY": 0, "KRW": 0, "VND": 0, "CLP": 0, "ISK": 0,
+	"BHD": 3, "KWD": 3, "OMR": 3, "JOD": 3, "TND": 3,
+}
+
+// Money — денежная сумма в минимальных единицах валюты (центах для USD).
+// В JSON сумма передаётся десятичной строкой, чтобы не терять точность:
+// {"amount": "1250.50", "currency": "USD"}. На вход принимается и просто
+// "1250.50" или 1250.50 — тогда валюта DefaultCurrency
+type Money struct {
+	Amount   int64  `json:"amount" swaggertype:"string" example:"1250.50"`
+	Currency string `json:"currency" example:"USD"`
+}
+
+// NewMoney — сумма amount в минимальных единицах валюты currency
+func NewMoney(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+// ParseMoney разбирает десятичную строку ("1250.5", "-3", "0.99") в сумму валюты currency.
+// Знаков после запятой не может быть больше, чем у валюты
+func ParseMoney(s, currency string) (Money, error) {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if currency == "" {
+		currency = DefaultCurrency
+	}
+	if !validCurrency(currency) {
+		return Money{}, ErrInvalidMoney
+	}
+	exp := currencyExponent(currency)
+
+	s = strings.TrimSpace(s)
+	negative := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+	whole, frac, hasFrac := strings.Cut(s, ".")
+	if whole == "" || !digitsOnly(whole) || (hasFrac && (frac == "" || !digitsOnly(frac))) || len(frac) > exp {
+		return Money{}, ErrInvalidMoney
+	}
+	frac += strings.Repeat("0", exp-len(frac))
+
+	amount, err := strconv.ParseInt(whole+frac, 10, 64)
+	if err != nil {
+		return Money{}, ErrInvalidMoney
+	}
+	if negative {
+		amount = -amount
+	}
+	return Money{Amount: amount, Currency: currency}, nil
+}
+
+// WithDefaultCurrency — та же сумма; если валюта не указана, то в DefaultCurrency
+func (m Money) WithDefaultCurrency() Money {
+	return Money{Amount: m.Amount, Currency: m.currency()}
+}
+
+// Decimal — сумма десятичной строкой с числом знаков валюты: "1250.50"
+func (m Money) Decimal() string {
+	exp := currencyExponent(m.currency())
+	sign := ""
+	abs := uint64(m.Amount)
+	if m.Amount < 0 {
+		sign = "-"
+		abs = uint64(-m.Amount)
+	}
+	if exp == 0 {
+		return sign + strconv.FormatUint(abs, 10)
+	}
+	unit := uint64(math.Pow10(exp))
+	return fmt.Sprintf("%s%d.%0*d", sign, abs/unit, exp, abs%unit)
+}
+
+// String — сумма с валютой для текстов: "1250.50 USD"
+func (m Money) String() string {
+	return m.Decimal() + " " + m.currency()
+}
+
+func (m Money) IsZero() bool     { return m.Amount == 0 }
+func (m Money) IsNegative() bool { return m.Amount < 0 }
+
+// SameCurrency сообщает, можно ли складывать и сравнивать суммы
+func (m Money) SameCurrency(other Money) bool {
+	return m.currency() == other.currency()
+}
+
+func (m Money) Add(other Money) (Money, error) {
+	if !m.SameCurrency(other) {
+		return Money{}, ErrCurrencyMismatch
+	}
+	return Money{Amount: m.Amount + other.Amount, Currency: m.currency()}, nil
+}
+
+func (m Money) Sub(other Money) (Money, error) {
+	if !m.SameCurrency(other) {
+		return Money{}, ErrCurrencyMismatch
+	}
+	return Money{Amount: m.Amount - other.Amount, Currency: m.currency()}, nil
+}
+
+// Valid — валюта указана кодом ISO 4217 из трёх латинских букв
+func (m Money) Valid() bool {
+	return validCurrency(m.currency())
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Amount   string `json:"amount"`
+		Currency string `json:"currency"`
+	}{m.Decimal(), m.currency()})
+}
+
+func (m *Money) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	if bytes.HasPrefix(data, []byte("{")) {
+		var v struct {
+			Amount   json.RawMessage `json:"amount"`
+			Currency string          `json:"currency"`
+		}
+		if err := json.Unmarshal(data, &v); err != nil {
+			return err
+		}
+		return m.parseJSONAmount(v.Amount, v.Currency)
+	}
+	return m.parseJSONAmount(data, "")
+}
+
+// parseJSONAmount принимает сумму строкой или числом. Число разбирается
+// по тексту литерала, без преобразования в float64
+func (m *Money) parseJSONAmount(data json.RawMessage, currency string) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		var n json.Number
+		if err := json.Unmarshal(data, &n); err != nil {
+			return ErrInvalidMoney
+		}
+		s = n.String()
+	}
+	parsed, err := ParseMoney(s, currency)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+func (m Money) currency() string {
+	if m.Currency == "" {
+		return DefaultCurrency
+	}
+	return m.Currency
+}
+
+func currencyExponent(currency string) int {
+	if exp, ok := currencyExponents[currency]; ok {
+		return exp
+	}
+	return 2
+}
+
+func validCurrency(currency string) bool {
+	if len(currency) != 3 {
+		return false
+	}
+	for _, c := range currency {
+		if c < 'A' || c > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+func digitsOnly(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,131 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		in       string
+		currency string
+		want     Money
+		wantErr  bool
+	}{
+		{"1250.50", "USD", Money{125050, "USD"}, false},
+		{"1250.5", "", Money{125050, "USD"}, false},
+		{"0.99", "usd", Money{99, "USD"}, false},
+		{"-3", "USD", Money{-300, "USD"}, false},
+		{" 42 ", "EUR", Money{4200, "EUR"}, false},
+		{"0", "USD", Money{0, "USD"}, false},
+		{"1500", "JPY", Money{1500, "JPY"}, false},
+		{"1.234", "KWD", Money{1234, "KWD"}, false},
+		{"92233720368547758.07", "USD", Money{9223372036854775807, "USD"}, false},
+		{"1.001", "USD", Money{}, true},
+		{"1.5", "JPY", Money{}, true},
+		{"92233720368547758.08", "USD", Money{}, true},
+		{"", "USD", Money{}, true},
+		{".5", "USD", Money{}, true},
+		{"5.", "USD", Money{}, true},
+		{"1e3", "USD", Money{}, true},
+		{"+5", "USD", Money{}, true},
+		{"--5", "USD", Money{}, true},
+		{"1,000.00", "USD", Money{}, true},
+		{"10", "US", Money{}, true},
+		{"10", "U$D", Money{}, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseMoney(tt.in, tt.currency)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseMoney(%q, %q): err = %v, wantErr %v", tt.in, tt.currency, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseMoney(%q, %q) = %+v, want %+v", tt.in, tt.currency, got, tt.want)
+		}
+	}
+}
+
+func TestMoneyDecimal(t *testing.T) {
+	tests := []struct {
+		m    Money
+		want string
+	}{
+		{Money{125050, "USD"}, "1250.50"},
+		{Money{5, "USD"}, "0.05"},
+		{Money{-5, "USD"}, "-0.05"},
+		{Money{0, ""}, "0.00"},
+		{Money{1500, "JPY"}, "1500"},
+		{Money{1234, "KWD"}, "1.234"},
+	}
+	for _, tt := range tests {
+		if got := tt.m.Decimal(); got != tt.want {
+			t.Errorf("%+v.Decimal() = %q, want %q", tt.m, got, tt.want)
+		}
+	}
+}
+
+func TestMoneyUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Money
+		wantErr bool
+	}{
+		{`{"amount": "1250.50", "currency": "USD"}`, Money{125050, "USD"}, false},
+		{`{"amount": 1250.5, "currency": "eur"}`, Money{125050, "EUR"}, false},
+		{`{"amount": "10"}`, Money{1000, "USD"}, false},
+		{`"19.99"`, Money{1999, "USD"}, false},
+		// Число разбирается по тексту литерала, без округления через float64
+		{`0.30`, Money{30, "USD"}, false},
+		{`1999.99`, Money{199999, "USD"}, false},
+		{`{"amount": "1.999", "currency": "USD"}`, Money{}, true},
+		{`1e2`, Money{}, true},
+		{`true`, Money{}, true},
+		{`{"amount": "abc"}`, Money{}, true},
+		{`{"amount": "1", "currency": "DOLLARS"}`, Money{}, true},
+	}
+	for _, tt := range tests {
+		var got Money
+		err := json.Unmarshal([]byte(tt.in), &got)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Unmarshal(%s): err = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Unmarshal(%s) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+
+	// null оставляет значение как есть
+	m := Money{100, "USD"}
+	if err := json.Unmarshal([]byte(`null`), &m); err != nil || m != (Money{100, "USD"}) {
+		t.Errorf("Unmarshal(null) = %+v, %v", m, err)
+	}
+}
+
+func TestMoneyJSONRoundTrip(t *testing.T) {
+	for _, m := range []Money{{125050, "USD"}, {-1, "USD"}, {1500, "JPY"}, {1234, "KWD"}} {
+		data, err := json.Marshal(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var back Money
+		if err := json.Unmarshal(data, &back); err != nil || back != m {
+			t.Errorf("%+v -> %s -> %+v, %v", m, data, back, err)
+		}
+	}
+}
+
+func TestMoneyArithmetic(t *testing.T) {
+	sum, err := Money{100, "USD"}.Add(Money{250, ""})
+	if err != nil || sum != (Money{350, "USD"}) {
+		t.Errorf("Add = %+v, %v", sum, err)
+	}
+	diff, err := Money{100, "USD"}.Sub(Money{250, "USD"})
+	if err != nil || diff != (Money{-150, "USD"}) || !diff.IsNegative() {
+		t.Errorf("Sub = %+v, %v", diff, err)
+	}
+	if _, err := (Money{100, "USD"}).Add(Money{100, "EUR"}); err != ErrCurrencyMismatch {
+		t.Errorf("Add across currencies: err = %v, want ErrCurrencyMismatch", err)
+	}
+}
@@ -0,0 +1,69 @@
+package models
+
+import "time"
+
+// EscrowStatus — состояние денег по работе
+type EscrowStatus string
+
+const (
+	// EscrowPending — работа закреплена, оплата с заказчика ещё не списана
+	EscrowPending EscrowStatus = "pending"
+	// EscrowFunded — оплата списана и удерживается площадкой до завершения работы
+	EscrowFunded EscrowStatus = "funded"
+	// EscrowReleased — работа завершена, перевозчику и брокеру выплачены их доли
+	EscrowReleased EscrowStatus = "released"
+	// EscrowRefunded — работа отменена, оплата возвращена заказчику
+	EscrowRefunded EscrowStatus = "refunded"
+	// EscrowCancelled — работа отменена до списания, движения денег не было
+	EscrowCancelled EscrowStatus = "cancelled"
+)
+
+// Escrow — оплата работы, удерживаемая площадкой. Создаётся при закреплении
+// работы с суммами из зафиксированного расчёта удержаний
+type Escrow struct {
+	ID          string       `json:"id" db:"id"`
+	JobID       string       `json:"job_id" db:"job_id"`
+	Status      EscrowStatus `json:"status" db:"status"`
+	PayerID     int          `json:"payer_id" db:"payer_id"`
+	PayeeID     int          `json:"payee_id" db:"payee_id"`
+	Payment     Money        `json:"payment" db:"payment"`
+	PlatformFee Money        `json:"platform_fee" db:"platform_fee"`
+	BrokerCut   Money        `json:"broker_cut" db:"broker_cut"`
+	CarrierNet  Money        `json:"carrier_net" db:"carrier_net"`
+	// ChargeRef — списание у платёжного провайдера, по нему делается возврат
+	ChargeRef string         `json:"charge_ref,omitempty" db:"charge_ref"`
+	CreatedAt time.Time      `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at" db:"updated_at"`
+	Ledger    []*LedgerEntry `json:"ledger"`
+}
+
+// LedgerEntryType — вид движения денег
+type LedgerEntryType string
+
+const (
+	// LedgerEscrowFunded — списание с заказчика на счёт площадки
+	LedgerEscrowFunded LedgerEntryType = "escrow_funded"
+	// LedgerCarrierPayout — выплата перевозчику
+	LedgerCarrierPayout LedgerEntryType = "carrier_payout"
+	// LedgerBrokerPayout — выплата доли брокера заказчику
+	LedgerBrokerPayout LedgerEntryType = "broker_payout"
+	// LedgerPlatformFee — комиссия остаётся у площадки
+	LedgerPlatformFee LedgerEntryType = "platform_fee"
+	// LedgerEscrowRefunded — возврат заказчику
+	LedgerEscrowRefunded LedgerEntryType = "escrow_refunded"
+)
+
+// LedgerEntry — запись журнала движений денег. Журнал только дополняется:
+// записи не меняются и не удаляются
+type LedgerEntry struct {
+	ID       string          `json:"id" db:"id"`
+	EscrowID string          `json:"escrow_id" db:"escrow_id"`
+	JobID    string          `json:"job_id" db:"job_id"`
+	Type     LedgerEntryType `json:"type" db:"type"`
+	Amount   Money           `json:"amount" db:"amount"`
+	// UserID — кто заплатил или получил деньги; у комиссии площадки не указывается
+	UserID      *int      `json:"user_id,omitempty" db:"user_id"`
+	Provider    string    `json:"provider,omitempty" db:"provider"`
+	ProviderRef string    `json:"provider_ref,omitempty" db:"provider_ref"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,59 @@
+package models
+
+import "time"
+
+// ReviewSide — сторона сделки, оставившая отзыв
+type ReviewSide string
+
+const (
+	ReviewByPoster  ReviewSide = "poster"
+	ReviewByCarrier ReviewSide = "carrier"
+)
+
+// Review — оценка второй стороны после завершения работы.
+// По одному отзыву от заказчика и от перевозчика на каждую работу.
+type Review struct {
+	ID                string     `json:"id" db:"id"`
+	JobID             string     `json:"job_id" db:"job_id"`
+	ReviewerID        int        `json:"reviewer_id" db:"reviewer_id"`
+	ReviewerSide      ReviewSide `json:"reviewer_side" db:"reviewer_side"`
+	RevieweeID        int        `json:"reviewee_id" db:"reviewee_id"`
+	RevieweeCompanyID *int       `json:"reviewee_company_id,omitempty" db:"reviewee_company_id"`
+	Rating            int        `json:"rating" db:"rating"`
+	Comment           string     `json:"comment" db:"comment"`
+	CreatedAt         time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at" db:"updated_at"`
+	// EditableUntil — после этого момента отзыв нельзя изменить
+	EditableUntil time.Time `json:"editable_until"`
+}
+
+// CreateReviewRequest — тот же формат принимает PUT /reviews/{id} для правки отзыва
+type CreateReviewRequest struct {
+	Rating  int    `json:"rating"` // от 1 до 5
+	Comment string `json:"comment"`
+}
+
+// RatingSummary — агрегированная репутация пользователя или компании
+type RatingSummary struct {
+	Rating        float64 `json:"rating"` // средняя оценка, 0 — отзывов нет
+	ReviewCount   int     `json:"review_count"`
+	CompletedJobs int     `json:"completed_jobs"`
+}
+
+type UserProfile struct {
+	ID       int      `json:"id"`
+	Username string   `json:"username"`
+	Role     UserRole `json:"role"`
+	RatingSummary
+}
+
+type CompanyProfile struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	RatingSummary
+}
+
+type ReviewListResponse struct {
+	Reviews []*Review `json:"reviews"`
+	Total   int       `json:"total"`
+}
@@ -0,0 +1,61 @@
+package models
+
+import "time"
+
+// NotificationChannel — способ доставки уведомления
+type NotificationChannel string
+
+const (
+	NotificationChannelEmail NotificationChannel = "email"
+	NotificationChannelInApp NotificationChannel = "in_app"
+	// NotificationChannelWebhook — событие saved_search.match в личные вебхуки
+	NotificationChannelWebhook NotificationChannel = "webhook"
+)
+
+// SavedSearch — сохранённый фильтр работ. Когда публикуется подходящая
+// работа, владелец получает уведомление по выбранным каналам
+type SavedSearch struct {
+	ID       string                `json:"id" db:"id"`
+	UserID   int                   `json:"user_id" db:"user_id"`
+	Name     string                `json:"name" db:"name"`
+	Filter   JobFilter             `json:"filter" db:"filter"`
+	Channels []NotificationChannel `json:"channels" db:"channels"`
+	// Paused — уведомления приостановлены, поиск сохранён
+	Paused         bool       `json:"paused" db:"paused"`
+	LastNotifiedAt *time.Time `json:"last_notified_at,omitempty" db:"last_notified_at"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// SavedSearchRequest — создание и полное обновление сохранённого поиска.
+// Без channels уведомления приходят в приложение
+type SavedSearchRequest struct {
+	Name     string                `json:"name"`
+	Filter   JobFilter             `json:"filter"`
+	Channels []NotificationChannel `json:"channels"`
+}
+
+// NotificationType — повод уведомления
+type NotificationType string
+
+const (
+	NotificationSavedSearchMatch NotificationType = "saved_search.match"
+)
+
+// Notification — уведомление в приложении
+type Notification struct {
+	ID        string           `json:"id" db:"id"`
+	UserID    int              `json:"user_id" db:"user_id"`
+	Type      NotificationType `json:"type" db:"type"`
+	Title     string           `json:"title" db:"title"`
+	Body      string           `json:"body" db:"body"`
+	JobID     *string          `json:"job_id,omitempty" db:"job_id"`
+	ReadAt    *time.Time       `json:"read_at,omitempty" db:"read_at"`
+	CreatedAt time.Time        `json:"created_at" db:"created_at"`
+}
+
+type NotificationListResponse struct {
+	Notifications []*Notification `json:"notifications"`
+	Total         int             `json:"total"`
+	Unread        int             `json:"unread"`
+}
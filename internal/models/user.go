@@ -4,16 +4,97 @@ import (
 	"time"
 )
 
+// UserRole определяет, что пользователь может делать на площадке
+type UserRole string
+
+const (
+	// RoleShipper — заказчик или брокер: публикует работы
+	RoleShipper UserRole = "shipper"
+	// RoleCarrier — перевозчик: берёт работы и делает ставки
+	RoleCarrier UserRole = "carrier"
+	// RoleAdmin — модератор площадки
+	RoleAdmin UserRole = "admin"
+)
+
+func (r UserRole) Valid() bool {
+	switch r {
+	case RoleShipper, RoleCarrier, RoleAdmin:
+		return true
+	}
+	return false
+}
+
 type User struct {
+	ID            int      `json:"id" db:"id"`
+	Email         string   `json:"email" db:"email"`
+	Username      string   `json:"username" db:"username"`
+	Password      string   `json:"-" db:"password_hash"`
+	Role          UserRole `json:"role" db:"role"`
+	EmailVerified bool     `json:"email_verified" db:"email_verified"`
+	TOTPEnabled   bool     `json:"two_factor_enabled" db:"totp_enabled"`
+	TOTPSecret    string   `json:"-" db:"totp_secret"`
+	TOTPLastStep  int64    `json:"-" db:"totp_last_step"`
+	// Компания, от имени которой пользователь сейчас работает (попадает в JWT)
+	ActiveCompanyID *int      `json:"active_company_id,omitempty" db:"active_company_id"`
+	CreatedAt       time.Time `json:"created_at" db:"created_at"`
+}
+
+type SignUpRequest struct {
+	Email    string   `json:"email"`
+	Username string   `json:"username"`
+	Password string   `json:"password"`
+	Role     UserRole `json:"role"` // shipper (по умолчанию) или carrier
+}
+
+// ChangeRoleRequest — смена роли пользователя администратором
+type ChangeRoleRequest struct {
+	Role   UserRole `json:"role"`
+	Reason string   `json:"reason"`
+}
+
+// RoleChange — запись журнала смены ролей
+type RoleChange struct {
 	ID        int       `json:"id" db:"id"`
-	Email     string    `json:"email" db:"email"`
-	Username  string    `json:"username" db:"username"`
-	Password  string    `json:"-" db:"password_hash"`
+	UserID    int       `json:"user_id" db:"user_id"`
+	OldRole   UserRole  `json:"old_role" db:"old_role"`
+	NewRole   UserRole  `json:"new_role" db:"new_role"`
+	ChangedBy int       `json:"changed_by" db:"changed_by"`
+	Reason    string    `json:"reason" db:"reason"`
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 }
 
-type SignUpRequest struct {
-	Email    string `json:"email"`
-	Username string `json:"username"`
+// UserListResponse для ответа на GET /admin/users
+type UserListResponse struct {
+	Users []*User `json:"users"`
+	Total int     `json:"total"`
+}
+
+type UserTokenPurpose string
+
+const (
+	UserTokenEmailVerification UserTokenPurpose = "email_verification"
+	UserTokenPasswordReset     UserTokenPurpose = "password_reset"
+)
+
+// UserToken — одноразовый токен из письма. Хранится только хэш
+type UserToken struct {
+	ID        string           `db:"id"`
+	UserID    int              `db:"user_id"`
+	Purpose   UserTokenPurpose `db:"purpose"`
+	TokenHash string           `db:"token_hash"`
+	ExpiresAt time.Time        `db:"expires_at"`
+	UsedAt    *time.Time       `db:"used_at"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token"`
+}
+
+type EmailRequest struct {
+	Email string `json:"email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token"`
 	Password string `json:"password"`
 }
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Webhook — подписка на события. Личная (CompanyID пуст) получает события,
+// адресованные создателю; подписка компании — события работ компании
+type Webhook struct {
+	ID         string      `json:"id" db:"id"`
+	UserID     int         `json:"user_id" db:"user_id"`
+	CompanyID  *int        `json:"company_id,omitempty" db:"company_id"`
+	URL        string      `json:"url" db:"url"`
+	EventTypes []EventType `json:"event_types" db:"event_types"`
+	Active     bool        `json:"active" db:"active"`
+	// Secret возвращается только при создании
+	Secret    string    `json:"secret,omitempty" db:"secret"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+type CreateWebhookRequest struct {
+	URL        string      `json:"url"`
+	EventTypes []EventType `json:"event_types"`
+	// CompanyID — подписка компании; доступно владельцам и диспетчерам
+	CompanyID *int `json:"company_id,omitempty"`
+}
+
+type UpdateWebhookRequest struct {
+	URL        string      `json:"url"`
+	EventTypes []EventType `json:"event_types"`
+	Active     bool        `json:"active"`
+}
+
+// WebhookDeliveryStatus — состояние доставки события подписчику
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliverySucceeded WebhookDeliveryStatus = "succeeded"
+	WebhookDeliveryFailed    WebhookDeliveryStatus = "failed"
+)
+
+// WebhookDelivery — запись журнала доставок
+type WebhookDelivery struct {
+	ID             string                `json:"id" db:"id"`
+	WebhookID      string                `json:"webhook_id" db:"webhook_id"`
+	EventID        string                `json:"event_id" db:"event_id"`
+	EventType      EventType             `json:"event_type" db:"event_type"`
+	Payload        json.RawMessage       `json:"payload" db:"payload" swaggertype:"object"`
+	Status         WebhookDeliveryStatus `json:"status" db:"status"`
+	Attempts       int                   `json:"attempts" db:"attempts"`
+	NextAttemptAt  time.Time             `json:"next_attempt_at" db:"next_attempt_at"`
+	LastAttemptAt  *time.Time            `json:"last_attempt_at,omitempty" db:"last_attempt_at"`
+	LastStatusCode *int                  `json:"last_status_code,omitempty" db:"last_status_code"`
+	LastError      *string               `json:"last_error,omitempty" db:"last_error"`
+	DeliveredAt    *time.Time            `json:"delivered_at,omitempty" db:"delivered_at"`
+	CreatedAt      time.Time             `json:"created_at" db:"created_at"`
+}
+
+type WebhookDeliveryListResponse struct {
+	Deliveries []*WebhookDelivery `json:"deliveries"`
+	Total      int                `json:"total"`
+}
@@ -0,0 +1,24 @@
+package notify
+
+import (
+	"fmt"
+	"moveshare/internal/mailer"
+	"moveshare/internal/models"
+)
+
+type emailNotifier struct {
+	mailer mailer.Mailer
+}
+
+// NewEmailNotifier отправляет уведомления письмом на адрес пользователя
+func NewEmailNotifier(m mailer.Mailer) Notifier {
+	return &emailNotifier{mailer: m}
+}
+
+func (e *emailNotifier) Notify(user *models.User, n *models.Notification) error {
+	return e.mailer.Send(mailer.Message{
+		To:      user.Email,
+		Subject: n.Title,
+		Body:    fmt.Sprintf("Hi %s,\n\n%s\n", user.Username, n.Body),
+	})
+}
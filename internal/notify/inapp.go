@@ -0,0 +1,22 @@
+package notify
+
+import (
+	"moveshare/internal/models"
+	"moveshare/internal/repository"
+)
+
+type inAppNotifier struct {
+	repo repository.NotificationRepository
+}
+
+// NewInAppNotifier сохраняет уведомление в ленту уведомлений приложения (GET /notifications)
+func NewInAppNotifier(repo repository.NotificationRepository) Notifier {
+	return &inAppNotifier{repo: repo}
+}
+
+func (a *inAppNotifier) Notify(user *models.User, n *models.Notification) error {
+	stored := *n
+	stored.UserID = user.ID
+	_, err := a.repo.CreateNotification(&stored)
+	return err
+}
@@ -0,0 +1,48 @@
+package notify
+
+import (
+	"errors"
+	"fmt"
+	"moveshare/internal/models"
+)
+
+// Notifier доставляет уведомление пользователю по одному каналу
+type Notifier interface {
+	Notify(user *models.User, n *models.Notification) error
+}
+
+// Dispatcher рассылает уведомление по выбранным пользователем каналам.
+// Новый способ доставки — это ещё один Notifier в NewDispatcher
+type Dispatcher interface {
+	Supports(channel models.NotificationChannel) bool
+	Send(channels []models.NotificationChannel, user *models.User, n *models.Notification) error
+}
+
+type dispatcher struct {
+	notifiers map[models.NotificationChannel]Notifier
+}
+
+func NewDispatcher(notifiers map[models.NotificationChannel]Notifier) Dispatcher {
+	return &dispatcher{notifiers: notifiers}
+}
+
+func (d *dispatcher) Supports(channel models.NotificationChannel) bool {
+	_, ok := d.notifiers[channel]
+	return ok
+}
+
+// Send пробует все каналы, даже если какой-то из них не сработал
+func (d *dispatcher) Send(channels []models.NotificationChannel, user *models.User, n *models.Notification) error {
+	var errs []error
+	for _, channel := range channels {
+		notifier, ok := d.notifiers[channel]
+		if !ok {
+			errs = append(errs, fmt.Errorf("unknown notification channel %q", channel))
+			continue
+		}
+		if err := notifier.Notify(user, n); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", channel, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,22 @@
+package notify
+
+import "moveshare/internal/models"
+
+// EventQueue ставит события в очередь доставки вебхуков
+type EventQueue interface {
+	Enqueue(event *models.Event) error
+}
+
+type webhookNotifier struct {
+	queue EventQueue
+}
+
+// NewWebhookNotifier доставляет уведомление событием saved_search.match
+// в личные вебхуки пользователя
+func NewWebhookNotifier(queue EventQueue) Notifier {
+	return &webhookNotifier{queue: queue}
+}
+
+func (h *webhookNotifier) Notify(user *models.User, n *models.Notification) error {
+	return h.queue.Enqueue(models.NewEvent(models.EventSavedSearchMatch, n, models.EventAudience{UserIDs: []int{user.ID}}, ""))
+}
@@ -0,0 +1,70 @@
+package payments
+
+import (
+	"moveshare/internal/models"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// fakeProvider проводит операции в памяти процесса: ничего не списывает по-настоящему,
+// но соблюдает идемпотентность и не даёт вернуть больше, чем списано. Для разработки и тестов
+type fakeProvider struct {
+	mu        sync.Mutex
+	transfers map[string]*Transfer
+	// refundable — сколько ещё можно вернуть по списанию
+	refundable map[string]models.Money
+}
+
+func NewFakeProvider() Provider {
+	return &fakeProvider{transfers: map[string]*Transfer{}, refundable: map[string]models.Money{}}
+}
+
+func (p *fakeProvider) Name() string { return "fake" }
+
+func (p *fakeProvider) Charge(req Charge) (*Transfer, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if t, ok := p.transfers[req.IdempotencyKey]; ok {
+		return t, nil
+	}
+	t := p.record(req.IdempotencyKey, "fake_ch_", req.Amount)
+	p.refundable[t.Ref] = req.Amount
+	return t, nil
+}
+
+func (p *fakeProvider) Payout(req Payout) (*Transfer, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if t, ok := p.transfers[req.IdempotencyKey]; ok {
+		return t, nil
+	}
+	return p.record(req.IdempotencyKey, "fake_po_", req.Amount), nil
+}
+
+func (p *fakeProvider) Refund(req Refund) (*Transfer, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if t, ok := p.transfers[req.IdempotencyKey]; ok {
+		return t, nil
+	}
+	// Списания, сделанные до перезапуска процесса, fake не помнит и возвращает без проверки
+	if left, ok := p.refundable[req.ChargeRef]; ok {
+		rest, err := left.Sub(req.Amount)
+		if err != nil {
+			return nil, err
+		}
+		if rest.IsNegative() {
+			return nil, ErrRefundTooLarge
+		}
+		p.refundable[req.ChargeRef] = rest
+	}
+	return p.record(req.IdempotencyKey, "fake_re_", req.Amount), nil
+}
+
+func (p *fakeProvider) record(key, prefix string, amount models.Money) *Transfer {
+	t := &Transfer{Ref: prefix + uuid.New().String(), Amount: amount, CreatedAt: time.Now().UTC()}
+	p.transfers[key] = t
+	return t
+}
@@ -0,0 +1,90 @@
+package payments
+
+import (
+	"fmt"
+	"moveshare/internal/models"
+	"testing"
+)
+
+func usd(amount int64) models.Money {
+	return models.NewMoney(amount, "USD")
+}
+
+func TestFakeProviderChargeIsIdempotent(t *testing.T) {
+	p := NewFakeProvider()
+	first, err := p.Charge(Charge{IdempotencyKey: "escrow:1:charge", PayerID: 1, Amount: usd(10000)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Повтор после сбоя — даже с другой суммой — возвращает первое списание
+	replay, err := p.Charge(Charge{IdempotencyKey: "escrow:1:charge", PayerID: 1, Amount: usd(99999)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replay != first || replay.Amount != usd(10000) {
+		t.Errorf("replayed charge = %+v, want %+v", replay, first)
+	}
+
+	other, err := p.Charge(Charge{IdempotencyKey: "escrow:2:charge", PayerID: 1, Amount: usd(10000)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other.Ref == first.Ref {
+		t.Errorf("different keys share transfer %s", other.Ref)
+	}
+}
+
+func TestFakeProviderPayoutIsIdempotent(t *testing.T) {
+	p := NewFakeProvider()
+	first, _ := p.Payout(Payout{IdempotencyKey: "escrow:1:carrier_payout", PayeeID: 2, Amount: usd(8500)})
+	replay, _ := p.Payout(Payout{IdempotencyKey: "escrow:1:carrier_payout", PayeeID: 2, Amount: usd(8500)})
+	if first.Ref != replay.Ref {
+		t.Errorf("replayed payout ref = %s, want %s", replay.Ref, first.Ref)
+	}
+}
+
+func TestFakeProviderRefund(t *testing.T) {
+	tests := []struct {
+		name    string
+		refunds []models.Money
+		wantErr []error
+	}{
+		{"full refund", []models.Money{usd(10000)}, []error{nil}},
+		{"partial refunds up to the charge", []models.Money{usd(4000), usd(6000)}, []error{nil, nil}},
+		{"more than charged", []models.Money{usd(10001)}, []error{ErrRefundTooLarge}},
+		{"second refund exceeds the rest", []models.Money{usd(7000), usd(3001)}, []error{nil, ErrRefundTooLarge}},
+		{"other currency", []models.Money{models.NewMoney(10000, "EUR")}, []error{models.ErrCurrencyMismatch}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewFakeProvider()
+			charge, err := p.Charge(Charge{IdempotencyKey: "charge", PayerID: 1, Amount: usd(10000)})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, amount := range tt.refunds {
+				_, err := p.Refund(Refund{IdempotencyKey: fmt.Sprintf("refund-%d", i), ChargeRef: charge.Ref, Amount: amount})
+				if err != tt.wantErr[i] {
+					t.Errorf("refund %d of %s: err = %v, want %v", i, amount, err, tt.wantErr[i])
+				}
+			}
+		})
+	}
+}
+
+func TestFakeProviderRefundReplayDoesNotRefundTwice(t *testing.T) {
+	p := NewFakeProvider()
+	charge, _ := p.Charge(Charge{IdempotencyKey: "charge", PayerID: 1, Amount: usd(10000)})
+	first, err := p.Refund(Refund{IdempotencyKey: "escrow:1:refund", ChargeRef: charge.Ref, Amount: usd(10000)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Повтор того же возврата не упирается в остаток и не возвращает деньги ещё раз
+	replay, err := p.Refund(Refund{IdempotencyKey: "escrow:1:refund", ChargeRef: charge.Ref, Amount: usd(10000)})
+	if err != nil || replay.Ref != first.Ref {
+		t.Errorf("replayed refund = %+v, %v; want %s", replay, err, first.Ref)
+	}
+	if _, err := p.Refund(Refund{IdempotencyKey: "another", ChargeRef: charge.Ref, Amount: usd(1)}); err != ErrRefundTooLarge {
+		t.Errorf("refund after full refund: err = %v, want ErrRefundTooLarge", err)
+	}
+}
@@ -0,0 +1,63 @@
+package payments
+
+import (
+	"errors"
+	"fmt"
+	"moveshare/internal/config"
+	"moveshare/internal/models"
+	"time"
+)
+
+var ErrRefundTooLarge = errors.New("refund exceeds charge")
+
+// Charge — списание с плательщика на счёт площадки
+type Charge struct {
+	// IdempotencyKey — повтор запроса с тем же ключом возвращает первый результат,
+	// а не списывает деньги ещё раз
+	IdempotencyKey string
+	PayerID        int
+	Amount         models.Money
+	Description    string
+}
+
+// Payout — перевод со счёта площадки получателю
+type Payout struct {
+	IdempotencyKey string
+	PayeeID        int
+	Amount         models.Money
+	Description    string
+}
+
+// Refund — возврат плательщику (части) списания ChargeRef
+type Refund struct {
+	IdempotencyKey string
+	ChargeRef      string
+	Amount         models.Money
+}
+
+// Transfer — проведённая провайдером операция
+type Transfer struct {
+	Ref       string
+	Amount    models.Money
+	CreatedAt time.Time
+}
+
+// Provider проводит деньги через платёжную систему. Все операции идемпотентны
+// по IdempotencyKey: их можно безопасно повторять после сбоя
+type Provider interface {
+	// Name — имя провайдера для журнала движений
+	Name() string
+	Charge(req Charge) (*Transfer, error)
+	Payout(req Payout) (*Transfer, error)
+	Refund(req Refund) (*Transfer, error)
+}
+
+// New создаёт Provider по настройкам; пока есть только fake
+func New(cfg *config.PaymentSettings) (Provider, error) {
+	switch cfg.Provider {
+	case "", "fake":
+		return NewFakeProvider(), nil
+	default:
+		return nil, fmt.Errorf("unknown payments provider %q", cfg.Provider)
+	}
+}
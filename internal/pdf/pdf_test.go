@@ -0,0 +1,100 @@
+package pdf
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func sample() *Document {
+	doc := New("INVOICE INV-000001", time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC))
+	doc.AddPage()
+	doc.Text(54, 738, 18, true, "MoveShare (Acme) \\ — €10")
+	doc.Line(54, 700, 558, 700, 0.75)
+	img := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	img.Set(0, 0, color.RGBA{R: 255, A: 255})
+	doc.Image(54, 600, 20, 20, img)
+	doc.AddPage()
+	doc.TextRight(558, 738, 10, false, "Page 2")
+	return doc
+}
+
+// Одинаковые данные дают побайтно одинаковый файл
+func TestBytesDeterministic(t *testing.T) {
+	a, b := sample().Bytes(), sample().Bytes()
+	if !bytes.Equal(a, b) {
+		t.Fatal("same input produced different files")
+	}
+	if !bytes.HasPrefix(a, []byte("%PDF-1.4\n")) || !bytes.HasSuffix(a, []byte("%%EOF\n")) {
+		t.Error("missing PDF header or trailer")
+	}
+}
+
+// Смещения в таблице xref и startxref указывают на начало объектов
+func TestBytesXref(t *testing.T) {
+	data := sample().Bytes()
+	m := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(data)
+	if m == nil {
+		t.Fatal("no startxref")
+	}
+	xref, _ := strconv.Atoi(string(m[1]))
+	if !bytes.HasPrefix(data[xref:], []byte("xref\n")) {
+		t.Fatalf("startxref %d does not point at the xref table", xref)
+	}
+	offsets := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(data[xref:], -1)
+	// Каталог, дерево страниц, два шрифта, метаданные, по два объекта на страницу и изображение
+	if len(offsets) != 10 {
+		t.Fatalf("xref has %d objects, want 10", len(offsets))
+	}
+	for i, o := range offsets {
+		offset, _ := strconv.Atoi(string(o[1]))
+		want := strconv.Itoa(i+1) + " 0 obj\n"
+		if !bytes.HasPrefix(data[offset:], []byte(want)) {
+			t.Errorf("offset %d does not point at object %d", offset, i+1)
+		}
+	}
+}
+
+func TestTextEncoding(t *testing.T) {
+	data := sample().Bytes()
+	// Скобки и обратная косая черта экранируются, тире и евро — в WinAnsiEncoding
+	want := []byte("(MoveShare \\(Acme\\) \\\\ \x97 \x8010) Tj")
+	if !bytes.Contains(data, want) {
+		t.Errorf("escaped text %q not found", want)
+	}
+	if got := encode("Привет"); string(got) != "??????" {
+		t.Errorf("encode(Cyrillic) = %q, want question marks", got)
+	}
+}
+
+func TestTextWidth(t *testing.T) {
+	tests := []struct {
+		s    string
+		size float64
+		bold bool
+		want float64
+	}{
+		{"", 10, false, 0},
+		{"a", 10, false, 5.56},
+		{"MM", 12, false, 19.992},
+		{"i", 10, true, 2.78},
+		{"Ж", 10, false, 5.56},
+	}
+	for _, tt := range tests {
+		if got := TextWidth(tt.s, tt.size, tt.bold); got < tt.want-1e-9 || got > tt.want+1e-9 {
+			t.Errorf("TextWidth(%q, %v, %v) = %v, want %v", tt.s, tt.size, tt.bold, got, tt.want)
+		}
+	}
+}
+
+func TestNum(t *testing.T) {
+	for in, want := range map[float64]string{72: "72", 10.5: "10.5", 0.75: "0.75", 1.004: "1", 612.1: "612.1"} {
+		if got := num(in); got != want {
+			t.Errorf("num(%v) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"moveshare/internal/models"
+)
+
+var (
+	ErrAttachmentNotFound = errors.New("attachment not found")
+	ErrAttachmentLimit    = errors.New("too many attachments")
+)
+
+const attachmentColumns = `id, job_id, uploaded_by, kind, file_name, content_type, size, sha256, width, height, blob_key, thumbnail_key, created_at`
+
+type AttachmentRepository interface {
+	// CreateAttachment записывает файл, если у работы их меньше maxPerJob
+	CreateAttachment(a *models.Attachment, maxPerJob int) (*models.Attachment, error)
+	GetAttachment(id string) (*models.Attachment, error)
+	GetAttachmentsByJob(jobID string) ([]*models.Attachment, error)
+	DeleteAttachment(id string) error
+}
+
+type attachmentRepository struct {
+	db *sql.DB
+}
+
+func NewAttachmentRepository(db *sql.DB) AttachmentRepository {
+	return &attachmentRepository{db: db}
+}
+
+// CreateAttachment проверяет лимит и пишет запись одной командой. Параллельные
+// загрузки могут вместе чуть превысить лимит — он защищает от злоупотреблений,
+// а не считает файлы точно
+func (r *attachmentRepository) CreateAttachment(a *models.Attachment, maxPerJob int) (*models.Attachment, error) {
+	created, err := scanAttachment(r.db.QueryRow(
+		`INSERT INTO job_attachments (id, job_id, uploaded_by, kind, file_name, content_type, size, sha256, width, height, blob_key, thumbnail_key)
+SELECT $1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12
+WHERE (SELECT COUNT(*) FROM job_attachments WHERE job_id = $2) < $13
+RETURNING `+attachmentColumns,
+		a.ID, a.JobID, a.UploadedBy, a.Kind, a.FileName, a.ContentType, a.Size, a.SHA256, a.Width, a.Height, a.BlobKey, a.ThumbnailKey, maxPerJob,
+	))
+	if err == sql.ErrNoRows {
+		return nil, ErrAttachmentLimit
+	}
+	return created, err
+}
+
+func (r *attachmentRepository) GetAttachment(id string) (*models.Attachment, error) {
+	a, err := scanAttachment(r.db.QueryRow(`SELECT `+attachmentColumns+` FROM job_attachments WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrAttachmentNotFound
+	}
+	return a, err
+}
+
+func (r *attachmentRepository) GetAttachmentsByJob(jobID string) ([]*models.Attachment, error) {
+	rows, err := r.db.Query(`SELECT `+attachmentColumns+` FROM job_attachments WHERE job_id = $1 ORDER BY created_at, id`, jobID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	attachments := []*models.Attachment{}
+	for rows.Next() {
+		a, err := scanAttachment(rows)
+		if err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, a)
+	}
+	return attachments, rows.Err()
+}
+
+func (r *attachmentRepository) DeleteAttachment(id string) error {
+	res, err := r.db.Exec(`DELETE FROM job_attachments WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrAttachmentNotFound
+	}
+	return nil
+}
+
+func scanAttachment(row rowScanner) (*models.Attachment, error) {
+	var a models.Attachment
+	err := row.Scan(&a.ID, &a.JobID, &a.UploadedBy, &a.Kind, &a.FileName, &a.ContentType, &a.Size, &a.SHA256,
+		&a.Width, &a.Height, &a.BlobKey, &a.ThumbnailKey, &a.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
@@ -0,0 +1,248 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"moveshare/internal/models"
+)
+
+var (
+	ErrBidNotFound  = errors.New("bid not found")
+	ErrBidNotActive = errors.New("bid is no longer active")
+)
+
+const bidColumns = `id, job_id, carrier_id, amount, currency, message, counter_amount, counter_message, status, expires_at, created_at, updated_at`
+
+type BidRepository interface {
+	CreateBid(bid *models.Bid, events BidEvents) (*models.Bid, error)
+	GetBidByID(id string) (*models.Bid, error)
+	GetBidsByJob(jobID string) ([]*models.Bid, error)
+	CounterBid(id string, amount models.Money, message string, events BidEvents) (*models.Bid, error)
+	RejectBid(id string, from models.BidStatus, events BidEvents) (*models.Bid, error)
+	AcceptBid(bid *models.Bid, fees *models.FeeBreakdown, events JobEvents, rejected BidEvents) (*models.Job, error)
+}
+
+type bidRepository struct {
+	db *sql.DB
+}
+
+func NewBidRepository(db *sql.DB) BidRepository {
+	return &bidRepository{db: db}
+}
+
+func (r *bidRepository) CreateBid(bid *models.Bid, events BidEvents) (*models.Bid, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	created, err := scanBid(tx.QueryRow(
+		`INSERT INTO bids (id, job_id, carrier_id, amount, currency, message, status, expires_at)
+VALUES ($1,$2,$3,$4,$5,$6,$7,$8)
+RETURNING `+bidColumns,
+		bid.ID, bid.JobID, bid.CarrierID, bid.Amount.Amount, bid.Amount.Currency, bid.Message, bid.Status, bid.ExpiresAt,
+	))
+	if err != nil {
+		return nil, err
+	}
+	if err := commitBid(tx, created, events); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+func (r *bidRepository) GetBidByID(id string) (*models.Bid, error) {
+	bid, err := scanBid(r.db.QueryRow(`SELECT `+bidColumns+` FROM bids WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrBidNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return bid, nil
+}
+
+func (r *bidRepository) GetBidsByJob(jobID string) ([]*models.Bid, error) {
+	rows, err := r.db.Query(`SELECT `+bidColumns+` FROM bids WHERE job_id = $1 ORDER BY created_at DESC`, jobID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var bids []*models.Bid
+	for rows.Next() {
+		bid, err := scanBid(rows)
+		if err != nil {
+			return nil, err
+		}
+		bids = append(bids, bid)
+	}
+	return bids, rows.Err()
+}
+
+// CounterBid записывает встречное предложение на ещё не истёкшую ставку
+func (r *bidRepository) CounterBid(id string, amount models.Money, message string, events BidEvents) (*models.Bid, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	bid, err := scanBid(tx.QueryRow(
+		`UPDATE bids SET status = $1, counter_amount = $2, counter_message = $3, updated_at = NOW()
+WHERE id = $4 AND status = $5 AND expires_at > NOW()
+RETURNING `+bidColumns,
+		models.BidStatusCountered, amount.Amount, message, id, models.BidStatusPending,
+	))
+	if err == sql.ErrNoRows {
+		return nil, ErrBidNotActive
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := commitBid(tx, bid, events); err != nil {
+		return nil, err
+	}
+	return bid, nil
+}
+
+func (r *bidRepository) RejectBid(id string, from models.BidStatus, events BidEvents) (*models.Bid, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	bid, err := scanBid(tx.QueryRow(
+		`UPDATE bids SET status = $1, updated_at = NOW()
+WHERE id = $2 AND status = $3
+RETURNING `+bidColumns,
+		models.BidStatusRejected, id, from,
+	))
+	if err == sql.ErrNoRows {
+		return nil, ErrBidNotActive
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := commitBid(tx, bid, events); err != nil {
+		return nil, err
+	}
+	return bid, nil
+}
+
+// AcceptBid в одной транзакции закрепляет работу за автором ставки по цене fees.Payment,
+// фиксирует расчёт удержаний и эскроу, помечает ставку принятой и отклоняет остальные
+// активные ставки на эту работу. events получает закреплённую работу, rejected —
+// каждую отклонённую ставку
+func (r *bidRepository) AcceptBid(bid *models.Bid, fees *models.FeeBreakdown, events JobEvents, rejected BidEvents) (*models.Job, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(
+		`UPDATE bids SET status = $1, updated_at = NOW()
+WHERE id = $2 AND status = $3 AND expires_at > NOW()`,
+		models.BidStatusAccepted, bid.ID, bid.Status,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return nil, err
+	} else if n == 0 {
+		return nil, ErrBidNotActive
+	}
+
+	job, err := scanJob(tx.QueryRow(
+		`UPDATE jobs SET status = $1, carrier_id = $2, payment_amount = $3, cut_amount = $4
+WHERE id = $5 AND status = $6
+RETURNING `+jobColumns,
+		models.JobStatusClaimed, bid.CarrierID, fees.Payment.Amount, fees.BrokerCut.Amount, bid.JobID, models.JobStatusOpen,
+	))
+	if err == sql.ErrNoRows {
+		return nil, ErrJobNotOpen
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := saveJobFees(tx, job.ID, fees); err != nil {
+		return nil, err
+	}
+	if err := createEscrow(tx, job, fees); err != nil {
+		return nil, err
+	}
+
+	competing, err := rejectCompetingBids(tx, bid)
+	if err != nil {
+		return nil, err
+	}
+	if rejected != nil {
+		for _, b := range competing {
+			if err := writeEvents(tx, rejected(b)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := commitJob(tx, job, events); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// rejectCompetingBids отклоняет остальные активные ставки на работу и возвращает их
+func rejectCompetingBids(tx *sql.Tx, bid *models.Bid) ([]*models.Bid, error) {
+	rows, err := tx.Query(
+		`UPDATE bids SET status = $1, updated_at = NOW()
+WHERE job_id = $2 AND id <> $3 AND status IN ($4, $5)
+RETURNING `+bidColumns,
+		models.BidStatusRejected, bid.JobID, bid.ID, models.BidStatusPending, models.BidStatusCountered,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var bids []*models.Bid
+	for rows.Next() {
+		b, err := scanBid(rows)
+		if err != nil {
+			return nil, err
+		}
+		bids = append(bids, b)
+	}
+	return bids, rows.Err()
+}
+
+func scanBid(row rowScanner) (*models.Bid, error) {
+	var (
+		bid           models.Bid
+		counterAmount *int64
+	)
+	err := row.Scan(
+		&bid.ID,
+		&bid.JobID,
+		&bid.CarrierID,
+		&bid.Amount.Amount,
+		&bid.Amount.Currency,
+		&bid.Message,
+		&counterAmount,
+		&bid.CounterMessage,
+		&bid.Status,
+		&bid.ExpiresAt,
+		&bid.CreatedAt,
+		&bid.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if counterAmount != nil {
+		counter := models.NewMoney(*counterAmount, bid.Amount.Currency)
+		bid.CounterAmount = &counter
+	}
+	return &bid, nil
+}
@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"moveshare/internal/models"
+)
+
+var (
+	ErrBOLNotFound      = errors.New("bill of lading not found")
+	ErrBOLAlreadySigned = errors.New("bill of lading already signed")
+	// ErrBOLChanged — накладную подписали параллельно, пока готовилась новая версия
+	ErrBOLChanged = errors.New("bill of lading changed")
+)
+
+const bolColumns = `id, job_id, number, snapshot, document_hash, file_path, issued_at`
+
+type BOLRepository interface {
+	// CreateBOL записывает выданную накладную. Если у работы накладная уже
+	// есть, возвращает её
+	CreateBOL(bol *models.BillOfLading) (*models.BillOfLading, error)
+	GetBOL(jobID string) (*models.BillOfLading, error)
+	// AddSignature добавляет подпись и новую версию файла. Версия меняется,
+	// только если текущий хеш накладной всё ещё prevHash
+	AddSignature(bol *models.BillOfLading, sig *models.BOLSignature, prevHash string) error
+}
+
+type bolRepository struct {
+	db *sql.DB
+}
+
+func NewBOLRepository(db *sql.DB) BOLRepository {
+	return &bolRepository{db: db}
+}
+
+func (r *bolRepository) CreateBOL(bol *models.BillOfLading) (*models.BillOfLading, error) {
+	snapshot, err := json.Marshal(bol.Snapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(
+		`INSERT INTO bills_of_lading (id, job_id, number, snapshot, document_hash, file_path, issued_at)
+VALUES ($1,$2,$3,$4,$5,$6,$7)
+ON CONFLICT (job_id) DO NOTHING`,
+		bol.ID, bol.JobID, bol.Number, snapshot, bol.DocumentHash, bol.FilePath, bol.IssuedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return nil, err
+	} else if n == 0 {
+		tx.Rollback()
+		return r.GetBOL(bol.JobID)
+	}
+	if _, err := tx.Exec(`UPDATE jobs SET bol_hash = $1 WHERE id = $2`, bol.DocumentHash, bol.JobID); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	bol.Signatures = []*models.BOLSignature{}
+	return bol, nil
+}
+
+func (r *bolRepository) GetBOL(jobID string) (*models.BillOfLading, error) {
+	var (
+		bol      models.BillOfLading
+		snapshot []byte
+	)
+	err := r.db.QueryRow(`SELECT `+bolColumns+` FROM bills_of_lading WHERE job_id = $1`, jobID).Scan(
+		&bol.ID, &bol.JobID, &bol.Number, &snapshot, &bol.DocumentHash, &bol.FilePath, &bol.IssuedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, ErrBOLNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(snapshot, &bol.Snapshot); err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(
+		`SELECT id, role, signer_name, image_path, image_sha256, ip, captured_by, document_hash, signed_at
+FROM bol_signatures WHERE bol_id = $1 ORDER BY signed_at`,
+		bol.ID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	bol.Signatures = []*models.BOLSignature{}
+	for rows.Next() {
+		var sig models.BOLSignature
+		err := rows.Scan(&sig.ID, &sig.Role, &sig.SignerName, &sig.ImagePath, &sig.ImageSHA256, &sig.IP, &sig.CapturedBy, &sig.DocumentHash, &sig.SignedAt)
+		if err != nil {
+			return nil, err
+		}
+		bol.Signatures = append(bol.Signatures, &sig)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return &bol, nil
+}
+
+// AddSignature пишет подпись, новую версию накладной и хеш у работы одной
+// транзакцией. Условие на prevHash не даёт двум подписям одновременно
+// собрать версии, каждая из которых не видит другую
+func (r *bolRepository) AddSignature(bol *models.BillOfLading, sig *models.BOLSignature, prevHash string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(
+		`UPDATE bills_of_lading SET document_hash = $1, file_path = $2 WHERE id = $3 AND document_hash = $4`,
+		bol.DocumentHash, bol.FilePath, bol.ID, prevHash,
+	)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrBOLChanged
+	}
+	_, err = tx.Exec(
+		`INSERT INTO bol_signatures (id, bol_id, role, signer_name, image_path, image_sha256, ip, captured_by, document_hash, signed_at)
+VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)`,
+		sig.ID, bol.ID, sig.Role, sig.SignerName, sig.ImagePath, sig.ImageSHA256, sig.IP, sig.CapturedBy, sig.DocumentHash, sig.SignedAt,
+	)
+	if isUniqueViolation(err) {
+		return ErrBOLAlreadySigned
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`UPDATE jobs SET bol_hash = $1 WHERE id = $2`, bol.DocumentHash, bol.JobID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
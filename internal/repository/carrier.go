@@ -0,0 +1,293 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"moveshare/internal/models"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var (
+	ErrCarrierProfileNotFound = errors.New("carrier profile not found")
+	ErrDOTNumberTaken         = errors.New("dot number already registered")
+	ErrCertificateNotFound    = errors.New("insurance certificate not found")
+	ErrAlreadyReviewed        = errors.New("already reviewed")
+)
+
+const carrierProfileColumns = `user_id, legal_name, dot_number, COALESCE(mc_number, ''), status, review_note, reviewed_by, reviewed_at, created_at, updated_at`
+
+const certificateColumns = `id, carrier_id, file_path, file_name, content_type, coverage_amount, coverage_currency, expires_at, status, review_note, reviewed_by, reviewed_at, created_at`
+
+// verifiedCarrierSQL — условие "перевозчик $1 проверен": профиль одобрен
+// и есть одобренный сертификат страхования, срок которого не истёк
+const verifiedCarrierSQL = `EXISTS (
+SELECT 1 FROM carrier_profiles p
+WHERE p.user_id = $1 AND p.status = 'approved' AND EXISTS (
+	SELECT 1 FROM insurance_certificates c
+	WHERE c.carrier_id = p.user_id AND c.status = 'approved' AND c.expires_at > NOW()))`
+
+type CarrierRepository interface {
+	UpsertProfile(profile *models.CarrierProfile) (*models.CarrierProfile, error)
+	GetProfile(userID int) (*models.CarrierProfile, error)
+	GetProfilesByStatus(status models.CarrierStatus) ([]*models.CarrierProfile, error)
+	ReviewProfile(userID int, status models.CarrierStatus, reviewerID int, note string) (*models.CarrierProfile, error)
+	IsCarrierVerified(userID int) (bool, error)
+	CreateCertificate(cert *models.InsuranceCertificate) (*models.InsuranceCertificate, error)
+	GetCertificate(id string) (*models.InsuranceCertificate, error)
+	GetCertificatesByCarrier(carrierID int) ([]*models.InsuranceCertificate, error)
+	GetCertificatesByStatus(status models.InsuranceStatus) ([]*models.InsuranceCertificate, error)
+	ReviewCertificate(id string, status models.InsuranceStatus, reviewerID int, note string) (*models.InsuranceCertificate, error)
+	ExpireCertificates(now time.Time) (expired int, suspended int, err error)
+}
+
+type carrierRepository struct {
+	db *sql.DB
+}
+
+func NewCarrierRepository(db *sql.DB) CarrierRepository {
+	return &carrierRepository{db: db}
+}
+
+// UpsertProfile создаёт или обновляет профиль перевозчика. Смена DOT/MC
+// номера отправляет профиль на повторную проверку.
+func (r *carrierRepository) UpsertProfile(profile *models.CarrierProfile) (*models.CarrierProfile, error) {
+	p, err := scanCarrierProfile(r.db.QueryRow(
+		`INSERT INTO carrier_profiles (user_id, legal_name, dot_number, mc_number)
+VALUES ($1, $2, $3, NULLIF($4, ''))
+ON CONFLICT (user_id) DO UPDATE SET
+	legal_name = EXCLUDED.legal_name,
+	dot_number = EXCLUDED.dot_number,
+	mc_number = EXCLUDED.mc_number,
+	status = CASE
+		WHEN carrier_profiles.dot_number IS DISTINCT FROM EXCLUDED.dot_number
+			OR carrier_profiles.mc_number IS DISTINCT FROM EXCLUDED.mc_number
+		THEN 'pending' ELSE carrier_profiles.status END,
+	updated_at = NOW()
+RETURNING `+carrierProfileColumns,
+		profile.UserID, profile.LegalName, profile.DOTNumber, profile.MCNumber,
+	))
+	if isUniqueViolation(err) {
+		return nil, ErrDOTNumberTaken
+	}
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (r *carrierRepository) GetProfile(userID int) (*models.CarrierProfile, error) {
+	p, err := scanCarrierProfile(r.db.QueryRow(
+		`SELECT `+carrierProfileColumns+` FROM carrier_profiles WHERE user_id = $1`, userID))
+	if err == sql.ErrNoRows {
+		return nil, ErrCarrierProfileNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (r *carrierRepository) GetProfilesByStatus(status models.CarrierStatus) ([]*models.CarrierProfile, error) {
+	rows, err := r.db.Query(
+		`SELECT `+carrierProfileColumns+` FROM carrier_profiles WHERE status = $1 ORDER BY updated_at`, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	profiles := []*models.CarrierProfile{}
+	for rows.Next() {
+		p, err := scanCarrierProfile(rows)
+		if err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, p)
+	}
+	return profiles, rows.Err()
+}
+
+// ReviewProfile фиксирует решение по профилю, ожидающему проверки
+func (r *carrierRepository) ReviewProfile(userID int, status models.CarrierStatus, reviewerID int, note string) (*models.CarrierProfile, error) {
+	p, err := scanCarrierProfile(r.db.QueryRow(
+		`UPDATE carrier_profiles SET status = $1, review_note = $2, reviewed_by = $3, reviewed_at = NOW(), updated_at = NOW()
+WHERE user_id = $4 AND status = $5
+RETURNING `+carrierProfileColumns,
+		status, note, reviewerID, userID, models.CarrierPending,
+	))
+	if err == sql.ErrNoRows {
+		if _, err := r.GetProfile(userID); err != nil {
+			return nil, err
+		}
+		return nil, ErrAlreadyReviewed
+	}
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (r *carrierRepository) IsCarrierVerified(userID int) (bool, error) {
+	var ok bool
+	err := r.db.QueryRow(`SELECT `+verifiedCarrierSQL, userID).Scan(&ok)
+	return ok, err
+}
+
+func (r *carrierRepository) CreateCertificate(cert *models.InsuranceCertificate) (*models.InsuranceCertificate, error) {
+	return scanCertificate(r.db.QueryRow(
+		`INSERT INTO insurance_certificates (id, carrier_id, file_path, file_name, content_type, coverage_amount, coverage_currency, expires_at)
+VALUES ($1,$2,$3,$4,$5,$6,$7,$8)
+RETURNING `+certificateColumns,
+		cert.ID, cert.CarrierID, cert.FilePath, cert.FileName, cert.ContentType, cert.CoverageAmount.Amount, cert.CoverageAmount.Currency, cert.ExpiresAt,
+	))
+}
+
+func (r *carrierRepository) GetCertificate(id string) (*models.InsuranceCertificate, error) {
+	cert, err := scanCertificate(r.db.QueryRow(
+		`SELECT `+certificateColumns+` FROM insurance_certificates WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrCertificateNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return cert, nil
+}
+
+func (r *carrierRepository) GetCertificatesByCarrier(carrierID int) ([]*models.InsuranceCertificate, error) {
+	return r.queryCertificates(
+		`SELECT `+certificateColumns+` FROM insurance_certificates WHERE carrier_id = $1 ORDER BY expires_at DESC`, carrierID)
+}
+
+func (r *carrierRepository) GetCertificatesByStatus(status models.InsuranceStatus) ([]*models.InsuranceCertificate, error) {
+	return r.queryCertificates(
+		`SELECT `+certificateColumns+` FROM insurance_certificates WHERE status = $1 ORDER BY created_at`, status)
+}
+
+// ReviewCertificate фиксирует решение по сертификату. Одобрение действующего
+// сертификата снимает приостановку с профиля перевозчика.
+func (r *carrierRepository) ReviewCertificate(id string, status models.InsuranceStatus, reviewerID int, note string) (*models.InsuranceCertificate, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	cert, err := scanCertificate(tx.QueryRow(
+		`UPDATE insurance_certificates SET status = $1, review_note = $2, reviewed_by = $3, reviewed_at = NOW()
+WHERE id = $4 AND status = $5
+RETURNING `+certificateColumns,
+		status, note, reviewerID, id, models.InsurancePending,
+	))
+	if err == sql.ErrNoRows {
+		if _, err := r.GetCertificate(id); err != nil {
+			return nil, err
+		}
+		return nil, ErrAlreadyReviewed
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if status == models.InsuranceApproved && cert.ExpiresAt.After(time.Now()) {
+		if _, err := tx.Exec(
+			`UPDATE carrier_profiles SET status = $1, updated_at = NOW() WHERE user_id = $2 AND status = $3`,
+			models.CarrierApproved, cert.CarrierID, models.CarrierSuspended,
+		); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return cert, nil
+}
+
+// ExpireCertificates помечает просроченные сертификаты и приостанавливает
+// одобренных перевозчиков, у которых не осталось действующей страховки
+func (r *carrierRepository) ExpireCertificates(now time.Time) (int, int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, 0, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(
+		`UPDATE insurance_certificates SET status = $1
+WHERE status IN ($2, $3) AND expires_at <= $4`,
+		models.InsuranceExpired, models.InsurancePending, models.InsuranceApproved, now,
+	)
+	if err != nil {
+		return 0, 0, err
+	}
+	expired, err := res.RowsAffected()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	res, err = tx.Exec(
+		`UPDATE carrier_profiles p SET status = $1, updated_at = NOW()
+WHERE p.status = $2 AND NOT EXISTS (
+	SELECT 1 FROM insurance_certificates c
+	WHERE c.carrier_id = p.user_id AND c.status = $3 AND c.expires_at > $4)`,
+		models.CarrierSuspended, models.CarrierApproved, models.InsuranceApproved, now,
+	)
+	if err != nil {
+		return 0, 0, err
+	}
+	suspended, err := res.RowsAffected()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, 0, err
+	}
+	return int(expired), int(suspended), nil
+}
+
+func (r *carrierRepository) queryCertificates(query string, args ...interface{}) ([]*models.InsuranceCertificate, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	certs := []*models.InsuranceCertificate{}
+	for rows.Next() {
+		cert, err := scanCertificate(rows)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+	return certs, rows.Err()
+}
+
+func scanCarrierProfile(row rowScanner) (*models.CarrierProfile, error) {
+	var p models.CarrierProfile
+	err := row.Scan(&p.UserID, &p.LegalName, &p.DOTNumber, &p.MCNumber, &p.Status, &p.ReviewNote,
+		&p.ReviewedBy, &p.ReviewedAt, &p.CreatedAt, &p.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func scanCertificate(row rowScanner) (*models.InsuranceCertificate, error) {
+	var c models.InsuranceCertificate
+	err := row.Scan(&c.ID, &c.CarrierID, &c.FilePath, &c.FileName, &c.ContentType, &c.CoverageAmount.Amount,
+		&c.CoverageAmount.Currency, &c.ExpiresAt, &c.Status, &c.ReviewNote, &c.ReviewedBy, &c.ReviewedAt, &c.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// isUniqueViolation — нарушение UNIQUE-ограничения (SQLSTATE 23505)
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
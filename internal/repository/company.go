@@ -0,0 +1,337 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"moveshare/internal/models"
+	"strings"
+)
+
+var (
+	ErrCompanyNotFound         = errors.New("company not found")
+	ErrMemberNotFound          = errors.New("company member not found")
+	ErrLastOwner               = errors.New("company must keep at least one owner")
+	ErrInvitationInvalid       = errors.New("invitation is invalid, used or expired")
+	ErrInvitationEmailMismatch = errors.New("invitation was sent to another email")
+)
+
+const invitationColumns = `id, company_id, email, role, token_hash, COALESCE(invited_by, 0), expires_at, accepted_at, created_at`
+
+type CompanyRepository interface {
+	CreateCompany(company *models.Company, ownerID int) (*models.Company, error)
+	GetCompanyByID(id int) (*models.Company, error)
+	GetCompaniesByUser(userID int) ([]*models.Company, error)
+	GetMember(companyID, userID int) (*models.CompanyMember, error)
+	GetMembers(companyID int) ([]*models.CompanyMember, error)
+	UpdateMemberRole(companyID, userID int, role models.CompanyRole) (*models.CompanyMember, error)
+	RemoveMember(companyID, userID int) error
+	SetActiveCompany(userID int, companyID *int) error
+	CreateInvitation(inv *models.CompanyInvitation) (*models.CompanyInvitation, error)
+	GetPendingInvitations(companyID int) ([]*models.CompanyInvitation, error)
+	AcceptInvitation(tokenHash string, user *models.User) (*models.CompanyMember, error)
+}
+
+type companyRepository struct {
+	db *sql.DB
+}
+
+func NewCompanyRepository(db *sql.DB) CompanyRepository {
+	return &companyRepository{db: db}
+}
+
+// CreateCompany создаёт компанию и делает ownerID её владельцем.
+// Если у владельца ещё нет активной компании, новая становится активной.
+func (r *companyRepository) CreateCompany(company *models.Company, ownerID int) (*models.Company, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow(
+		`INSERT INTO companies (name, created_by) VALUES ($1, $2) RETURNING id, created_at`,
+		company.Name, ownerID,
+	).Scan(&company.ID, &company.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec(
+		`INSERT INTO company_members (company_id, user_id, role) VALUES ($1, $2, $3)`,
+		company.ID, ownerID, models.CompanyOwner,
+	); err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec(
+		`UPDATE users SET active_company_id = $1 WHERE id = $2 AND active_company_id IS NULL`,
+		company.ID, ownerID,
+	); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	company.CreatedBy = ownerID
+	company.MyRole = models.CompanyOwner
+	return company, nil
+}
+
+func (r *companyRepository) GetCompanyByID(id int) (*models.Company, error) {
+	var c models.Company
+	err := r.db.QueryRow(
+		`SELECT id, name, COALESCE(created_by, 0), created_at FROM companies WHERE id = $1`, id,
+	).Scan(&c.ID, &c.Name, &c.CreatedBy, &c.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrCompanyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// GetCompaniesByUser возвращает компании, в которых состоит пользователь, с его ролью в каждой
+func (r *companyRepository) GetCompaniesByUser(userID int) ([]*models.Company, error) {
+	rows, err := r.db.Query(
+		`SELECT c.id, c.name, COALESCE(c.created_by, 0), c.created_at, m.role
+FROM companies c JOIN company_members m ON m.company_id = c.id
+WHERE m.user_id = $1 ORDER BY c.name`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	companies := []*models.Company{}
+	for rows.Next() {
+		var c models.Company
+		if err := rows.Scan(&c.ID, &c.Name, &c.CreatedBy, &c.CreatedAt, &c.MyRole); err != nil {
+			return nil, err
+		}
+		companies = append(companies, &c)
+	}
+	return companies, rows.Err()
+}
+
+func (r *companyRepository) GetMember(companyID, userID int) (*models.CompanyMember, error) {
+	member, err := scanMember(r.db.QueryRow(
+		`SELECT m.company_id, m.user_id, u.email, u.username, m.role, m.joined_at
+FROM company_members m JOIN users u ON u.id = m.user_id
+WHERE m.company_id = $1 AND m.user_id = $2`, companyID, userID))
+	if err == sql.ErrNoRows {
+		return nil, ErrMemberNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return member, nil
+}
+
+func (r *companyRepository) GetMembers(companyID int) ([]*models.CompanyMember, error) {
+	rows, err := r.db.Query(
+		`SELECT m.company_id, m.user_id, u.email, u.username, m.role, m.joined_at
+FROM company_members m JOIN users u ON u.id = m.user_id
+WHERE m.company_id = $1 ORDER BY m.joined_at`, companyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := []*models.CompanyMember{}
+	for rows.Next() {
+		member, err := scanMember(rows)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, member)
+	}
+	return members, rows.Err()
+}
+
+// UpdateMemberRole меняет роль сотрудника. Последнего владельца понизить нельзя.
+func (r *companyRepository) UpdateMemberRole(companyID, userID int, role models.CompanyRole) (*models.CompanyMember, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := lockLastOwner(tx, companyID, userID); err != nil {
+		if !errors.Is(err, ErrLastOwner) || role != models.CompanyOwner {
+			return nil, err
+		}
+	}
+	if _, err := tx.Exec(
+		`UPDATE company_members SET role = $1 WHERE company_id = $2 AND user_id = $3`,
+		role, companyID, userID,
+	); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return r.GetMember(companyID, userID)
+}
+
+// RemoveMember исключает сотрудника из компании. Если компания была у него
+// активной, он возвращается к работе от своего имени.
+func (r *companyRepository) RemoveMember(companyID, userID int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockLastOwner(tx, companyID, userID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(
+		`DELETE FROM company_members WHERE company_id = $1 AND user_id = $2`, companyID, userID,
+	); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(
+		`UPDATE users SET active_company_id = NULL WHERE id = $1 AND active_company_id = $2`, userID, companyID,
+	); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// lockLastOwner блокирует состав владельцев компании до конца транзакции и
+// возвращает ErrLastOwner, если userID — её единственный владелец
+func lockLastOwner(tx *sql.Tx, companyID, userID int) error {
+	rows, err := tx.Query(
+		`SELECT user_id, role FROM company_members WHERE company_id = $1 FOR UPDATE`, companyID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var (
+		found      bool
+		isOwner    bool
+		ownerCount int
+	)
+	for rows.Next() {
+		var (
+			id   int
+			role models.CompanyRole
+		)
+		if err := rows.Scan(&id, &role); err != nil {
+			return err
+		}
+		if role == models.CompanyOwner {
+			ownerCount++
+		}
+		if id == userID {
+			found = true
+			isOwner = role == models.CompanyOwner
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if !found {
+		return ErrMemberNotFound
+	}
+	if isOwner && ownerCount == 1 {
+		return ErrLastOwner
+	}
+	return nil
+}
+
+// SetActiveCompany меняет активную компанию пользователя; nil — работать от своего имени
+func (r *companyRepository) SetActiveCompany(userID int, companyID *int) error {
+	_, err := r.db.Exec(`UPDATE users SET active_company_id = $1 WHERE id = $2`, companyID, userID)
+	return err
+}
+
+func (r *companyRepository) CreateInvitation(inv *models.CompanyInvitation) (*models.CompanyInvitation, error) {
+	return scanInvitation(r.db.QueryRow(
+		`INSERT INTO company_invitations (id, company_id, email, role, token_hash, invited_by, expires_at)
+VALUES ($1,$2,$3,$4,$5,$6,$7)
+RETURNING `+invitationColumns,
+		inv.ID, inv.CompanyID, inv.Email, inv.Role, inv.TokenHash, inv.InvitedBy, inv.ExpiresAt,
+	))
+}
+
+func (r *companyRepository) GetPendingInvitations(companyID int) ([]*models.CompanyInvitation, error) {
+	rows, err := r.db.Query(
+		`SELECT `+invitationColumns+` FROM company_invitations
+WHERE company_id = $1 AND accepted_at IS NULL AND expires_at > NOW()
+ORDER BY created_at DESC`, companyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	invitations := []*models.CompanyInvitation{}
+	for rows.Next() {
+		inv, err := scanInvitation(rows)
+		if err != nil {
+			return nil, err
+		}
+		invitations = append(invitations, inv)
+	}
+	return invitations, rows.Err()
+}
+
+// AcceptInvitation одноразово принимает приглашение и добавляет пользователя
+// в компанию. Приглашение действительно только для адреса, на который отправлено.
+// Если пользователь уже состоит в компании, его роль не меняется.
+func (r *companyRepository) AcceptInvitation(tokenHash string, user *models.User) (*models.CompanyMember, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	inv, err := scanInvitation(tx.QueryRow(
+		`SELECT `+invitationColumns+` FROM company_invitations
+WHERE token_hash = $1 AND accepted_at IS NULL AND expires_at > NOW()
+FOR UPDATE`, tokenHash))
+	if err == sql.ErrNoRows {
+		return nil, ErrInvitationInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(inv.Email, user.Email) {
+		return nil, ErrInvitationEmailMismatch
+	}
+
+	if _, err := tx.Exec(
+		`INSERT INTO company_members (company_id, user_id, role) VALUES ($1, $2, $3)
+ON CONFLICT (company_id, user_id) DO NOTHING`,
+		inv.CompanyID, user.ID, inv.Role,
+	); err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec(
+		`UPDATE company_invitations SET accepted_at = NOW() WHERE id = $1`, inv.ID,
+	); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return r.GetMember(inv.CompanyID, user.ID)
+}
+
+func scanMember(row rowScanner) (*models.CompanyMember, error) {
+	var m models.CompanyMember
+	if err := row.Scan(&m.CompanyID, &m.UserID, &m.Email, &m.Username, &m.Role, &m.JoinedAt); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+func scanInvitation(row rowScanner) (*models.CompanyInvitation, error) {
+	var inv models.CompanyInvitation
+	err := row.Scan(&inv.ID, &inv.CompanyID, &inv.Email, &inv.Role, &inv.TokenHash, &inv.InvitedBy,
+		&inv.ExpiresAt, &inv.AcceptedAt, &inv.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &inv, nil
+}
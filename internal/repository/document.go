@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"moveshare/internal/models"
+
+	"github.com/google/uuid"
+)
+
+var ErrDocumentNotFound = errors.New("document not found")
+
+const documentColumns = `id, job_id, kind, issuer, number, file_path, issued_at`
+
+type DocumentRepository interface {
+	// IssueDocument выдаёт работе документ kind со следующим номером выставителя
+	// issuer. Если документ уже выдан, возвращает его с прежним номером
+	IssueDocument(jobID string, kind models.DocumentKind, issuer string) (*models.JobDocument, error)
+	GetDocument(jobID string, kind models.DocumentKind) (*models.JobDocument, error)
+	GetDocumentsByJob(jobID string) ([]*models.JobDocument, error)
+	SetFilePath(id, filePath string) error
+}
+
+type documentRepository struct {
+	db *sql.DB
+}
+
+func NewDocumentRepository(db *sql.DB) DocumentRepository {
+	return &documentRepository{db: db}
+}
+
+// IssueDocument берёт номер и записывает документ одной транзакцией: если
+// документ параллельно выдал другой запрос, транзакция откатывается вместе
+// с номером, поэтому в нумерации не бывает пропусков
+func (r *documentRepository) IssueDocument(jobID string, kind models.DocumentKind, issuer string) (*models.JobDocument, error) {
+	if doc, err := r.GetDocument(jobID, kind); err != ErrDocumentNotFound {
+		return doc, err
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var number int
+	err = tx.QueryRow(
+		`INSERT INTO document_sequences (issuer, kind, last_number) VALUES ($1, $2, 1)
+ON CONFLICT (issuer, kind) DO UPDATE SET last_number = document_sequences.last_number + 1
+RETURNING last_number`,
+		issuer, kind,
+	).Scan(&number)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := scanDocument(tx.QueryRow(
+		`INSERT INTO job_documents (id, job_id, kind, issuer, number) VALUES ($1,$2,$3,$4,$5)
+ON CONFLICT (job_id, kind) DO NOTHING
+RETURNING `+documentColumns,
+		uuid.New().String(), jobID, kind, issuer, number,
+	))
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		return r.GetDocument(jobID, kind)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+func (r *documentRepository) GetDocument(jobID string, kind models.DocumentKind) (*models.JobDocument, error) {
+	doc, err := scanDocument(r.db.QueryRow(
+		`SELECT `+documentColumns+` FROM job_documents WHERE job_id = $1 AND kind = $2`, jobID, kind,
+	))
+	if err == sql.ErrNoRows {
+		return nil, ErrDocumentNotFound
+	}
+	return doc, err
+}
+
+func (r *documentRepository) GetDocumentsByJob(jobID string) ([]*models.JobDocument, error) {
+	rows, err := r.db.Query(`SELECT `+documentColumns+` FROM job_documents WHERE job_id = $1 ORDER BY issued_at`, jobID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	docs := []*models.JobDocument{}
+	for rows.Next() {
+		doc, err := scanDocument(rows)
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, doc)
+	}
+	return docs, rows.Err()
+}
+
+func (r *documentRepository) SetFilePath(id, filePath string) error {
+	_, err := r.db.Exec(`UPDATE job_documents SET file_path = $1 WHERE id = $2`, filePath, id)
+	return err
+}
+
+func scanDocument(row rowScanner) (*models.JobDocument, error) {
+	var doc models.JobDocument
+	err := row.Scan(&doc.ID, &doc.JobID, &doc.Kind, &doc.Issuer, &doc.Number, &doc.FilePath, &doc.IssuedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"moveshare/internal/models"
+	"time"
+)
+
+var (
+	ErrNoFeeRuleSet    = errors.New("no active fee rule set")
+	ErrJobFeesNotFound = errors.New("job fees not found")
+)
+
+const feeRuleSetColumns = `id, version, name, rules, active_from, created_by, created_at`
+
+type FeeRepository interface {
+	CreateRuleSet(set *models.FeeRuleSet) (*models.FeeRuleSet, error)
+	GetRuleSets() ([]*models.FeeRuleSet, error)
+	GetActiveRuleSet(at time.Time) (*models.FeeRuleSet, error)
+	GetJobFees(jobID string) (*models.FeeBreakdown, error)
+}
+
+type feeRepository struct {
+	db *sql.DB
+}
+
+func NewFeeRepository(db *sql.DB) FeeRepository {
+	return &feeRepository{db: db}
+}
+
+// CreateRuleSet сохраняет новую версию правил; номер версии выдаёт база
+func (r *feeRepository) CreateRuleSet(set *models.FeeRuleSet) (*models.FeeRuleSet, error) {
+	rules, err := json.Marshal(set.Rules)
+	if err != nil {
+		return nil, err
+	}
+	return scanFeeRuleSet(r.db.QueryRow(
+		`INSERT INTO fee_rule_sets (id, name, rules, active_from, created_by)
+VALUES ($1,$2,$3,$4,$5)
+RETURNING `+feeRuleSetColumns,
+		set.ID, set.Name, rules, set.ActiveFrom, set.CreatedBy,
+	))
+}
+
+// GetRuleSets — все версии правил, новые первыми
+func (r *feeRepository) GetRuleSets() ([]*models.FeeRuleSet, error) {
+	rows, err := r.db.Query(`SELECT ` + feeRuleSetColumns + ` FROM fee_rule_sets ORDER BY version DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sets := []*models.FeeRuleSet{}
+	for rows.Next() {
+		set, err := scanFeeRuleSet(rows)
+		if err != nil {
+			return nil, err
+		}
+		sets = append(sets, set)
+	}
+	return sets, rows.Err()
+}
+
+// GetActiveRuleSet — последняя версия, которая уже действует в момент at
+func (r *feeRepository) GetActiveRuleSet(at time.Time) (*models.FeeRuleSet, error) {
+	set, err := scanFeeRuleSet(r.db.QueryRow(
+		`SELECT `+feeRuleSetColumns+` FROM fee_rule_sets WHERE active_from <= $1 ORDER BY version DESC LIMIT 1`, at,
+	))
+	if err == sql.ErrNoRows {
+		return nil, ErrNoFeeRuleSet
+	}
+	return set, err
+}
+
+func (r *feeRepository) GetJobFees(jobID string) (*models.FeeBreakdown, error) {
+	var (
+		fees                                        models.FeeBreakdown
+		currency                                    string
+		payment, platformFee, brokerCut, carrierNet int64
+		lines                                       []byte
+	)
+	err := r.db.QueryRow(
+		`SELECT rule_set_id, rule_set_version, currency, payment, platform_fee, broker_cut, carrier_net, promo_code, lines, calculated_at
+FROM job_fees WHERE job_id = $1`, jobID,
+	).Scan(&fees.RuleSetID, &fees.RuleSetVersion, &currency, &payment, &platformFee, &brokerCut, &carrierNet,
+		&fees.PromoCode, &lines, &fees.CalculatedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrJobFeesNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(lines, &fees.Lines); err != nil {
+		return nil, err
+	}
+	fees.Payment = models.NewMoney(payment, currency)
+	fees.PlatformFee = models.NewMoney(platformFee, currency)
+	fees.BrokerCut = models.NewMoney(brokerCut, currency)
+	fees.CarrierNet = models.NewMoney(carrierNet, currency)
+	return &fees, nil
+}
+
+// saveJobFees фиксирует расчёт удержаний работы в транзакции её закрепления
+func saveJobFees(tx *sql.Tx, jobID string, fees *models.FeeBreakdown) error {
+	lines, err := json.Marshal(fees.Lines)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(
+		`INSERT INTO job_fees (job_id, rule_set_id, rule_set_version, currency, payment, platform_fee, broker_cut, carrier_net, promo_code, lines, calculated_at)
+VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11)`,
+		jobID, fees.RuleSetID, fees.RuleSetVersion, fees.Payment.Currency, fees.Payment.Amount,
+		fees.PlatformFee.Amount, fees.BrokerCut.Amount, fees.CarrierNet.Amount, fees.PromoCode, lines, fees.CalculatedAt,
+	)
+	return err
+}
+
+func scanFeeRuleSet(row rowScanner) (*models.FeeRuleSet, error) {
+	var (
+		set   models.FeeRuleSet
+		rules []byte
+	)
+	if err := row.Scan(&set.ID, &set.Version, &set.Name, &rules, &set.ActiveFrom, &set.CreatedBy, &set.CreatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(rules, &set.Rules); err != nil {
+		return nil, err
+	}
+	return &set, nil
+}
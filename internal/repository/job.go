@@ -4,14 +4,35 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"moveshare/internal/geo"
 	"moveshare/internal/models"
 	"strings"
 )
 
+var (
+	ErrJobNotFound       = errors.New("job not found")
+	ErrJobForbidden      = errors.New("job belongs to another user")
+	ErrJobNotOpen        = errors.New("job is no longer open")
+	ErrJobStatusConflict = errors.New("job status changed concurrently")
+	ErrJobInProgress     = errors.New("job is in progress")
+)
+
+// jobColumns — порядок колонок, который ожидает scanJob
+const jobColumns = `id, title, number_of_bedrooms, additional_services, description_additional_services, truck_size, pickup_datetime, delivery_datetime, cut_amount, payment_amount, currency, COALESCE(poster_id, 0), status, carrier_id,
+pickup_street, pickup_city, pickup_state, pickup_zip, COALESCE(pickup_lat, 0), COALESCE(pickup_lng, 0),
+delivery_street, delivery_city, delivery_state, delivery_zip, COALESCE(delivery_lat, 0), COALESCE(delivery_lng, 0),
+COALESCE(distance_miles, 0), company_id, promo_code, bol_hash`
+
 type JobRepository interface {
-	CreateJob(job *models.Job) (*models.Job, error)
+	CreateJob(job *models.Job, events JobEvents) (*models.Job, error)
+	GetJobByID(id string) (*models.Job, error)
 	GetJobs(filter models.JobFilter, limit, offset int) ([]*models.Job, int, error)
-	DeleteJob(id string) error
+	UpdateJob(job *models.Job, userID int) (*models.Job, error)
+	DeleteJob(id string, userID int, events JobEvents) error
+	ForceDeleteJob(id string, events JobEvents) error
+	ClaimJob(id string, carrierID int, fees *models.FeeBreakdown, events JobEvents) (*models.Job, error)
+	CanManageJob(id string, userID int) (bool, error)
+	UpdateJobStatus(id string, from, to models.JobStatus, journal []*models.JournalEntry, events JobEvents) (*models.Job, error)
 }
 
 type jobRepository struct {
@@ -22,17 +43,44 @@ func NewJobRepository(db *sql.DB) JobRepository {
 	return &jobRepository{db: db}
 }
 
-func (r *jobRepository) CreateJob(job *models.Job) (*models.Job, error) {
-	_, err := r.db.Exec(
+// CreateJob сохраняет работу и её события в одной транзакции
+func (r *jobRepository) CreateJob(job *models.Job, events JobEvents) (*models.Job, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(
 		`INSERT INTO jobs 
-(id, title, number_of_bedrooms, additional_services, description_additional_services, truck_size, pickup_datetime, delivery_datetime, cut_amount, payment_amount)
-VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)`,
+(id, title, number_of_bedrooms, additional_services, description_additional_services, truck_size, pickup_datetime, delivery_datetime, cut_amount, payment_amount, poster_id,
+pickup_street, pickup_city, pickup_state, pickup_zip, pickup_lat, pickup_lng,
+delivery_street, delivery_city, delivery_state, delivery_zip, delivery_lat, delivery_lng, distance_miles, company_id, currency, promo_code)
+VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,$19,$20,$21,$22,$23,$24,$25,$26,$27)`,
 		job.ID, job.JobTitle, job.NumberOfBedrooms, job.AdditionalServices, job.DescriptionAdditionalServices,
-		job.TruckSize, job.PickupDateTime, job.DeliveryDateTime, job.CutAmount, job.PaymentAmount,
+		job.TruckSize, job.PickupDateTime, job.DeliveryDateTime, job.CutAmount.Amount, job.PaymentAmount.Amount, job.PosterID,
+		job.PickupAddress.Street, job.PickupAddress.City, job.PickupAddress.State, job.PickupAddress.ZIP,
+		job.PickupAddress.Latitude, job.PickupAddress.Longitude,
+		job.DeliveryAddress.Street, job.DeliveryAddress.City, job.DeliveryAddress.State, job.DeliveryAddress.ZIP,
+		job.DeliveryAddress.Latitude, job.DeliveryAddress.Longitude, job.DistanceMiles, job.CompanyID, job.PaymentAmount.Currency, job.PromoCode,
 	)
 	if err != nil {
 		return nil, err
 	}
+	if err := commitJob(tx, job, events); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+func (r *jobRepository) GetJobByID(id string) (*models.Job, error) {
+	job, err := scanJob(r.db.QueryRow(`SELECT `+jobColumns+` FROM jobs WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrJobNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
 	return job, nil
 }
 
@@ -63,16 +111,49 @@ func (r *jobRepository) GetJobs(filter models.JobFilter, limit, offset int) ([]*
 		args = append(args, filter.DateEnd)
 		argIdx++
 	}
+	// Суммы в разных валютах несравнимы, поэтому граница оплаты отсекает и другие валюты
 	if filter.PayoutMin != nil {
-		where = append(where, fmt.Sprintf("payment_amount >= $%d", argIdx))
-		args = append(args, filter.PayoutMin)
-		argIdx++
+		where = append(where, fmt.Sprintf("payment_amount >= $%d AND currency = $%d", argIdx, argIdx+1))
+		args = append(args, filter.PayoutMin.Amount, filter.PayoutMin.Currency)
+		argIdx += 2
 	}
 	if filter.PayoutMax != nil {
-		where = append(where, fmt.Sprintf("payment_amount <= $%d", argIdx))
-		args = append(args, filter.PayoutMax)
+		where = append(where, fmt.Sprintf("payment_amount <= $%d AND currency = $%d", argIdx, argIdx+1))
+		args = append(args, filter.PayoutMax.Amount, filter.PayoutMax.Currency)
+		argIdx += 2
+	}
+	if filter.Status != "" {
+		where = append(where, fmt.Sprintf("status = $%d", argIdx))
+		args = append(args, filter.Status)
+		argIdx++
+	}
+	// Рейтинг заказчика — только отзывы перевозчиков о нём как о заказчике
+	if filter.MinPosterRating != nil {
+		where = append(where, fmt.Sprintf("(SELECT AVG(rating) FROM reviews WHERE reviewee_id = jobs.poster_id AND reviewer_side = 'carrier') >= $%d", argIdx))
+		args = append(args, filter.MinPosterRating)
+		argIdx++
+	}
+	if filter.CompanyID != 0 {
+		where = append(where, fmt.Sprintf("company_id = $%d", argIdx))
+		args = append(args, filter.CompanyID)
 		argIdx++
 	}
+	orderBy := "pickup_datetime DESC"
+	if filter.Origin != nil {
+		originDistance := haversineSQL("pickup_lat", "pickup_lng", argIdx, argIdx+1)
+		if filter.Sort == models.JobSortDeadhead {
+			// При равном расстоянии оплата сравнивается только внутри одной валюты
+			orderBy = originDistance + " ASC, currency, payment_amount DESC"
+		}
+		where = append(where, fmt.Sprintf("%s <= $%d", originDistance, argIdx+2))
+		args = append(args, filter.Origin.Latitude, filter.Origin.Longitude, filter.Origin.Miles)
+		argIdx += 3
+	}
+	if filter.Destination != nil {
+		where = append(where, fmt.Sprintf("%s <= $%d", haversineSQL("delivery_lat", "delivery_lng", argIdx, argIdx+1), argIdx+2))
+		args = append(args, filter.Destination.Latitude, filter.Destination.Longitude, filter.Destination.Miles)
+		argIdx += 3
+	}
 
 	whereClause := ""
 	if len(where) > 0 {
@@ -80,8 +161,8 @@ func (r *jobRepository) GetJobs(filter models.JobFilter, limit, offset int) ([]*
 	}
 
 	// Основной запрос
-	query := fmt.Sprintf(`SELECT id, title, number_of_bedrooms, additional_services, description_additional_services, truck_size, pickup_datetime, delivery_datetime, cut_amount, payment_amount
-FROM jobs %s ORDER BY pickup_datetime DESC LIMIT $%d OFFSET $%d`, whereClause, argIdx, argIdx+1)
+	query := fmt.Sprintf(`SELECT %s
+FROM jobs %s ORDER BY %s LIMIT $%d OFFSET $%d`, jobColumns, whereClause, orderBy, argIdx, argIdx+1)
 
 	args = append(args, limit, offset)
 
@@ -93,23 +174,11 @@ FROM jobs %s ORDER BY pickup_datetime DESC LIMIT $%d OFFSET $%d`, whereClause, a
 
 	var jobs []*models.Job
 	for rows.Next() {
-		var job models.Job
-		err := rows.Scan(
-			&job.ID,
-			&job.JobTitle,
-			&job.NumberOfBedrooms,
-			&job.AdditionalServices,
-			&job.DescriptionAdditionalServices,
-			&job.TruckSize,
-			&job.PickupDateTime,
-			&job.DeliveryDateTime,
-			&job.CutAmount,
-			&job.PaymentAmount,
-		)
+		job, err := scanJob(rows)
 		if err != nil {
 			return nil, 0, err
 		}
-		jobs = append(jobs, &job)
+		jobs = append(jobs, job)
 	}
 
 	// Считаем total
@@ -122,17 +191,242 @@ FROM jobs %s ORDER BY pickup_datetime DESC LIMIT $%d OFFSET $%d`, whereClause, a
 	return jobs, total, nil
 }
 
-func (r *jobRepository) DeleteJob(id string) error {
-	res, err := r.db.Exec("DELETE FROM jobs WHERE id = $1", id)
+func (r *jobRepository) UpdateJob(job *models.Job, userID int) (*models.Job, error) {
+	res, err := r.db.Exec(
+		`UPDATE jobs SET title = $1, number_of_bedrooms = $2, additional_services = $3, description_additional_services = $4,
+truck_size = $5, pickup_datetime = $6, delivery_datetime = $7, cut_amount = $8, payment_amount = $9,
+pickup_street = $10, pickup_city = $11, pickup_state = $12, pickup_zip = $13, pickup_lat = $14, pickup_lng = $15,
+delivery_street = $16, delivery_city = $17, delivery_state = $18, delivery_zip = $19, delivery_lat = $20, delivery_lng = $21,
+distance_miles = $22, currency = $23, promo_code = $24
+WHERE id = $25 AND status = 'open' AND `+managedBy(26),
+		job.JobTitle, job.NumberOfBedrooms, job.AdditionalServices, job.DescriptionAdditionalServices,
+		job.TruckSize, job.PickupDateTime, job.DeliveryDateTime, job.CutAmount.Amount, job.PaymentAmount.Amount,
+		job.PickupAddress.Street, job.PickupAddress.City, job.PickupAddress.State, job.PickupAddress.ZIP,
+		job.PickupAddress.Latitude, job.PickupAddress.Longitude,
+		job.DeliveryAddress.Street, job.DeliveryAddress.City, job.DeliveryAddress.State, job.DeliveryAddress.ZIP,
+		job.DeliveryAddress.Latitude, job.DeliveryAddress.Longitude, job.DistanceMiles, job.PaymentAmount.Currency, job.PromoCode,
+		job.ID, userID,
+	)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	rows, err := res.RowsAffected()
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if rows == 0 {
-		return errors.New("job not found")
+		return nil, r.mutationError(job.ID, userID)
+	}
+	return r.GetJobByID(job.ID)
+}
+
+// DeleteJob удаляет открытую работу. События строятся по удалённой строке
+func (r *jobRepository) DeleteJob(id string, userID int, events JobEvents) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	job, err := scanJob(tx.QueryRow(
+		"DELETE FROM jobs WHERE id = $1 AND status = 'open' AND "+managedBy(2)+" RETURNING "+jobColumns,
+		id, userID,
+	))
+	if err == sql.ErrNoRows {
+		return r.mutationError(id, userID)
+	}
+	if err != nil {
+		return err
+	}
+	return commitJob(tx, job, events)
+}
+
+// ForceDeleteJob удаляет открытую или отменённую работу без проверки владельца —
+// для модерации. Работу в исполнении удалять нельзя (ErrJobInProgress): по ней
+// есть эскроу и проводки, её сначала отменяют с возвратом оплаты
+func (r *jobRepository) ForceDeleteJob(id string, events JobEvents) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	job, err := scanJob(tx.QueryRow(
+		"DELETE FROM jobs WHERE id = $1 AND status IN ($2, $3) RETURNING "+jobColumns,
+		id, models.JobStatusOpen, models.JobStatusCancelled,
+	))
+	if err == sql.ErrNoRows {
+		if _, err := r.GetJobByID(id); err != nil {
+			return err
+		}
+		return ErrJobInProgress
+	}
+	if err != nil {
+		return err
+	}
+	return commitJob(tx, job, events)
+}
+
+// ClaimJob атомарно закрепляет открытую работу за перевозчиком, фиксирует
+// расчёт удержаний fees и заводит по нему эскроу. Условие status = 'open' в UPDATE гарантирует, что из
+// нескольких одновременных запросов выиграет только один, а условие на оплату —
+// что fees посчитаны от текущей оплаты (иначе работа считается изменившейся).
+func (r *jobRepository) ClaimJob(id string, carrierID int, fees *models.FeeBreakdown, events JobEvents) (*models.Job, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	job, err := scanJob(tx.QueryRow(
+		`UPDATE jobs SET status = $1, carrier_id = $2, cut_amount = $3
+WHERE id = $4 AND status = $5 AND payment_amount = $6 AND currency = $7
+RETURNING `+jobColumns,
+		models.JobStatusClaimed, carrierID, fees.BrokerCut.Amount, id, models.JobStatusOpen, fees.Payment.Amount, fees.Payment.Currency,
+	))
+	if err == sql.ErrNoRows {
+		if _, err := r.GetJobByID(id); err != nil {
+			return nil, err
+		}
+		return nil, ErrJobNotOpen
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := saveJobFees(tx, job.ID, fees); err != nil {
+		return nil, err
+	}
+	if err := createEscrow(tx, job, fees); err != nil {
+		return nil, err
+	}
+	if err := commitJob(tx, job, events); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// CanManageJob сообщает, может ли пользователь управлять работой:
+// он её автор либо владелец или диспетчер компании, которой она принадлежит
+func (r *jobRepository) CanManageJob(id string, userID int) (bool, error) {
+	var ok bool
+	err := r.db.QueryRow(
+		"SELECT EXISTS (SELECT 1 FROM jobs WHERE id = $1 AND "+managedBy(2)+")",
+		id, userID,
+	).Scan(&ok)
+	return ok, err
+}
+
+// UpdateJobStatus переводит работу из статуса from в статус to и в той же
+// транзакции записывает проводки journal (nil — без проводок).
+// Если статус успел измениться, возвращает ErrJobStatusConflict.
+func (r *jobRepository) UpdateJobStatus(id string, from, to models.JobStatus, journal []*models.JournalEntry, events JobEvents) (*models.Job, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	job, err := scanJob(tx.QueryRow(
+		`UPDATE jobs SET status = $1 WHERE id = $2 AND status = $3 RETURNING `+jobColumns,
+		to, id, from,
+	))
+	if err == sql.ErrNoRows {
+		if _, err := r.GetJobByID(id); err != nil {
+			return nil, err
+		}
+		return nil, ErrJobStatusConflict
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := postJournal(tx, journal); err != nil {
+		return nil, err
+	}
+	if err := commitJob(tx, job, events); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// mutationError объясняет, почему изменение не затронуло ни одной строки:
+// работы нет, она принадлежит другому пользователю или уже не открыта.
+func (r *jobRepository) mutationError(id string, userID int) error {
+	if _, err := r.GetJobByID(id); err != nil {
+		return err
+	}
+	ok, err := r.CanManageJob(id, userID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrJobForbidden
+	}
+	return ErrJobNotOpen
+}
+
+// managedBy — условие "работой управляет пользователь $userArg": он автор,
+// либо работа принадлежит компании, где он владелец или диспетчер
+func managedBy(userArg int) string {
+	return fmt.Sprintf(
+		"(poster_id = $%[1]d OR company_id IN (SELECT company_id FROM company_members WHERE user_id = $%[1]d AND role IN ('%[2]s', '%[3]s')))",
+		userArg, models.CompanyOwner, models.CompanyDispatcher,
+	)
+}
+
+// haversineSQL — расстояние в милях от колонок latCol/lngCol до точки ($latArg, $lngArg).
+// LEAST защищает ASIN от аргумента чуть больше 1 из-за погрешности округления
+func haversineSQL(latCol, lngCol string, latArg, lngArg int) string {
+	return fmt.Sprintf(
+		"(%[5]f * 2 * ASIN(LEAST(1, SQRT(POWER(SIN(RADIANS(%[1]s - $%[3]d) / 2), 2) + "+
+			"COS(RADIANS($%[3]d)) * COS(RADIANS(%[1]s)) * POWER(SIN(RADIANS(%[2]s - $%[4]d) / 2), 2)))))",
+		latCol, lngCol, latArg, lngArg, geo.EarthRadiusMiles,
+	)
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanJob(row rowScanner) (*models.Job, error) {
+	var (
+		job      models.Job
+		currency string
+	)
+	err := row.Scan(
+		&job.ID,
+		&job.JobTitle,
+		&job.NumberOfBedrooms,
+		&job.AdditionalServices,
+		&job.DescriptionAdditionalServices,
+		&job.TruckSize,
+		&job.PickupDateTime,
+		&job.DeliveryDateTime,
+		&job.CutAmount.Amount,
+		&job.PaymentAmount.Amount,
+		&currency,
+		&job.PosterID,
+		&job.Status,
+		&job.CarrierID,
+		&job.PickupAddress.Street,
+		&job.PickupAddress.City,
+		&job.PickupAddress.State,
+		&job.PickupAddress.ZIP,
+		&job.PickupAddress.Latitude,
+		&job.PickupAddress.Longitude,
+		&job.DeliveryAddress.Street,
+		&job.DeliveryAddress.City,
+		&job.DeliveryAddress.State,
+		&job.DeliveryAddress.ZIP,
+		&job.DeliveryAddress.Latitude,
+		&job.DeliveryAddress.Longitude,
+		&job.DistanceMiles,
+		&job.CompanyID,
+		&job.PromoCode,
+		&job.BOLHash,
+	)
+	if err != nil {
+		return nil, err
 	}
-	return nil
+	job.CutAmount.Currency, job.PaymentAmount.Currency = currency, currency
+	return &job, nil
 }
@@ -0,0 +1,214 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"moveshare/internal/models"
+	"time"
+)
+
+var ErrUnbalancedEntry = errors.New("journal entry is not balanced")
+
+const ledgerAccountColumns = `a.id, a.type, a.user_id, a.currency, a.created_at`
+
+type LedgerRepository interface {
+	// GetUserAccounts — личные счета пользователя с остатками
+	GetUserAccounts(userID int) ([]*models.LedgerAccount, error)
+	// GetAccounts — все счета типа accountType с остатками
+	GetAccounts(accountType models.AccountType) ([]*models.LedgerAccount, error)
+	// GetStatement — выписка по счетам пользователя за [from, to)
+	GetStatement(userID int, from, to time.Time) (*models.Statement, error)
+}
+
+type ledgerRepository struct {
+	db *sql.DB
+}
+
+func NewLedgerRepository(db *sql.DB) LedgerRepository {
+	return &ledgerRepository{db: db}
+}
+
+// postJournal записывает проводки в транзакции изменения, которое их вызвало.
+// Проводка с уже записанным dedup_key пропускается. Несбалансированная проводка
+// не записывается; в базе то же проверяет отложенный триггер
+func postJournal(tx *sql.Tx, entries []*models.JournalEntry) error {
+	for _, entry := range entries {
+		if err := checkBalanced(entry); err != nil {
+			return err
+		}
+		err := tx.QueryRow(
+			`INSERT INTO journal_entries (id, type, job_id, description, dedup_key)
+VALUES ($1,$2,$3,$4,$5)
+ON CONFLICT (dedup_key) DO NOTHING
+RETURNING posted_at`,
+			entry.ID, entry.Type, entry.JobID, entry.Description, entry.DedupKey,
+		).Scan(&entry.PostedAt)
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		for _, posting := range entry.Postings {
+			accountID, err := ensureAccount(tx, posting.AccountType, posting.UserID, posting.Amount.Currency)
+			if err != nil {
+				return err
+			}
+			_, err = tx.Exec(
+				`INSERT INTO ledger_postings (entry_id, account_id, amount, currency) VALUES ($1,$2,$3,$4)`,
+				entry.ID, accountID, posting.Amount.Amount, posting.Amount.Currency,
+			)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkBalanced — в проводке не меньше двух движений, и в каждой валюте они дают ноль
+func checkBalanced(entry *models.JournalEntry) error {
+	if len(entry.Postings) < 2 {
+		return ErrUnbalancedEntry
+	}
+	sums := map[string]int64{}
+	for _, posting := range entry.Postings {
+		if !posting.Amount.Valid() || posting.AccountType.Personal() != (posting.UserID != nil) {
+			return ErrUnbalancedEntry
+		}
+		sums[posting.Amount.Currency] += posting.Amount.Amount
+	}
+	for _, sum := range sums {
+		if sum != 0 {
+			return ErrUnbalancedEntry
+		}
+	}
+	return nil
+}
+
+// ensureAccount возвращает id счёта, заводя его при первом движении
+func ensureAccount(tx *sql.Tx, accountType models.AccountType, userID *int, currency string) (int, error) {
+	code := string(accountType) + ":" + currency
+	if userID != nil {
+		code = fmt.Sprintf("%s:%d:%s", accountType, *userID, currency)
+	}
+	var id int
+	err := tx.QueryRow(
+		`INSERT INTO ledger_accounts (code, type, user_id, currency) VALUES ($1,$2,$3,$4)
+ON CONFLICT (code) DO UPDATE SET code = EXCLUDED.code
+RETURNING id`,
+		code, accountType, userID, currency,
+	).Scan(&id)
+	return id, err
+}
+
+func (r *ledgerRepository) GetUserAccounts(userID int) ([]*models.LedgerAccount, error) {
+	return r.queryAccounts(`WHERE a.user_id = $1`, userID)
+}
+
+func (r *ledgerRepository) GetAccounts(accountType models.AccountType) ([]*models.LedgerAccount, error) {
+	return r.queryAccounts(`WHERE a.type = $1`, accountType)
+}
+
+func (r *ledgerRepository) queryAccounts(where string, arg interface{}) ([]*models.LedgerAccount, error) {
+	rows, err := r.db.Query(
+		`SELECT `+ledgerAccountColumns+`, COALESCE(SUM(p.amount), 0)
+FROM ledger_accounts a
+LEFT JOIN ledger_postings p ON p.account_id = a.id
+`+where+`
+GROUP BY a.id
+ORDER BY a.id`, arg,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	accounts := []*models.LedgerAccount{}
+	for rows.Next() {
+		var (
+			account models.LedgerAccount
+			sum     int64
+		)
+		if err := rows.Scan(&account.ID, &account.Type, &account.UserID, &account.Currency, &account.CreatedAt, &sum); err != nil {
+			return nil, err
+		}
+		account.Balance = models.NewMoney(normalSide(account.Type, sum), account.Currency)
+		accounts = append(accounts, &account)
+	}
+	return accounts, rows.Err()
+}
+
+func (r *ledgerRepository) GetStatement(userID int, from, to time.Time) (*models.Statement, error) {
+	accounts, err := r.GetUserAccounts(userID)
+	if err != nil {
+		return nil, err
+	}
+	statement := &models.Statement{From: from, To: to, Accounts: []*models.AccountStatement{}}
+	for _, account := range accounts {
+		accountStatement, err := r.accountStatement(account, from, to)
+		if err != nil {
+			return nil, err
+		}
+		statement.Accounts = append(statement.Accounts, accountStatement)
+	}
+	return statement, nil
+}
+
+// accountStatement — остаток на начало периода и движения по счёту за период
+func (r *ledgerRepository) accountStatement(account *models.LedgerAccount, from, to time.Time) (*models.AccountStatement, error) {
+	var opening int64
+	err := r.db.QueryRow(
+		`SELECT COALESCE(SUM(p.amount), 0)
+FROM ledger_postings p JOIN journal_entries e ON e.id = p.entry_id
+WHERE p.account_id = $1 AND e.posted_at < $2`,
+		account.ID, from,
+	).Scan(&opening)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(
+		`SELECT e.id, e.type, e.job_id, e.description, e.posted_at, p.amount
+FROM ledger_postings p JOIN journal_entries e ON e.id = p.entry_id
+WHERE p.account_id = $1 AND e.posted_at >= $2 AND e.posted_at < $3
+ORDER BY e.posted_at, p.id`,
+		account.ID, from, to,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	balance := normalSide(account.Type, opening)
+	statement := &models.AccountStatement{
+		Account:        account,
+		OpeningBalance: models.NewMoney(balance, account.Currency),
+		Lines:          []*models.StatementLine{},
+	}
+	for rows.Next() {
+		var (
+			line   models.StatementLine
+			amount int64
+		)
+		if err := rows.Scan(&line.EntryID, &line.Type, &line.JobID, &line.Description, &line.PostedAt, &amount); err != nil {
+			return nil, err
+		}
+		amount = normalSide(account.Type, amount)
+		balance += amount
+		line.Amount = models.NewMoney(amount, account.Currency)
+		line.Balance = models.NewMoney(balance, account.Currency)
+		statement.Lines = append(statement.Lines, &line)
+	}
+	statement.ClosingBalance = models.NewMoney(balance, account.Currency)
+	return statement, rows.Err()
+}
+
+// normalSide переводит сумму движений (дебет — плюс) в нормальную сторону счёта
+func normalSide(accountType models.AccountType, amount int64) int64 {
+	if accountType.DebitNormal() {
+		return amount
+	}
+	return -amount
+}
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"moveshare/internal/models"
+	"testing"
+)
+
+func posting(accountType models.AccountType, userID *int, amount int64, currency string) *models.Posting {
+	return &models.Posting{AccountType: accountType, UserID: userID, Amount: models.NewMoney(amount, currency)}
+}
+
+func TestCheckBalanced(t *testing.T) {
+	poster, carrier := 1, 2
+	tests := []struct {
+		name     string
+		postings []*models.Posting
+		balanced bool
+	}{
+		{"escrow funded", []*models.Posting{
+			posting(models.AccountEscrow, nil, 10000, "USD"),
+			posting(models.AccountPosterBalance, &poster, -10000, "USD"),
+		}, true},
+		{"job completed, three legs", []*models.Posting{
+			posting(models.AccountPosterBalance, &poster, 9500, "USD"),
+			posting(models.AccountCarrierPayable, &carrier, -8500, "USD"),
+			posting(models.AccountPlatformRevenue, nil, -1000, "USD"),
+		}, true},
+		{"balanced in each currency", []*models.Posting{
+			posting(models.AccountEscrow, nil, 100, "USD"),
+			posting(models.AccountPosterBalance, &poster, -100, "USD"),
+			posting(models.AccountEscrow, nil, 200, "EUR"),
+			posting(models.AccountPosterBalance, &poster, -200, "EUR"),
+		}, true},
+		{"off by one cent", []*models.Posting{
+			posting(models.AccountEscrow, nil, 10000, "USD"),
+			posting(models.AccountPosterBalance, &poster, -9999, "USD"),
+		}, false},
+		{"zero total across currencies", []*models.Posting{
+			posting(models.AccountEscrow, nil, 100, "USD"),
+			posting(models.AccountPosterBalance, &poster, -100, "EUR"),
+		}, false},
+		{"single posting", []*models.Posting{
+			posting(models.AccountEscrow, nil, 0, "USD"),
+		}, false},
+		{"personal account without user", []*models.Posting{
+			posting(models.AccountEscrow, nil, 100, "USD"),
+			posting(models.AccountPosterBalance, nil, -100, "USD"),
+		}, false},
+		{"platform account with user", []*models.Posting{
+			posting(models.AccountPlatformRevenue, &poster, 100, "USD"),
+			posting(models.AccountPosterBalance, &poster, -100, "USD"),
+		}, false},
+		{"invalid currency", []*models.Posting{
+			posting(models.AccountEscrow, nil, 100, "usd"),
+			posting(models.AccountPosterBalance, &poster, -100, "usd"),
+		}, false},
+	}
+	for _, tt := range tests {
+		err := checkBalanced(&models.JournalEntry{Type: models.JournalEscrowFunded, Postings: tt.postings})
+		if (err == nil) != tt.balanced {
+			t.Errorf("%s: checkBalanced err = %v, want balanced %v", tt.name, err, tt.balanced)
+		}
+		if err != nil && err != ErrUnbalancedEntry {
+			t.Errorf("%s: err = %v, want ErrUnbalancedEntry", tt.name, err)
+		}
+	}
+}
+
+func TestNormalSide(t *testing.T) {
+	// Эскроу — актив: дебет увеличивает остаток. Остальные счета растут по кредиту
+	if got := normalSide(models.AccountEscrow, 500); got != 500 {
+		t.Errorf("escrow debit = %d, want 500", got)
+	}
+	for _, accountType := range []models.AccountType{models.AccountPosterBalance, models.AccountCarrierPayable, models.AccountPlatformRevenue} {
+		if got := normalSide(accountType, -500); got != 500 {
+			t.Errorf("%s credit = %d, want 500", accountType, got)
+		}
+	}
+}
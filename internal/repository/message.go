@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"moveshare/internal/models"
+)
+
+var ErrThreadNotFound = errors.New("message thread not found")
+
+const messageColumns = `id, thread_id, COALESCE(sender_id, 0), from_carrier, body, created_at, read_at`
+
+// threadColumns ожидает параметр $1 — сторону читателя (true — перевозчик),
+// чтобы посчитать непрочитанные сообщения от другой стороны
+const threadColumns = `t.id, t.job_id, t.carrier_id, t.created_at,
+(SELECT MAX(m.created_at) FROM messages m WHERE m.thread_id = t.id),
+(SELECT COUNT(*) FROM messages m WHERE m.thread_id = t.id AND m.read_at IS NULL AND m.from_carrier <> $1)`
+
+type MessageRepository interface {
+	CanOpenThread(jobID string, carrierID int) (bool, error)
+	GetOrCreateThread(jobID string, carrierID int) (*models.MessageThread, error)
+	GetThread(jobID string, carrierID int, viewerIsCarrier bool) (*models.MessageThread, error)
+	GetThreadsByJob(jobID string, carrierID int, viewerIsCarrier bool) ([]*models.MessageThread, error)
+	CreateMessage(msg *models.Message) (*models.Message, error)
+	GetMessages(threadID string, limit, offset int) ([]*models.Message, int, error)
+	MarkRead(threadID string, viewerIsCarrier bool) (int, error)
+}
+
+type messageRepository struct {
+	db *sql.DB
+}
+
+func NewMessageRepository(db *sql.DB) MessageRepository {
+	return &messageRepository{db: db}
+}
+
+// CanOpenThread — переписка возможна, только если перевозчик сделал ставку
+// на работу или взял её
+func (r *messageRepository) CanOpenThread(jobID string, carrierID int) (bool, error) {
+	var ok bool
+	err := r.db.QueryRow(
+		`SELECT EXISTS (SELECT 1 FROM bids WHERE job_id = $1 AND carrier_id = $2)
+OR EXISTS (SELECT 1 FROM jobs WHERE id = $1 AND carrier_id = $2)`,
+		jobID, carrierID,
+	).Scan(&ok)
+	return ok, err
+}
+
+func (r *messageRepository) GetOrCreateThread(jobID string, carrierID int) (*models.MessageThread, error) {
+	var t models.MessageThread
+	err := r.db.QueryRow(
+		`INSERT INTO message_threads (id, job_id, carrier_id) VALUES (gen_random_uuid(), $1, $2)
+ON CONFLICT (job_id, carrier_id) DO UPDATE SET job_id = EXCLUDED.job_id
+RETURNING id, job_id, carrier_id, created_at`,
+		jobID, carrierID,
+	).Scan(&t.ID, &t.JobID, &t.CarrierID, &t.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *messageRepository) GetThread(jobID string, carrierID int, viewerIsCarrier bool) (*models.MessageThread, error) {
+	t, err := scanThread(r.db.QueryRow(
+		`SELECT `+threadColumns+` FROM message_threads t WHERE t.job_id = $2 AND t.carrier_id = $3`,
+		viewerIsCarrier, jobID, carrierID,
+	))
+	if err == sql.ErrNoRows {
+		return nil, ErrThreadNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// GetThreadsByJob возвращает переписки по работе; carrierID != 0 — только с этим перевозчиком
+func (r *messageRepository) GetThreadsByJob(jobID string, carrierID int, viewerIsCarrier bool) ([]*models.MessageThread, error) {
+	query := `SELECT ` + threadColumns + ` FROM message_threads t WHERE t.job_id = $2`
+	args := []interface{}{viewerIsCarrier, jobID}
+	if carrierID != 0 {
+		query += fmt.Sprintf(" AND t.carrier_id = $%d", len(args)+1)
+		args = append(args, carrierID)
+	}
+	query += " ORDER BY 5 DESC NULLS LAST, t.created_at DESC"
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	threads := []*models.MessageThread{}
+	for rows.Next() {
+		t, err := scanThread(rows)
+		if err != nil {
+			return nil, err
+		}
+		threads = append(threads, t)
+	}
+	return threads, rows.Err()
+}
+
+func (r *messageRepository) CreateMessage(msg *models.Message) (*models.Message, error) {
+	return scanMessage(r.db.QueryRow(
+		`INSERT INTO messages (id, thread_id, sender_id, from_carrier, body)
+VALUES ($1,$2,$3,$4,$5)
+RETURNING `+messageColumns,
+		msg.ID, msg.ThreadID, msg.SenderID, msg.FromCarrier, msg.Body,
+	))
+}
+
+// GetMessages возвращает сообщения переписки, новые первыми
+func (r *messageRepository) GetMessages(threadID string, limit, offset int) ([]*models.Message, int, error) {
+	rows, err := r.db.Query(
+		`SELECT `+messageColumns+` FROM messages WHERE thread_id = $1 ORDER BY created_at DESC, id LIMIT $2 OFFSET $3`,
+		threadID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	messages := []*models.Message{}
+	for rows.Next() {
+		msg, err := scanMessage(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		messages = append(messages, msg)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	var total int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM messages WHERE thread_id = $1`, threadID).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	return messages, total, nil
+}
+
+// MarkRead отмечает прочитанными все сообщения другой стороны
+func (r *messageRepository) MarkRead(threadID string, viewerIsCarrier bool) (int, error) {
+	res, err := r.db.Exec(
+		`UPDATE messages SET read_at = NOW() WHERE thread_id = $1 AND from_carrier <> $2 AND read_at IS NULL`,
+		threadID, viewerIsCarrier,
+	)
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+func scanThread(row rowScanner) (*models.MessageThread, error) {
+	var t models.MessageThread
+	if err := row.Scan(&t.ID, &t.JobID, &t.CarrierID, &t.CreatedAt, &t.LastMessageAt, &t.UnreadCount); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func scanMessage(row rowScanner) (*models.Message, error) {
+	var m models.Message
+	if err := row.Scan(&m.ID, &m.ThreadID, &m.SenderID, &m.FromCarrier, &m.Body, &m.CreatedAt, &m.ReadAt); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
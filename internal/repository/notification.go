@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"moveshare/internal/models"
+)
+
+const notificationColumns = `id, user_id, type, title, body, job_id, read_at, created_at`
+
+type NotificationRepository interface {
+	CreateNotification(n *models.Notification) (*models.Notification, error)
+	GetNotifications(userID int, unreadOnly bool, limit, offset int) ([]*models.Notification, int, error)
+	CountUnread(userID int) (int, error)
+	MarkAllRead(userID int) (int, error)
+}
+
+type notificationRepository struct {
+	db *sql.DB
+}
+
+func NewNotificationRepository(db *sql.DB) NotificationRepository {
+	return &notificationRepository{db: db}
+}
+
+func (r *notificationRepository) CreateNotification(n *models.Notification) (*models.Notification, error) {
+	return scanNotification(r.db.QueryRow(
+		`INSERT INTO notifications (id, user_id, type, title, body, job_id)
+VALUES ($1,$2,$3,$4,$5,$6)
+RETURNING `+notificationColumns,
+		n.ID, n.UserID, n.Type, n.Title, n.Body, n.JobID,
+	))
+}
+
+// GetNotifications возвращает уведомления пользователя, новые первыми
+func (r *notificationRepository) GetNotifications(userID int, unreadOnly bool, limit, offset int) ([]*models.Notification, int, error) {
+	where := "WHERE user_id = $1"
+	if unreadOnly {
+		where += " AND read_at IS NULL"
+	}
+	rows, err := r.db.Query(
+		fmt.Sprintf(`SELECT %s FROM notifications %s ORDER BY created_at DESC, id LIMIT $2 OFFSET $3`, notificationColumns, where),
+		userID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	notifications := []*models.Notification{}
+	for rows.Next() {
+		n, err := scanNotification(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		notifications = append(notifications, n)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	var total int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM notifications `+where, userID).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	return notifications, total, nil
+}
+
+func (r *notificationRepository) CountUnread(userID int) (int, error) {
+	var n int
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM notifications WHERE user_id = $1 AND read_at IS NULL`, userID).Scan(&n)
+	return n, err
+}
+
+func (r *notificationRepository) MarkAllRead(userID int) (int, error) {
+	res, err := r.db.Exec(`UPDATE notifications SET read_at = NOW() WHERE user_id = $1 AND read_at IS NULL`, userID)
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+func scanNotification(row rowScanner) (*models.Notification, error) {
+	var n models.Notification
+	if err := row.Scan(&n.ID, &n.UserID, &n.Type, &n.Title, &n.Body, &n.JobID, &n.ReadAt, &n.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &n, nil
+}
@@ -0,0 +1,182 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"moveshare/internal/models"
+	"sort"
+	"time"
+)
+
+// JobEvents строит события по работе, которую вернуло изменение. Вызывается
+// внутри транзакции, так что события попадают в outbox вместе с изменением.
+// nil — изменение без событий
+type JobEvents func(job *models.Job) []*models.Event
+
+// BidEvents — то же для ставок
+type BidEvents func(bid *models.Bid) []*models.Event
+
+// OutboxRepository — таблица outbox_events: события, записанные вместе с
+// изменениями данных и ещё не разосланные подписчикам
+type OutboxRepository interface {
+	ClaimPending(limit int, lease time.Duration) ([]*PendingEvent, error)
+	GetConsumers(eventID string) (map[string]bool, error)
+	MarkConsumed(eventID, subscriber string) error
+	MarkPublished(eventID string) error
+	RecordFailure(eventID string, errMsg string, nextAttemptAt *time.Time) error
+}
+
+// PendingEvent — событие из outbox и число уже сделанных попыток его разослать
+type PendingEvent struct {
+	Event    *models.Event
+	Attempts int
+}
+
+type outboxRepository struct {
+	db *sql.DB
+}
+
+func NewOutboxRepository(db *sql.DB) OutboxRepository {
+	return &outboxRepository{db: db}
+}
+
+// writeEvents пишет события в outbox в транзакции изменения. Событие с уже
+// записанным dedup_key пропускается
+func writeEvents(tx *sql.Tx, events []*models.Event) error {
+	for _, event := range events {
+		payload, err := json.Marshal(event.Data)
+		if err != nil {
+			return err
+		}
+		audience, err := json.Marshal(event.Audience)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(
+			`INSERT INTO outbox_events (id, type, payload, audience, dedup_key, created_at)
+VALUES ($1,$2,$3,$4,$5,$6)
+ON CONFLICT (dedup_key) DO NOTHING`,
+			event.ID, event.Type, payload, audience, event.DedupKey, event.CreatedAt,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// commitJob пишет в outbox события по изменённой работе и фиксирует транзакцию
+func commitJob(tx *sql.Tx, job *models.Job, events JobEvents) error {
+	if events != nil {
+		if err := writeEvents(tx, events(job)); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// commitBid — то же для ставок
+func commitBid(tx *sql.Tx, bid *models.Bid, events BidEvents) error {
+	if events != nil {
+		if err := writeEvents(tx, events(bid)); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// ClaimPending забирает до limit событий, которые пора разослать, и сдвигает их
+// следующую попытку на lease вперёд: если экземпляр упадёт посреди рассылки,
+// событие позже подхватит другой. SKIP LOCKED не даёт двум экземплярам взять одно событие
+func (r *outboxRepository) ClaimPending(limit int, lease time.Duration) ([]*PendingEvent, error) {
+	rows, err := r.db.Query(
+		`UPDATE outbox_events SET next_attempt_at = NOW() + $2 * INTERVAL '1 second'
+WHERE id IN (
+	SELECT id FROM outbox_events
+	WHERE published_at IS NULL AND failed_at IS NULL AND next_attempt_at <= NOW()
+	ORDER BY created_at
+	LIMIT $1
+	FOR UPDATE SKIP LOCKED
+)
+RETURNING id, type, payload, audience, dedup_key, attempts, created_at`,
+		limit, lease.Seconds(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	pending := []*PendingEvent{}
+	for rows.Next() {
+		var (
+			event             models.Event
+			payload, audience []byte
+			attempts          int
+		)
+		if err := rows.Scan(&event.ID, &event.Type, &payload, &audience, &event.DedupKey, &attempts, &event.CreatedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(audience, &event.Audience); err != nil {
+			return nil, err
+		}
+		event.Data = json.RawMessage(payload)
+		pending = append(pending, &PendingEvent{Event: &event, Attempts: attempts})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	// RETURNING не сохраняет порядок подзапроса, а подписчикам важен порядок записи
+	sort.SliceStable(pending, func(i, j int) bool {
+		return pending[i].Event.CreatedAt.Before(pending[j].Event.CreatedAt)
+	})
+	return pending, nil
+}
+
+// GetConsumers — подписчики, которые уже обработали событие
+func (r *outboxRepository) GetConsumers(eventID string) (map[string]bool, error) {
+	rows, err := r.db.Query(`SELECT subscriber FROM outbox_consumers WHERE event_id = $1`, eventID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	consumers := map[string]bool{}
+	for rows.Next() {
+		var subscriber string
+		if err := rows.Scan(&subscriber); err != nil {
+			return nil, err
+		}
+		consumers[subscriber] = true
+	}
+	return consumers, rows.Err()
+}
+
+func (r *outboxRepository) MarkConsumed(eventID, subscriber string) error {
+	_, err := r.db.Exec(
+		`INSERT INTO outbox_consumers (event_id, subscriber) VALUES ($1, $2) ON CONFLICT DO NOTHING`,
+		eventID, subscriber,
+	)
+	return err
+}
+
+func (r *outboxRepository) MarkPublished(eventID string) error {
+	_, err := r.db.Exec(
+		`UPDATE outbox_events SET published_at = NOW(), attempts = attempts + 1, last_error = NULL WHERE id = $1`,
+		eventID,
+	)
+	return err
+}
+
+// RecordFailure записывает неудачную рассылку. nextAttemptAt == nil — попытки
+// исчерпаны, событие помечается failed
+func (r *outboxRepository) RecordFailure(eventID string, errMsg string, nextAttemptAt *time.Time) error {
+	_, err := r.db.Exec(
+		`UPDATE outbox_events
+SET attempts = attempts + 1, last_error = $1,
+	next_attempt_at = COALESCE($2, next_attempt_at),
+	failed_at = CASE WHEN $2::timestamp IS NULL THEN NOW() END
+WHERE id = $3`,
+		errMsg, nextAttemptAt, eventID,
+	)
+	return err
+}
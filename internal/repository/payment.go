@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"moveshare/internal/models"
+
+	"github.com/google/uuid"
+)
+
+var ErrEscrowNotFound = errors.New("escrow not found")
+
+const escrowColumns = `id, job_id, status, payer_id, payee_id, currency, payment, platform_fee, broker_cut, carrier_net, charge_ref, created_at, updated_at`
+
+// EscrowChange — что записать вместе с изменённым эскроу
+type EscrowChange struct {
+	// Movements — движения денег у платёжного провайдера (payment_ledger)
+	Movements []*models.LedgerEntry
+	// Journal — проводки двойной записи по этим движениям
+	Journal []*models.JournalEntry
+}
+
+// EscrowTransition меняет эскроу и возвращает, что записать вместе с ним (nil — ничего).
+// Вызывается, пока строка эскроу заблокирована. Ошибка отменяет изменение
+type EscrowTransition func(escrow *models.Escrow) (*EscrowChange, error)
+
+type PaymentRepository interface {
+	// GetEscrow — эскроу работы вместе с журналом движений
+	GetEscrow(jobID string) (*models.Escrow, error)
+	// UpdateEscrow блокирует эскроу работы, применяет transition и сохраняет
+	// новое состояние, движения и проводки одной транзакцией. Операции по одной
+	// работе (в том числе из разных экземпляров) выполняются по очереди
+	UpdateEscrow(jobID string, transition EscrowTransition) (*models.Escrow, error)
+}
+
+type paymentRepository struct {
+	db *sql.DB
+}
+
+func NewPaymentRepository(db *sql.DB) PaymentRepository {
+	return &paymentRepository{db: db}
+}
+
+// createEscrow заводит эскроу в транзакции закрепления работы: платит автор
+// работы, получает назначенный перевозчик, суммы — из расчёта удержаний
+func createEscrow(tx *sql.Tx, job *models.Job, fees *models.FeeBreakdown) error {
+	_, err := tx.Exec(
+		`INSERT INTO escrows (id, job_id, status, payer_id, payee_id, currency, payment, platform_fee, broker_cut, carrier_net)
+VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)`,
+		uuid.New().String(), job.ID, models.EscrowPending, job.PosterID, *job.CarrierID, fees.Payment.Currency,
+		fees.Payment.Amount, fees.PlatformFee.Amount, fees.BrokerCut.Amount, fees.CarrierNet.Amount,
+	)
+	return err
+}
+
+func (r *paymentRepository) GetEscrow(jobID string) (*models.Escrow, error) {
+	escrow, err := scanEscrow(r.db.QueryRow(`SELECT `+escrowColumns+` FROM escrows WHERE job_id = $1`, jobID))
+	if err == sql.ErrNoRows {
+		return nil, ErrEscrowNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(
+		`SELECT id, escrow_id, job_id, type, amount, currency, user_id, provider, provider_ref, created_at
+FROM payment_ledger WHERE escrow_id = $1 ORDER BY created_at, id`, escrow.ID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			entry    models.LedgerEntry
+			amount   int64
+			currency string
+		)
+		if err := rows.Scan(&entry.ID, &entry.EscrowID, &entry.JobID, &entry.Type, &amount, &currency,
+			&entry.UserID, &entry.Provider, &entry.ProviderRef, &entry.CreatedAt); err != nil {
+			return nil, err
+		}
+		entry.Amount = models.NewMoney(amount, currency)
+		escrow.Ledger = append(escrow.Ledger, &entry)
+	}
+	return escrow, rows.Err()
+}
+
+func (r *paymentRepository) UpdateEscrow(jobID string, transition EscrowTransition) (*models.Escrow, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	escrow, err := scanEscrow(tx.QueryRow(`SELECT `+escrowColumns+` FROM escrows WHERE job_id = $1 FOR UPDATE`, jobID))
+	if err == sql.ErrNoRows {
+		return nil, ErrEscrowNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	change, err := transition(escrow)
+	if err != nil {
+		return nil, err
+	}
+	if change == nil {
+		change = &EscrowChange{}
+	}
+
+	err = tx.QueryRow(
+		`UPDATE escrows SET status = $1, charge_ref = $2, updated_at = NOW() WHERE id = $3 RETURNING updated_at`,
+		escrow.Status, escrow.ChargeRef, escrow.ID,
+	).Scan(&escrow.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range change.Movements {
+		err := tx.QueryRow(
+			`INSERT INTO payment_ledger (id, escrow_id, job_id, type, amount, currency, user_id, provider, provider_ref)
+VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)
+RETURNING created_at`,
+			entry.ID, escrow.ID, escrow.JobID, entry.Type, entry.Amount.Amount, entry.Amount.Currency,
+			entry.UserID, entry.Provider, entry.ProviderRef,
+		).Scan(&entry.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		entry.EscrowID, entry.JobID = escrow.ID, escrow.JobID
+	}
+	if err := postJournal(tx, change.Journal); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return escrow, nil
+}
+
+func scanEscrow(row rowScanner) (*models.Escrow, error) {
+	var (
+		escrow                                      models.Escrow
+		currency                                    string
+		payment, platformFee, brokerCut, carrierNet int64
+	)
+	err := row.Scan(&escrow.ID, &escrow.JobID, &escrow.Status, &escrow.PayerID, &escrow.PayeeID, &currency,
+		&payment, &platformFee, &brokerCut, &carrierNet, &escrow.ChargeRef, &escrow.CreatedAt, &escrow.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	escrow.Payment = models.NewMoney(payment, currency)
+	escrow.PlatformFee = models.NewMoney(platformFee, currency)
+	escrow.BrokerCut = models.NewMoney(brokerCut, currency)
+	escrow.CarrierNet = models.NewMoney(carrierNet, currency)
+	escrow.Ledger = []*models.LedgerEntry{}
+	return &escrow, nil
+}
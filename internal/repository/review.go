@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"moveshare/internal/models"
+	"time"
+)
+
+var (
+	ErrReviewNotFound = errors.New("review not found")
+	ErrReviewExists   = errors.New("review already left for this job")
+	ErrReviewLocked   = errors.New("review can no longer be edited")
+)
+
+const reviewColumns = `id, job_id, COALESCE(reviewer_id, 0), reviewer_side, reviewee_id, reviewee_company_id, rating, comment, created_at, updated_at`
+
+type ReviewRepository interface {
+	CreateReview(review *models.Review) (*models.Review, error)
+	GetReviewByID(id string) (*models.Review, error)
+	GetReviewsByJob(jobID string) ([]*models.Review, error)
+	GetReviewsByUser(revieweeID, limit, offset int) ([]*models.Review, int, error)
+	UpdateReview(id string, rating int, comment string, createdAfter time.Time) (*models.Review, error)
+	GetUserRating(userID int) (*models.RatingSummary, error)
+	GetCompanyRating(companyID int) (*models.RatingSummary, error)
+}
+
+type reviewRepository struct {
+	db *sql.DB
+}
+
+func NewReviewRepository(db *sql.DB) ReviewRepository {
+	return &reviewRepository{db: db}
+}
+
+func (r *reviewRepository) CreateReview(review *models.Review) (*models.Review, error) {
+	created, err := scanReview(r.db.QueryRow(
+		`INSERT INTO reviews (id, job_id, reviewer_id, reviewer_side, reviewee_id, reviewee_company_id, rating, comment)
+VALUES ($1,$2,$3,$4,$5,$6,$7,$8)
+RETURNING `+reviewColumns,
+		review.ID, review.JobID, review.ReviewerID, review.ReviewerSide, review.RevieweeID, review.RevieweeCompanyID,
+		review.Rating, review.Comment,
+	))
+	if isUniqueViolation(err) {
+		return nil, ErrReviewExists
+	}
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+func (r *reviewRepository) GetReviewByID(id string) (*models.Review, error) {
+	review, err := scanReview(r.db.QueryRow(`SELECT `+reviewColumns+` FROM reviews WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrReviewNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return review, nil
+}
+
+func (r *reviewRepository) GetReviewsByJob(jobID string) ([]*models.Review, error) {
+	rows, err := r.db.Query(`SELECT `+reviewColumns+` FROM reviews WHERE job_id = $1 ORDER BY created_at`, jobID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return collectReviews(rows)
+}
+
+func (r *reviewRepository) GetReviewsByUser(revieweeID, limit, offset int) ([]*models.Review, int, error) {
+	rows, err := r.db.Query(
+		`SELECT `+reviewColumns+` FROM reviews WHERE reviewee_id = $1 ORDER BY created_at DESC LIMIT $2 OFFSET $3`,
+		revieweeID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+	reviews, err := collectReviews(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var total int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM reviews WHERE reviewee_id = $1`, revieweeID).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	return reviews, total, nil
+}
+
+// UpdateReview меняет оценку, только если отзыв создан позже createdAfter —
+// окно редактирования проверяется в том же UPDATE
+func (r *reviewRepository) UpdateReview(id string, rating int, comment string, createdAfter time.Time) (*models.Review, error) {
+	review, err := scanReview(r.db.QueryRow(
+		`UPDATE reviews SET rating = $1, comment = $2, updated_at = NOW()
+WHERE id = $3 AND created_at > $4
+RETURNING `+reviewColumns,
+		rating, comment, id, createdAfter,
+	))
+	if err == sql.ErrNoRows {
+		if _, err := r.GetReviewByID(id); err != nil {
+			return nil, err
+		}
+		return nil, ErrReviewLocked
+	}
+	if err != nil {
+		return nil, err
+	}
+	return review, nil
+}
+
+// GetUserRating — средняя оценка пользователя и число завершённых им работ
+// в любой роли (заказчик или перевозчик)
+func (r *reviewRepository) GetUserRating(userID int) (*models.RatingSummary, error) {
+	var s models.RatingSummary
+	err := r.db.QueryRow(
+		`SELECT
+	COALESCE((SELECT AVG(rating) FROM reviews WHERE reviewee_id = $1), 0),
+	(SELECT COUNT(*) FROM reviews WHERE reviewee_id = $1),
+	(SELECT COUNT(*) FROM jobs WHERE status = $2 AND (poster_id = $1 OR carrier_id = $1))`,
+		userID, models.JobStatusCompleted,
+	).Scan(&s.Rating, &s.ReviewCount, &s.CompletedJobs)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// GetCompanyRating — средняя оценка по отзывам на работы компании
+func (r *reviewRepository) GetCompanyRating(companyID int) (*models.RatingSummary, error) {
+	var s models.RatingSummary
+	err := r.db.QueryRow(
+		`SELECT
+	COALESCE((SELECT AVG(rating) FROM reviews WHERE reviewee_company_id = $1), 0),
+	(SELECT COUNT(*) FROM reviews WHERE reviewee_company_id = $1),
+	(SELECT COUNT(*) FROM jobs WHERE status = $2 AND company_id = $1)`,
+		companyID, models.JobStatusCompleted,
+	).Scan(&s.Rating, &s.ReviewCount, &s.CompletedJobs)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func collectReviews(rows *sql.Rows) ([]*models.Review, error) {
+	reviews := []*models.Review{}
+	for rows.Next() {
+		review, err := scanReview(rows)
+		if err != nil {
+			return nil, err
+		}
+		reviews = append(reviews, review)
+	}
+	return reviews, rows.Err()
+}
+
+func scanReview(row rowScanner) (*models.Review, error) {
+	var rv models.Review
+	err := row.Scan(&rv.ID, &rv.JobID, &rv.ReviewerID, &rv.ReviewerSide, &rv.RevieweeID, &rv.RevieweeCompanyID,
+		&rv.Rating, &rv.Comment, &rv.CreatedAt, &rv.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &rv, nil
+}
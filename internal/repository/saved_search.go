@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"moveshare/internal/models"
+)
+
+var ErrSavedSearchNotFound = errors.New("saved search not found")
+
+const savedSearchColumns = `id, user_id, name, filter, channels, paused, last_notified_at, created_at, updated_at`
+
+type SavedSearchRepository interface {
+	CreateSavedSearch(search *models.SavedSearch) (*models.SavedSearch, error)
+	GetSavedSearch(id string, userID int) (*models.SavedSearch, error)
+	GetSavedSearchesByUser(userID int) ([]*models.SavedSearch, error)
+	UpdateSavedSearch(search *models.SavedSearch) (*models.SavedSearch, error)
+	SetPaused(id string, userID int, paused bool) (*models.SavedSearch, error)
+	DeleteSavedSearch(id string, userID int) error
+	GetActiveSavedSearches() ([]*models.SavedSearch, error)
+	MarkNotified(id string) error
+}
+
+type savedSearchRepository struct {
+	db *sql.DB
+}
+
+func NewSavedSearchRepository(db *sql.DB) SavedSearchRepository {
+	return &savedSearchRepository{db: db}
+}
+
+func (r *savedSearchRepository) CreateSavedSearch(search *models.SavedSearch) (*models.SavedSearch, error) {
+	filter, channels, err := encodeSavedSearch(search)
+	if err != nil {
+		return nil, err
+	}
+	return scanSavedSearch(r.db.QueryRow(
+		`INSERT INTO saved_searches (id, user_id, name, filter, channels)
+VALUES ($1,$2,$3,$4,$5)
+RETURNING `+savedSearchColumns,
+		search.ID, search.UserID, search.Name, filter, channels,
+	))
+}
+
+func (r *savedSearchRepository) GetSavedSearch(id string, userID int) (*models.SavedSearch, error) {
+	return savedSearchOrNotFound(scanSavedSearch(r.db.QueryRow(
+		`SELECT `+savedSearchColumns+` FROM saved_searches WHERE id = $1 AND user_id = $2`, id, userID,
+	)))
+}
+
+func (r *savedSearchRepository) GetSavedSearchesByUser(userID int) ([]*models.SavedSearch, error) {
+	return r.query(`SELECT `+savedSearchColumns+` FROM saved_searches WHERE user_id = $1 ORDER BY created_at DESC`, userID)
+}
+
+// UpdateSavedSearch меняет название, фильтр и каналы; поиск другого пользователя не найдётся
+func (r *savedSearchRepository) UpdateSavedSearch(search *models.SavedSearch) (*models.SavedSearch, error) {
+	filter, channels, err := encodeSavedSearch(search)
+	if err != nil {
+		return nil, err
+	}
+	return savedSearchOrNotFound(scanSavedSearch(r.db.QueryRow(
+		`UPDATE saved_searches SET name = $1, filter = $2, channels = $3, updated_at = NOW()
+WHERE id = $4 AND user_id = $5
+RETURNING `+savedSearchColumns,
+		search.Name, filter, channels, search.ID, search.UserID,
+	)))
+}
+
+func (r *savedSearchRepository) SetPaused(id string, userID int, paused bool) (*models.SavedSearch, error) {
+	return savedSearchOrNotFound(scanSavedSearch(r.db.QueryRow(
+		`UPDATE saved_searches SET paused = $1, updated_at = NOW()
+WHERE id = $2 AND user_id = $3
+RETURNING `+savedSearchColumns,
+		paused, id, userID,
+	)))
+}
+
+func (r *savedSearchRepository) DeleteSavedSearch(id string, userID int) error {
+	res, err := r.db.Exec(`DELETE FROM saved_searches WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrSavedSearchNotFound
+	}
+	return nil
+}
+
+// GetActiveSavedSearches — все поиски, по которым сейчас нужно слать уведомления
+func (r *savedSearchRepository) GetActiveSavedSearches() ([]*models.SavedSearch, error) {
+	return r.query(`SELECT ` + savedSearchColumns + ` FROM saved_searches WHERE NOT paused`)
+}
+
+func (r *savedSearchRepository) MarkNotified(id string) error {
+	_, err := r.db.Exec(`UPDATE saved_searches SET last_notified_at = NOW() WHERE id = $1`, id)
+	return err
+}
+
+func (r *savedSearchRepository) query(query string, args ...interface{}) ([]*models.SavedSearch, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	searches := []*models.SavedSearch{}
+	for rows.Next() {
+		search, err := scanSavedSearch(rows)
+		if err != nil {
+			return nil, err
+		}
+		searches = append(searches, search)
+	}
+	return searches, rows.Err()
+}
+
+// savedSearchOrNotFound переводит sql.ErrNoRows в ErrSavedSearchNotFound
+func savedSearchOrNotFound(search *models.SavedSearch, err error) (*models.SavedSearch, error) {
+	if err == sql.ErrNoRows {
+		return nil, ErrSavedSearchNotFound
+	}
+	return search, err
+}
+
+// encodeSavedSearch сериализует фильтр и каналы в JSONB
+func encodeSavedSearch(search *models.SavedSearch) ([]byte, []byte, error) {
+	filter, err := json.Marshal(search.Filter)
+	if err != nil {
+		return nil, nil, err
+	}
+	channels, err := json.Marshal(search.Channels)
+	if err != nil {
+		return nil, nil, err
+	}
+	return filter, channels, nil
+}
+
+func scanSavedSearch(row rowScanner) (*models.SavedSearch, error) {
+	var (
+		s                models.SavedSearch
+		filter, channels []byte
+	)
+	err := row.Scan(&s.ID, &s.UserID, &s.Name, &filter, &channels, &s.Paused, &s.LastNotifiedAt, &s.CreatedAt, &s.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(filter, &s.Filter); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(channels, &s.Channels); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"moveshare/internal/models"
+	"time"
+)
+
+var (
+	ErrRefreshTokenNotFound = errors.New("refresh token not found")
+	ErrRefreshTokenRevoked  = errors.New("refresh token already revoked")
+)
+
+type TokenRepository interface {
+	CreateRefreshToken(token *models.RefreshToken) error
+	GetRefreshTokenByHash(hash string) (*models.RefreshToken, error)
+	RotateRefreshToken(oldID string, next *models.RefreshToken) error
+	RevokeFamily(familyID string) error
+	RevokeUserSessions(userID int) error
+	RevokeAccessToken(jti string, expiresAt time.Time) error
+	IsAccessTokenRevoked(jti string, userID int, issuedAt time.Time) (bool, error)
+}
+
+type tokenRepository struct {
+	db *sql.DB
+}
+
+func NewTokenRepository(db *sql.DB) TokenRepository {
+	return &tokenRepository{db: db}
+}
+
+func (r *tokenRepository) CreateRefreshToken(token *models.RefreshToken) error {
+	return r.db.QueryRow(
+		`INSERT INTO refresh_tokens (id, user_id, family_id, token_hash, expires_at)
+VALUES ($1,$2,$3,$4,$5)
+RETURNING created_at`,
+		token.ID, token.UserID, token.FamilyID, token.TokenHash, token.ExpiresAt,
+	).Scan(&token.CreatedAt)
+}
+
+func (r *tokenRepository) GetRefreshTokenByHash(hash string) (*models.RefreshToken, error) {
+	var t models.RefreshToken
+	err := r.db.QueryRow(
+		`SELECT id, user_id, family_id, token_hash, expires_at, revoked_at, replaced_by, created_at
+FROM refresh_tokens WHERE token_hash = $1`, hash,
+	).Scan(&t.ID, &t.UserID, &t.FamilyID, &t.TokenHash, &t.ExpiresAt, &t.RevokedAt, &t.ReplacedBy, &t.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrRefreshTokenNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// RotateRefreshToken отзывает токен oldID и выпускает next в том же семействе.
+// Если oldID уже отозван (например, параллельным запросом), возвращает ErrRefreshTokenRevoked.
+func (r *tokenRepository) RotateRefreshToken(oldID string, next *models.RefreshToken) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow(
+		`INSERT INTO refresh_tokens (id, user_id, family_id, token_hash, expires_at)
+VALUES ($1,$2,$3,$4,$5)
+RETURNING created_at`,
+		next.ID, next.UserID, next.FamilyID, next.TokenHash, next.ExpiresAt,
+	).Scan(&next.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	res, err := tx.Exec(
+		`UPDATE refresh_tokens SET revoked_at = NOW(), replaced_by = $1
+WHERE id = $2 AND revoked_at IS NULL`,
+		next.ID, oldID,
+	)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrRefreshTokenRevoked
+	}
+
+	return tx.Commit()
+}
+
+func (r *tokenRepository) RevokeFamily(familyID string) error {
+	_, err := r.db.Exec(
+		`UPDATE refresh_tokens SET revoked_at = NOW() WHERE family_id = $1 AND revoked_at IS NULL`,
+		familyID,
+	)
+	return err
+}
+
+func (r *tokenRepository) RevokeUserSessions(userID int) error {
+	_, err := r.db.Exec(
+		`UPDATE refresh_tokens SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL`,
+		userID,
+	)
+	return err
+}
+
+// RevokeAccessToken заносит jti в список отозванных до истечения срока токена.
+// Заодно удаляет записи, срок которых уже прошёл
+func (r *tokenRepository) RevokeAccessToken(jti string, expiresAt time.Time) error {
+	if _, err := r.db.Exec(`DELETE FROM revoked_tokens WHERE expires_at < NOW()`); err != nil {
+		return err
+	}
+	_, err := r.db.Exec(
+		`INSERT INTO revoked_tokens (jti, expires_at) VALUES ($1, $2) ON CONFLICT (jti) DO NOTHING`,
+		jti, expiresAt,
+	)
+	return err
+}
+
+// IsAccessTokenRevoked — токен отозван явно (по jti) или выпущен до смены
+// пароля или роли пользователя. iat в токене хранится с точностью до секунды,
+// поэтому и время смены сравнивается без долей секунды
+func (r *tokenRepository) IsAccessTokenRevoked(jti string, userID int, issuedAt time.Time) (bool, error) {
+	var revoked bool
+	err := r.db.QueryRow(
+		`SELECT EXISTS(SELECT 1 FROM revoked_tokens WHERE jti = $1)
+OR EXISTS(SELECT 1 FROM users WHERE id = $2 AND date_trunc('second', GREATEST(password_changed_at, role_changed_at)) > $3)`,
+		jti, userID, issuedAt.UTC(),
+	).Scan(&revoked)
+	return revoked, err
+}
@@ -2,14 +2,37 @@ package repository
 
 import (
 	"database/sql"
+	"errors"
 	"moveshare/internal/models"
 	"time"
 )
 
+var (
+	ErrUserNotFound     = errors.New("user not found")
+	ErrUserTokenInvalid = errors.New("token is invalid, used or expired")
+	ErrTOTPCodeReused   = errors.New("totp code already used")
+)
+
+const userColumns = `id, email, username, password_hash, role, email_verified,
+totp_enabled, COALESCE(totp_secret, ''), COALESCE(totp_last_step, 0), active_company_id, created_at`
+
 type UserRepository interface {
 	CreateUser(user *models.User) (*models.User, error)
 	UserExists(email, username string) (bool, error)
 	GetUserByEmail(email string) (*models.User, error)
+	GetUserByID(id int) (*models.User, error)
+	MarkEmailVerified(userID int) error
+	UpdatePassword(userID int, passwordHash string) error
+	CreateUserToken(token *models.UserToken) error
+	ConsumeUserToken(tokenHash string, purpose models.UserTokenPurpose) (*models.UserToken, error)
+	SetPendingTOTPSecret(userID int, secret string) error
+	EnableTOTP(userID int, step int64, recoveryCodeHashes []string) error
+	DisableTOTP(userID int) error
+	UseTOTPStep(userID int, step int64) error
+	ConsumeRecoveryCode(userID int, codeHash string) (bool, error)
+	ListUsers(limit, offset int) ([]*models.User, int, error)
+	ChangeUserRole(userID int, role models.UserRole, changedBy int, reason string) (*models.RoleChange, error)
+	GetRoleChanges(userID int) ([]*models.RoleChange, error)
 }
 
 type userRepository struct {
@@ -22,13 +45,13 @@ func NewUserRepository(db *sql.DB) UserRepository {
 
 func (r *userRepository) CreateUser(user *models.User) (*models.User, error) {
 	query := `
-		INSERT INTO users (email, username, password_hash, created_at)
-		VALUES ($1, $2, $3, $4)
+		INSERT INTO users (email, username, password_hash, role, email_verified, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
 		RETURNING id, created_at`
 
 	user.CreatedAt = time.Now()
 
-	err := r.db.QueryRow(query, user.Email, user.Username, user.Password, user.CreatedAt).
+	err := r.db.QueryRow(query, user.Email, user.Username, user.Password, user.Role, user.EmailVerified, user.CreatedAt).
 		Scan(&user.ID, &user.CreatedAt)
 	if err != nil {
 		return nil, err
@@ -45,10 +68,250 @@ func (r *userRepository) UserExists(email, username string) (bool, error) {
 }
 
 func (r *userRepository) GetUserByEmail(email string) (*models.User, error) {
-	query := `SELECT id, email, username, password_hash, created_at FROM users WHERE email = $1`
+	return scanUser(r.db.QueryRow(`SELECT `+userColumns+` FROM users WHERE email = $1`, email))
+}
+
+func (r *userRepository) GetUserByID(id int) (*models.User, error) {
+	user, err := scanUser(r.db.QueryRow(`SELECT `+userColumns+` FROM users WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (r *userRepository) MarkEmailVerified(userID int) error {
+	_, err := r.db.Exec(`UPDATE users SET email_verified = TRUE WHERE id = $1`, userID)
+	return err
+}
+
+// UpdatePassword меняет пароль и запоминает время смены: access-токены,
+// выпущенные раньше, перестают приниматься
+func (r *userRepository) UpdatePassword(userID int, passwordHash string) error {
+	_, err := r.db.Exec(`UPDATE users SET password_hash = $1, password_changed_at = NOW() WHERE id = $2`, passwordHash, userID)
+	return err
+}
+
+// CreateUserToken сохраняет новый токен и гасит все неиспользованные токены
+// того же назначения, чтобы действовала только последняя ссылка из писем
+func (r *userRepository) CreateUserToken(token *models.UserToken) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(
+		`UPDATE user_tokens SET used_at = NOW() WHERE user_id = $1 AND purpose = $2 AND used_at IS NULL`,
+		token.UserID, token.Purpose,
+	)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(
+		`INSERT INTO user_tokens (id, user_id, purpose, token_hash, expires_at) VALUES ($1,$2,$3,$4,$5)`,
+		token.ID, token.UserID, token.Purpose, token.TokenHash, token.ExpiresAt,
+	)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ConsumeUserToken атомарно помечает токен использованным.
+// Повторное или просроченное использование возвращает ErrUserTokenInvalid
+func (r *userRepository) ConsumeUserToken(tokenHash string, purpose models.UserTokenPurpose) (*models.UserToken, error) {
+	var t models.UserToken
+	err := r.db.QueryRow(
+		`UPDATE user_tokens SET used_at = NOW()
+WHERE token_hash = $1 AND purpose = $2 AND used_at IS NULL AND expires_at > NOW()
+RETURNING id, user_id, purpose, token_hash, expires_at, used_at`,
+		tokenHash, purpose,
+	).Scan(&t.ID, &t.UserID, &t.Purpose, &t.TokenHash, &t.ExpiresAt, &t.UsedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrUserTokenInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// SetPendingTOTPSecret сохраняет секрет, который вступит в силу после EnableTOTP
+func (r *userRepository) SetPendingTOTPSecret(userID int, secret string) error {
+	_, err := r.db.Exec(
+		`UPDATE users SET totp_secret = $1, totp_last_step = NULL WHERE id = $2 AND NOT totp_enabled`,
+		secret, userID,
+	)
+	return err
+}
+
+// EnableTOTP включает 2FA и заменяет резервные коды пользователя
+func (r *userRepository) EnableTOTP(userID int, step int64, recoveryCodeHashes []string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(
+		`UPDATE users SET totp_enabled = TRUE, totp_last_step = $1 WHERE id = $2`, step, userID,
+	); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+	for _, hash := range recoveryCodeHashes {
+		if _, err := tx.Exec(
+			`INSERT INTO recovery_codes (user_id, code_hash) VALUES ($1, $2)`, userID, hash,
+		); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *userRepository) DisableTOTP(userID int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(
+		`UPDATE users SET totp_enabled = FALSE, totp_secret = NULL, totp_last_step = NULL WHERE id = $1`, userID,
+	); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// UseTOTPStep запоминает интервал последнего принятого кода. Код из того же
+// или более раннего интервала повторно не принимается (ErrTOTPCodeReused)
+func (r *userRepository) UseTOTPStep(userID int, step int64) error {
+	res, err := r.db.Exec(
+		`UPDATE users SET totp_last_step = $1
+WHERE id = $2 AND (totp_last_step IS NULL OR totp_last_step < $1)`,
+		step, userID,
+	)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrTOTPCodeReused
+	}
+	return nil
+}
+
+func (r *userRepository) ConsumeRecoveryCode(userID int, codeHash string) (bool, error) {
+	res, err := r.db.Exec(
+		`UPDATE recovery_codes SET used_at = NOW() WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL`,
+		userID, codeHash,
+	)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+func (r *userRepository) ListUsers(limit, offset int) ([]*models.User, int, error) {
+	rows, err := r.db.Query(`SELECT `+userColumns+` FROM users ORDER BY id LIMIT $1 OFFSET $2`, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var users []*models.User
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		users = append(users, user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	var total int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM users`).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	return users, total, nil
+}
+
+// ChangeUserRole меняет роль и в той же транзакции пишет запись в журнал role_changes
+func (r *userRepository) ChangeUserRole(userID int, role models.UserRole, changedBy int, reason string) (*models.RoleChange, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	change := models.RoleChange{UserID: userID, NewRole: role, ChangedBy: changedBy, Reason: reason}
+	err = tx.QueryRow(`SELECT role FROM users WHERE id = $1 FOR UPDATE`, userID).Scan(&change.OldRole)
+	if err == sql.ErrNoRows {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec(`UPDATE users SET role = $1, role_changed_at = NOW() WHERE id = $2`, role, userID); err != nil {
+		return nil, err
+	}
+	err = tx.QueryRow(
+		`INSERT INTO role_changes (user_id, old_role, new_role, changed_by, reason)
+VALUES ($1,$2,$3,$4,$5)
+RETURNING id, created_at`,
+		change.UserID, change.OldRole, change.NewRole, change.ChangedBy, change.Reason,
+	).Scan(&change.ID, &change.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &change, nil
+}
+
+func (r *userRepository) GetRoleChanges(userID int) ([]*models.RoleChange, error) {
+	rows, err := r.db.Query(
+		`SELECT id, user_id, old_role, new_role, COALESCE(changed_by, 0), reason, created_at
+FROM role_changes WHERE user_id = $1 ORDER BY created_at DESC`, userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	changes := []*models.RoleChange{}
+	for rows.Next() {
+		var c models.RoleChange
+		if err := rows.Scan(&c.ID, &c.UserID, &c.OldRole, &c.NewRole, &c.ChangedBy, &c.Reason, &c.CreatedAt); err != nil {
+			return nil, err
+		}
+		changes = append(changes, &c)
+	}
+	return changes, rows.Err()
+}
+
+func scanUser(row rowScanner) (*models.User, error) {
 	var user models.User
-	err := r.db.QueryRow(query, email).
-		Scan(&user.ID, &user.Email, &user.Username, &user.Password, &user.CreatedAt)
+	err := row.Scan(&user.ID, &user.Email, &user.Username, &user.Password, &user.Role, &user.EmailVerified,
+		&user.TOTPEnabled, &user.TOTPSecret, &user.TOTPLastStep, &user.ActiveCompanyID, &user.CreatedAt)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,301 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"moveshare/internal/models"
+	"time"
+)
+
+var (
+	ErrWebhookNotFound  = errors.New("webhook not found")
+	ErrDeliveryNotFound = errors.New("webhook delivery not found")
+)
+
+const webhookColumns = `id, user_id, company_id, url, event_types, active, created_at, updated_at`
+
+const deliveryColumns = `d.id, d.webhook_id, d.event_id, d.event_type, d.payload, d.status, d.attempts, d.next_attempt_at,
+d.last_attempt_at, d.last_status_code, d.last_error, d.delivered_at, d.created_at`
+
+// DueDelivery — доставка, которую пора отправить, вместе с адресом и секретом подписки
+type DueDelivery struct {
+	Delivery *models.WebhookDelivery
+	URL      string
+	Secret   string
+}
+
+type WebhookRepository interface {
+	CreateWebhook(webhook *models.Webhook) (*models.Webhook, error)
+	GetWebhook(id string, userID int) (*models.Webhook, error)
+	GetWebhooks(userID int) ([]*models.Webhook, error)
+	UpdateWebhook(webhook *models.Webhook, userID int) (*models.Webhook, error)
+	DeleteWebhook(id string, userID int) error
+
+	EnqueueEvent(event *models.Event, payload []byte) (int, error)
+	ClaimDueDeliveries(limit int, lease time.Duration) ([]*DueDelivery, error)
+	RecordSuccess(id string, statusCode int) error
+	RecordFailure(id string, statusCode *int, errMsg string, nextAttemptAt *time.Time) error
+	GetDeliveries(webhookID string, limit, offset int) ([]*models.WebhookDelivery, int, error)
+	Redeliver(webhookID, deliveryID string) (*models.WebhookDelivery, error)
+}
+
+type webhookRepository struct {
+	db *sql.DB
+}
+
+func NewWebhookRepository(db *sql.DB) WebhookRepository {
+	return &webhookRepository{db: db}
+}
+
+// webhookManagedBy — условие "подпиской управляет пользователь $userArg": личная подписка
+// создана им, подписка компании — компании, где он владелец или диспетчер
+func webhookManagedBy(userArg int) string {
+	return fmt.Sprintf(
+		"((company_id IS NULL AND user_id = $%[1]d) OR company_id IN (SELECT company_id FROM company_members WHERE user_id = $%[1]d AND role IN ('%[2]s', '%[3]s')))",
+		userArg, models.CompanyOwner, models.CompanyDispatcher,
+	)
+}
+
+func (r *webhookRepository) CreateWebhook(webhook *models.Webhook) (*models.Webhook, error) {
+	eventTypes, err := json.Marshal(webhook.EventTypes)
+	if err != nil {
+		return nil, err
+	}
+	created, err := scanWebhook(r.db.QueryRow(
+		`INSERT INTO webhooks (id, user_id, company_id, url, secret, event_types)
+VALUES ($1,$2,$3,$4,$5,$6)
+RETURNING `+webhookColumns,
+		webhook.ID, webhook.UserID, webhook.CompanyID, webhook.URL, webhook.Secret, eventTypes,
+	))
+	if err != nil {
+		return nil, err
+	}
+	created.Secret = webhook.Secret
+	return created, nil
+}
+
+func (r *webhookRepository) GetWebhook(id string, userID int) (*models.Webhook, error) {
+	webhook, err := scanWebhook(r.db.QueryRow(
+		`SELECT `+webhookColumns+` FROM webhooks WHERE id = $1 AND `+webhookManagedBy(2), id, userID,
+	))
+	if err == sql.ErrNoRows {
+		return nil, ErrWebhookNotFound
+	}
+	return webhook, err
+}
+
+// GetWebhooks — личные подписки пользователя и подписки компаний, которыми он управляет
+func (r *webhookRepository) GetWebhooks(userID int) ([]*models.Webhook, error) {
+	rows, err := r.db.Query(
+		`SELECT `+webhookColumns+` FROM webhooks WHERE `+webhookManagedBy(1)+` ORDER BY created_at DESC`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	webhooks := []*models.Webhook{}
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, webhook)
+	}
+	return webhooks, rows.Err()
+}
+
+func (r *webhookRepository) UpdateWebhook(webhook *models.Webhook, userID int) (*models.Webhook, error) {
+	eventTypes, err := json.Marshal(webhook.EventTypes)
+	if err != nil {
+		return nil, err
+	}
+	updated, err := scanWebhook(r.db.QueryRow(
+		`UPDATE webhooks SET url = $1, event_types = $2, active = $3, updated_at = NOW()
+WHERE id = $4 AND `+webhookManagedBy(5)+`
+RETURNING `+webhookColumns,
+		webhook.URL, eventTypes, webhook.Active, webhook.ID, userID,
+	))
+	if err == sql.ErrNoRows {
+		return nil, ErrWebhookNotFound
+	}
+	return updated, err
+}
+
+func (r *webhookRepository) DeleteWebhook(id string, userID int) error {
+	res, err := r.db.Exec(`DELETE FROM webhooks WHERE id = $1 AND `+webhookManagedBy(2), id, userID)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrWebhookNotFound
+	}
+	return nil
+}
+
+// EnqueueEvent ставит событие в очередь доставки всем активным подпискам его аудитории,
+// которые выбрали этот тип события. Повторная постановка того же события в подписку
+// пропускается. Возвращает число созданных доставок
+func (r *webhookRepository) EnqueueEvent(event *models.Event, payload []byte) (int, error) {
+	audience := event.Audience
+	userIDs := make([]int64, 0, len(audience.UserIDs))
+	for _, id := range audience.UserIDs {
+		userIDs = append(userIDs, int64(id))
+	}
+	res, err := r.db.Exec(
+		`INSERT INTO webhook_deliveries (id, webhook_id, event_id, event_type, payload)
+SELECT gen_random_uuid(), w.id, $1, $2::text, $3
+FROM webhooks w
+WHERE w.active
+	AND w.event_types @> jsonb_build_array($2::text)
+	AND ((w.company_id IS NULL AND w.user_id = ANY($4)) OR w.company_id = $5)
+ON CONFLICT (webhook_id, event_id) WHERE redelivered_from IS NULL DO NOTHING`,
+		event.ID, event.Type, payload, userIDs, audience.CompanyID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+// ClaimDueDeliveries забирает до limit доставок, которые пора отправить, и сдвигает
+// их следующую попытку на lease вперёд: если экземпляр упадёт посреди отправки,
+// доставку позже подхватит другой. SKIP LOCKED не даёт двум экземплярам взять одну доставку
+func (r *webhookRepository) ClaimDueDeliveries(limit int, lease time.Duration) ([]*DueDelivery, error) {
+	rows, err := r.db.Query(
+		`UPDATE webhook_deliveries d SET next_attempt_at = NOW() + $2 * INTERVAL '1 second'
+FROM webhooks w
+WHERE w.id = d.webhook_id AND d.id IN (
+	SELECT dd.id FROM webhook_deliveries dd
+	JOIN webhooks ww ON ww.id = dd.webhook_id
+	WHERE dd.status = $3 AND dd.next_attempt_at <= NOW() AND ww.active
+	ORDER BY dd.next_attempt_at
+	LIMIT $1
+	FOR UPDATE OF dd SKIP LOCKED
+)
+RETURNING `+deliveryColumns+`, w.url, w.secret`,
+		limit, lease.Seconds(), models.WebhookDeliveryPending,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	due := []*DueDelivery{}
+	for rows.Next() {
+		var (
+			d           models.WebhookDelivery
+			url, secret string
+		)
+		if err := rows.Scan(deliveryFields(&d, &url, &secret)...); err != nil {
+			return nil, err
+		}
+		due = append(due, &DueDelivery{Delivery: &d, URL: url, Secret: secret})
+	}
+	return due, rows.Err()
+}
+
+func (r *webhookRepository) RecordSuccess(id string, statusCode int) error {
+	_, err := r.db.Exec(
+		`UPDATE webhook_deliveries
+SET status = $1, attempts = attempts + 1, last_attempt_at = NOW(), last_status_code = $2, last_error = NULL, delivered_at = NOW()
+WHERE id = $3`,
+		models.WebhookDeliverySucceeded, statusCode, id,
+	)
+	return err
+}
+
+// RecordFailure записывает неудачную попытку. nextAttemptAt == nil — попытки
+// исчерпаны, доставка помечается failed
+func (r *webhookRepository) RecordFailure(id string, statusCode *int, errMsg string, nextAttemptAt *time.Time) error {
+	status := models.WebhookDeliveryPending
+	if nextAttemptAt == nil {
+		status = models.WebhookDeliveryFailed
+	}
+	_, err := r.db.Exec(
+		`UPDATE webhook_deliveries
+SET status = $1, attempts = attempts + 1, last_attempt_at = NOW(), last_status_code = $2, last_error = $3,
+	next_attempt_at = COALESCE($4, next_attempt_at)
+WHERE id = $5`,
+		status, statusCode, errMsg, nextAttemptAt, id,
+	)
+	return err
+}
+
+// GetDeliveries — журнал доставок подписки, новые первыми
+func (r *webhookRepository) GetDeliveries(webhookID string, limit, offset int) ([]*models.WebhookDelivery, int, error) {
+	rows, err := r.db.Query(
+		`SELECT `+deliveryColumns+` FROM webhook_deliveries d WHERE d.webhook_id = $1
+ORDER BY d.created_at DESC, d.id LIMIT $2 OFFSET $3`,
+		webhookID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	deliveries := []*models.WebhookDelivery{}
+	for rows.Next() {
+		var d models.WebhookDelivery
+		if err := rows.Scan(deliveryFields(&d)...); err != nil {
+			return nil, 0, err
+		}
+		deliveries = append(deliveries, &d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	var total int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM webhook_deliveries WHERE webhook_id = $1`, webhookID).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	return deliveries, total, nil
+}
+
+// Redeliver ставит в очередь копию доставки: исходная запись журнала не меняется
+func (r *webhookRepository) Redeliver(webhookID, deliveryID string) (*models.WebhookDelivery, error) {
+	var d models.WebhookDelivery
+	err := r.db.QueryRow(
+		`INSERT INTO webhook_deliveries AS d (id, webhook_id, event_id, event_type, payload, redelivered_from)
+SELECT gen_random_uuid(), webhook_id, event_id, event_type, payload, id
+FROM webhook_deliveries WHERE id = $1 AND webhook_id = $2
+RETURNING `+deliveryColumns,
+		deliveryID, webhookID,
+	).Scan(deliveryFields(&d)...)
+	if err == sql.ErrNoRows {
+		return nil, ErrDeliveryNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// deliveryFields — адреса полей для Scan в порядке deliveryColumns, плюс extra
+func deliveryFields(d *models.WebhookDelivery, extra ...interface{}) []interface{} {
+	return append([]interface{}{
+		&d.ID, &d.WebhookID, &d.EventID, &d.EventType, (*[]byte)(&d.Payload), &d.Status, &d.Attempts, &d.NextAttemptAt,
+		&d.LastAttemptAt, &d.LastStatusCode, &d.LastError, &d.DeliveredAt, &d.CreatedAt,
+	}, extra...)
+}
+
+func scanWebhook(row rowScanner) (*models.Webhook, error) {
+	var (
+		w          models.Webhook
+		eventTypes []byte
+	)
+	err := row.Scan(&w.ID, &w.UserID, &w.CompanyID, &w.URL, &eventTypes, &w.Active, &w.CreatedAt, &w.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(eventTypes, &w.EventTypes); err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
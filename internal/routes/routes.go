@@ -2,37 +2,274 @@ package routes
 
 import (
 	"database/sql"
+	"moveshare/internal/blobstore"
+	"moveshare/internal/events"
+	"moveshare/internal/feed"
+	"moveshare/internal/geo"
 	"moveshare/internal/handlers"
+	"moveshare/internal/mailer"
 	"moveshare/internal/middleware"
+	"moveshare/internal/models"
+	"moveshare/internal/notify"
+	"moveshare/internal/payments"
 	"moveshare/internal/repository"
 	"moveshare/internal/services"
+	"net/http"
+	"time"
 
 	"github.com/gorilla/mux"
 )
 
-func NewRouter(db *sql.DB, jwtService services.JWTService) *mux.Router {
+// Dependencies — внешние зависимости, из которых собирается приложение
+type Dependencies struct {
+	DB         *sql.DB
+	JWTService services.JWTService
+	Geocoder   geo.Geocoder
+	Mailer     mailer.Mailer
+	// Адрес фронтенда, на который ведут ссылки из писем
+	AppBaseURL     string
+	UploadsDir     string
+	MaxUploadBytes int64
+	// Broker раздаёт события ленты работ (GET /jobs/stream)
+	Broker feed.Broker
+	// Webhooks — очередь вебхуков; её же обрабатывает фоновая доставка в main
+	Webhooks services.WebhookService
+	// Events раздаёт доменные события из outbox; здесь на него подписываются
+	// лента, сохранённые поиски, вебхуки, платежи и документы, а рассылку запускает main
+	Events events.Bus
+	// Payments проводит деньги эскроу
+	Payments payments.Provider
+	// Blobs хранит файлы работ; ссылки на скачивание подписываются AttachmentURLSecret
+	Blobs                blobstore.BlobStore
+	AttachmentURLSecret  []byte
+	AttachmentURLTTL     time.Duration
+	MaxAttachmentsPerJob int
+	ThumbnailSize        int
+}
+
+func NewRouter(deps Dependencies) *mux.Router {
+	db, jwtService, m, appBaseURL := deps.DB, deps.JWTService, deps.Mailer, deps.AppBaseURL
+
 	userRepo := repository.NewUserRepository(db)
-	authSvc := services.NewAuthService(userRepo)
+	tokenRepo := repository.NewTokenRepository(db)
+	authSvc := services.NewAuthService(userRepo, tokenRepo, m, appBaseURL)
+	tokenSvc := services.NewTokenService(jwtService, tokenRepo, userRepo)
+	twoFactorSvc := services.NewTwoFactorService(userRepo, tokenRepo, jwtService, tokenSvc)
 	authHandler := &handlers.AuthHandler{
-		AuthService: authSvc,
-		JWTService:  jwtService,
+		AuthService:      authSvc,
+		TokenService:     tokenSvc,
+		TwoFactorService: twoFactorSvc,
 	}
+	twoFactorHandler := handlers.NewTwoFactorHandler(twoFactorSvc)
+
+	companyRepo := repository.NewCompanyRepository(db)
+	companyService := services.NewCompanyService(companyRepo, userRepo, tokenSvc, m, appBaseURL)
+	companyHandler := handlers.NewCompanyHandler(companyService)
+
+	carrierRepo := repository.NewCarrierRepository(db)
+	carrierService := services.NewCarrierService(carrierRepo, deps.UploadsDir, deps.MaxUploadBytes)
+	carrierHandler := handlers.NewCarrierHandler(carrierService, deps.MaxUploadBytes)
+
+	reviewRepo := repository.NewReviewRepository(db)
+	webhookHandler := handlers.NewWebhookHandler(deps.Webhooks)
+
+	notificationRepo := repository.NewNotificationRepository(db)
+	notificationService := services.NewNotificationService(notificationRepo)
+	notificationHandler := handlers.NewNotificationHandler(notificationService)
+	dispatcher := notify.NewDispatcher(map[models.NotificationChannel]notify.Notifier{
+		models.NotificationChannelEmail:   notify.NewEmailNotifier(m),
+		models.NotificationChannelInApp:   notify.NewInAppNotifier(notificationRepo),
+		models.NotificationChannelWebhook: notify.NewWebhookNotifier(deps.Webhooks),
+	})
+
+	savedSearchRepo := repository.NewSavedSearchRepository(db)
+	savedSearchService := services.NewSavedSearchService(savedSearchRepo, userRepo, reviewRepo, deps.Geocoder, dispatcher, appBaseURL)
+	savedSearchHandler := handlers.NewSavedSearchHandler(savedSearchService)
 
 	jobRepo := repository.NewJobRepository(db)
-	jobService := services.NewJobService(jobRepo)
+	feeRepo := repository.NewFeeRepository(db)
+	feeService := services.NewFeeService(feeRepo, jobRepo)
+	feeHandler := handlers.NewFeeHandler(feeService)
+	paymentRepo := repository.NewPaymentRepository(db)
+	ledgerService := services.NewLedgerService(repository.NewLedgerRepository(db), paymentRepo)
+	ledgerHandler := handlers.NewLedgerHandler(ledgerService)
+	jobService := services.NewJobService(jobRepo, userRepo, companyRepo, carrierRepo, deps.Geocoder, feeService, ledgerService)
 	jobHandler := handlers.NewJobHandler(jobService)
+	feedHandler := handlers.NewFeedHandler(deps.Broker, jobService)
+
+	deps.Events.Subscribe("feed", feed.Subscriber(deps.Broker),
+		models.EventJobCreated, models.EventJobClaimed, models.EventJobCancelled)
+	deps.Events.Subscribe("saved_searches", events.JobHandler(savedSearchService.NotifyNewJob), models.EventJobCreated)
+	deps.Events.Subscribe("webhooks", deps.Webhooks.Enqueue)
+
+	documentService := services.NewDocumentService(repository.NewDocumentRepository(db), jobRepo, userRepo, companyRepo, carrierRepo, feeRepo, paymentRepo, deps.UploadsDir)
+	documentHandler := handlers.NewDocumentHandler(documentService)
+	deps.Events.Subscribe("invoices", events.JobHandler(documentService.IssueInvoice), models.EventJobCompleted)
+
+	bolService := services.NewBOLService(repository.NewBOLRepository(db), jobRepo, userRepo, companyRepo, carrierRepo, deps.UploadsDir)
+	bolHandler := handlers.NewBOLHandler(bolService)
+	deps.Events.Subscribe("bills_of_lading", events.JobHandler(bolService.IssueBOL), models.EventJobStarted)
+
+	attachmentService := services.NewAttachmentService(repository.NewAttachmentRepository(db), jobRepo, deps.Blobs,
+		deps.AttachmentURLSecret, deps.AttachmentURLTTL, deps.MaxUploadBytes, deps.MaxAttachmentsPerJob, deps.ThumbnailSize)
+	attachmentHandler := handlers.NewAttachmentHandler(attachmentService, deps.MaxUploadBytes)
+	deps.Events.Subscribe("attachments", events.JobHandler(attachmentService.DeleteJobAttachments), models.EventJobDeleted)
+
+	paymentService := services.NewPaymentService(paymentRepo, jobRepo, deps.Payments)
+	paymentHandler := handlers.NewPaymentHandler(paymentService)
+	deps.Events.Subscribe("escrow_fund", events.JobHandler(paymentService.FundEscrow), models.EventJobClaimed)
+	deps.Events.Subscribe("escrow_release", events.JobHandler(paymentService.ReleaseEscrow), models.EventJobCompleted)
+	deps.Events.Subscribe("escrow_refund", events.JobHandler(paymentService.RefundEscrow),
+		models.EventJobCancelled, models.EventJobDeleted)
+
+	bidRepo := repository.NewBidRepository(db)
+	bidService := services.NewBidService(bidRepo, jobRepo, carrierRepo, feeService)
+	bidHandler := handlers.NewBidHandler(bidService)
+
+	messageRepo := repository.NewMessageRepository(db)
+	messageService := services.NewMessageService(messageRepo, jobRepo)
+	messageHandler := handlers.NewMessageHandler(messageService)
+
+	reviewService := services.NewReviewService(reviewRepo, jobRepo, userRepo, companyRepo)
+	reviewHandler := handlers.NewReviewHandler(reviewService)
+
+	adminService := services.NewAdminService(userRepo, tokenRepo, jobRepo)
+	adminHandler := handlers.NewAdminHandler(adminService)
 
 	r := mux.NewRouter()
 	r.Use(middleware.LoggingMiddleware)
 
 	r.HandleFunc("/sign-up", authHandler.SignUp).Methods("POST")
 	r.HandleFunc("/login", authHandler.Login).Methods("POST")
+	r.HandleFunc("/login/2fa", twoFactorHandler.Login).Methods("POST")
+	r.HandleFunc("/token/refresh", authHandler.Refresh).Methods("POST")
+	r.HandleFunc("/verify-email", authHandler.VerifyEmail).Methods("POST")
+	r.HandleFunc("/verify-email/resend", authHandler.ResendVerification).Methods("POST")
+	r.HandleFunc("/password/forgot", authHandler.ForgotPassword).Methods("POST")
+	r.HandleFunc("/password/reset", authHandler.ResetPassword).Methods("POST")
+	r.HandleFunc("/.well-known/jwks.json", handlers.NewJWKSHandler(jwtService).GetJWKS).Methods("GET")
+	// Без токена: доступ даёт подпись ссылки
+	r.HandleFunc("/attachments/{id}/content", attachmentHandler.DownloadAttachment).Methods("GET")
+
+	authMiddleware := middleware.AuthMiddleware(jwtService, tokenSvc)
+	r.Handle("/logout", authMiddleware(http.HandlerFunc(authHandler.Logout))).Methods("POST")
+
+	twoFactor := r.PathPrefix("/2fa").Subrouter()
+	twoFactor.Use(authMiddleware)
+	twoFactor.HandleFunc("/enroll", twoFactorHandler.Enroll).Methods("POST")
+	twoFactor.HandleFunc("/confirm", twoFactorHandler.Confirm).Methods("POST")
+	twoFactor.HandleFunc("/disable", twoFactorHandler.Disable).Methods("POST")
+
+	companies := r.PathPrefix("/companies").Subrouter()
+	companies.Use(authMiddleware)
+	companies.HandleFunc("", companyHandler.CreateCompany).Methods("POST")
+	companies.HandleFunc("", companyHandler.GetMyCompanies).Methods("GET")
+	companies.HandleFunc("/switch", companyHandler.SwitchCompany).Methods("POST")
+	companies.HandleFunc("/invitations/accept", companyHandler.AcceptInvitation).Methods("POST")
+	companies.HandleFunc("/{id}/members", companyHandler.GetMembers).Methods("GET")
+	companies.HandleFunc("/{id}/members/{userID}", companyHandler.UpdateMember).Methods("PUT")
+	companies.HandleFunc("/{id}/members/{userID}", companyHandler.RemoveMember).Methods("DELETE")
+	companies.HandleFunc("/{id}/invitations", companyHandler.Invite).Methods("POST")
+	companies.HandleFunc("/{id}/invitations", companyHandler.GetInvitations).Methods("GET")
+	companies.HandleFunc("/{id}/profile", reviewHandler.GetCompanyProfile).Methods("GET")
+
+	users := r.PathPrefix("/users").Subrouter()
+	users.Use(authMiddleware)
+	users.HandleFunc("/{id}/profile", reviewHandler.GetUserProfile).Methods("GET")
+	users.HandleFunc("/{id}/reviews", reviewHandler.GetUserReviews).Methods("GET")
+
+	savedSearches := r.PathPrefix("/saved-searches").Subrouter()
+	savedSearches.Use(authMiddleware)
+	savedSearches.HandleFunc("", savedSearchHandler.CreateSavedSearch).Methods("POST")
+	savedSearches.HandleFunc("", savedSearchHandler.GetSavedSearches).Methods("GET")
+	savedSearches.HandleFunc("/{id}", savedSearchHandler.UpdateSavedSearch).Methods("PUT")
+	savedSearches.HandleFunc("/{id}", savedSearchHandler.DeleteSavedSearch).Methods("DELETE")
+	savedSearches.HandleFunc("/{id}/pause", savedSearchHandler.PauseSavedSearch).Methods("POST")
+	savedSearches.HandleFunc("/{id}/resume", savedSearchHandler.ResumeSavedSearch).Methods("POST")
+
+	notifications := r.PathPrefix("/notifications").Subrouter()
+	notifications.Use(authMiddleware)
+	notifications.HandleFunc("", notificationHandler.GetNotifications).Methods("GET")
+	notifications.HandleFunc("/read", notificationHandler.MarkNotificationsRead).Methods("POST")
+
+	ledger := r.PathPrefix("/ledger").Subrouter()
+	ledger.Use(authMiddleware)
+	ledger.HandleFunc("/balances", ledgerHandler.GetBalances).Methods("GET")
+	ledger.HandleFunc("/statement", ledgerHandler.GetStatement).Methods("GET")
+
+	webhooks := r.PathPrefix("/webhooks").Subrouter()
+	webhooks.Use(authMiddleware)
+	webhooks.HandleFunc("", webhookHandler.CreateWebhook).Methods("POST")
+	webhooks.HandleFunc("", webhookHandler.GetWebhooks).Methods("GET")
+	webhooks.HandleFunc("/{id}", webhookHandler.UpdateWebhook).Methods("PUT")
+	webhooks.HandleFunc("/{id}", webhookHandler.DeleteWebhook).Methods("DELETE")
+	webhooks.HandleFunc("/{id}/deliveries", webhookHandler.GetDeliveries).Methods("GET")
+	webhooks.HandleFunc("/{id}/deliveries/{deliveryID}/redeliver", webhookHandler.Redeliver).Methods("POST")
+
+	r.Handle("/reviews/{id}", authMiddleware(http.HandlerFunc(reviewHandler.UpdateReview))).Methods("PUT")
+
+	carrierOnly := middleware.RequireRole(models.RoleCarrier)
+
+	carrier := r.PathPrefix("/carrier").Subrouter()
+	carrier.Use(authMiddleware, carrierOnly)
+	carrier.HandleFunc("/profile", carrierHandler.GetProfile).Methods("GET")
+	carrier.HandleFunc("/profile", carrierHandler.SaveProfile).Methods("PUT")
+	carrier.HandleFunc("/insurance", carrierHandler.UploadCertificate).Methods("POST")
+	carrier.HandleFunc("/insurance", carrierHandler.GetCertificates).Methods("GET")
+
+	// Регистрируется до /jobs: EventSource передаёт токен в query, а не в заголовке
+	r.Handle("/jobs/stream", middleware.TokenFromQuery(authMiddleware(http.HandlerFunc(feedHandler.Stream)))).Methods("GET")
 
 	jobs := r.PathPrefix("/jobs").Subrouter()
-	jobs.Use(middleware.AuthMiddleware(jwtService))
+	jobs.Use(authMiddleware)
 	jobs.HandleFunc("", jobHandler.CreateJob).Methods("POST")
 	jobs.HandleFunc("", jobHandler.GetJobs).Methods("GET")
+	jobs.HandleFunc("/quote", feeHandler.Quote).Methods("POST")
+	jobs.HandleFunc("/{id}", jobHandler.UpdateJob).Methods("PUT")
 	jobs.HandleFunc("/{id}", jobHandler.DeleteJob).Methods("DELETE")
+	jobs.HandleFunc("/{id}/backhauls", jobHandler.GetBackhauls).Methods("GET")
+	jobs.HandleFunc("/{id}/fees", feeHandler.GetJobFees).Methods("GET")
+	jobs.HandleFunc("/{id}/escrow", paymentHandler.GetEscrow).Methods("GET")
+	jobs.HandleFunc("/{id}/invoice", documentHandler.GetInvoice).Methods("GET")
+	jobs.HandleFunc("/{id}/receipt", documentHandler.GetReceipt).Methods("GET")
+	jobs.HandleFunc("/{id}/bol", bolHandler.GetBOL).Methods("GET")
+	jobs.HandleFunc("/{id}/bol/pdf", bolHandler.GetBOLFile).Methods("GET")
+	jobs.HandleFunc("/{id}/bol/verify", bolHandler.VerifyBOL).Methods("GET")
+	jobs.HandleFunc("/{id}/bol/signatures/{role}", bolHandler.SignBOL).Methods("POST")
+	jobs.HandleFunc("/{id}/attachments", attachmentHandler.UploadAttachment).Methods("POST")
+	jobs.HandleFunc("/{id}/attachments", attachmentHandler.GetAttachments).Methods("GET")
+	jobs.HandleFunc("/{id}/attachments/{attachmentID}", attachmentHandler.DeleteAttachment).Methods("DELETE")
+	jobs.Handle("/{id}/claim", carrierOnly(http.HandlerFunc(jobHandler.ClaimJob))).Methods("POST")
+	jobs.Handle("/{id}/start", carrierOnly(http.HandlerFunc(jobHandler.StartJob))).Methods("POST")
+	jobs.Handle("/{id}/deliver", carrierOnly(http.HandlerFunc(jobHandler.DeliverJob))).Methods("POST")
+	jobs.HandleFunc("/{id}/complete", jobHandler.CompleteJob).Methods("POST")
+	jobs.HandleFunc("/{id}/cancel", jobHandler.CancelJob).Methods("POST")
+	jobs.Handle("/{id}/bids", carrierOnly(http.HandlerFunc(bidHandler.CreateBid))).Methods("POST")
+	jobs.HandleFunc("/{id}/bids", bidHandler.GetBids).Methods("GET")
+	jobs.HandleFunc("/{id}/bids/{bidID}/accept", bidHandler.AcceptBid).Methods("POST")
+	jobs.HandleFunc("/{id}/bids/{bidID}/reject", bidHandler.RejectBid).Methods("POST")
+	jobs.HandleFunc("/{id}/bids/{bidID}/counter", bidHandler.CounterBid).Methods("POST")
+	jobs.HandleFunc("/{id}/threads", messageHandler.GetThreads).Methods("GET")
+	jobs.HandleFunc("/{id}/threads/{carrierID}/messages", messageHandler.GetMessages).Methods("GET")
+	jobs.HandleFunc("/{id}/threads/{carrierID}/messages", messageHandler.SendMessage).Methods("POST")
+	jobs.HandleFunc("/{id}/threads/{carrierID}/read", messageHandler.MarkRead).Methods("POST")
+	jobs.HandleFunc("/{id}/reviews", reviewHandler.CreateReview).Methods("POST")
+	jobs.HandleFunc("/{id}/reviews", reviewHandler.GetJobReviews).Methods("GET")
+
+	admin := r.PathPrefix("/admin").Subrouter()
+	admin.Use(authMiddleware, middleware.RequireRole(models.RoleAdmin))
+	admin.HandleFunc("/users", adminHandler.ListUsers).Methods("GET")
+	admin.HandleFunc("/users/{id}/role", adminHandler.ChangeRole).Methods("PUT")
+	admin.HandleFunc("/users/{id}/role-changes", adminHandler.GetRoleChanges).Methods("GET")
+	admin.HandleFunc("/jobs/{id}", adminHandler.DeleteJob).Methods("DELETE")
+	admin.HandleFunc("/jobs/{id}/documents/regenerate", documentHandler.RegenerateDocuments).Methods("POST")
+	admin.HandleFunc("/fee-rules", feeHandler.GetRuleSets).Methods("GET")
+	admin.HandleFunc("/fee-rules", feeHandler.CreateRuleSet).Methods("POST")
+	admin.HandleFunc("/ledger/accounts", ledgerHandler.GetAccounts).Methods("GET")
+	admin.HandleFunc("/carrier-reviews", carrierHandler.ReviewQueue).Methods("GET")
+	admin.HandleFunc("/carriers/{id}/review", carrierHandler.ReviewProfile).Methods("POST")
+	admin.HandleFunc("/insurance/{id}/review", carrierHandler.ReviewCertificate).Methods("POST")
+	admin.HandleFunc("/insurance/{id}/file", carrierHandler.DownloadCertificate).Methods("GET")
 
 	return r
 }
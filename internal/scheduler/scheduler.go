@@ -0,0 +1,29 @@
+package scheduler
+
+import (
+	"log/slog"
+	"time"
+)
+
+// Every запускает task в фоне сразу и затем каждые interval.
+// Ошибки задачи только логируются. Возвращает функцию остановки.
+func Every(name string, interval time.Duration, task func() error) (stop func()) {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if err := task(); err != nil {
+				slog.Error("Scheduled task failed",
+					slog.String("task", name),
+					slog.String("error", err.Error()))
+			}
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return func() { close(done) }
+}
@@ -0,0 +1,70 @@
+package services
+
+import (
+	"errors"
+	"moveshare/internal/models"
+	"moveshare/internal/repository"
+)
+
+var (
+	ErrUserNotFound        = errors.New("user not found")
+	ErrInvalidRole         = errors.New("invalid role")
+	ErrCannotChangeOwnRole = errors.New("admins cannot change their own role")
+)
+
+// AdminService — операции модерации, доступные только администраторам
+type AdminService interface {
+	ListUsers(limit, offset int) ([]*models.User, int, error)
+	ChangeRole(adminID, userID int, req models.ChangeRoleRequest) (*models.RoleChange, error)
+	GetRoleChanges(userID int) ([]*models.RoleChange, error)
+	DeleteJob(jobID string) error
+}
+
+type adminService struct {
+	userRepo  repository.UserRepository
+	tokenRepo repository.TokenRepository
+	jobRepo   repository.JobRepository
+}
+
+func NewAdminService(userRepo repository.UserRepository, tokenRepo repository.TokenRepository, jobRepo repository.JobRepository) AdminService {
+	return &adminService{userRepo: userRepo, tokenRepo: tokenRepo, jobRepo: jobRepo}
+}
+
+func (s *adminService) ListUsers(limit, offset int) ([]*models.User, int, error) {
+	return s.userRepo.ListUsers(limit, offset)
+}
+
+// ChangeRole меняет роль пользователя и записывает изменение в журнал. Роль
+// зашита в access-токен, поэтому сессии пользователя завершаются, а выданные
+// раньше access-токены перестают приниматься: со старой ролью он работать не сможет
+func (s *adminService) ChangeRole(adminID, userID int, req models.ChangeRoleRequest) (*models.RoleChange, error) {
+	if !req.Role.Valid() {
+		return nil, ErrInvalidRole
+	}
+	// Иначе последний администратор может случайно лишить площадку модерации
+	if adminID == userID {
+		return nil, ErrCannotChangeOwnRole
+	}
+	change, err := s.userRepo.ChangeUserRole(userID, req.Role, adminID, req.Reason)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	if err := s.tokenRepo.RevokeUserSessions(userID); err != nil {
+		return nil, err
+	}
+	return change, nil
+}
+
+func (s *adminService) GetRoleChanges(userID int) ([]*models.RoleChange, error) {
+	return s.userRepo.GetRoleChanges(userID)
+}
+
+func (s *adminService) DeleteJob(jobID string) error {
+	if err := s.jobRepo.ForceDeleteJob(jobID, jobEvents(models.EventJobDeleted)); err != nil {
+		return mapJobError(err)
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package services
+
+import (
+	"moveshare/internal/models"
+	"moveshare/internal/repository"
+	"testing"
+	"time"
+)
+
+func (r *fakeUserRepo) ChangeUserRole(userID int, role models.UserRole, changedBy int, reason string) (*models.RoleChange, error) {
+	user, ok := r.users[userID]
+	if !ok {
+		return nil, repository.ErrUserNotFound
+	}
+	change := &models.RoleChange{UserID: userID, OldRole: user.Role, NewRole: role, ChangedBy: changedBy, Reason: reason}
+	user.Role = role
+	r.roleChangedAt[userID] = time.Now()
+	return change, nil
+}
+
+func newTestAdminService() (*adminService, *fakeUserRepo, *fakeTokenRepo) {
+	users := newFakeUserRepo(
+		&models.User{ID: 1, Role: models.RoleAdmin},
+		&models.User{ID: 2, Role: models.RoleAdmin},
+	)
+	tokens := newFakeTokenRepo()
+	tokens.users = users
+	return NewAdminService(users, tokens, nil).(*adminService), users, tokens
+}
+
+func TestChangeRole(t *testing.T) {
+	tests := []struct {
+		name    string
+		adminID int
+		userID  int
+		role    models.UserRole
+		wantErr error
+	}{
+		{"demote another admin", 1, 2, models.RoleShipper, nil},
+		{"unknown role", 1, 2, "owner", ErrInvalidRole},
+		{"own role", 1, 1, models.RoleShipper, ErrCannotChangeOwnRole},
+		{"unknown user", 1, 99, models.RoleCarrier, ErrUserNotFound},
+	}
+	for _, tt := range tests {
+		svc, users, _ := newTestAdminService()
+		change, err := svc.ChangeRole(tt.adminID, tt.userID, models.ChangeRoleRequest{Role: tt.role, Reason: "test"})
+		if err != tt.wantErr {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if err == nil && (change.OldRole != models.RoleAdmin || users.users[tt.userID].Role != tt.role) {
+			t.Errorf("%s: change = %+v", tt.name, change)
+		}
+	}
+}
+
+// Разжалованный администратор теряет права сразу, а не когда истечёт его токен
+func TestChangeRoleEndsSessions(t *testing.T) {
+	svc, users, tokens := newTestAdminService()
+	jwt := newTestJWTService(t)
+	tokenService := NewTokenService(jwt, tokens, users)
+	before := time.Now().Add(-time.Minute)
+	session, err := tokenService.IssueTokens(users.users[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := svc.ChangeRole(1, 2, models.ChangeRoleRequest{Role: models.RoleShipper}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tokenService.Refresh(session.RefreshToken); err != ErrInvalidRefreshToken {
+		t.Errorf("refresh after demotion: err = %v, want ErrInvalidRefreshToken", err)
+	}
+	if revoked, _ := tokenService.IsRevoked(&AccessClaims{UserID: 2, Role: models.RoleAdmin, JTI: "old", IssuedAt: before}); !revoked {
+		t.Error("access token with the old role is still accepted")
+	}
+	if revoked, _ := tokenService.IsRevoked(&AccessClaims{UserID: 1, Role: models.RoleAdmin, JTI: "admin", IssuedAt: before}); revoked {
+		t.Error("access token of the acting admin revoked")
+	}
+
+	// После нового входа в токене уже новая роль
+	fresh, err := tokenService.IssueTokens(users.users[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := jwt.ValidateToken(fresh.AccessToken)
+	if err != nil || claims.Role != models.RoleShipper {
+		t.Fatalf("claims after re-login = %+v, %v", claims, err)
+	}
+	if revoked, _ := tokenService.IsRevoked(claims); revoked {
+		t.Error("token issued after the role change is rejected")
+	}
+}
+
+// fakeForceDeleteRepo повторяет условие ForceDeleteJob: удаляются только
+// открытые и отменённые работы
+type fakeForceDeleteRepo struct {
+	repository.JobRepository
+	jobs map[string]models.JobStatus
+}
+
+func (r *fakeForceDeleteRepo) ForceDeleteJob(id string, events repository.JobEvents) error {
+	status, ok := r.jobs[id]
+	if !ok {
+		return repository.ErrJobNotFound
+	}
+	if status != models.JobStatusOpen && status != models.JobStatusCancelled {
+		return repository.ErrJobInProgress
+	}
+	delete(r.jobs, id)
+	return nil
+}
+
+func TestDeleteJob(t *testing.T) {
+	tests := []struct {
+		status models.JobStatus
+		want   error
+	}{
+		{models.JobStatusOpen, nil},
+		{models.JobStatusCancelled, nil},
+		{models.JobStatusClaimed, ErrJobInProgress},
+		{models.JobStatusInTransit, ErrJobInProgress},
+		{models.JobStatusDelivered, ErrJobInProgress},
+		{models.JobStatusCompleted, ErrJobInProgress},
+	}
+	for _, tt := range tests {
+		jobs := &fakeForceDeleteRepo{jobs: map[string]models.JobStatus{"job": tt.status}}
+		svc := NewAdminService(newFakeUserRepo(), newFakeTokenRepo(), jobs)
+		if err := svc.DeleteJob("job"); err != tt.want {
+			t.Errorf("%s: err = %v, want %v", tt.status, err, tt.want)
+		}
+		if _, kept := jobs.jobs["job"]; kept != (tt.want != nil) {
+			t.Errorf("%s: job kept = %v", tt.status, kept)
+		}
+	}
+	svc := NewAdminService(newFakeUserRepo(), newFakeTokenRepo(), &fakeForceDeleteRepo{})
+	if err := svc.DeleteJob("missing"); err != ErrJobNotFound {
+		t.Errorf("unknown job: err = %v, want ErrJobNotFound", err)
+	}
+}
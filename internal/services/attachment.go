@@ -0,0 +1,444 @@
+package services
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+	"log/slog"
+	"moveshare/internal/blobstore"
+	"moveshare/internal/models"
+	"moveshare/internal/repository"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrAttachmentNotFound = errors.New("attachment not found")
+	ErrAttachmentLimit    = errors.New("too many attachments")
+	ErrInvalidAttachment  = errors.New("invalid attachment")
+	// ErrDeliveryNotStarted — фото доставки до того, как груз забран
+	ErrDeliveryNotStarted = errors.New("job is not in transit or delivered")
+	ErrInvalidLink        = errors.New("invalid or expired link")
+)
+
+const (
+	// AttachmentOriginal и AttachmentThumbnail — что отдаёт подписанная ссылка
+	AttachmentOriginal  = "original"
+	AttachmentThumbnail = "thumbnail"
+	// maxThumbnailPixels — изображения больше не декодируются ради миниатюры:
+	// декодированное занимает до 4 байт на пиксель, то есть до 64 МБ
+	maxThumbnailPixels = 16_000_000
+	// maxWideThumbnailPixels — то же для 16 бит на канал (PNG декодируется в
+	// RGBA64/NRGBA64 по 8 байт на пиксель)
+	maxWideThumbnailPixels = 4_000_000
+	// maxConcurrentThumbnails — сколько миниатюр строится одновременно; остальные
+	// загрузки ждут, чтобы декодирование не заняло всю память и процессор
+	maxConcurrentThumbnails = 2
+	// thumbnailSamples — сколько исходных пикселей по каждой оси усредняется
+	// в один пиксель миниатюры
+	thumbnailSamples = 4
+)
+
+// attachmentTypes — допустимые форматы файлов работы и расширения для них
+var attachmentTypes = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"image/gif":       ".gif",
+	"image/webp":      ".webp",
+	"application/pdf": ".pdf",
+}
+
+// thumbnailTypes — форматы, для которых строится миниатюра (есть декодер)
+var thumbnailTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+}
+
+// AttachmentService хранит файлы работ в BlobStore: фото описи от заказчика
+// и фото доставки от перевозчика. Файлы скачиваются по подписанным ссылкам
+type AttachmentService interface {
+	Upload(jobID string, userID int, kind models.AttachmentKind, fileName string, file io.Reader) (*models.Attachment, error)
+	// GetAttachments — файлы работы со ссылками на скачивание. Участники работы
+	// видят все файлы, остальные — только опись открытой работы
+	GetAttachments(jobID string, userID int) ([]*models.Attachment, error)
+	// DeleteAttachment удаляет файл; можно загрузившему и тем, кто управляет работой
+	DeleteAttachment(jobID, attachmentID string, userID int) error
+	// Open открывает файл по подписанной ссылке; закрыть его должен вызывающий
+	Open(attachmentID, variant string, expires int64, signature string) (*models.Attachment, io.ReadCloser, error)
+	// DeleteJobAttachments удаляет файлы удалённой работы
+	DeleteJobAttachments(job *models.Job) error
+}
+
+type attachmentService struct {
+	repo          repository.AttachmentRepository
+	jobRepo       repository.JobRepository
+	store         blobstore.BlobStore
+	urlSecret     []byte
+	urlTTL        time.Duration
+	maxBytes      int64
+	maxPerJob     int
+	thumbnailSize int
+	// thumbnails — семафор построения миниатюр
+	thumbnails chan struct{}
+}
+
+func NewAttachmentService(
+	repo repository.AttachmentRepository,
+	jobRepo repository.JobRepository,
+	store blobstore.BlobStore,
+	urlSecret []byte,
+	urlTTL time.Duration,
+	maxBytes int64,
+	maxPerJob int,
+	thumbnailSize int,
+) AttachmentService {
+	return &attachmentService{
+		repo:          repo,
+		jobRepo:       jobRepo,
+		store:         store,
+		urlSecret:     urlSecret,
+		urlTTL:        urlTTL,
+		maxBytes:      maxBytes,
+		maxPerJob:     maxPerJob,
+		thumbnailSize: thumbnailSize,
+		thumbnails:    make(chan struct{}, maxConcurrentThumbnails),
+	}
+}
+
+func (s *attachmentService) Upload(jobID string, userID int, kind models.AttachmentKind, fileName string, file io.Reader) (*models.Attachment, error) {
+	if kind == "" {
+		kind = models.AttachmentOther
+	}
+	job, err := s.jobRepo.GetJobByID(jobID)
+	if err != nil {
+		return nil, mapJobError(err)
+	}
+	isCarrier := job.CarrierID != nil && *job.CarrierID == userID
+	manager, err := canManageJob(s.jobRepo, job, userID)
+	if err != nil {
+		return nil, err
+	}
+	switch kind {
+	case models.AttachmentInventory:
+		if !manager {
+			return nil, ErrJobForbidden
+		}
+	case models.AttachmentProofOfDelivery:
+		if !isCarrier {
+			return nil, ErrJobForbidden
+		}
+		if job.Status != models.JobStatusInTransit && job.Status != models.JobStatusDelivered {
+			return nil, ErrDeliveryNotStarted
+		}
+	case models.AttachmentOther:
+		if !manager && !isCarrier {
+			return nil, ErrJobForbidden
+		}
+	default:
+		return nil, ErrInvalidAttachment
+	}
+
+	content, err := io.ReadAll(io.LimitReader(file, s.maxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(content)) > s.maxBytes {
+		return nil, ErrFileTooLarge
+	}
+	if len(content) == 0 {
+		return nil, ErrInvalidAttachment
+	}
+	// Тип определяем по содержимому, а не по имени файла или заголовку клиента
+	contentType := http.DetectContentType(content)
+	ext, ok := attachmentTypes[contentType]
+	if !ok {
+		return nil, ErrUnsupportedFileType
+	}
+
+	sum := sha256.Sum256(content)
+	a := &models.Attachment{
+		ID:          uuid.New().String(),
+		JobID:       job.ID,
+		UploadedBy:  userID,
+		Kind:        kind,
+		FileName:    attachmentFileName(fileName, ext),
+		ContentType: contentType,
+		Size:        int64(len(content)),
+		SHA256:      hex.EncodeToString(sum[:]),
+	}
+	a.BlobKey = fmt.Sprintf("jobs/%s/%s%s", job.ID, a.ID, ext)
+
+	var thumb []byte
+	if thumbnailTypes[contentType] {
+		if thumb, err = s.thumbnail(content, a); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.store.Put(a.BlobKey, bytes.NewReader(content), a.Size, contentType); err != nil {
+		return nil, err
+	}
+	if thumb != nil {
+		a.ThumbnailKey = fmt.Sprintf("jobs/%s/%s_thumb.jpg", job.ID, a.ID)
+		if err := s.store.Put(a.ThumbnailKey, bytes.NewReader(thumb), int64(len(thumb)), "image/jpeg"); err != nil {
+			s.deleteBlobs(a)
+			return nil, err
+		}
+	}
+
+	created, err := s.repo.CreateAttachment(a, s.maxPerJob)
+	if err != nil {
+		s.deleteBlobs(a)
+		if errors.Is(err, repository.ErrAttachmentLimit) {
+			return nil, ErrAttachmentLimit
+		}
+		return nil, err
+	}
+	s.signURLs(created)
+	return created, nil
+}
+
+func (s *attachmentService) GetAttachments(jobID string, userID int) ([]*models.Attachment, error) {
+	job, err := s.jobRepo.GetJobByID(jobID)
+	if err != nil {
+		return nil, mapJobError(err)
+	}
+	participant := job.CarrierID != nil && *job.CarrierID == userID
+	if !participant {
+		if participant, err = canManageJob(s.jobRepo, job, userID); err != nil {
+			return nil, err
+		}
+	}
+	// Опись открытой работы помогает перевозчикам оценить её до ставки
+	if !participant && job.Status != models.JobStatusOpen {
+		return nil, ErrJobForbidden
+	}
+
+	attachments, err := s.repo.GetAttachmentsByJob(job.ID)
+	if err != nil {
+		return nil, err
+	}
+	visible := []*models.Attachment{}
+	for _, a := range attachments {
+		if participant || a.Kind == models.AttachmentInventory {
+			s.signURLs(a)
+			visible = append(visible, a)
+		}
+	}
+	return visible, nil
+}
+
+func (s *attachmentService) DeleteAttachment(jobID, attachmentID string, userID int) error {
+	a, err := s.repo.GetAttachment(attachmentID)
+	if errors.Is(err, repository.ErrAttachmentNotFound) || (err == nil && a.JobID != jobID) {
+		return ErrAttachmentNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if a.UploadedBy != userID {
+		job, err := s.jobRepo.GetJobByID(jobID)
+		if err != nil {
+			return mapJobError(err)
+		}
+		manager, err := canManageJob(s.jobRepo, job, userID)
+		if err != nil {
+			return err
+		}
+		if !manager {
+			return ErrJobForbidden
+		}
+	}
+	if err := s.repo.DeleteAttachment(a.ID); err != nil {
+		if errors.Is(err, repository.ErrAttachmentNotFound) {
+			return ErrAttachmentNotFound
+		}
+		return err
+	}
+	s.deleteBlobs(a)
+	return nil
+}
+
+func (s *attachmentService) Open(attachmentID, variant string, expires int64, signature string) (*models.Attachment, io.ReadCloser, error) {
+	if time.Now().Unix() > expires || !hmac.Equal([]byte(signature), []byte(s.sign(attachmentID, variant, expires))) {
+		return nil, nil, ErrInvalidLink
+	}
+	a, err := s.repo.GetAttachment(attachmentID)
+	if errors.Is(err, repository.ErrAttachmentNotFound) {
+		return nil, nil, ErrAttachmentNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	key := a.BlobKey
+	if variant == AttachmentThumbnail {
+		if a.ThumbnailKey == "" {
+			return nil, nil, ErrAttachmentNotFound
+		}
+		key = a.ThumbnailKey
+		a.ContentType = "image/jpeg"
+		a.Size = 0
+	}
+	f, err := s.store.Get(key)
+	if errors.Is(err, blobstore.ErrNotFound) {
+		return nil, nil, ErrAttachmentNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return a, f, nil
+}
+
+func (s *attachmentService) DeleteJobAttachments(job *models.Job) error {
+	attachments, err := s.repo.GetAttachmentsByJob(job.ID)
+	if err != nil {
+		return err
+	}
+	for _, a := range attachments {
+		if err := s.repo.DeleteAttachment(a.ID); err != nil && !errors.Is(err, repository.ErrAttachmentNotFound) {
+			return err
+		}
+		s.deleteBlobs(a)
+	}
+	return nil
+}
+
+// thumbnail проверяет изображение, записывает его размеры в a и возвращает
+// миниатюру в JPEG. Слишком большие изображения остаются без миниатюры
+func (s *attachmentService) thumbnail(content []byte, a *models.Attachment) ([]byte, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(content))
+	if err != nil || cfg.Width <= 0 || cfg.Height <= 0 {
+		return nil, ErrInvalidAttachment
+	}
+	a.Width, a.Height = cfg.Width, cfg.Height
+	limit := maxThumbnailPixels
+	if cfg.ColorModel == color.RGBA64Model || cfg.ColorModel == color.NRGBA64Model {
+		limit = maxWideThumbnailPixels
+	}
+	if int64(cfg.Width)*int64(cfg.Height) > int64(limit) {
+		return nil, nil
+	}
+
+	s.thumbnails <- struct{}{}
+	defer func() { <-s.thumbnails }()
+	img, _, err := image.Decode(bytes.NewReader(content))
+	if err != nil {
+		return nil, ErrInvalidAttachment
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, downscale(img, s.thumbnailSize), &jpeg.Options{Quality: 80}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// deleteBlobs удаляет файлы вложения из хранилища. Ошибка только пишется
+// в журнал: запись о файле уже удалена или не создана
+func (s *attachmentService) deleteBlobs(a *models.Attachment) {
+	for _, key := range []string{a.BlobKey, a.ThumbnailKey} {
+		if key == "" {
+			continue
+		}
+		if err := s.store.Delete(key); err != nil {
+			slog.Error("Failed to delete attachment blob",
+				slog.String("error", err.Error()),
+				slog.String("key", key))
+		}
+	}
+}
+
+// signURLs заполняет ссылки на скачивание файла и миниатюры
+func (s *attachmentService) signURLs(a *models.Attachment) {
+	expiresAt := time.Now().Add(s.urlTTL).Truncate(time.Second)
+	a.URL = s.signedURL(a.ID, AttachmentOriginal, expiresAt.Unix())
+	if a.ThumbnailKey != "" {
+		a.ThumbnailURL = s.signedURL(a.ID, AttachmentThumbnail, expiresAt.Unix())
+	}
+	a.URLExpiresAt = &expiresAt
+}
+
+// signedURL — путь относительно адреса API: /attachments/<id>/content?variant=...&expires=...&signature=...
+func (s *attachmentService) signedURL(id, variant string, expires int64) string {
+	return fmt.Sprintf("/attachments/%s/content?variant=%s&expires=%d&signature=%s", id, variant, expires, s.sign(id, variant, expires))
+}
+
+// sign — HMAC-SHA256 от id, варианта и срока действия ссылки
+func (s *attachmentService) sign(id, variant string, expires int64) string {
+	mac := hmac.New(sha256.New, s.urlSecret)
+	mac.Write([]byte(id + "\n" + variant + "\n" + strconv.FormatInt(expires, 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// attachmentFileName — имя файла для скачивания: без пути и управляющих
+// символов, с расширением по настоящему типу
+func attachmentFileName(name, ext string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f || r == '"' {
+			return -1
+		}
+		return r
+	}, name)
+	name = strings.TrimSuffix(name, filepath.Ext(name))
+	if name == "" || name == "." || name == "/" {
+		name = "attachment"
+	}
+	if len(name) > 200 {
+		name = name[:200]
+	}
+	return name + ext
+}
+
+// downscale уменьшает изображение так, чтобы большая сторона была не больше
+// size, усредняя до thumbnailSamples×thumbnailSamples пикселей исходника на
+// пиксель миниатюры (сетка выборки, а не все пиксели); прозрачные области становятся белыми (в JPEG нет
+// прозрачности). Изображения меньше size не увеличиваются
+func downscale(img image.Image, size int) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w >= h && w > size {
+		w, h = size, max(1, h*size/w)
+	} else if h > w && h > size {
+		w, h = max(1, w*size/h), size
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		sy0, sy1 := b.Min.Y+y*b.Dy()/h, b.Min.Y+(y+1)*b.Dy()/h
+		stepY := max(1, (sy1-sy0)/thumbnailSamples)
+		for x := 0; x < w; x++ {
+			sx0, sx1 := b.Min.X+x*b.Dx()/w, b.Min.X+(x+1)*b.Dx()/w
+			var r, g, bl, a, n uint64
+			stepX := max(1, (sx1-sx0)/thumbnailSamples)
+			for sy := sy0; sy < max(sy1, sy0+1); sy += stepY {
+				for sx := sx0; sx < max(sx1, sx0+1); sx += stepX {
+					cr, cg, cb, ca := img.At(sx, sy).RGBA()
+					r, g, bl, a, n = r+uint64(cr), g+uint64(cg), bl+uint64(cb), a+uint64(ca), n+1
+				}
+			}
+			// Значения с предумноженной альфой: белый фон добавляет 0xffff - a
+			bg := n*0xffff - a
+			dst.SetRGBA(x, y, color.RGBA{
+				R: uint8((r + bg) / n >> 8),
+				G: uint8((g + bg) / n >> 8),
+				B: uint8((bl + bg) / n >> 8),
+				A: 0xff,
+			})
+		}
+	}
+	return dst
+}
@@ -0,0 +1,226 @@
+package services
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"moveshare/internal/blobstore"
+	"moveshare/internal/models"
+	"moveshare/internal/repository"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// fakeAttachmentRepo — AttachmentRepository в памяти
+type fakeAttachmentRepo struct {
+	attachments map[string]*models.Attachment
+}
+
+func (r *fakeAttachmentRepo) CreateAttachment(a *models.Attachment, maxPerJob int) (*models.Attachment, error) {
+	r.attachments[a.ID] = a
+	return a, nil
+}
+
+func (r *fakeAttachmentRepo) GetAttachment(id string) (*models.Attachment, error) {
+	a, ok := r.attachments[id]
+	if !ok {
+		return nil, repository.ErrAttachmentNotFound
+	}
+	copied := *a
+	return &copied, nil
+}
+
+func (r *fakeAttachmentRepo) GetAttachmentsByJob(jobID string) ([]*models.Attachment, error) {
+	list := []*models.Attachment{}
+	for _, a := range r.attachments {
+		if a.JobID == jobID {
+			list = append(list, a)
+		}
+	}
+	return list, nil
+}
+
+func (r *fakeAttachmentRepo) DeleteAttachment(id string) error {
+	delete(r.attachments, id)
+	return nil
+}
+
+func newTestAttachmentService(t *testing.T) (*attachmentService, blobstore.BlobStore) {
+	t.Helper()
+	store, err := blobstore.NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := &fakeAttachmentRepo{attachments: map[string]*models.Attachment{
+		"a1": {ID: "a1", JobID: "j1", ContentType: "application/pdf", Size: 4, BlobKey: "jobs/j1/a1.pdf"},
+	}}
+	if err := store.Put("jobs/j1/a1.pdf", bytes.NewReader([]byte("%PDF")), 4, "application/pdf"); err != nil {
+		t.Fatal(err)
+	}
+	svc := NewAttachmentService(repo, nil, store, []byte("test-secret"), 15*time.Minute, 1<<20, 10, 320)
+	return svc.(*attachmentService), store
+}
+
+// linkParams разбирает подписанную ссылку на variant, expires и signature
+func linkParams(t *testing.T, link string) (string, int64, string) {
+	t.Helper()
+	u, err := url.Parse(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := u.Query()
+	expires, err := strconv.ParseInt(q.Get("expires"), 10, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return q.Get("variant"), expires, q.Get("signature")
+}
+
+func TestAttachmentOpenSignedLink(t *testing.T) {
+	svc, _ := newTestAttachmentService(t)
+	a := &models.Attachment{ID: "a1"}
+	svc.signURLs(a)
+	if a.URL == "" || a.ThumbnailURL != "" || a.URLExpiresAt == nil {
+		t.Fatalf("signURLs = %+v", a)
+	}
+	variant, expires, signature := linkParams(t, a.URL)
+
+	got, f, err := svc.Open("a1", variant, expires, signature)
+	if err != nil {
+		t.Fatalf("Open with a valid link: %v", err)
+	}
+	content, _ := io.ReadAll(f)
+	f.Close()
+	if string(content) != "%PDF" || got.ContentType != "application/pdf" {
+		t.Errorf("Open = %+v, %q", got, content)
+	}
+}
+
+func TestAttachmentOpenRejectsBadLinks(t *testing.T) {
+	svc, _ := newTestAttachmentService(t)
+	valid := time.Now().Add(time.Minute).Unix()
+	signature := svc.sign("a1", AttachmentOriginal, valid)
+	expired := time.Now().Add(-time.Second).Unix()
+
+	tests := []struct {
+		name      string
+		id        string
+		variant   string
+		expires   int64
+		signature string
+		want      error
+	}{
+		{"expired", "a1", AttachmentOriginal, expired, svc.sign("a1", AttachmentOriginal, expired), ErrInvalidLink},
+		{"extended expiry", "a1", AttachmentOriginal, valid + 3600, signature, ErrInvalidLink},
+		{"other attachment", "a2", AttachmentOriginal, valid, signature, ErrInvalidLink},
+		{"other variant", "a1", AttachmentThumbnail, valid, signature, ErrInvalidLink},
+		{"tampered signature", "a1", AttachmentOriginal, valid, tamper(signature), ErrInvalidLink},
+		{"empty signature", "a1", AttachmentOriginal, valid, "", ErrInvalidLink},
+		{"missing thumbnail", "a1", AttachmentThumbnail, valid, svc.sign("a1", AttachmentThumbnail, valid), ErrAttachmentNotFound},
+		{"deleted attachment", "gone", AttachmentOriginal, valid, svc.sign("gone", AttachmentOriginal, valid), ErrAttachmentNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, f, err := svc.Open(tt.id, tt.variant, tt.expires, tt.signature)
+			if f != nil {
+				f.Close()
+			}
+			if err != tt.want {
+				t.Errorf("Open: err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+// tamper меняет последний символ подписи
+func tamper(signature string) string {
+	last := byte('0')
+	if signature[len(signature)-1] == last {
+		last = '1'
+	}
+	return signature[:len(signature)-1] + string(last)
+}
+
+func TestAttachmentLinksDependOnSecret(t *testing.T) {
+	svc, _ := newTestAttachmentService(t)
+	other := *svc
+	other.urlSecret = []byte("another-secret")
+	expires := time.Now().Add(time.Minute).Unix()
+	if _, _, err := svc.Open("a1", AttachmentOriginal, expires, other.sign("a1", AttachmentOriginal, expires)); err != ErrInvalidLink {
+		t.Errorf("link signed with another secret: err = %v, want ErrInvalidLink", err)
+	}
+}
+
+func TestDownscale(t *testing.T) {
+	// Левая половина красная, правая — прозрачная: в миниатюре она белая
+	src := image.NewNRGBA(image.Rect(0, 0, 1000, 500))
+	for y := 0; y < 500; y++ {
+		for x := 0; x < 500; x++ {
+			src.SetNRGBA(x, y, color.NRGBA{R: 255, A: 255})
+		}
+	}
+	thumb := downscale(src, 100)
+	if b := thumb.Bounds(); b.Dx() != 100 || b.Dy() != 50 {
+		t.Fatalf("thumbnail size = %dx%d, want 100x50", b.Dx(), b.Dy())
+	}
+	if r, g, b, _ := thumb.At(10, 10).RGBA(); r>>8 != 255 || g>>8 != 0 || b>>8 != 0 {
+		t.Errorf("opaque area = %d,%d,%d, want red", r>>8, g>>8, b>>8)
+	}
+	if r, g, b, _ := thumb.At(90, 10).RGBA(); r>>8 != 255 || g>>8 != 255 || b>>8 != 255 {
+		t.Errorf("transparent area = %d,%d,%d, want white", r>>8, g>>8, b>>8)
+	}
+
+	small := downscale(image.NewRGBA(image.Rect(0, 0, 30, 80)), 100)
+	if b := small.Bounds(); b.Dx() != 30 || b.Dy() != 80 {
+		t.Errorf("small image resized to %dx%d", b.Dx(), b.Dy())
+	}
+	tall := downscale(image.NewRGBA(image.Rect(0, 0, 10, 5000)), 100)
+	if b := tall.Bounds(); b.Dx() != 1 || b.Dy() != 100 {
+		t.Errorf("tall image resized to %dx%d, want 1x100", b.Dx(), b.Dy())
+	}
+}
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestThumbnail(t *testing.T) {
+	svc, _ := newTestAttachmentService(t)
+
+	a := &models.Attachment{}
+	thumb, err := svc.thumbnail(encodePNG(t, image.NewNRGBA(image.Rect(0, 0, 640, 480))), a)
+	if err != nil || thumb == nil {
+		t.Fatalf("thumbnail: %v", err)
+	}
+	if a.Width != 640 || a.Height != 480 {
+		t.Errorf("dimensions = %dx%d, want 640x480", a.Width, a.Height)
+	}
+	cfg, err := jpeg.DecodeConfig(bytes.NewReader(thumb))
+	if err != nil || cfg.Width != 320 || cfg.Height != 240 {
+		t.Errorf("thumbnail = %+v, %v; want 320x240 JPEG", cfg, err)
+	}
+
+	// 16 бит на канал: больше maxWideThumbnailPixels — без миниатюры, но с размерами
+	wide := &models.Attachment{}
+	thumb, err = svc.thumbnail(encodePNG(t, image.NewNRGBA64(image.Rect(0, 0, 2100, 2000))), wide)
+	if err != nil || thumb != nil {
+		t.Errorf("16-bit image over the limit: thumb = %d bytes, err = %v", len(thumb), err)
+	}
+	if wide.Width != 2100 || wide.Height != 2000 {
+		t.Errorf("dimensions = %dx%d, want 2100x2000", wide.Width, wide.Height)
+	}
+
+	if _, err := svc.thumbnail([]byte("\x89PNG\r\n\x1a\nbroken"), &models.Attachment{}); err != ErrInvalidAttachment {
+		t.Errorf("broken image: err = %v, want ErrInvalidAttachment", err)
+	}
+}
@@ -2,31 +2,54 @@ package services
 
 import (
 	"errors"
+	"fmt"
+	"log/slog"
+	"moveshare/internal/mailer"
 	"moveshare/internal/models"
 	"moveshare/internal/repository"
+	"net/mail"
+	"net/url"
 	"strings"
+	"time"
 
+	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 )
 
+const (
+	EmailVerificationTTL = 48 * time.Hour
+	PasswordResetTTL     = time.Hour
+)
+
 var (
-	ErrUserExists   = errors.New("user already exists")
-	ErrInvalidInput = errors.New("invalid input data")
-	ErrInvalidCreds = errors.New("invalid credentials")
+	ErrUserExists       = errors.New("user already exists")
+	ErrInvalidInput     = errors.New("invalid input data")
+	ErrInvalidCreds     = errors.New("invalid credentials")
+	ErrInvalidUserToken = errors.New("token is invalid, used or expired")
 )
 
 type AuthService interface {
 	CreateUser(req models.SignUpRequest) (*models.User, error)
 	Authenticate(req models.LoginRequest) (*models.User, error)
+	VerifyEmail(token string) error
+	ResendVerification(email string) error
+	RequestPasswordReset(email string) error
+	ResetPassword(req models.ResetPasswordRequest) error
 }
 
 type authService struct {
-	userRepo repository.UserRepository
+	userRepo   repository.UserRepository
+	tokenRepo  repository.TokenRepository
+	mailer     mailer.Mailer
+	appBaseURL string
 }
 
-func NewAuthService(userRepo repository.UserRepository) AuthService {
+func NewAuthService(userRepo repository.UserRepository, tokenRepo repository.TokenRepository, m mailer.Mailer, appBaseURL string) AuthService {
 	return &authService{
-		userRepo: userRepo,
+		userRepo:   userRepo,
+		tokenRepo:  tokenRepo,
+		mailer:     m,
+		appBaseURL: appBaseURL,
 	}
 }
 
@@ -48,13 +71,30 @@ func (s *authService) CreateUser(req models.SignUpRequest) (*models.User, error)
 		return nil, err
 	}
 
+	role := req.Role
+	if role == "" {
+		role = models.RoleShipper
+	}
+
 	user := &models.User{
 		Email:    req.Email,
 		Username: req.Username,
 		Password: string(hashedPassword),
+		Role:     role,
+	}
+
+	user, err = s.userRepo.CreateUser(user)
+	if err != nil {
+		return nil, err
 	}
 
-	return s.userRepo.CreateUser(user)
+	// Письмо можно запросить повторно, поэтому ошибка отправки не отменяет регистрацию
+	if err := s.sendVerification(user); err != nil {
+		slog.Error("Failed to send verification email",
+			slog.String("error", err.Error()),
+			slog.Int("user_id", user.ID))
+	}
+	return user, nil
 }
 
 func (s *authService) Authenticate(req models.LoginRequest) (*models.User, error) {
@@ -68,15 +108,131 @@ func (s *authService) Authenticate(req models.LoginRequest) (*models.User, error
 	return user, nil
 }
 
+func (s *authService) VerifyEmail(token string) error {
+	t, err := s.userRepo.ConsumeUserToken(hashToken(token), models.UserTokenEmailVerification)
+	if err != nil {
+		return mapUserTokenError(err)
+	}
+	return s.userRepo.MarkEmailVerified(t.UserID)
+}
+
+// ResendVerification молча ничего не делает для неизвестных и уже
+// подтверждённых адресов, чтобы по ответу нельзя было перебирать email
+func (s *authService) ResendVerification(email string) error {
+	user, err := s.userRepo.GetUserByEmail(email)
+	if err != nil || user.EmailVerified {
+		return nil
+	}
+	return s.sendVerification(user)
+}
+
+// RequestPasswordReset, как и ResendVerification, не раскрывает, существует ли пользователь
+func (s *authService) RequestPasswordReset(email string) error {
+	user, err := s.userRepo.GetUserByEmail(email)
+	if err != nil {
+		return nil
+	}
+	token, err := s.issueUserToken(user.ID, models.UserTokenPasswordReset, PasswordResetTTL)
+	if err != nil {
+		return err
+	}
+	return s.mailer.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your MoveShare password",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nTo choose a new password, open this link within an hour:\n\n%s\n\nIf you did not request a password reset, ignore this email.\n",
+			user.Username, s.link("/reset-password", token),
+		),
+	})
+}
+
+// ResetPassword меняет пароль и завершает все сессии пользователя. Выданные
+// раньше access-токены отклоняются по времени смены пароля (см. TokenService.IsRevoked)
+func (s *authService) ResetPassword(req models.ResetPasswordRequest) error {
+	if len(req.Password) < 6 {
+		return ErrInvalidInput
+	}
+	t, err := s.userRepo.ConsumeUserToken(hashToken(req.Token), models.UserTokenPasswordReset)
+	if err != nil {
+		return mapUserTokenError(err)
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	if err := s.userRepo.UpdatePassword(t.UserID, string(hashedPassword)); err != nil {
+		return err
+	}
+	return s.tokenRepo.RevokeUserSessions(t.UserID)
+}
+
+func (s *authService) sendVerification(user *models.User) error {
+	token, err := s.issueUserToken(user.ID, models.UserTokenEmailVerification, EmailVerificationTTL)
+	if err != nil {
+		return err
+	}
+	return s.mailer.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Confirm your MoveShare email",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nPlease confirm your email address by opening this link:\n\n%s\n\nThe link is valid for 48 hours.\n",
+			user.Username, s.link("/verify-email", token),
+		),
+	})
+}
+
+func (s *authService) issueUserToken(userID int, purpose models.UserTokenPurpose, ttl time.Duration) (string, error) {
+	raw, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+	err = s.userRepo.CreateUserToken(&models.UserToken{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: hashToken(raw),
+		ExpiresAt: time.Now().Add(ttl),
+	})
+	if err != nil {
+		return "", err
+	}
+	return raw, nil
+}
+
+func (s *authService) link(path, token string) string {
+	return s.appBaseURL + path + "?token=" + url.QueryEscape(token)
+}
+
 func (s *authService) validateSignUpRequest(req models.SignUpRequest) error {
 	if req.Email == "" || req.Username == "" || req.Password == "" {
 		return ErrInvalidInput
 	}
-	if !strings.Contains(req.Email, "@") {
+	if !validEmail(req.Email) {
 		return ErrInvalidInput
 	}
 	if len(req.Password) < 6 {
 		return ErrInvalidInput
 	}
+	// Роль администратора выдаётся только через PUT /admin/users/{id}/role
+	if req.Role != "" && req.Role != models.RoleShipper && req.Role != models.RoleCarrier {
+		return ErrInvalidInput
+	}
 	return nil
 }
+
+func validEmail(email string) bool {
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email {
+		return false
+	}
+	// net/mail допускает адреса вида user@localhost, нам нужен домен с зоной
+	at := strings.LastIndex(addr.Address, "@")
+	return strings.Contains(addr.Address[at+1:], ".")
+}
+
+func mapUserTokenError(err error) error {
+	if errors.Is(err, repository.ErrUserTokenInvalid) {
+		return ErrInvalidUserToken
+	}
+	return err
+}
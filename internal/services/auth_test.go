@@ -0,0 +1,176 @@
+package services
+
+import (
+	"moveshare/internal/mailer"
+	"moveshare/internal/models"
+	"moveshare/internal/repository"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func (r *fakeUserRepo) GetUserByEmail(email string) (*models.User, error) {
+	for _, user := range r.users {
+		if user.Email == email {
+			copied := *user
+			return &copied, nil
+		}
+	}
+	return nil, repository.ErrUserNotFound
+}
+
+func (r *fakeUserRepo) CreateUserToken(token *models.UserToken) error {
+	if r.userTokens == nil {
+		r.userTokens = map[string]*models.UserToken{}
+	}
+	for _, t := range r.userTokens {
+		if t.UserID == token.UserID && t.Purpose == token.Purpose && t.UsedAt == nil {
+			now := time.Now()
+			t.UsedAt = &now
+		}
+	}
+	r.userTokens[token.TokenHash] = token
+	return nil
+}
+
+func (r *fakeUserRepo) ConsumeUserToken(tokenHash string, purpose models.UserTokenPurpose) (*models.UserToken, error) {
+	t, ok := r.userTokens[tokenHash]
+	if !ok || t.Purpose != purpose || t.UsedAt != nil || !t.ExpiresAt.After(time.Now()) {
+		return nil, repository.ErrUserTokenInvalid
+	}
+	now := time.Now()
+	t.UsedAt = &now
+	return t, nil
+}
+
+func (r *fakeUserRepo) UpdatePassword(userID int, passwordHash string) error {
+	r.users[userID].Password = passwordHash
+	r.passwordChangedAt[userID] = time.Now()
+	return nil
+}
+
+// fakeMailer запоминает отправленные письма
+type fakeMailer struct {
+	sent []mailer.Message
+}
+
+func (m *fakeMailer) Send(msg mailer.Message) error {
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+// linkToken достаёт токен из ссылки в письме
+func linkToken(t *testing.T, msg mailer.Message) string {
+	t.Helper()
+	start := strings.Index(msg.Body, "http")
+	if start < 0 {
+		t.Fatalf("no link in %q", msg.Body)
+	}
+	link, err := url.Parse(strings.Fields(msg.Body[start:])[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return link.Query().Get("token")
+}
+
+func newTestAuthService(t *testing.T) (*authService, *fakeUserRepo, *fakeTokenRepo, *fakeMailer) {
+	t.Helper()
+	hash, _ := bcrypt.GenerateFromPassword([]byte("old-password"), bcrypt.MinCost)
+	users := newFakeUserRepo(&models.User{ID: 7, Email: "carrier@example.com", Username: "carrier", Password: string(hash)})
+	tokens := newFakeTokenRepo()
+	tokens.users = users
+	m := &fakeMailer{}
+	return NewAuthService(users, tokens, m, "https://app.example.com").(*authService), users, tokens, m
+}
+
+func TestResetPassword(t *testing.T) {
+	svc, users, tokens, m := newTestAuthService(t)
+	session := &models.RefreshToken{ID: "s1", UserID: 7, FamilyID: "f1", TokenHash: "h1", ExpiresAt: time.Now().Add(time.Hour)}
+	tokens.CreateRefreshToken(session)
+
+	if err := svc.RequestPasswordReset("carrier@example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if len(m.sent) != 1 || !strings.Contains(m.sent[0].Body, "https://app.example.com/reset-password?token=") {
+		t.Fatalf("reset email = %+v", m.sent)
+	}
+	token := linkToken(t, m.sent[0])
+
+	if err := svc.ResetPassword(models.ResetPasswordRequest{Token: token, Password: "short"}); err != ErrInvalidInput {
+		t.Errorf("short password: err = %v, want ErrInvalidInput", err)
+	}
+	if err := svc.ResetPassword(models.ResetPasswordRequest{Token: token, Password: "new-password"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.Authenticate(models.LoginRequest{Email: "carrier@example.com", Password: "new-password"}); err != nil {
+		t.Errorf("login with the new password: %v", err)
+	}
+	if _, err := svc.Authenticate(models.LoginRequest{Email: "carrier@example.com", Password: "old-password"}); err != ErrInvalidCreds {
+		t.Errorf("login with the old password: err = %v, want ErrInvalidCreds", err)
+	}
+	if tokens.tokens["s1"].RevokedAt == nil {
+		t.Error("refresh session survived the password reset")
+	}
+	if _, ok := users.passwordChangedAt[7]; !ok {
+		t.Error("password change time was not recorded")
+	}
+	if err := svc.ResetPassword(models.ResetPasswordRequest{Token: token, Password: "another-password"}); err != ErrInvalidUserToken {
+		t.Errorf("reused reset token: err = %v, want ErrInvalidUserToken", err)
+	}
+}
+
+// Access-токены, выпущенные до сброса пароля, больше не принимаются, а
+// выпущенные после — принимаются, даже в ту же секунду
+func TestResetPasswordRevokesEarlierAccessTokens(t *testing.T) {
+	svc, users, tokens, m := newTestAuthService(t)
+	tokenService := NewTokenService(newTestJWTService(t), tokens, users)
+
+	svc.RequestPasswordReset("carrier@example.com")
+	if err := svc.ResetPassword(models.ResetPasswordRequest{Token: linkToken(t, m.sent[0]), Password: "new-password"}); err != nil {
+		t.Fatal(err)
+	}
+	changedAt := users.passwordChangedAt[7]
+	tests := []struct {
+		name     string
+		issuedAt time.Time
+		revoked  bool
+	}{
+		{"issued a minute before the reset", changedAt.Add(-time.Minute), true},
+		{"issued a second before the reset", changedAt.Truncate(time.Second).Add(-time.Second), true},
+		{"issued in the same second", changedAt.Truncate(time.Second), false},
+		{"issued after the reset", changedAt.Add(time.Minute), false},
+	}
+	for _, tt := range tests {
+		revoked, err := tokenService.IsRevoked(&AccessClaims{UserID: 7, JTI: tt.name, IssuedAt: tt.issuedAt})
+		if err != nil || revoked != tt.revoked {
+			t.Errorf("%s: revoked = %v, %v; want %v", tt.name, revoked, err, tt.revoked)
+		}
+	}
+	// Другие пользователи не затронуты
+	if revoked, _ := tokenService.IsRevoked(&AccessClaims{UserID: 8, JTI: "other", IssuedAt: changedAt.Add(-time.Minute)}); revoked {
+		t.Error("access token of another user revoked")
+	}
+}
+
+func TestRequestPasswordResetUnknownEmail(t *testing.T) {
+	svc, _, _, m := newTestAuthService(t)
+	if err := svc.RequestPasswordReset("nobody@example.com"); err != nil || len(m.sent) != 0 {
+		t.Errorf("unknown email: err = %v, sent = %d", err, len(m.sent))
+	}
+}
+
+func TestResetPasswordUsesOnlyLatestLink(t *testing.T) {
+	svc, _, _, m := newTestAuthService(t)
+	svc.RequestPasswordReset("carrier@example.com")
+	svc.RequestPasswordReset("carrier@example.com")
+	first, second := linkToken(t, m.sent[0]), linkToken(t, m.sent[1])
+	if err := svc.ResetPassword(models.ResetPasswordRequest{Token: first, Password: "new-password"}); err != ErrInvalidUserToken {
+		t.Errorf("superseded link: err = %v, want ErrInvalidUserToken", err)
+	}
+	if err := svc.ResetPassword(models.ResetPasswordRequest{Token: second, Password: "new-password"}); err != nil {
+		t.Errorf("latest link: err = %v", err)
+	}
+}
@@ -0,0 +1,248 @@
+package services
+
+import (
+	"errors"
+	"moveshare/internal/models"
+	"moveshare/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrBidNotFound  = errors.New("bid not found")
+	ErrBidNotActive = errors.New("bid is no longer active")
+	ErrBidForbidden = errors.New("not allowed to act on this bid")
+	ErrInvalidBid   = errors.New("invalid bid")
+)
+
+const defaultBidTTL = 24 * time.Hour
+
+type BidService interface {
+	CreateBid(jobID string, carrierID int, req models.CreateBidRequest) (*models.Bid, error)
+	GetBids(jobID string, userID int) ([]*models.Bid, error)
+	AcceptBid(jobID, bidID string, userID int) (*models.Job, error)
+	RejectBid(jobID, bidID string, userID int) (*models.Bid, error)
+	CounterBid(jobID, bidID string, userID int, req models.CounterBidRequest) (*models.Bid, error)
+}
+
+type bidService struct {
+	bidRepo     repository.BidRepository
+	jobRepo     repository.JobRepository
+	carrierRepo repository.CarrierRepository
+	fees        FeeService
+}
+
+func NewBidService(bidRepo repository.BidRepository, jobRepo repository.JobRepository, carrierRepo repository.CarrierRepository, fees FeeService) BidService {
+	return &bidService{bidRepo: bidRepo, jobRepo: jobRepo, carrierRepo: carrierRepo, fees: fees}
+}
+
+func (s *bidService) CreateBid(jobID string, carrierID int, req models.CreateBidRequest) (*models.Bid, error) {
+	amount := req.Amount.WithDefaultCurrency()
+	if amount.Amount <= 0 {
+		return nil, ErrInvalidBid
+	}
+	expiresAt := req.ExpiresAt
+	if expiresAt.IsZero() {
+		expiresAt = time.Now().Add(defaultBidTTL)
+	}
+	if !expiresAt.After(time.Now()) {
+		return nil, ErrInvalidBid
+	}
+
+	job, err := s.jobRepo.GetJobByID(jobID)
+	if err != nil {
+		return nil, mapJobError(err)
+	}
+	own, err := canManageJob(s.jobRepo, job, carrierID)
+	if err != nil {
+		return nil, err
+	}
+	if own {
+		return nil, ErrCannotClaimOwnJob
+	}
+	if job.Status != models.JobStatusOpen {
+		return nil, ErrJobNotOpen
+	}
+	// Ставка становится оплатой работы, поэтому она в валюте работы
+	if !amount.SameCurrency(job.PaymentAmount) {
+		return nil, ErrInvalidBid
+	}
+	// Принятая ставка закрепляет работу, поэтому ставки — тоже только от проверенных
+	verified, err := s.carrierRepo.IsCarrierVerified(carrierID)
+	if err != nil {
+		return nil, err
+	}
+	if !verified {
+		return nil, ErrCarrierNotVerified
+	}
+
+	bid, err := s.bidRepo.CreateBid(&models.Bid{
+		ID:        uuid.New().String(),
+		JobID:     jobID,
+		CarrierID: carrierID,
+		Amount:    amount,
+		Message:   req.Message,
+		Status:    models.BidStatusPending,
+		ExpiresAt: expiresAt,
+	}, bidEvents(models.EventBidReceived, job))
+	if err != nil {
+		return nil, err
+	}
+	return bid, nil
+}
+
+// GetBids возвращает заказчику (и диспетчерам его компании) все ставки на работу,
+// остальным — только их собственные
+func (s *bidService) GetBids(jobID string, userID int) ([]*models.Bid, error) {
+	job, err := s.jobRepo.GetJobByID(jobID)
+	if err != nil {
+		return nil, mapJobError(err)
+	}
+	bids, err := s.bidRepo.GetBidsByJob(jobID)
+	if err != nil {
+		return nil, err
+	}
+	manager, err := canManageJob(s.jobRepo, job, userID)
+	if err != nil {
+		return nil, err
+	}
+	if manager {
+		return bids, nil
+	}
+	own := []*models.Bid{}
+	for _, bid := range bids {
+		if bid.CarrierID == userID {
+			own = append(own, bid)
+		}
+	}
+	return own, nil
+}
+
+// AcceptBid: заказчик принимает ставку перевозчика, либо перевозчик
+// принимает встречное предложение заказчика. В обоих случаях работа
+// закрепляется за перевозчиком по согласованной цене.
+func (s *bidService) AcceptBid(jobID, bidID string, userID int) (*models.Job, error) {
+	job, bid, manager, err := s.load(jobID, bidID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var amount models.Money
+	switch {
+	case bid.Status == models.BidStatusPending && manager:
+		amount = bid.Amount
+	case bid.Status == models.BidStatusCountered && bid.CarrierID == userID && bid.CounterAmount != nil:
+		amount = *bid.CounterAmount
+	case bid.Status == models.BidStatusPending || bid.Status == models.BidStatusCountered:
+		return nil, ErrBidForbidden
+	default:
+		return nil, ErrBidNotActive
+	}
+	if bid.ExpiresAt.Before(time.Now()) {
+		return nil, ErrBidNotActive
+	}
+	// Проверка могла потерять силу после ставки: профиль приостановлен или страховка истекла
+	verified, err := s.carrierRepo.IsCarrierVerified(bid.CarrierID)
+	if err != nil {
+		return nil, err
+	}
+	if !verified {
+		return nil, ErrCarrierNotVerified
+	}
+
+	// Удержания считаются от согласованной цены и фиксируются вместе с закреплением
+	fees, err := s.fees.QuoteJob(job, amount)
+	if err != nil {
+		return nil, err
+	}
+	job, err = s.bidRepo.AcceptBid(bid, fees, func(job *models.Job) []*models.Event {
+		accepted := *bid
+		accepted.Status = models.BidStatusAccepted
+		return []*models.Event{
+			bidEvent(models.EventBidAccepted, job, &accepted),
+			jobEvent(models.EventJobClaimed, job),
+		}
+	}, bidEvents(models.EventBidRejected, job))
+	if err != nil {
+		return nil, mapBidError(err)
+	}
+	return job, nil
+}
+
+// RejectBid: заказчик отклоняет ставку, либо перевозчик отклоняет встречное предложение
+func (s *bidService) RejectBid(jobID, bidID string, userID int) (*models.Bid, error) {
+	job, bid, manager, err := s.load(jobID, bidID, userID)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case bid.Status == models.BidStatusPending && manager:
+	case bid.Status == models.BidStatusCountered && bid.CarrierID == userID:
+	case bid.Status == models.BidStatusPending || bid.Status == models.BidStatusCountered:
+		return nil, ErrBidForbidden
+	default:
+		return nil, ErrBidNotActive
+	}
+	bid, err = s.bidRepo.RejectBid(bidID, bid.Status, bidEvents(models.EventBidRejected, job))
+	if err != nil {
+		return nil, mapBidError(err)
+	}
+	return bid, nil
+}
+
+func (s *bidService) CounterBid(jobID, bidID string, userID int, req models.CounterBidRequest) (*models.Bid, error) {
+	amount := req.Amount.WithDefaultCurrency()
+	if amount.Amount <= 0 {
+		return nil, ErrInvalidBid
+	}
+	job, bid, manager, err := s.load(jobID, bidID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !amount.SameCurrency(bid.Amount) {
+		return nil, ErrInvalidBid
+	}
+	if !manager {
+		return nil, ErrBidForbidden
+	}
+	if job.Status != models.JobStatusOpen {
+		return nil, ErrJobNotOpen
+	}
+	bid, err = s.bidRepo.CounterBid(bid.ID, amount, req.Message, bidEvents(models.EventBidCountered, job))
+	if err != nil {
+		return nil, mapBidError(err)
+	}
+	return bid, nil
+}
+
+// load загружает работу и ставку и сообщает, управляет ли userID этой работой
+func (s *bidService) load(jobID, bidID string, userID int) (*models.Job, *models.Bid, bool, error) {
+	job, err := s.jobRepo.GetJobByID(jobID)
+	if err != nil {
+		return nil, nil, false, mapJobError(err)
+	}
+	bid, err := s.bidRepo.GetBidByID(bidID)
+	if err != nil {
+		return nil, nil, false, mapBidError(err)
+	}
+	if bid.JobID != job.ID {
+		return nil, nil, false, ErrBidNotFound
+	}
+	manager, err := canManageJob(s.jobRepo, job, userID)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	return job, bid, manager, nil
+}
+
+func mapBidError(err error) error {
+	switch {
+	case errors.Is(err, repository.ErrBidNotFound):
+		return ErrBidNotFound
+	case errors.Is(err, repository.ErrBidNotActive):
+		return ErrBidNotActive
+	default:
+		return mapJobError(err)
+	}
+}
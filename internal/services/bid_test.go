@@ -0,0 +1,117 @@
+package services
+
+import (
+	"moveshare/internal/models"
+	"moveshare/internal/repository"
+	"slices"
+	"testing"
+	"time"
+)
+
+// fakeBidRepo хранит ставки в памяти; AcceptBid повторяет условия SQL в bidRepository
+type fakeBidRepo struct {
+	repository.BidRepository
+	bids   map[string]*models.Bid
+	events []*models.Event
+}
+
+func (r *fakeBidRepo) GetBidByID(id string) (*models.Bid, error) {
+	bid, ok := r.bids[id]
+	if !ok {
+		return nil, repository.ErrBidNotFound
+	}
+	copied := *bid
+	return &copied, nil
+}
+
+func (r *fakeBidRepo) AcceptBid(bid *models.Bid, fees *models.FeeBreakdown, events repository.JobEvents, rejected repository.BidEvents) (*models.Job, error) {
+	if stored := r.bids[bid.ID]; stored.Status != bid.Status || !stored.ExpiresAt.After(time.Now()) {
+		return nil, repository.ErrBidNotActive
+	}
+	r.bids[bid.ID].Status = models.BidStatusAccepted
+	carrierID := bid.CarrierID
+	job := &models.Job{ID: bid.JobID, PosterID: 1, Status: models.JobStatusClaimed, CarrierID: &carrierID, PaymentAmount: fees.Payment}
+	var written []*models.Event
+	for _, b := range r.bids {
+		if b.JobID == bid.JobID && b.ID != bid.ID && (b.Status == models.BidStatusPending || b.Status == models.BidStatusCountered) {
+			b.Status = models.BidStatusRejected
+			copied := *b
+			written = append(written, rejected(&copied)...)
+		}
+	}
+	r.events = append(append(r.events, written...), events(job)...)
+	return job, nil
+}
+
+type fakeBidJobRepo struct {
+	repository.JobRepository
+	job *models.Job
+}
+
+func (r *fakeBidJobRepo) GetJobByID(id string) (*models.Job, error) {
+	if id != r.job.ID {
+		return nil, repository.ErrJobNotFound
+	}
+	copied := *r.job
+	return &copied, nil
+}
+
+type verifiedCarriers struct {
+	repository.CarrierRepository
+}
+
+func (verifiedCarriers) IsCarrierVerified(carrierID int) (bool, error) {
+	return true, nil
+}
+
+// flatFees удерживает 10% и не ходит в базу
+type flatFees struct {
+	FeeService
+}
+
+func (flatFees) QuoteJob(job *models.Job, payment models.Money) (*models.FeeBreakdown, error) {
+	cut := models.NewMoney(payment.Amount/10, payment.Currency)
+	return &models.FeeBreakdown{Payment: payment, BrokerCut: cut}, nil
+}
+
+func TestAcceptBidRejectsCompetingBids(t *testing.T) {
+	expires := time.Now().Add(time.Hour)
+	bids := &fakeBidRepo{bids: map[string]*models.Bid{
+		"accepted":  {ID: "accepted", JobID: "job", CarrierID: 10, Amount: usd(90000), Status: models.BidStatusPending, ExpiresAt: expires},
+		"pending":   {ID: "pending", JobID: "job", CarrierID: 11, Amount: usd(95000), Status: models.BidStatusPending, ExpiresAt: expires},
+		"countered": {ID: "countered", JobID: "job", CarrierID: 12, Amount: usd(99000), Status: models.BidStatusCountered, ExpiresAt: expires},
+		"declined":  {ID: "declined", JobID: "job", CarrierID: 13, Amount: usd(80000), Status: models.BidStatusRejected, ExpiresAt: expires},
+		"other job": {ID: "other job", JobID: "other", CarrierID: 14, Amount: usd(70000), Status: models.BidStatusPending, ExpiresAt: expires},
+	}}
+	jobs := &fakeBidJobRepo{job: &models.Job{ID: "job", PosterID: 1, Status: models.JobStatusOpen, PaymentAmount: usd(100000)}}
+	svc := NewBidService(bids, jobs, verifiedCarriers{}, flatFees{})
+
+	if _, err := svc.AcceptBid("job", "accepted", 1); err != nil {
+		t.Fatal(err)
+	}
+
+	got := map[models.EventType][]string{}
+	for _, event := range bids.events {
+		switch data := event.Data.(type) {
+		case *models.Bid:
+			got[event.Type] = append(got[event.Type], data.ID)
+			if event.Type == models.EventBidRejected && (data.Status != models.BidStatusRejected || !slices.Contains(event.Audience.UserIDs, data.CarrierID)) {
+				t.Errorf("bid.rejected for %s: status = %s, audience = %+v", data.ID, data.Status, event.Audience)
+			}
+		case *models.Job:
+			got[event.Type] = append(got[event.Type], data.ID)
+		}
+	}
+	if rejected := got[models.EventBidRejected]; len(rejected) != 2 || !slices.Contains(rejected, "pending") || !slices.Contains(rejected, "countered") {
+		t.Errorf("bid.rejected events for %v, want pending and countered", rejected)
+	}
+	if accepted := got[models.EventBidAccepted]; len(accepted) != 1 || accepted[0] != "accepted" {
+		t.Errorf("bid.accepted events for %v", accepted)
+	}
+	if claimed := got[models.EventJobClaimed]; len(claimed) != 1 {
+		t.Errorf("job.claimed events for %v", claimed)
+	}
+	if bids.bids["other job"].Status != models.BidStatusPending {
+		t.Error("bid on another job was rejected")
+	}
+}
@@ -0,0 +1,362 @@
+package services
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"moveshare/internal/documents"
+	"moveshare/internal/models"
+	"moveshare/internal/repository"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrBOLNotIssued     = errors.New("bill of lading is not issued yet")
+	ErrBOLAlreadySigned = errors.New("bill of lading already signed")
+	ErrBOLNotSignable   = errors.New("bill of lading can not be signed now")
+	ErrShipperNotSigned = errors.New("shipper has not signed yet")
+	// ErrNotDelivered — получатель подписывает накладную только при доставке
+	ErrNotDelivered     = errors.New("job is not delivered yet")
+	ErrBOLConflict      = errors.New("bill of lading changed")
+	ErrInvalidSignature = errors.New("invalid signature")
+)
+
+const (
+	// MaxSignatureBytes — предельный размер изображения подписи
+	MaxSignatureBytes = 1 << 20
+	// maxSignatureSide — предельная ширина и высота изображения подписи в пикселях
+	maxSignatureSide = 2000
+	maxSignerName    = 200
+)
+
+// signatureTypes — допустимые форматы изображения подписи и расширения файлов
+var signatureTypes = map[string]string{
+	"image/png":  ".png",
+	"image/jpeg": ".jpg",
+}
+
+// BOLService ведёт транспортные накладные: выдаёт накладную, когда груз
+// забран, и принимает подписи отправителя и получателя. Каждая подпись
+// даёт новую версию PDF, её SHA-256 записывается в накладную и у работы
+type BOLService interface {
+	// GetBOL — накладная работы. Если груз уже забран, а накладной ещё нет,
+	// она выдаётся при первом запросе
+	GetBOL(jobID string, userID int) (*models.BillOfLading, error)
+	// GetBOLFile — накладная и PDF её последней версии
+	GetBOLFile(jobID string, userID int) (*models.BillOfLading, []byte, error)
+	// IssueBOL выдаёт накладную при переходе работы в in_transit
+	IssueBOL(job *models.Job) error
+	// Sign добавляет подпись роли role: изображение image, имя подписавшего
+	// и IP, с которого пришёл запрос. Время подписи ставит сервер
+	Sign(jobID string, userID int, role models.SignatureRole, signerName string, image io.Reader, ip string) (*models.BillOfLading, error)
+	// Verify сверяет сохранённые файлы накладной и подписей с записанными хешами
+	Verify(jobID string, userID int) (*models.BOLVerification, error)
+}
+
+type bolService struct {
+	repo        repository.BOLRepository
+	jobRepo     repository.JobRepository
+	userRepo    repository.UserRepository
+	companyRepo repository.CompanyRepository
+	carrierRepo repository.CarrierRepository
+	uploadsDir  string
+}
+
+func NewBOLService(
+	repo repository.BOLRepository,
+	jobRepo repository.JobRepository,
+	userRepo repository.UserRepository,
+	companyRepo repository.CompanyRepository,
+	carrierRepo repository.CarrierRepository,
+	uploadsDir string,
+) BOLService {
+	return &bolService{
+		repo:        repo,
+		jobRepo:     jobRepo,
+		userRepo:    userRepo,
+		companyRepo: companyRepo,
+		carrierRepo: carrierRepo,
+		uploadsDir:  uploadsDir,
+	}
+}
+
+func (s *bolService) GetBOL(jobID string, userID int) (*models.BillOfLading, error) {
+	job, err := participantJob(s.jobRepo, jobID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.issue(job)
+}
+
+func (s *bolService) GetBOLFile(jobID string, userID int) (*models.BillOfLading, []byte, error) {
+	bol, err := s.GetBOL(jobID, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	content, err := os.ReadFile(filepath.Join(s.uploadsDir, bol.FilePath))
+	if err != nil {
+		return nil, nil, err
+	}
+	return bol, content, nil
+}
+
+func (s *bolService) IssueBOL(job *models.Job) error {
+	_, err := s.issue(job)
+	if errors.Is(err, ErrBOLNotIssued) {
+		// Работу успели отменить или удалить — накладная не нужна
+		return nil
+	}
+	return err
+}
+
+// issue возвращает накладную работы, выдавая её, если груз уже забран
+func (s *bolService) issue(job *models.Job) (*models.BillOfLading, error) {
+	bol, err := s.repo.GetBOL(job.ID)
+	if !errors.Is(err, repository.ErrBOLNotFound) {
+		return bol, err
+	}
+	switch job.Status {
+	case models.JobStatusInTransit, models.JobStatusDelivered, models.JobStatusCompleted:
+	default:
+		return nil, ErrBOLNotIssued
+	}
+
+	shipper, carrier, err := jobParties(s.userRepo, s.companyRepo, s.carrierRepo, job)
+	if err != nil {
+		return nil, err
+	}
+	bol = &models.BillOfLading{
+		ID:     uuid.New().String(),
+		JobID:  job.ID,
+		Number: "BOL-" + strings.ToUpper(strings.ReplaceAll(job.ID, "-", "")[:10]),
+		Snapshot: models.BOLSnapshot{
+			JobTitle:                      job.JobTitle,
+			Shipper:                       models.BOLParty(shipper),
+			Carrier:                       models.BOLParty(carrier),
+			PickupAddress:                 job.PickupAddress,
+			DeliveryAddress:               job.DeliveryAddress,
+			PickupDateTime:                job.PickupDateTime,
+			DeliveryDateTime:              job.DeliveryDateTime,
+			NumberOfBedrooms:              job.NumberOfBedrooms,
+			TruckSize:                     job.TruckSize,
+			AdditionalServices:            job.AdditionalServices,
+			DescriptionAdditionalServices: job.DescriptionAdditionalServices,
+			DistanceMiles:                 job.DistanceMiles,
+			Charges:                       job.PaymentAmount,
+		},
+		// Время хранится в базе с точностью до микросекунд; секунд хватает,
+		// и собранный заново PDF совпадёт с сохранённым
+		IssuedAt: time.Now().UTC().Truncate(time.Second),
+	}
+	if err := s.saveVersion(bol, nil); err != nil {
+		return nil, err
+	}
+	created, err := s.repo.CreateBOL(bol)
+	if err != nil || created.ID != bol.ID {
+		// Накладную параллельно выдал другой запрос — наш файл не нужен
+		os.Remove(filepath.Join(s.uploadsDir, bol.FilePath))
+	}
+	return created, err
+}
+
+func (s *bolService) Sign(jobID string, userID int, role models.SignatureRole, signerName string, file io.Reader, ip string) (*models.BillOfLading, error) {
+	signerName = strings.TrimSpace(signerName)
+	if (role != models.SignatureShipper && role != models.SignatureConsignee) || signerName == "" || len(signerName) > maxSignerName {
+		return nil, ErrInvalidSignature
+	}
+	job, err := participantJob(s.jobRepo, jobID, userID)
+	if err != nil {
+		return nil, err
+	}
+	// Подписи принимаются, пока груз в пути или только что доставлен
+	if job.Status != models.JobStatusInTransit && job.Status != models.JobStatusDelivered {
+		if job.Status == models.JobStatusOpen || job.Status == models.JobStatusClaimed {
+			return nil, ErrBOLNotIssued
+		}
+		return nil, ErrBOLNotSignable
+	}
+	bol, err := s.issue(job)
+	if err != nil {
+		return nil, err
+	}
+	if bol.Signature(role) != nil {
+		return nil, ErrBOLAlreadySigned
+	}
+	if role == models.SignatureConsignee && bol.Signature(models.SignatureShipper) == nil {
+		return nil, ErrShipperNotSigned
+	}
+	// Получатель подтверждает приёмку груза, поэтому до доставки его подпись не принимается
+	if role == models.SignatureConsignee && job.Status != models.JobStatusDelivered {
+		return nil, ErrNotDelivered
+	}
+
+	content, img, ext, err := readSignature(file)
+	if err != nil {
+		return nil, err
+	}
+	images, err := s.signatureImages(bol)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(content)
+	sig := &models.BOLSignature{
+		ID:          uuid.New().String(),
+		Role:        role,
+		SignerName:  signerName,
+		ImageSHA256: hex.EncodeToString(sum[:]),
+		IP:          ip,
+		CapturedBy:  &userID,
+		SignedAt:    time.Now().UTC().Truncate(time.Second),
+	}
+	sig.ImagePath = filepath.Join("bol", job.ID, fmt.Sprintf("%s-%s%s", role, sig.ID, ext))
+	if err := saveFile(filepath.Join(s.uploadsDir, sig.ImagePath), content); err != nil {
+		return nil, err
+	}
+
+	prevHash := bol.DocumentHash
+	bol.Signatures = append(bol.Signatures, sig)
+	images[role] = img
+	if err := s.saveVersion(bol, images); err != nil {
+		os.Remove(filepath.Join(s.uploadsDir, sig.ImagePath))
+		return nil, err
+	}
+	sig.DocumentHash = bol.DocumentHash
+
+	if err := s.repo.AddSignature(bol, sig, prevHash); err != nil {
+		os.Remove(filepath.Join(s.uploadsDir, sig.ImagePath))
+		os.Remove(filepath.Join(s.uploadsDir, bol.FilePath))
+		switch {
+		case errors.Is(err, repository.ErrBOLChanged):
+			return nil, ErrBOLConflict
+		case errors.Is(err, repository.ErrBOLAlreadySigned):
+			return nil, ErrBOLAlreadySigned
+		}
+		return nil, err
+	}
+	return bol, nil
+}
+
+func (s *bolService) Verify(jobID string, userID int) (*models.BOLVerification, error) {
+	job, err := participantJob(s.jobRepo, jobID, userID)
+	if err != nil {
+		return nil, err
+	}
+	bol, err := s.repo.GetBOL(job.ID)
+	if errors.Is(err, repository.ErrBOLNotFound) {
+		return nil, ErrBOLNotIssued
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.BOLVerification{DocumentHash: bol.DocumentHash}
+	fileHash, err := s.fileHash(bol.FilePath)
+	if err != nil {
+		return nil, err
+	}
+	result.FileHash = fileHash
+	if fileHash != bol.DocumentHash || job.BOLHash != bol.DocumentHash {
+		result.Mismatches = append(result.Mismatches, "document")
+	}
+	for _, sig := range bol.Signatures {
+		imageHash, err := s.fileHash(sig.ImagePath)
+		if err != nil {
+			return nil, err
+		}
+		if imageHash != sig.ImageSHA256 {
+			result.Mismatches = append(result.Mismatches, string(sig.Role))
+		}
+	}
+	result.Valid = len(result.Mismatches) == 0
+	return result, nil
+}
+
+// saveVersion собирает PDF накладной с подписями images, сохраняет его
+// и записывает в bol хеш и путь новой версии. Версии не перезаписываются:
+// имя файла — начало хеша
+func (s *bolService) saveVersion(bol *models.BillOfLading, images map[models.SignatureRole]image.Image) error {
+	content := documents.RenderBOL(bol, images)
+	sum := sha256.Sum256(content)
+	hash := hex.EncodeToString(sum[:])
+	relPath := filepath.Join("bol", bol.JobID, bol.Number+"-"+hash[:16]+".pdf")
+	if err := saveFile(filepath.Join(s.uploadsDir, relPath), content); err != nil {
+		return err
+	}
+	bol.DocumentHash, bol.FilePath = hash, relPath
+	return nil
+}
+
+// signatureImages загружает изображения уже поставленных подписей,
+// сверяя их с хешами, записанными при подписании
+func (s *bolService) signatureImages(bol *models.BillOfLading) (map[models.SignatureRole]image.Image, error) {
+	images := map[models.SignatureRole]image.Image{}
+	for _, sig := range bol.Signatures {
+		content, err := os.ReadFile(filepath.Join(s.uploadsDir, sig.ImagePath))
+		if err != nil {
+			return nil, err
+		}
+		if sum := sha256.Sum256(content); hex.EncodeToString(sum[:]) != sig.ImageSHA256 {
+			return nil, fmt.Errorf("%s signature image of bill of lading %s does not match its hash", sig.Role, bol.Number)
+		}
+		img, _, err := image.Decode(bytes.NewReader(content))
+		if err != nil {
+			return nil, err
+		}
+		images[sig.Role] = img
+	}
+	return images, nil
+}
+
+func (s *bolService) fileHash(relPath string) (string, error) {
+	f, err := os.Open(filepath.Join(s.uploadsDir, relPath))
+	if os.IsNotExist(err) {
+		// Пропавший файл — тоже несовпадение
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// readSignature читает и декодирует изображение подписи (PNG или JPEG).
+// Тип определяется по содержимому; размеры проверяются до декодирования
+func readSignature(file io.Reader) ([]byte, image.Image, string, error) {
+	content, err := io.ReadAll(io.LimitReader(file, MaxSignatureBytes+1))
+	if err != nil {
+		return nil, nil, "", err
+	}
+	if len(content) > MaxSignatureBytes {
+		return nil, nil, "", ErrFileTooLarge
+	}
+	ext, ok := signatureTypes[http.DetectContentType(content)]
+	if !ok {
+		return nil, nil, "", ErrUnsupportedFileType
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(content))
+	if err != nil || cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width > maxSignatureSide || cfg.Height > maxSignatureSide {
+		return nil, nil, "", ErrInvalidSignature
+	}
+	img, _, err := image.Decode(bytes.NewReader(content))
+	if err != nil {
+		return nil, nil, "", ErrInvalidSignature
+	}
+	return content, img, ext, nil
+}
@@ -0,0 +1,264 @@
+package services
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"moveshare/internal/models"
+	"moveshare/internal/repository"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const bolJobID = "3f2a9c1e-7b4d-4e8a-9f10-2c6d8e4b1a57"
+
+// fakeBOLRepo хранит накладные в памяти и, как bolRepository, записывает хеш
+// последней версии у работы. Новая версия принимается, только если хеш не
+// изменился с прочтения; concurrent имитирует подпись из параллельного запроса
+type fakeBOLRepo struct {
+	repository.BOLRepository
+	jobs       *fakeJobRepo
+	bols       map[string]*models.BillOfLading
+	concurrent bool
+}
+
+func (r *fakeBOLRepo) CreateBOL(bol *models.BillOfLading) (*models.BillOfLading, error) {
+	if _, ok := r.bols[bol.JobID]; !ok {
+		bol.Signatures = []*models.BOLSignature{}
+		r.bols[bol.JobID] = bol
+		r.jobs.jobs[bol.JobID].BOLHash = bol.DocumentHash
+	}
+	return r.GetBOL(bol.JobID)
+}
+
+func (r *fakeBOLRepo) GetBOL(jobID string) (*models.BillOfLading, error) {
+	bol, ok := r.bols[jobID]
+	if !ok {
+		return nil, repository.ErrBOLNotFound
+	}
+	copied := *bol
+	copied.Signatures = slices.Clone(bol.Signatures)
+	return &copied, nil
+}
+
+func (r *fakeBOLRepo) AddSignature(bol *models.BillOfLading, sig *models.BOLSignature, prevHash string) error {
+	stored := r.bols[bol.JobID]
+	if r.concurrent {
+		stored.DocumentHash = "signed concurrently"
+	}
+	if stored.DocumentHash != prevHash {
+		return repository.ErrBOLChanged
+	}
+	if stored.Signature(sig.Role) != nil {
+		return repository.ErrBOLAlreadySigned
+	}
+	stored.DocumentHash, stored.FilePath = bol.DocumentHash, bol.FilePath
+	stored.Signatures = append(slices.Clone(stored.Signatures), sig)
+	r.jobs.jobs[bol.JobID].BOLHash = bol.DocumentHash
+	return nil
+}
+
+type bolFixture struct {
+	service BOLService
+	repo    *fakeBOLRepo
+	jobs    *fakeJobRepo
+	dir     string
+}
+
+// newBOLFixture — сервис накладных по работе компании Acme Movers в статусе
+// status (автор 1, перевозчик 5)
+func newBOLFixture(t *testing.T, status models.JobStatus) *bolFixture {
+	t.Helper()
+	job := completedJob()
+	job.ID, job.Status, job.JobTitle = bolJobID, status, "2 bedroom move"
+	jobs := newFakeJobRepo(job)
+	f := &bolFixture{
+		repo: &fakeBOLRepo{jobs: jobs, bols: map[string]*models.BillOfLading{}},
+		jobs: jobs,
+		dir:  t.TempDir(),
+	}
+	users := newFakeUserRepo(
+		&models.User{ID: 1, Username: "poster", Email: "poster@example.com"},
+		&models.User{ID: 5, Username: "carrier", Email: "carrier@example.com"},
+	)
+	f.service = NewBOLService(f.repo, jobs, users, newFakeCompanyRepo(nil), newFakeCarrierRepo(), f.dir)
+	return f
+}
+
+func (f *bolFixture) sign(t *testing.T, userID int, role models.SignatureRole) (*models.BillOfLading, error) {
+	t.Helper()
+	return f.service.Sign(bolJobID, userID, role, "Jane Doe", bytes.NewReader(pngBytes(t)), "203.0.113.7")
+}
+
+func (f *bolFixture) setStatus(status models.JobStatus) {
+	f.jobs.jobs[bolJobID].Status = status
+}
+
+func sha256File(t *testing.T, path string) string {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// Накладная выдаётся, когда груз забран, один раз; её хеш записывается у работы
+func TestGetBOLIssuesOnPickup(t *testing.T) {
+	f := newBOLFixture(t, models.JobStatusClaimed)
+	if _, err := f.service.GetBOL(bolJobID, 1); err != ErrBOLNotIssued {
+		t.Fatalf("claimed job: err = %v, want %v", err, ErrBOLNotIssued)
+	}
+	// Работу успели отменить до выдачи — это не ошибка
+	job, _ := f.jobs.GetJobByID(bolJobID)
+	if err := f.service.IssueBOL(job); err != nil {
+		t.Fatalf("IssueBOL on a claimed job: %v", err)
+	}
+
+	f.setStatus(models.JobStatusInTransit)
+	bol, err := f.service.GetBOL(bolJobID, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bol.Number != "BOL-3F2A9C1E7B" || bol.Snapshot.Shipper.Name != "Acme Movers" || bol.Snapshot.Charges != usd(100000) {
+		t.Errorf("issued %+v", bol)
+	}
+	if got := sha256File(t, filepath.Join(f.dir, bol.FilePath)); got != bol.DocumentHash {
+		t.Errorf("file hash = %s, recorded %s", got, bol.DocumentHash)
+	}
+	if f.jobs.jobs[bolJobID].BOLHash != bol.DocumentHash {
+		t.Error("document hash is not recorded on the job")
+	}
+	again, err := f.service.GetBOL(bolJobID, 1)
+	if err != nil || again.ID != bol.ID || again.DocumentHash != bol.DocumentHash {
+		t.Errorf("second request issued another bill of lading: %+v, %v", again, err)
+	}
+	if _, err := f.service.GetBOL(bolJobID, 99); err != ErrJobForbidden {
+		t.Errorf("stranger: err = %v, want %v", err, ErrJobForbidden)
+	}
+}
+
+// Отправитель подписывает первым, пока груз в пути или доставлен; получатель —
+// только после него и только при доставке. Каждая роль подписывает один раз
+func TestSignOrder(t *testing.T) {
+	f := newBOLFixture(t, models.JobStatusInTransit)
+	if _, err := f.sign(t, 1, models.SignatureConsignee); err != ErrShipperNotSigned {
+		t.Errorf("consignee before shipper: err = %v, want %v", err, ErrShipperNotSigned)
+	}
+	issued, _ := f.service.GetBOL(bolJobID, 1)
+
+	bol, err := f.sign(t, 5, models.SignatureShipper)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shipper := bol.Signature(models.SignatureShipper)
+	if shipper == nil || shipper.SignerName != "Jane Doe" || shipper.IP != "203.0.113.7" || *shipper.CapturedBy != 5 {
+		t.Fatalf("shipper signature %+v", shipper)
+	}
+	// Подпись даёт новую версию файла; прежняя остаётся на месте
+	if bol.DocumentHash == issued.DocumentHash || shipper.DocumentHash != bol.DocumentHash {
+		t.Errorf("signature did not produce a new version: %s -> %s", issued.DocumentHash, bol.DocumentHash)
+	}
+	if _, err := os.Stat(filepath.Join(f.dir, issued.FilePath)); err != nil {
+		t.Errorf("previous version was removed: %v", err)
+	}
+	if f.jobs.jobs[bolJobID].BOLHash != bol.DocumentHash {
+		t.Error("job keeps the hash of the previous version")
+	}
+
+	if _, err := f.sign(t, 5, models.SignatureShipper); err != ErrBOLAlreadySigned {
+		t.Errorf("second shipper signature: err = %v, want %v", err, ErrBOLAlreadySigned)
+	}
+	if _, err := f.sign(t, 1, models.SignatureConsignee); err != ErrNotDelivered {
+		t.Errorf("consignee in transit: err = %v, want %v", err, ErrNotDelivered)
+	}
+
+	f.setStatus(models.JobStatusDelivered)
+	bol, err = f.sign(t, 1, models.SignatureConsignee)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bol.Signatures) != 2 || f.repo.bols[bolJobID].DocumentHash != bol.DocumentHash {
+		t.Errorf("consignee signature not saved: %+v", bol)
+	}
+
+	f.setStatus(models.JobStatusCompleted)
+	if _, err := f.sign(t, 1, models.SignatureConsignee); err != ErrBOLNotSignable {
+		t.Errorf("completed job: err = %v, want %v", err, ErrBOLNotSignable)
+	}
+}
+
+func TestSignRejects(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  models.JobStatus
+		userID  int
+		role    models.SignatureRole
+		signer  string
+		content []byte
+		want    error
+	}{
+		{"claimed job", models.JobStatusClaimed, 5, models.SignatureShipper, "Jane Doe", nil, ErrBOLNotIssued},
+		{"cancelled job", models.JobStatusCancelled, 5, models.SignatureShipper, "Jane Doe", nil, ErrBOLNotSignable},
+		{"stranger", models.JobStatusInTransit, 99, models.SignatureShipper, "Jane Doe", nil, ErrJobForbidden},
+		{"unknown role", models.JobStatusInTransit, 5, "witness", "Jane Doe", nil, ErrInvalidSignature},
+		{"blank signer", models.JobStatusInTransit, 5, models.SignatureShipper, "  ", nil, ErrInvalidSignature},
+		{"long signer", models.JobStatusInTransit, 5, models.SignatureShipper, strings.Repeat("a", maxSignerName+1), nil, ErrInvalidSignature},
+		{"not an image", models.JobStatusInTransit, 5, models.SignatureShipper, "Jane Doe", []byte("%PDF-1.4"), ErrUnsupportedFileType},
+		{"too large", models.JobStatusInTransit, 5, models.SignatureShipper, "Jane Doe", make([]byte, MaxSignatureBytes+1), ErrFileTooLarge},
+	}
+	for _, tt := range tests {
+		f := newBOLFixture(t, tt.status)
+		content := tt.content
+		if content == nil {
+			content = pngBytes(t)
+		}
+		if _, err := f.service.Sign(bolJobID, tt.userID, tt.role, tt.signer, bytes.NewReader(content), "203.0.113.7"); err != tt.want {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+// Если накладную успели подписать параллельно, подпись не записывается, а её файлы удаляются
+func TestSignConflict(t *testing.T) {
+	f := newBOLFixture(t, models.JobStatusInTransit)
+	issued, _ := f.service.GetBOL(bolJobID, 1)
+	f.repo.concurrent = true
+	if _, err := f.sign(t, 5, models.SignatureShipper); err != ErrBOLConflict {
+		t.Fatalf("err = %v, want %v", err, ErrBOLConflict)
+	}
+	files, _ := filepath.Glob(filepath.Join(f.dir, "bol", bolJobID, "*"))
+	if len(files) != 1 || files[0] != filepath.Join(f.dir, issued.FilePath) {
+		t.Errorf("files left after the conflict: %v", files)
+	}
+}
+
+// Verify сверяет файлы накладной и подписей с записанными хешами
+func TestVerifyBOL(t *testing.T) {
+	f := newBOLFixture(t, models.JobStatusInTransit)
+	if _, err := f.service.Verify(bolJobID, 1); err != ErrBOLNotIssued {
+		t.Fatalf("not issued: err = %v, want %v", err, ErrBOLNotIssued)
+	}
+	bol, err := f.sign(t, 5, models.SignatureShipper)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := f.service.Verify(bolJobID, 1)
+	if err != nil || !result.Valid || result.FileHash != bol.DocumentHash {
+		t.Fatalf("untouched files: %+v, %v", result, err)
+	}
+
+	os.WriteFile(filepath.Join(f.dir, bol.Signature(models.SignatureShipper).ImagePath), []byte("forged"), 0o640)
+	os.Remove(filepath.Join(f.dir, bol.FilePath))
+	result, err = f.service.Verify(bolJobID, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Valid || !slices.Equal(result.Mismatches, []string{"document", "shipper"}) {
+		t.Errorf("tampered files: %+v", result)
+	}
+}
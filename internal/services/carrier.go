@@ -0,0 +1,289 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log/slog"
+	"moveshare/internal/models"
+	"moveshare/internal/repository"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrCarrierProfileNotFound = errors.New("carrier profile not found")
+	ErrCarrierNotVerified     = errors.New("carrier is not verified")
+	ErrDOTNumberTaken         = errors.New("dot number already registered")
+	ErrCertificateNotFound    = errors.New("insurance certificate not found")
+	ErrAlreadyReviewed        = errors.New("already reviewed")
+	ErrFileTooLarge           = errors.New("file is too large")
+	ErrUnsupportedFileType    = errors.New("unsupported file type")
+)
+
+var (
+	dotNumberRe = regexp.MustCompile(`^[0-9]{1,8}$`)
+	mcNumberRe  = regexp.MustCompile(`^[0-9]{1,8}$`)
+)
+
+// certificateTypes — допустимые форматы сертификата и расширения файлов для них
+var certificateTypes = map[string]string{
+	"application/pdf": ".pdf",
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+}
+
+// CarrierService ведёт профили перевозчиков (USDOT/MC), их страховые
+// сертификаты и проверку администратором. Брать работы могут только
+// проверенные перевозчики — см. IsCarrierVerified в JobService.
+type CarrierService interface {
+	SaveProfile(userID int, req models.CarrierProfileRequest) (*models.CarrierProfile, error)
+	GetProfile(userID int) (*models.CarrierProfile, error)
+	UploadCertificate(userID int, file io.Reader, fileName string, coverage models.Money, expiresAt time.Time) (*models.InsuranceCertificate, error)
+	GetCertificates(userID int) ([]*models.InsuranceCertificate, error)
+	OpenCertificate(id string) (*models.InsuranceCertificate, *os.File, error)
+	ReviewQueue() (*models.CarrierReviewQueue, error)
+	ReviewProfile(adminID, userID int, req models.ReviewRequest) (*models.CarrierProfile, error)
+	ReviewCertificate(adminID int, id string, req models.ReviewRequest) (*models.InsuranceCertificate, error)
+	ExpireInsurance() error
+}
+
+type carrierService struct {
+	repo       repository.CarrierRepository
+	uploadsDir string
+	maxBytes   int64
+}
+
+func NewCarrierService(repo repository.CarrierRepository, uploadsDir string, maxBytes int64) CarrierService {
+	return &carrierService{repo: repo, uploadsDir: uploadsDir, maxBytes: maxBytes}
+}
+
+// SaveProfile создаёт или обновляет профиль. Изменение номеров отправляет
+// профиль на повторную проверку
+func (s *carrierService) SaveProfile(userID int, req models.CarrierProfileRequest) (*models.CarrierProfile, error) {
+	profile := &models.CarrierProfile{
+		UserID:    userID,
+		LegalName: strings.TrimSpace(req.LegalName),
+		DOTNumber: normalizeCarrierNumber(req.DOTNumber, "USDOT", "DOT"),
+		MCNumber:  normalizeCarrierNumber(req.MCNumber, "MC"),
+	}
+	if profile.LegalName == "" || !dotNumberRe.MatchString(profile.DOTNumber) {
+		return nil, ErrInvalidInput
+	}
+	if profile.MCNumber != "" && !mcNumberRe.MatchString(profile.MCNumber) {
+		return nil, ErrInvalidInput
+	}
+	profile, err := s.repo.UpsertProfile(profile)
+	if err != nil {
+		return nil, mapCarrierError(err)
+	}
+	return s.withVerification(profile)
+}
+
+func (s *carrierService) GetProfile(userID int) (*models.CarrierProfile, error) {
+	profile, err := s.repo.GetProfile(userID)
+	if err != nil {
+		return nil, mapCarrierError(err)
+	}
+	if profile, err = s.withVerification(profile); err != nil {
+		return nil, err
+	}
+	if profile.Certificates, err = s.repo.GetCertificatesByCarrier(userID); err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
+
+// UploadCertificate сохраняет сертификат страхования (PDF, JPEG или PNG)
+// и отправляет его на проверку
+func (s *carrierService) UploadCertificate(userID int, file io.Reader, fileName string, coverage models.Money, expiresAt time.Time) (*models.InsuranceCertificate, error) {
+	coverage = coverage.WithDefaultCurrency()
+	if coverage.Amount <= 0 || !coverage.Valid() || !expiresAt.After(time.Now()) {
+		return nil, ErrInvalidInput
+	}
+	if _, err := s.repo.GetProfile(userID); err != nil {
+		return nil, mapCarrierError(err)
+	}
+
+	// Тип определяем по содержимому, а не по имени файла или заголовку клиента
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		if err == io.EOF {
+			return nil, ErrInvalidInput
+		}
+		return nil, err
+	}
+	head = head[:n]
+	contentType := http.DetectContentType(head)
+	ext, ok := certificateTypes[contentType]
+	if !ok {
+		return nil, ErrUnsupportedFileType
+	}
+
+	id := uuid.New().String()
+	relPath := filepath.Join("insurance", id+ext)
+	if err := s.store(relPath, io.MultiReader(bytes.NewReader(head), file)); err != nil {
+		return nil, err
+	}
+
+	cert, err := s.repo.CreateCertificate(&models.InsuranceCertificate{
+		ID:             id,
+		CarrierID:      userID,
+		FilePath:       relPath,
+		FileName:       filepath.Base(fileName),
+		ContentType:    contentType,
+		CoverageAmount: coverage,
+		ExpiresAt:      expiresAt,
+	})
+	if err != nil {
+		os.Remove(filepath.Join(s.uploadsDir, relPath))
+		return nil, err
+	}
+	return cert, nil
+}
+
+// store пишет файл в каталог загрузок, не допуская превышения лимита размера
+func (s *carrierService) store(relPath string, r io.Reader) error {
+	path := filepath.Join(s.uploadsDir, relPath)
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o640)
+	if err != nil {
+		return err
+	}
+	n, err := io.Copy(f, io.LimitReader(r, s.maxBytes+1))
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil && n > s.maxBytes {
+		err = ErrFileTooLarge
+	}
+	if err != nil {
+		os.Remove(path)
+		return err
+	}
+	return nil
+}
+
+func (s *carrierService) GetCertificates(userID int) ([]*models.InsuranceCertificate, error) {
+	return s.repo.GetCertificatesByCarrier(userID)
+}
+
+// OpenCertificate открывает файл сертификата; закрыть его должен вызывающий
+func (s *carrierService) OpenCertificate(id string) (*models.InsuranceCertificate, *os.File, error) {
+	cert, err := s.repo.GetCertificate(id)
+	if err != nil {
+		return nil, nil, mapCarrierError(err)
+	}
+	f, err := os.Open(filepath.Join(s.uploadsDir, cert.FilePath))
+	if err != nil {
+		return nil, nil, err
+	}
+	return cert, f, nil
+}
+
+func (s *carrierService) ReviewQueue() (*models.CarrierReviewQueue, error) {
+	profiles, err := s.repo.GetProfilesByStatus(models.CarrierPending)
+	if err != nil {
+		return nil, err
+	}
+	certs, err := s.repo.GetCertificatesByStatus(models.InsurancePending)
+	if err != nil {
+		return nil, err
+	}
+	return &models.CarrierReviewQueue{Profiles: profiles, Certificates: certs}, nil
+}
+
+func (s *carrierService) ReviewProfile(adminID, userID int, req models.ReviewRequest) (*models.CarrierProfile, error) {
+	var status models.CarrierStatus
+	switch req.Decision {
+	case models.ReviewApprove:
+		status = models.CarrierApproved
+	case models.ReviewReject:
+		status = models.CarrierRejected
+	default:
+		return nil, ErrInvalidInput
+	}
+	profile, err := s.repo.ReviewProfile(userID, status, adminID, req.Note)
+	if err != nil {
+		return nil, mapCarrierError(err)
+	}
+	return s.withVerification(profile)
+}
+
+func (s *carrierService) ReviewCertificate(adminID int, id string, req models.ReviewRequest) (*models.InsuranceCertificate, error) {
+	var status models.InsuranceStatus
+	switch req.Decision {
+	case models.ReviewApprove:
+		status = models.InsuranceApproved
+	case models.ReviewReject:
+		status = models.InsuranceRejected
+	default:
+		return nil, ErrInvalidInput
+	}
+	cert, err := s.repo.ReviewCertificate(id, status, adminID, req.Note)
+	if err != nil {
+		return nil, mapCarrierError(err)
+	}
+	return cert, nil
+}
+
+// ExpireInsurance — периодическая задача: помечает просроченные сертификаты
+// и приостанавливает перевозчиков без действующей страховки
+func (s *carrierService) ExpireInsurance() error {
+	expired, suspended, err := s.repo.ExpireCertificates(time.Now())
+	if err != nil {
+		return err
+	}
+	if expired > 0 || suspended > 0 {
+		slog.Info("Insurance expiry check",
+			slog.Int("expired_certificates", expired),
+			slog.Int("suspended_carriers", suspended))
+	}
+	return nil
+}
+
+func (s *carrierService) withVerification(profile *models.CarrierProfile) (*models.CarrierProfile, error) {
+	verified, err := s.repo.IsCarrierVerified(profile.UserID)
+	if err != nil {
+		return nil, err
+	}
+	profile.Verified = verified
+	return profile, nil
+}
+
+// normalizeCarrierNumber убирает пробелы, дефисы и необязательный префикс
+// ("USDOT 1234567" -> "1234567", "MC-123456" -> "123456")
+func normalizeCarrierNumber(number string, prefixes ...string) string {
+	number = strings.ToUpper(strings.TrimSpace(number))
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(number, prefix) {
+			number = strings.TrimPrefix(number, prefix)
+			break
+		}
+	}
+	return strings.NewReplacer(" ", "", "-", "", "#", "").Replace(number)
+}
+
+func mapCarrierError(err error) error {
+	switch {
+	case errors.Is(err, repository.ErrCarrierProfileNotFound):
+		return ErrCarrierProfileNotFound
+	case errors.Is(err, repository.ErrDOTNumberTaken):
+		return ErrDOTNumberTaken
+	case errors.Is(err, repository.ErrCertificateNotFound):
+		return ErrCertificateNotFound
+	case errors.Is(err, repository.ErrAlreadyReviewed):
+		return ErrAlreadyReviewed
+	default:
+		return err
+	}
+}
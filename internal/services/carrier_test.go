@@ -0,0 +1,251 @@
+package services
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"moveshare/internal/models"
+	"moveshare/internal/repository"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeCarrierRepo хранит профили и сертификаты в памяти. Перевозчик считается
+// проверенным, если профиль одобрен и есть одобренный непросроченный сертификат
+type fakeCarrierRepo struct {
+	repository.CarrierRepository
+	profiles map[int]*models.CarrierProfile
+	certs    map[string]*models.InsuranceCertificate
+}
+
+func newFakeCarrierRepo() *fakeCarrierRepo {
+	return &fakeCarrierRepo{profiles: map[int]*models.CarrierProfile{}, certs: map[string]*models.InsuranceCertificate{}}
+}
+
+func (r *fakeCarrierRepo) UpsertProfile(profile *models.CarrierProfile) (*models.CarrierProfile, error) {
+	for _, p := range r.profiles {
+		if p.UserID != profile.UserID && p.DOTNumber == profile.DOTNumber {
+			return nil, repository.ErrDOTNumberTaken
+		}
+	}
+	profile.Status = models.CarrierPending
+	if old, ok := r.profiles[profile.UserID]; ok && old.DOTNumber == profile.DOTNumber && old.MCNumber == profile.MCNumber {
+		profile.Status = old.Status
+	}
+	r.profiles[profile.UserID] = profile
+	copied := *profile
+	return &copied, nil
+}
+
+func (r *fakeCarrierRepo) GetProfile(userID int) (*models.CarrierProfile, error) {
+	p, ok := r.profiles[userID]
+	if !ok {
+		return nil, repository.ErrCarrierProfileNotFound
+	}
+	copied := *p
+	return &copied, nil
+}
+
+func (r *fakeCarrierRepo) ReviewProfile(userID int, status models.CarrierStatus, reviewerID int, note string) (*models.CarrierProfile, error) {
+	p, ok := r.profiles[userID]
+	if !ok {
+		return nil, repository.ErrCarrierProfileNotFound
+	}
+	p.Status, p.ReviewedBy, p.ReviewNote = status, &reviewerID, note
+	return r.GetProfile(userID)
+}
+
+func (r *fakeCarrierRepo) IsCarrierVerified(userID int) (bool, error) {
+	p, ok := r.profiles[userID]
+	if !ok || p.Status != models.CarrierApproved {
+		return false, nil
+	}
+	for _, cert := range r.certs {
+		if cert.CarrierID == userID && cert.Status == models.InsuranceApproved && cert.ExpiresAt.After(time.Now()) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *fakeCarrierRepo) CreateCertificate(cert *models.InsuranceCertificate) (*models.InsuranceCertificate, error) {
+	cert.Status = models.InsurancePending
+	r.certs[cert.ID] = cert
+	return cert, nil
+}
+
+func (r *fakeCarrierRepo) GetCertificatesByCarrier(carrierID int) ([]*models.InsuranceCertificate, error) {
+	var certs []*models.InsuranceCertificate
+	for _, cert := range r.certs {
+		if cert.CarrierID == carrierID {
+			certs = append(certs, cert)
+		}
+	}
+	return certs, nil
+}
+
+func (r *fakeCarrierRepo) ReviewCertificate(id string, status models.InsuranceStatus, reviewerID int, note string) (*models.InsuranceCertificate, error) {
+	cert, ok := r.certs[id]
+	if !ok {
+		return nil, repository.ErrCertificateNotFound
+	}
+	if cert.Status != models.InsurancePending {
+		return nil, repository.ErrAlreadyReviewed
+	}
+	cert.Status = status
+	return cert, nil
+}
+
+func TestNormalizeCarrierNumber(t *testing.T) {
+	tests := []struct {
+		number   string
+		prefixes []string
+		want     string
+	}{
+		{"1234567", []string{"USDOT", "DOT"}, "1234567"},
+		{"USDOT 1234567", []string{"USDOT", "DOT"}, "1234567"},
+		{" usdot#1234567 ", []string{"USDOT", "DOT"}, "1234567"},
+		{"DOT-123 4567", []string{"USDOT", "DOT"}, "1234567"},
+		{"MC-123456", []string{"MC"}, "123456"},
+		{"mc 123456", []string{"MC"}, "123456"},
+		{"", []string{"MC"}, ""},
+	}
+	for _, tt := range tests {
+		if got := normalizeCarrierNumber(tt.number, tt.prefixes...); got != tt.want {
+			t.Errorf("normalizeCarrierNumber(%q) = %q, want %q", tt.number, got, tt.want)
+		}
+	}
+}
+
+func TestSaveCarrierProfile(t *testing.T) {
+	tests := []struct {
+		name string
+		req  models.CarrierProfileRequest
+		want error
+	}{
+		{"with MC number", models.CarrierProfileRequest{LegalName: "Acme Movers LLC", DOTNumber: "USDOT 1234567", MCNumber: "MC-123456"}, nil},
+		{"without MC number", models.CarrierProfileRequest{LegalName: "Acme Movers LLC", DOTNumber: "1234567"}, nil},
+		{"no legal name", models.CarrierProfileRequest{LegalName: " ", DOTNumber: "1234567"}, ErrInvalidInput},
+		{"no DOT number", models.CarrierProfileRequest{LegalName: "Acme Movers LLC"}, ErrInvalidInput},
+		{"DOT number too long", models.CarrierProfileRequest{LegalName: "Acme Movers LLC", DOTNumber: "123456789"}, ErrInvalidInput},
+		{"letters in MC number", models.CarrierProfileRequest{LegalName: "Acme Movers LLC", DOTNumber: "1234567", MCNumber: "MC-12A"}, ErrInvalidInput},
+	}
+	for _, tt := range tests {
+		svc := NewCarrierService(newFakeCarrierRepo(), t.TempDir(), 1<<20)
+		if _, err := svc.SaveProfile(5, tt.req); err != tt.want {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+
+	repo := newFakeCarrierRepo()
+	svc := NewCarrierService(repo, t.TempDir(), 1<<20)
+	svc.SaveProfile(5, models.CarrierProfileRequest{LegalName: "Acme Movers LLC", DOTNumber: "1234567"})
+	if _, err := svc.SaveProfile(6, models.CarrierProfileRequest{LegalName: "Other LLC", DOTNumber: "USDOT 1234567"}); err != ErrDOTNumberTaken {
+		t.Errorf("duplicate DOT number: err = %v, want ErrDOTNumberTaken", err)
+	}
+}
+
+func pngBytes(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 4, 4))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestUploadCertificate(t *testing.T) {
+	pdf := []byte("%PDF-1.4\n1 0 obj\n<<>>\nendobj\ntrailer\n<<>>\n%%EOF\n")
+	nextYear := time.Now().AddDate(1, 0, 0)
+	tests := []struct {
+		name      string
+		content   []byte
+		coverage  models.Money
+		expiresAt time.Time
+		want      error
+		ext       string
+	}{
+		{"pdf", pdf, usd(100000000), nextYear, nil, ".pdf"},
+		{"png", pngBytes(t), usd(100000000), nextYear, nil, ".png"},
+		{"plain text", []byte("not a certificate"), usd(100000000), nextYear, ErrUnsupportedFileType, ""},
+		{"empty file", nil, usd(100000000), nextYear, ErrInvalidInput, ""},
+		{"too large", append(pdf, bytes.Repeat([]byte{' '}, 2048)...), usd(100000000), nextYear, ErrFileTooLarge, ""},
+		{"already expired", pdf, usd(100000000), time.Now().Add(-time.Hour), ErrInvalidInput, ""},
+		{"no coverage", pdf, usd(0), nextYear, ErrInvalidInput, ""},
+	}
+	for _, tt := range tests {
+		dir := t.TempDir()
+		repo := newFakeCarrierRepo()
+		repo.profiles[5] = &models.CarrierProfile{UserID: 5, LegalName: "Acme Movers LLC", DOTNumber: "1234567"}
+		svc := NewCarrierService(repo, dir, 1024)
+
+		cert, err := svc.UploadCertificate(5, bytes.NewReader(tt.content), "../../coi"+tt.ext, tt.coverage, tt.expiresAt)
+		if err != tt.want {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.want)
+			continue
+		}
+		files, _ := filepath.Glob(filepath.Join(dir, "insurance", "*"))
+		if err != nil {
+			if len(files) != 0 {
+				t.Errorf("%s: rejected upload left files %v", tt.name, files)
+			}
+			continue
+		}
+		if cert.Status != models.InsurancePending || cert.FileName != "coi"+tt.ext || !strings.HasSuffix(cert.FilePath, tt.ext) {
+			t.Errorf("%s: certificate = %+v", tt.name, cert)
+		}
+		stored, _ := os.ReadFile(filepath.Join(dir, cert.FilePath))
+		if !bytes.Equal(stored, tt.content) {
+			t.Errorf("%s: stored %d bytes, want %d", tt.name, len(stored), len(tt.content))
+		}
+	}
+
+	svc := NewCarrierService(newFakeCarrierRepo(), t.TempDir(), 1024)
+	if _, err := svc.UploadCertificate(5, bytes.NewReader(pdf), "coi.pdf", usd(100000000), nextYear); err != ErrCarrierProfileNotFound {
+		t.Errorf("no profile: err = %v, want ErrCarrierProfileNotFound", err)
+	}
+}
+
+// Перевозчик проверен, когда одобрены и профиль, и страховка; смена номеров
+// отправляет профиль на повторную проверку
+func TestCarrierVerification(t *testing.T) {
+	repo := newFakeCarrierRepo()
+	svc := NewCarrierService(repo, t.TempDir(), 1<<20)
+	req := models.CarrierProfileRequest{LegalName: "Acme Movers LLC", DOTNumber: "1234567"}
+	if _, err := svc.SaveProfile(5, req); err != nil {
+		t.Fatal(err)
+	}
+	cert, err := svc.UploadCertificate(5, bytes.NewReader(pngBytes(t)), "coi.png", usd(100000000), time.Now().AddDate(1, 0, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := svc.ReviewProfile(1, 5, models.ReviewRequest{Decision: "maybe"}); err != ErrInvalidInput {
+		t.Errorf("unknown decision: err = %v, want ErrInvalidInput", err)
+	}
+	profile, err := svc.ReviewProfile(1, 5, models.ReviewRequest{Decision: models.ReviewApprove})
+	if err != nil || profile.Status != models.CarrierApproved || profile.Verified {
+		t.Errorf("approved profile without insurance = %+v, %v", profile, err)
+	}
+	if _, err := svc.ReviewCertificate(1, cert.ID, models.ReviewRequest{Decision: models.ReviewApprove}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.ReviewCertificate(1, cert.ID, models.ReviewRequest{Decision: models.ReviewReject}); err != ErrAlreadyReviewed {
+		t.Errorf("second review: err = %v, want ErrAlreadyReviewed", err)
+	}
+	if profile, _ := svc.GetProfile(5); !profile.Verified {
+		t.Errorf("approved profile and insurance: profile = %+v", profile)
+	}
+
+	req.LegalName = "Acme Movers Inc"
+	if profile, _ := svc.SaveProfile(5, req); profile.Status != models.CarrierApproved || !profile.Verified {
+		t.Errorf("renamed profile = %+v, want it to stay approved", profile)
+	}
+	req.DOTNumber = "7654321"
+	if profile, _ := svc.SaveProfile(5, req); profile.Status != models.CarrierPending || profile.Verified {
+		t.Errorf("profile with a new DOT number = %+v, want it back in review", profile)
+	}
+}
@@ -0,0 +1,248 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"moveshare/internal/mailer"
+	"moveshare/internal/models"
+	"moveshare/internal/repository"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// InvitationTTL — сколько действует приглашение в компанию
+const InvitationTTL = 7 * 24 * time.Hour
+
+var (
+	ErrCompanyNotFound         = errors.New("company not found")
+	ErrCompanyForbidden        = errors.New("not allowed to manage this company")
+	ErrMemberNotFound          = errors.New("company member not found")
+	ErrLastOwner               = errors.New("company must keep at least one owner")
+	ErrInvalidInvitation       = errors.New("invitation is invalid, used or expired")
+	ErrInvitationEmailMismatch = errors.New("invitation was sent to another email")
+)
+
+// CompanyService управляет компаниями, их сотрудниками и приглашениями.
+// Владелец (owner) управляет составом и ролями, диспетчер (dispatcher)
+// управляет объявлениями компании и приглашает водителей (driver).
+type CompanyService interface {
+	CreateCompany(userID int, req models.CreateCompanyRequest) (*models.Company, error)
+	GetMyCompanies(userID int) ([]*models.Company, error)
+	GetMembers(companyID, userID int) ([]*models.CompanyMember, error)
+	UpdateMemberRole(companyID, actorID, memberID int, role models.CompanyRole) (*models.CompanyMember, error)
+	RemoveMember(companyID, actorID, memberID int) error
+	Invite(companyID, inviterID int, req models.InviteMemberRequest) (*models.CompanyInvitation, error)
+	GetInvitations(companyID, userID int) ([]*models.CompanyInvitation, error)
+	AcceptInvitation(userID int, token string) (*models.CompanyMember, error)
+	SwitchCompany(userID int, companyID *int) (*models.LoginResponse, error)
+}
+
+type companyService struct {
+	companyRepo  repository.CompanyRepository
+	userRepo     repository.UserRepository
+	tokenService TokenService
+	mailer       mailer.Mailer
+	appBaseURL   string
+}
+
+func NewCompanyService(companyRepo repository.CompanyRepository, userRepo repository.UserRepository, tokenService TokenService, m mailer.Mailer, appBaseURL string) CompanyService {
+	return &companyService{
+		companyRepo:  companyRepo,
+		userRepo:     userRepo,
+		tokenService: tokenService,
+		mailer:       m,
+		appBaseURL:   appBaseURL,
+	}
+}
+
+func (s *companyService) CreateCompany(userID int, req models.CreateCompanyRequest) (*models.Company, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, ErrInvalidInput
+	}
+	return s.companyRepo.CreateCompany(&models.Company{Name: name}, userID)
+}
+
+func (s *companyService) GetMyCompanies(userID int) ([]*models.Company, error) {
+	return s.companyRepo.GetCompaniesByUser(userID)
+}
+
+// GetMembers доступен любому сотруднику компании
+func (s *companyService) GetMembers(companyID, userID int) ([]*models.CompanyMember, error) {
+	if _, err := s.member(companyID, userID); err != nil {
+		return nil, err
+	}
+	return s.companyRepo.GetMembers(companyID)
+}
+
+// UpdateMemberRole — только владелец; последнего владельца понизить нельзя
+func (s *companyService) UpdateMemberRole(companyID, actorID, memberID int, role models.CompanyRole) (*models.CompanyMember, error) {
+	if !role.Valid() {
+		return nil, ErrInvalidRole
+	}
+	if err := s.requireRole(companyID, actorID, models.CompanyOwner); err != nil {
+		return nil, err
+	}
+	member, err := s.companyRepo.UpdateMemberRole(companyID, memberID, role)
+	if err != nil {
+		return nil, mapCompanyError(err)
+	}
+	return member, nil
+}
+
+// RemoveMember — владелец исключает сотрудника, либо сотрудник сам покидает компанию
+func (s *companyService) RemoveMember(companyID, actorID, memberID int) error {
+	if actorID != memberID {
+		if err := s.requireRole(companyID, actorID, models.CompanyOwner); err != nil {
+			return err
+		}
+	}
+	if err := s.companyRepo.RemoveMember(companyID, memberID); err != nil {
+		return mapCompanyError(err)
+	}
+	return nil
+}
+
+// Invite отправляет приглашение на email. Владелец может пригласить с любой
+// ролью, диспетчер — только водителя.
+func (s *companyService) Invite(companyID, inviterID int, req models.InviteMemberRequest) (*models.CompanyInvitation, error) {
+	email := strings.TrimSpace(req.Email)
+	if !validEmail(email) {
+		return nil, ErrInvalidInput
+	}
+	if !req.Role.Valid() {
+		return nil, ErrInvalidRole
+	}
+	inviter, err := s.member(companyID, inviterID)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case inviter.Role == models.CompanyOwner:
+	case inviter.Role == models.CompanyDispatcher && req.Role == models.CompanyDriver:
+	default:
+		return nil, ErrCompanyForbidden
+	}
+	company, err := s.companyRepo.GetCompanyByID(companyID)
+	if err != nil {
+		return nil, mapCompanyError(err)
+	}
+
+	raw, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	inv, err := s.companyRepo.CreateInvitation(&models.CompanyInvitation{
+		ID:        uuid.New().String(),
+		CompanyID: companyID,
+		Email:     email,
+		Role:      req.Role,
+		TokenHash: hashToken(raw),
+		InvitedBy: inviterID,
+		ExpiresAt: time.Now().Add(InvitationTTL),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.mailer.Send(mailer.Message{
+		To:      email,
+		Subject: fmt.Sprintf("You are invited to join %s on MoveShare", company.Name),
+		Body: fmt.Sprintf(
+			"Hi,\n\n%s invited you to join %s on MoveShare as %s.\n\nTo accept, sign in with this email address and open this link within 7 days:\n\n%s\n",
+			inviter.Username, company.Name, req.Role,
+			s.appBaseURL+"/invitations/accept?token="+url.QueryEscape(raw),
+		),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return inv, nil
+}
+
+// GetInvitations — неотвеченные приглашения, видны владельцам и диспетчерам
+func (s *companyService) GetInvitations(companyID, userID int) ([]*models.CompanyInvitation, error) {
+	if err := s.requireRole(companyID, userID, models.CompanyOwner, models.CompanyDispatcher); err != nil {
+		return nil, err
+	}
+	return s.companyRepo.GetPendingInvitations(companyID)
+}
+
+func (s *companyService) AcceptInvitation(userID int, token string) (*models.CompanyMember, error) {
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	member, err := s.companyRepo.AcceptInvitation(hashToken(token), user)
+	if err != nil {
+		return nil, mapCompanyError(err)
+	}
+	return member, nil
+}
+
+// SwitchCompany меняет активную компанию (nil или 0 — работать от своего имени)
+// и выдаёт новую пару токенов, в которой она указана
+func (s *companyService) SwitchCompany(userID int, companyID *int) (*models.LoginResponse, error) {
+	if companyID != nil && *companyID == 0 {
+		companyID = nil
+	}
+	if companyID != nil {
+		if _, err := s.member(*companyID, userID); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.companyRepo.SetActiveCompany(userID, companyID); err != nil {
+		return nil, err
+	}
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.tokenService.IssueTokens(user)
+}
+
+// member возвращает сотрудника компании. Для посторонних компания неотличима
+// от несуществующей
+func (s *companyService) member(companyID, userID int) (*models.CompanyMember, error) {
+	m, err := s.companyRepo.GetMember(companyID, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrMemberNotFound) {
+			return nil, ErrCompanyNotFound
+		}
+		return nil, err
+	}
+	return m, nil
+}
+
+func (s *companyService) requireRole(companyID, userID int, roles ...models.CompanyRole) error {
+	m, err := s.member(companyID, userID)
+	if err != nil {
+		return err
+	}
+	for _, role := range roles {
+		if m.Role == role {
+			return nil
+		}
+	}
+	return ErrCompanyForbidden
+}
+
+func mapCompanyError(err error) error {
+	switch {
+	case errors.Is(err, repository.ErrCompanyNotFound):
+		return ErrCompanyNotFound
+	case errors.Is(err, repository.ErrMemberNotFound):
+		return ErrMemberNotFound
+	case errors.Is(err, repository.ErrLastOwner):
+		return ErrLastOwner
+	case errors.Is(err, repository.ErrInvitationInvalid):
+		return ErrInvalidInvitation
+	case errors.Is(err, repository.ErrInvitationEmailMismatch):
+		return ErrInvitationEmailMismatch
+	default:
+		return err
+	}
+}
@@ -0,0 +1,301 @@
+package services
+
+import (
+	"moveshare/internal/models"
+	"moveshare/internal/repository"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeCompanyRepo хранит одну компанию в памяти и повторяет условия SQL в companyRepository
+type fakeCompanyRepo struct {
+	repository.CompanyRepository
+	company     *models.Company
+	members     map[int]models.CompanyRole
+	invitations map[string]*models.CompanyInvitation
+	active      map[int]*int
+}
+
+func newFakeCompanyRepo(members map[int]models.CompanyRole) *fakeCompanyRepo {
+	return &fakeCompanyRepo{
+		company:     &models.Company{ID: 10, Name: "Acme Movers"},
+		members:     members,
+		invitations: map[string]*models.CompanyInvitation{},
+		active:      map[int]*int{},
+	}
+}
+
+func (r *fakeCompanyRepo) GetCompanyByID(id int) (*models.Company, error) {
+	if id != r.company.ID {
+		return nil, repository.ErrCompanyNotFound
+	}
+	return r.company, nil
+}
+
+func (r *fakeCompanyRepo) GetMember(companyID, userID int) (*models.CompanyMember, error) {
+	role, ok := r.members[userID]
+	if companyID != r.company.ID || !ok {
+		return nil, repository.ErrMemberNotFound
+	}
+	return &models.CompanyMember{CompanyID: companyID, UserID: userID, Role: role}, nil
+}
+
+func (r *fakeCompanyRepo) lastOwner(userID int) error {
+	role, ok := r.members[userID]
+	if !ok {
+		return repository.ErrMemberNotFound
+	}
+	owners := 0
+	for _, role := range r.members {
+		if role == models.CompanyOwner {
+			owners++
+		}
+	}
+	if role == models.CompanyOwner && owners == 1 {
+		return repository.ErrLastOwner
+	}
+	return nil
+}
+
+func (r *fakeCompanyRepo) UpdateMemberRole(companyID, userID int, role models.CompanyRole) (*models.CompanyMember, error) {
+	if err := r.lastOwner(userID); err != nil && (err != repository.ErrLastOwner || role != models.CompanyOwner) {
+		return nil, err
+	}
+	r.members[userID] = role
+	return r.GetMember(companyID, userID)
+}
+
+func (r *fakeCompanyRepo) RemoveMember(companyID, userID int) error {
+	if err := r.lastOwner(userID); err != nil {
+		return err
+	}
+	delete(r.members, userID)
+	return nil
+}
+
+func (r *fakeCompanyRepo) SetActiveCompany(userID int, companyID *int) error {
+	r.active[userID] = companyID
+	return nil
+}
+
+func (r *fakeCompanyRepo) CreateInvitation(inv *models.CompanyInvitation) (*models.CompanyInvitation, error) {
+	r.invitations[inv.TokenHash] = inv
+	return inv, nil
+}
+
+func (r *fakeCompanyRepo) AcceptInvitation(tokenHash string, user *models.User) (*models.CompanyMember, error) {
+	inv, ok := r.invitations[tokenHash]
+	if !ok || inv.AcceptedAt != nil || !inv.ExpiresAt.After(time.Now()) {
+		return nil, repository.ErrInvitationInvalid
+	}
+	if !strings.EqualFold(inv.Email, user.Email) {
+		return nil, repository.ErrInvitationEmailMismatch
+	}
+	if _, member := r.members[user.ID]; !member {
+		r.members[user.ID] = inv.Role
+	}
+	now := time.Now()
+	inv.AcceptedAt = &now
+	return r.GetMember(inv.CompanyID, user.ID)
+}
+
+const (
+	companyOwner      = 1
+	companyDispatcher = 2
+	companyDriver     = 3
+	outsider          = 4
+)
+
+func newTestCompanyService(t *testing.T) (*companyService, *fakeCompanyRepo, *fakeMailer) {
+	t.Helper()
+	companies := newFakeCompanyRepo(map[int]models.CompanyRole{
+		companyOwner:      models.CompanyOwner,
+		companyDispatcher: models.CompanyDispatcher,
+		companyDriver:     models.CompanyDriver,
+	})
+	users := newFakeUserRepo(
+		&models.User{ID: companyOwner, Email: "owner@example.com"},
+		&models.User{ID: outsider, Email: "Driver@Example.com"},
+	)
+	tokens := newFakeTokenRepo()
+	tokens.users = users
+	m := &fakeMailer{}
+	svc := NewCompanyService(companies, users, NewTokenService(newTestJWTService(t), tokens, users), m, "https://app.example.com")
+	return svc.(*companyService), companies, m
+}
+
+// Владелец приглашает с любой ролью, диспетчер — только водителей
+func TestInviteRights(t *testing.T) {
+	tests := []struct {
+		inviter int
+		role    models.CompanyRole
+		want    error
+	}{
+		{companyOwner, models.CompanyOwner, nil},
+		{companyOwner, models.CompanyDispatcher, nil},
+		{companyDispatcher, models.CompanyDriver, nil},
+		{companyDispatcher, models.CompanyDispatcher, ErrCompanyForbidden},
+		{companyDriver, models.CompanyDriver, ErrCompanyForbidden},
+		{outsider, models.CompanyDriver, ErrCompanyNotFound},
+		{companyOwner, "manager", ErrInvalidRole},
+	}
+	for _, tt := range tests {
+		svc, _, m := newTestCompanyService(t)
+		_, err := svc.Invite(10, tt.inviter, models.InviteMemberRequest{Email: "driver@example.com", Role: tt.role})
+		if err != tt.want {
+			t.Errorf("user %d invites %s: err = %v, want %v", tt.inviter, tt.role, err, tt.want)
+		}
+		if sent := len(m.sent); (err == nil) != (sent == 1) {
+			t.Errorf("user %d invites %s: %d emails sent", tt.inviter, tt.role, sent)
+		}
+	}
+}
+
+func TestAcceptInvitation(t *testing.T) {
+	svc, companies, m := newTestCompanyService(t)
+	if _, err := svc.Invite(10, companyDispatcher, models.InviteMemberRequest{Email: "driver@example.com", Role: models.CompanyDriver}); err != nil {
+		t.Fatal(err)
+	}
+	if len(m.sent) != 1 || m.sent[0].To != "driver@example.com" || !strings.Contains(m.sent[0].Subject, "Acme Movers") {
+		t.Fatalf("invitation email = %+v", m.sent)
+	}
+	token := linkToken(t, m.sent[0])
+	for hash := range companies.invitations {
+		if hash == token {
+			t.Fatal("invitation token is stored in plain text")
+		}
+	}
+
+	if _, err := svc.AcceptInvitation(companyOwner, token); err != ErrInvitationEmailMismatch {
+		t.Errorf("accepted by another email: err = %v, want ErrInvitationEmailMismatch", err)
+	}
+	member, err := svc.AcceptInvitation(outsider, token)
+	if err != nil || member.Role != models.CompanyDriver {
+		t.Fatalf("accept = %+v, %v", member, err)
+	}
+	if _, err := svc.AcceptInvitation(outsider, token); err != ErrInvalidInvitation {
+		t.Errorf("accepted twice: err = %v, want ErrInvalidInvitation", err)
+	}
+	if _, err := svc.AcceptInvitation(outsider, "forged"); err != ErrInvalidInvitation {
+		t.Errorf("unknown token: err = %v, want ErrInvalidInvitation", err)
+	}
+}
+
+func TestAcceptExpiredInvitation(t *testing.T) {
+	svc, companies, m := newTestCompanyService(t)
+	svc.Invite(10, companyOwner, models.InviteMemberRequest{Email: "driver@example.com", Role: models.CompanyDriver})
+	for _, inv := range companies.invitations {
+		inv.ExpiresAt = time.Now().Add(-time.Minute)
+	}
+	if _, err := svc.AcceptInvitation(outsider, linkToken(t, m.sent[0])); err != ErrInvalidInvitation {
+		t.Errorf("expired invitation: err = %v, want ErrInvalidInvitation", err)
+	}
+}
+
+func TestCompanyMembership(t *testing.T) {
+	tests := []struct {
+		name string
+		do   func(svc *companyService) error
+		want error
+	}{
+		{"owner promotes the dispatcher", func(svc *companyService) error {
+			_, err := svc.UpdateMemberRole(10, companyOwner, companyDispatcher, models.CompanyOwner)
+			return err
+		}, nil},
+		{"sole owner demotes themselves", func(svc *companyService) error {
+			_, err := svc.UpdateMemberRole(10, companyOwner, companyOwner, models.CompanyDispatcher)
+			return err
+		}, ErrLastOwner},
+		{"dispatcher changes a role", func(svc *companyService) error {
+			_, err := svc.UpdateMemberRole(10, companyDispatcher, companyDriver, models.CompanyDispatcher)
+			return err
+		}, ErrCompanyForbidden},
+		{"owner removes the driver", func(svc *companyService) error {
+			return svc.RemoveMember(10, companyOwner, companyDriver)
+		}, nil},
+		{"dispatcher removes the driver", func(svc *companyService) error {
+			return svc.RemoveMember(10, companyDispatcher, companyDriver)
+		}, ErrCompanyForbidden},
+		{"driver leaves", func(svc *companyService) error {
+			return svc.RemoveMember(10, companyDriver, companyDriver)
+		}, nil},
+		{"sole owner leaves", func(svc *companyService) error {
+			return svc.RemoveMember(10, companyOwner, companyOwner)
+		}, ErrLastOwner},
+		{"owner removes an outsider", func(svc *companyService) error {
+			return svc.RemoveMember(10, companyOwner, outsider)
+		}, ErrMemberNotFound},
+		{"outsider lists members", func(svc *companyService) error {
+			_, err := svc.GetMembers(10, outsider)
+			return err
+		}, ErrCompanyNotFound},
+		{"driver lists invitations", func(svc *companyService) error {
+			_, err := svc.GetInvitations(10, companyDriver)
+			return err
+		}, ErrCompanyForbidden},
+	}
+	for _, tt := range tests {
+		svc, _, _ := newTestCompanyService(t)
+		if err := tt.do(svc); err != tt.want {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestSwitchCompany(t *testing.T) {
+	svc, companies, _ := newTestCompanyService(t)
+	companyID := 10
+	if _, err := svc.SwitchCompany(outsider, &companyID); err != ErrCompanyNotFound {
+		t.Errorf("outsider: err = %v, want ErrCompanyNotFound", err)
+	}
+	resp, err := svc.SwitchCompany(companyOwner, &companyID)
+	if err != nil || resp.AccessToken == "" {
+		t.Fatalf("switch = %+v, %v", resp, err)
+	}
+	if active := companies.active[companyOwner]; active == nil || *active != 10 {
+		t.Errorf("active company = %v", active)
+	}
+	zero := 0
+	if _, err := svc.SwitchCompany(companyOwner, &zero); err != nil || companies.active[companyOwner] != nil {
+		t.Errorf("switch back to personal: err = %v, active = %v", err, companies.active[companyOwner])
+	}
+}
+
+// Работу от имени компании публикуют только её владельцы и диспетчеры
+func TestCreateJobForCompany(t *testing.T) {
+	companies := newFakeCompanyRepo(map[int]models.CompanyRole{
+		companyOwner:  models.CompanyOwner,
+		companyDriver: models.CompanyDriver,
+	})
+	users := newFakeUserRepo(
+		&models.User{ID: companyOwner, EmailVerified: true},
+		&models.User{ID: companyDriver, EmailVerified: true},
+		&models.User{ID: outsider, EmailVerified: true},
+	)
+	jobs := &createdJobs{fakeJobRepo: newFakeJobRepo()}
+	svc := NewJobService(jobs, users, companies, verifiedCarriers{}, nil, flatFees{}, nil)
+	req := jobRequest(openJob("", companyOwner))
+
+	job, err := svc.CreateJob(companyOwner, 10, req)
+	if err != nil || job.CompanyID == nil || *job.CompanyID != 10 || job.PosterID != companyOwner {
+		t.Fatalf("company job = %+v, %v", job, err)
+	}
+	for _, userID := range []int{companyDriver, outsider} {
+		if _, err := svc.CreateJob(userID, 10, req); err != ErrCompanyForbidden {
+			t.Errorf("user %d posts for the company: err = %v, want ErrCompanyForbidden", userID, err)
+		}
+	}
+	if job, err := svc.CreateJob(outsider, 0, req); err != nil || job.CompanyID != nil {
+		t.Errorf("personal job = %+v, %v", job, err)
+	}
+}
+
+type createdJobs struct {
+	*fakeJobRepo
+}
+
+func (r *createdJobs) CreateJob(job *models.Job, events repository.JobEvents) (*models.Job, error) {
+	r.jobs[job.ID] = job
+	return r.GetJobByID(job.ID)
+}
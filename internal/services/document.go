@@ -0,0 +1,248 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"moveshare/internal/documents"
+	"moveshare/internal/models"
+	"moveshare/internal/repository"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+var (
+	ErrPaymentNotReceived = errors.New("payment not received")
+	// ErrJobDeleted — документы выданы, но работы уже нет: собрать их заново не из
+	// чего, сохранённые файлы остаются
+	ErrJobDeleted = errors.New("job was deleted")
+)
+
+// DocumentService выдаёт счета и квитанции по завершённым работам. Номер
+// документа выдаётся один раз, файл хранится в каталоге загрузок
+type DocumentService interface {
+	// GetDocument — документ kind и его PDF. При первом запросе документу
+	// выдаётся номер и сохраняется файл, дальше отдаётся сохранённый
+	GetDocument(jobID string, userID int, kind models.DocumentKind) (*models.JobDocument, []byte, error)
+	// IssueInvoice выдаёт счёт при завершении работы, чтобы номера шли
+	// в порядке завершения, а не первых запросов
+	IssueInvoice(job *models.Job) error
+	// Regenerate заново собирает файлы выданных документов работы; номера не меняются.
+	// Документы удалённой работы не пересобираются (ErrJobDeleted)
+	Regenerate(jobID string) ([]*models.JobDocument, error)
+}
+
+type documentService struct {
+	repo        repository.DocumentRepository
+	jobRepo     repository.JobRepository
+	userRepo    repository.UserRepository
+	companyRepo repository.CompanyRepository
+	carrierRepo repository.CarrierRepository
+	feeRepo     repository.FeeRepository
+	paymentRepo repository.PaymentRepository
+	uploadsDir  string
+}
+
+func NewDocumentService(
+	repo repository.DocumentRepository,
+	jobRepo repository.JobRepository,
+	userRepo repository.UserRepository,
+	companyRepo repository.CompanyRepository,
+	carrierRepo repository.CarrierRepository,
+	feeRepo repository.FeeRepository,
+	paymentRepo repository.PaymentRepository,
+	uploadsDir string,
+) DocumentService {
+	return &documentService{
+		repo:        repo,
+		jobRepo:     jobRepo,
+		userRepo:    userRepo,
+		companyRepo: companyRepo,
+		carrierRepo: carrierRepo,
+		feeRepo:     feeRepo,
+		paymentRepo: paymentRepo,
+		uploadsDir:  uploadsDir,
+	}
+}
+
+func (s *documentService) GetDocument(jobID string, userID int, kind models.DocumentKind) (*models.JobDocument, []byte, error) {
+	job, err := participantJob(s.jobRepo, jobID, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s.issue(job, kind, false)
+}
+
+func (s *documentService) IssueInvoice(job *models.Job) error {
+	_, _, err := s.issue(job, models.DocumentInvoice, false)
+	return err
+}
+
+func (s *documentService) Regenerate(jobID string) ([]*models.JobDocument, error) {
+	docs, err := s.repo.GetDocumentsByJob(jobID)
+	if err != nil {
+		return nil, err
+	}
+	job, err := s.jobRepo.GetJobByID(jobID)
+	if errors.Is(err, repository.ErrJobNotFound) && len(docs) > 0 {
+		return nil, ErrJobDeleted
+	}
+	if err != nil {
+		return nil, mapJobError(err)
+	}
+	for i, doc := range docs {
+		if docs[i], _, err = s.issue(job, doc.Kind, true); err != nil {
+			return nil, err
+		}
+	}
+	return docs, nil
+}
+
+// issue выдаёт документ (или берёт выданный) и возвращает его файл. Файл
+// собирается заново, если его нет или regenerate
+func (s *documentService) issue(job *models.Job, kind models.DocumentKind, regenerate bool) (*models.JobDocument, []byte, error) {
+	if job.Status != models.JobStatusCompleted {
+		return nil, nil, ErrJobNotCompleted
+	}
+	data := documents.Data{Job: job}
+	if kind == models.DocumentReceipt {
+		if err := s.payment(job, &data); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	doc, err := s.repo.IssueDocument(job.ID, kind, documentIssuer(job))
+	if err != nil {
+		return nil, nil, err
+	}
+	if doc.FilePath != "" && !regenerate {
+		content, err := os.ReadFile(filepath.Join(s.uploadsDir, doc.FilePath))
+		if err == nil {
+			return doc, content, nil
+		}
+		if !os.IsNotExist(err) {
+			return nil, nil, err
+		}
+	}
+
+	data.Document = doc
+	if err := s.parties(job, &data); err != nil {
+		return nil, nil, err
+	}
+	fees, err := s.feeRepo.GetJobFees(job.ID)
+	if err != nil && !errors.Is(err, repository.ErrJobFeesNotFound) {
+		return nil, nil, err
+	}
+	data.Fees = fees
+	content := documents.Render(data)
+
+	relPath := filepath.Join("documents", job.ID, doc.DisplayNumber()+".pdf")
+	if err := saveFile(filepath.Join(s.uploadsDir, relPath), content); err != nil {
+		return nil, nil, err
+	}
+	if doc.FilePath != relPath {
+		if err := s.repo.SetFilePath(doc.ID, relPath); err != nil {
+			return nil, nil, err
+		}
+		doc.FilePath = relPath
+	}
+	return doc, content, nil
+}
+
+// payment заполняет данные об оплате для квитанции. Квитанция есть, только
+// когда оплата списана с заказчика
+func (s *documentService) payment(job *models.Job, data *documents.Data) error {
+	escrow, err := s.paymentRepo.GetEscrow(job.ID)
+	if errors.Is(err, repository.ErrEscrowNotFound) {
+		return ErrPaymentNotReceived
+	}
+	if err != nil {
+		return err
+	}
+	if escrow.Status != models.EscrowFunded && escrow.Status != models.EscrowReleased {
+		return ErrPaymentNotReceived
+	}
+	data.PaymentRef = escrow.ChargeRef
+	for _, entry := range escrow.Ledger {
+		if entry.Type == models.LedgerEscrowFunded {
+			paidAt := entry.CreatedAt
+			data.PaidAt = &paidAt
+		}
+	}
+	return nil
+}
+
+// parties — заказчик (компания работы или её автор) и перевозчик
+func (s *documentService) parties(job *models.Job, data *documents.Data) error {
+	billTo, carrier, err := jobParties(s.userRepo, s.companyRepo, s.carrierRepo, job)
+	if err != nil {
+		return err
+	}
+	data.BillTo, data.Carrier = billTo, carrier
+	return nil
+}
+
+// jobParties — стороны работы для документов: заказчик (компания работы
+// или её автор) и назначенный перевозчик с реквизитами из профиля
+func jobParties(userRepo repository.UserRepository, companyRepo repository.CompanyRepository, carrierRepo repository.CarrierRepository, job *models.Job) (documents.Party, documents.Party, error) {
+	var customer, carrierParty documents.Party
+	poster, err := userRepo.GetUserByID(job.PosterID)
+	if err != nil {
+		return customer, carrierParty, err
+	}
+	customer = documents.Party{Name: poster.Username, Details: []string{poster.Email}}
+	if job.CompanyID != nil {
+		company, err := companyRepo.GetCompanyByID(*job.CompanyID)
+		if err != nil {
+			return customer, carrierParty, err
+		}
+		customer = documents.Party{Name: company.Name, Details: []string{"Attn: " + poster.Username, poster.Email}}
+	}
+
+	if job.CarrierID == nil {
+		return customer, carrierParty, nil
+	}
+	carrier, err := userRepo.GetUserByID(*job.CarrierID)
+	if err != nil {
+		return customer, carrierParty, err
+	}
+	carrierParty = documents.Party{Name: carrier.Username, Details: []string{carrier.Email}}
+	profile, err := carrierRepo.GetProfile(carrier.ID)
+	if errors.Is(err, repository.ErrCarrierProfileNotFound) {
+		return customer, carrierParty, nil
+	}
+	if err != nil {
+		return customer, carrierParty, err
+	}
+	carrierParty.Name = profile.LegalName
+	carrierParty.Details = []string{"USDOT " + profile.DOTNumber}
+	if profile.MCNumber != "" {
+		carrierParty.Details = append(carrierParty.Details, "MC "+profile.MCNumber)
+	}
+	carrierParty.Details = append(carrierParty.Details, carrier.Email)
+	return customer, carrierParty, nil
+}
+
+// saveFile записывает файл через временный, чтобы при сбое не остался обрезанный
+func saveFile(path string, content []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return err
+	}
+	tmp := fmt.Sprintf("%s.%d.tmp", path, time.Now().UnixNano())
+	if err := os.WriteFile(tmp, content, 0o640); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// documentIssuer — чья нумерация: компании работы или её автора
+func documentIssuer(job *models.Job) string {
+	if job.CompanyID != nil {
+		return fmt.Sprintf("company:%d", *job.CompanyID)
+	}
+	return fmt.Sprintf("user:%d", job.PosterID)
+}
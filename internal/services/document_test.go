@@ -0,0 +1,247 @@
+package services
+
+import (
+	"bytes"
+	"moveshare/internal/models"
+	"moveshare/internal/repository"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// fakeDocumentRepo выдаёт номера подряд для каждого выставителя, как documentRepository
+type fakeDocumentRepo struct {
+	repository.DocumentRepository
+	docs map[string]*models.JobDocument
+	last map[string]int
+}
+
+func newFakeDocumentRepo() *fakeDocumentRepo {
+	return &fakeDocumentRepo{docs: map[string]*models.JobDocument{}, last: map[string]int{}}
+}
+
+func (r *fakeDocumentRepo) IssueDocument(jobID string, kind models.DocumentKind, issuer string) (*models.JobDocument, error) {
+	key := jobID + "/" + string(kind)
+	if _, ok := r.docs[key]; !ok {
+		r.last[issuer+"/"+string(kind)]++
+		r.docs[key] = &models.JobDocument{
+			ID:       strconv.Itoa(len(r.docs) + 1),
+			JobID:    jobID,
+			Kind:     kind,
+			Issuer:   issuer,
+			Number:   r.last[issuer+"/"+string(kind)],
+			IssuedAt: time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC),
+		}
+	}
+	copied := *r.docs[key]
+	return &copied, nil
+}
+
+func (r *fakeDocumentRepo) GetDocumentsByJob(jobID string) ([]*models.JobDocument, error) {
+	var docs []*models.JobDocument
+	for _, kind := range []models.DocumentKind{models.DocumentInvoice, models.DocumentReceipt} {
+		if doc, ok := r.docs[jobID+"/"+string(kind)]; ok {
+			copied := *doc
+			docs = append(docs, &copied)
+		}
+	}
+	return docs, nil
+}
+
+func (r *fakeDocumentRepo) SetFilePath(id, filePath string) error {
+	for _, doc := range r.docs {
+		if doc.ID == id {
+			doc.FilePath = filePath
+			return nil
+		}
+	}
+	return repository.ErrDocumentNotFound
+}
+
+// fakeFeeRepo хранит расчёты удержаний по работам
+type fakeFeeRepo struct {
+	repository.FeeRepository
+	fees map[string]*models.FeeBreakdown
+}
+
+func (r *fakeFeeRepo) GetJobFees(jobID string) (*models.FeeBreakdown, error) {
+	fees, ok := r.fees[jobID]
+	if !ok {
+		return nil, repository.ErrJobFeesNotFound
+	}
+	return fees, nil
+}
+
+type documentFixture struct {
+	service  DocumentService
+	docs     *fakeDocumentRepo
+	jobs     *fakeJobRepo
+	payments *fakePaymentRepo
+	dir      string
+}
+
+// newDocumentFixture — сервис документов по завершённой работе "job" компании
+// Acme Movers (автор 1, перевозчик 5 с профилем)
+func newDocumentFixture(t *testing.T, jobs ...*models.Job) *documentFixture {
+	t.Helper()
+	carriers := newFakeCarrierRepo()
+	carriers.profiles[5] = &models.CarrierProfile{UserID: 5, LegalName: "Fast Haul LLC", DOTNumber: "1234567"}
+	f := &documentFixture{
+		docs:     newFakeDocumentRepo(),
+		jobs:     newFakeJobRepo(append([]*models.Job{completedJob()}, jobs...)...),
+		payments: &fakePaymentRepo{},
+		dir:      t.TempDir(),
+	}
+	users := newFakeUserRepo(
+		&models.User{ID: 1, Username: "poster", Email: "poster@example.com"},
+		&models.User{ID: 5, Username: "carrier", Email: "carrier@example.com"},
+	)
+	fees := &fakeFeeRepo{fees: map[string]*models.FeeBreakdown{
+		"job": {RuleSetVersion: 3, Payment: usd(100000), PlatformFee: usd(5000), CarrierNet: usd(95000)},
+	}}
+	f.service = NewDocumentService(f.docs, f.jobs, users, newFakeCompanyRepo(nil), carriers, fees, f.payments, f.dir)
+	return f
+}
+
+// Номер выдаётся при первом запросе, дальше отдаётся сохранённый файл
+func TestGetDocumentIssuesOnce(t *testing.T) {
+	f := newDocumentFixture(t)
+	doc, content, err := f.service.GetDocument("job", 1, models.DocumentInvoice)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.Number != 1 || doc.Issuer != "company:10" || doc.FilePath != filepath.Join("documents", "job", "INV-000001.pdf") {
+		t.Fatalf("issued %+v", doc)
+	}
+	for _, want := range []string{"%PDF-1.4", "INV-000001", "Acme Movers", "Fast Haul LLC", "USDOT 1234567", "fee rules v3"} {
+		if !bytes.Contains(content, []byte(want)) {
+			t.Errorf("invoice does not mention %q", want)
+		}
+	}
+	saved, err := os.ReadFile(filepath.Join(f.dir, doc.FilePath))
+	if err != nil || !bytes.Equal(saved, content) {
+		t.Fatalf("saved file differs from the response: %v", err)
+	}
+
+	// Перевозчик получает тот же документ; сохранённый файл не пересобирается
+	os.WriteFile(filepath.Join(f.dir, doc.FilePath), []byte("stored"), 0o640)
+	again, content, err := f.service.GetDocument("job", 5, models.DocumentInvoice)
+	if err != nil || again.Number != 1 || string(content) != "stored" {
+		t.Fatalf("second request: %+v, %q, %v", again, content, err)
+	}
+}
+
+func TestGetDocumentAccess(t *testing.T) {
+	open := openJob("open", 1)
+	f := newDocumentFixture(t, open)
+	tests := []struct {
+		name   string
+		jobID  string
+		userID int
+		want   error
+	}{
+		{"stranger", "job", 99, ErrJobForbidden},
+		{"missing job", "missing", 1, ErrJobNotFound},
+		{"job not completed", "open", 1, ErrJobNotCompleted},
+	}
+	for _, tt := range tests {
+		if _, _, err := f.service.GetDocument(tt.jobID, tt.userID, models.DocumentInvoice); err != tt.want {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+	if len(f.docs.docs) != 0 {
+		t.Errorf("documents were issued: %v", f.docs.docs)
+	}
+}
+
+// Номера идут подряд у каждого выставителя: у компании и у автора без компании — свои
+func TestDocumentNumbering(t *testing.T) {
+	second := completedJob()
+	second.ID = "second"
+	personal := completedJob()
+	personal.ID, personal.CompanyID = "personal", nil
+	f := newDocumentFixture(t, second, personal)
+
+	for _, tt := range []struct {
+		jobID string
+		want  string
+	}{
+		{"job", "INV-000001"},
+		{"personal", "INV-000001"},
+		{"second", "INV-000002"},
+		{"job", "INV-000001"},
+	} {
+		job, _ := f.jobs.GetJobByID(tt.jobID)
+		if err := f.service.IssueInvoice(job); err != nil {
+			t.Fatal(err)
+		}
+		docs, _ := f.docs.GetDocumentsByJob(tt.jobID)
+		if got := docs[0].DisplayNumber(); got != tt.want {
+			t.Errorf("%s: number = %s, want %s", tt.jobID, got, tt.want)
+		}
+	}
+}
+
+// Квитанция выдаётся, только когда оплата списана с заказчика
+func TestReceiptRequiresPayment(t *testing.T) {
+	paidAt := time.Date(2025, 2, 27, 9, 0, 0, 0, time.UTC)
+	for _, tt := range []struct {
+		name   string
+		escrow *models.Escrow
+		want   error
+	}{
+		{"no escrow", nil, ErrPaymentNotReceived},
+		{"pending", &models.Escrow{JobID: "job", Status: models.EscrowPending}, ErrPaymentNotReceived},
+		{"refunded", &models.Escrow{JobID: "job", Status: models.EscrowRefunded}, ErrPaymentNotReceived},
+		{"funded", &models.Escrow{JobID: "job", Status: models.EscrowFunded, ChargeRef: "ch_42"}, nil},
+		{"released", &models.Escrow{JobID: "job", Status: models.EscrowReleased, ChargeRef: "ch_42"}, nil},
+	} {
+		f := newDocumentFixture(t)
+		if tt.escrow != nil {
+			tt.escrow.Ledger = []*models.LedgerEntry{{Type: models.LedgerEscrowFunded, CreatedAt: paidAt}}
+		}
+		f.payments.escrow = tt.escrow
+		_, content, err := f.service.GetDocument("job", 1, models.DocumentReceipt)
+		if err != tt.want {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.want)
+			continue
+		}
+		if err == nil {
+			for _, want := range []string{"RCT-000001", "Payment reference: ch_42", "Paid on Feb 27, 2025"} {
+				if !bytes.Contains(content, []byte(want)) {
+					t.Errorf("%s: receipt does not mention %q", tt.name, want)
+				}
+			}
+		}
+	}
+}
+
+// Пересборка перезаписывает файлы, не меняя номеров; документы удалённой работы не пересобираются
+func TestRegenerate(t *testing.T) {
+	f := newDocumentFixture(t)
+	f.payments.escrow = &models.Escrow{JobID: "job", Status: models.EscrowReleased}
+	invoice, original, _ := f.service.GetDocument("job", 1, models.DocumentInvoice)
+	receipt, _, _ := f.service.GetDocument("job", 1, models.DocumentReceipt)
+	os.WriteFile(filepath.Join(f.dir, invoice.FilePath), []byte("stale"), 0o640)
+
+	docs, err := f.service.Regenerate("job")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(docs) != 2 || docs[0].Number != invoice.Number || docs[1].Number != receipt.Number {
+		t.Fatalf("regenerated %+v", docs)
+	}
+	if saved, _ := os.ReadFile(filepath.Join(f.dir, invoice.FilePath)); !bytes.Equal(saved, original) {
+		t.Error("invoice file was not rebuilt")
+	}
+
+	delete(f.jobs.jobs, "job")
+	if _, err := f.service.Regenerate("job"); err != ErrJobDeleted {
+		t.Errorf("deleted job: err = %v, want %v", err, ErrJobDeleted)
+	}
+	if _, err := f.service.Regenerate("missing"); err != ErrJobNotFound {
+		t.Errorf("missing job: err = %v, want %v", err, ErrJobNotFound)
+	}
+}
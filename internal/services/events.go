@@ -0,0 +1,48 @@
+package services
+
+import (
+	"moveshare/internal/models"
+	"moveshare/internal/repository"
+)
+
+// jobEvents — событие eventType по работе, которую вернуло изменение. Каждое
+// событие случается с работой один раз, поэтому ключ дедупликации — тип и работа
+func jobEvents(eventType models.EventType) repository.JobEvents {
+	return func(job *models.Job) []*models.Event {
+		return []*models.Event{jobEvent(eventType, job)}
+	}
+}
+
+func jobEvent(eventType models.EventType, job *models.Job) *models.Event {
+	return models.NewEvent(eventType, job, jobAudience(job), string(eventType)+":"+job.ID)
+}
+
+// bidEvents — событие eventType по ставке на работу job
+func bidEvents(eventType models.EventType, job *models.Job) repository.BidEvents {
+	return func(bid *models.Bid) []*models.Event {
+		return []*models.Event{bidEvent(eventType, job, bid)}
+	}
+}
+
+func bidEvent(eventType models.EventType, job *models.Job, bid *models.Bid) *models.Event {
+	audience := bidAudience(job, bid)
+	// О новой ставке автору ставки сообщать незачем
+	if eventType == models.EventBidReceived {
+		audience = models.EventAudience{UserIDs: []int{job.PosterID}, CompanyID: job.CompanyID}
+	}
+	return models.NewEvent(eventType, bid, audience, string(eventType)+":"+bid.ID)
+}
+
+// jobAudience — события работы получают её автор, компания и назначенный перевозчик
+func jobAudience(job *models.Job) models.EventAudience {
+	audience := models.EventAudience{UserIDs: []int{job.PosterID}, CompanyID: job.CompanyID}
+	if job.CarrierID != nil {
+		audience.UserIDs = append(audience.UserIDs, *job.CarrierID)
+	}
+	return audience
+}
+
+// bidAudience — события ставки получают заказчик (с компанией) и перевозчик, сделавший ставку
+func bidAudience(job *models.Job, bid *models.Bid) models.EventAudience {
+	return models.EventAudience{UserIDs: []int{job.PosterID, bid.CarrierID}, CompanyID: job.CompanyID}
+}
@@ -0,0 +1,104 @@
+package services
+
+import (
+	"errors"
+	"moveshare/internal/fees"
+	"moveshare/internal/models"
+	"moveshare/internal/repository"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidFeeRules   = errors.New("invalid fee rules")
+	ErrFeesExceedPayment = errors.New("fees exceed payment")
+	ErrJobFeesNotFound   = errors.New("job is not booked yet")
+)
+
+// FeeService — правила удержаний из оплаты работ и расчёты по ним
+type FeeService interface {
+	// Quote — предварительный расчёт по действующим правилам
+	Quote(req models.FeeQuoteRequest) (*models.FeeBreakdown, error)
+	// QuoteJob — расчёт для работы job при оплате payment
+	QuoteJob(job *models.Job, payment models.Money) (*models.FeeBreakdown, error)
+	// GetJobFees — расчёт, зафиксированный при закреплении работы. Виден тем,
+	// кто управляет работой, и назначенному перевозчику
+	GetJobFees(jobID string, userID int) (*models.FeeBreakdown, error)
+	GetRuleSets() ([]*models.FeeRuleSet, error)
+	CreateRuleSet(adminID int, req models.CreateFeeRuleSetRequest) (*models.FeeRuleSet, error)
+}
+
+type feeService struct {
+	repo    repository.FeeRepository
+	jobRepo repository.JobRepository
+}
+
+func NewFeeService(repo repository.FeeRepository, jobRepo repository.JobRepository) FeeService {
+	return &feeService{repo: repo, jobRepo: jobRepo}
+}
+
+func (s *feeService) Quote(req models.FeeQuoteRequest) (*models.FeeBreakdown, error) {
+	return s.calculate(req.PaymentAmount, req.NumberOfBedrooms, req.PromoCode)
+}
+
+func (s *feeService) QuoteJob(job *models.Job, payment models.Money) (*models.FeeBreakdown, error) {
+	return s.calculate(payment, job.NumberOfBedrooms, job.PromoCode)
+}
+
+func (s *feeService) calculate(payment models.Money, size models.NumberOfBedrooms, promoCode string) (*models.FeeBreakdown, error) {
+	now := time.Now().UTC()
+	set, err := s.repo.GetActiveRuleSet(now)
+	if err != nil {
+		return nil, err
+	}
+	breakdown, err := fees.Calculate(set, fees.Input{Payment: payment, Size: size, PromoCode: promoCode, At: now})
+	switch {
+	case errors.Is(err, models.ErrInvalidMoney):
+		return nil, ErrInvalidAmount
+	case errors.Is(err, fees.ErrFeesExceedPayment):
+		return nil, ErrFeesExceedPayment
+	case err != nil:
+		return nil, err
+	}
+	return breakdown, nil
+}
+
+func (s *feeService) GetJobFees(jobID string, userID int) (*models.FeeBreakdown, error) {
+	if _, err := participantJob(s.jobRepo, jobID, userID); err != nil {
+		return nil, err
+	}
+	breakdown, err := s.repo.GetJobFees(jobID)
+	if errors.Is(err, repository.ErrJobFeesNotFound) {
+		return nil, ErrJobFeesNotFound
+	}
+	return breakdown, err
+}
+
+func (s *feeService) GetRuleSets() ([]*models.FeeRuleSet, error) {
+	return s.repo.GetRuleSets()
+}
+
+// CreateRuleSet сохраняет новую версию правил. Уже закреплённые работы
+// сохраняют свои расчёты, новая версия действует для следующих
+func (s *feeService) CreateRuleSet(adminID int, req models.CreateFeeRuleSetRequest) (*models.FeeRuleSet, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, ErrInvalidFeeRules
+	}
+	if err := fees.Validate(req.Rules); err != nil {
+		return nil, ErrInvalidFeeRules
+	}
+	activeFrom := time.Now().UTC()
+	if req.ActiveFrom != nil {
+		activeFrom = req.ActiveFrom.UTC()
+	}
+	return s.repo.CreateRuleSet(&models.FeeRuleSet{
+		ID:         uuid.New().String(),
+		Name:       name,
+		Rules:      req.Rules,
+		ActiveFrom: activeFrom,
+		CreatedBy:  &adminID,
+	})
+}
@@ -2,31 +2,215 @@ package services
 
 import (
 	"errors"
+	"moveshare/internal/geo"
 	"moveshare/internal/models"
 	"moveshare/internal/repository"
+	"strings"
 
 	"github.com/google/uuid"
 )
 
-var ErrJobNotFound = errors.New("job not found")
+var (
+	ErrJobNotFound       = errors.New("job not found")
+	ErrJobForbidden      = errors.New("job belongs to another user")
+	ErrJobNotOpen        = errors.New("job is no longer open")
+	ErrInvalidTransition = errors.New("invalid job status transition")
+	ErrCannotClaimOwnJob = errors.New("cannot claim own job")
+	ErrInvalidAddress    = errors.New("address could not be located")
+	ErrInvalidAmount     = errors.New("invalid amount")
+	ErrEmailNotVerified  = errors.New("email is not verified")
+	ErrJobInProgress     = errors.New("job is in progress")
+)
+
+// jobTransitions — допустимые переходы между статусами работы
+var jobTransitions = map[models.JobStatus][]models.JobStatus{
+	models.JobStatusOpen:      {models.JobStatusClaimed, models.JobStatusCancelled},
+	models.JobStatusClaimed:   {models.JobStatusInTransit, models.JobStatusCancelled},
+	models.JobStatusInTransit: {models.JobStatusDelivered},
+	models.JobStatusDelivered: {models.JobStatusCompleted},
+}
+
+// transitionEvents — доменное событие для каждого статуса, в который переходит работа
+var transitionEvents = map[models.JobStatus]models.EventType{
+	models.JobStatusInTransit: models.EventJobStarted,
+	models.JobStatusDelivered: models.EventJobDelivered,
+	models.JobStatusCompleted: models.EventJobCompleted,
+	models.JobStatusCancelled: models.EventJobCancelled,
+}
+
+func canTransition(from, to models.JobStatus) bool {
+	for _, next := range jobTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
 
 type JobService interface {
-	CreateJob(req models.CreateJobRequest) (*models.Job, error)
+	CreateJob(posterID, companyID int, req models.CreateJobRequest) (*models.Job, error)
 	GetJobs(filter models.JobFilter, limit, offset int) ([]*models.Job, int, error)
-	DeleteJob(id string) error
+	ResolveFilter(filter *models.JobFilter) error
+	UpdateJob(id string, userID int, req models.CreateJobRequest) (*models.Job, error)
+	DeleteJob(id string, userID int) error
+	ClaimJob(id string, carrierID int) (*models.Job, error)
+	StartJob(id string, userID int) (*models.Job, error)
+	DeliverJob(id string, userID int) (*models.Job, error)
+	CompleteJob(id string, userID int) (*models.Job, error)
+	CancelJob(id string, userID int) (*models.Job, error)
+	GetBackhauls(id string, radiusMiles float64, limit, offset int) ([]*models.Backhaul, int, error)
 }
 
+// jobService не вызывает подписчиков сам: изменения работ пишут доменные
+// события в outbox, а лента, сохранённые поиски и вебхуки получают их из events.Bus
 type jobService struct {
-	repo repository.JobRepository
+	repo        repository.JobRepository
+	userRepo    repository.UserRepository
+	companyRepo repository.CompanyRepository
+	carrierRepo repository.CarrierRepository
+	geocoder    geo.Geocoder
+	fees        FeeService
+	ledger      LedgerService
 }
 
-func NewJobService(repo repository.JobRepository) JobService {
-	return &jobService{repo: repo}
+func NewJobService(repo repository.JobRepository, userRepo repository.UserRepository, companyRepo repository.CompanyRepository, carrierRepo repository.CarrierRepository, geocoder geo.Geocoder, fees FeeService, ledger LedgerService) JobService {
+	return &jobService{
+		repo:        repo,
+		userRepo:    userRepo,
+		companyRepo: companyRepo,
+		carrierRepo: carrierRepo,
+		geocoder:    geocoder,
+		fees:        fees,
+		ledger:      ledger,
+	}
 }
 
-func (s *jobService) CreateJob(req models.CreateJobRequest) (*models.Job, error) {
+// CreateJob публикует работу. Если задан companyID (активная компания из токена),
+// работа принадлежит компании и ею могут управлять её владельцы и диспетчеры
+func (s *jobService) CreateJob(posterID, companyID int, req models.CreateJobRequest) (*models.Job, error) {
+	poster, err := s.userRepo.GetUserByID(posterID)
+	if err != nil {
+		return nil, err
+	}
+	if !poster.EmailVerified {
+		return nil, ErrEmailNotVerified
+	}
+
+	job, err := s.jobFromRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	if companyID != 0 {
+		member, err := s.companyRepo.GetMember(companyID, posterID)
+		if err != nil {
+			if errors.Is(err, repository.ErrMemberNotFound) {
+				return nil, ErrCompanyForbidden
+			}
+			return nil, err
+		}
+		if !member.Role.CanManageJobs() {
+			return nil, ErrCompanyForbidden
+		}
+		job.CompanyID = &companyID
+	}
+	job.ID = uuid.New().String()
+	job.PosterID = posterID
+	job.Status = models.JobStatusOpen
+	job, err = s.repo.CreateJob(job, jobEvents(models.EventJobCreated))
+	if err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+func (s *jobService) GetJobs(filter models.JobFilter, limit, offset int) ([]*models.Job, int, error) {
+	if err := s.ResolveFilter(&filter); err != nil {
+		return nil, 0, err
+	}
+	return s.repo.GetJobs(filter, limit, offset)
+}
+
+// ResolveFilter определяет координаты точек фильтра, заданных ZIP-кодом
+func (s *jobService) ResolveFilter(filter *models.JobFilter) error {
+	return resolveFilter(s.geocoder, filter)
+}
+
+func resolveFilter(geocoder geo.Geocoder, filter *models.JobFilter) error {
+	for _, radius := range []*models.GeoRadius{filter.Origin, filter.Destination} {
+		if radius == nil || radius.ZIP == "" {
+			continue
+		}
+		lat, lng, err := geocoder.Geocode(models.Address{ZIP: radius.ZIP})
+		if err != nil {
+			return mapGeoError(err)
+		}
+		radius.Latitude, radius.Longitude = lat, lng
+	}
+	return nil
+}
+
+func (s *jobService) UpdateJob(id string, userID int, req models.CreateJobRequest) (*models.Job, error) {
+	job, err := s.jobFromRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	job.ID = id
+	job, err = s.repo.UpdateJob(job, userID)
+	if err != nil {
+		return nil, mapJobError(err)
+	}
+	return job, nil
+}
+
+// GetBackhauls подбирает открытые работы, которые начинаются рядом с местом
+// доставки работы id и не раньше её доставки. Сначала — с меньшим порожним
+// пробегом, при равном пробеге — с большей оплатой.
+func (s *jobService) GetBackhauls(id string, radiusMiles float64, limit, offset int) ([]*models.Backhaul, int, error) {
+	job, err := s.repo.GetJobByID(id)
+	if err != nil {
+		return nil, 0, mapJobError(err)
+	}
+	if !job.DeliveryAddress.HasCoordinates() {
+		return nil, 0, ErrInvalidAddress
+	}
+
+	deliveredAt := job.DeliveryDateTime
+	filter := models.JobFilter{
+		Status:    string(models.JobStatusOpen),
+		DateStart: &deliveredAt,
+		Origin: &models.GeoRadius{
+			Latitude:  job.DeliveryAddress.Latitude,
+			Longitude: job.DeliveryAddress.Longitude,
+			Miles:     radiusMiles,
+		},
+		Sort: models.JobSortDeadhead,
+	}
+	jobs, total, err := s.repo.GetJobs(filter, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	backhauls := make([]*models.Backhaul, 0, len(jobs))
+	for _, candidate := range jobs {
+		backhauls = append(backhauls, &models.Backhaul{
+			Job: candidate,
+			DeadheadMiles: geo.HaversineMiles(
+				job.DeliveryAddress.Latitude, job.DeliveryAddress.Longitude,
+				candidate.PickupAddress.Latitude, candidate.PickupAddress.Longitude,
+			),
+		})
+	}
+	return backhauls, total, nil
+}
+
+// jobFromRequest собирает Job из запроса, дополняя адреса координатами,
+// рассчитывая расстояние перевозки и оценку доли брокера по действующим правилам
+func (s *jobService) jobFromRequest(req models.CreateJobRequest) (*models.Job, error) {
+	payment := req.PaymentAmount.WithDefaultCurrency()
+	if !payment.Valid() || payment.IsNegative() {
+		return nil, ErrInvalidAmount
+	}
 	job := &models.Job{
-		ID:                            uuid.New().String(),
 		JobTitle:                      req.JobTitle,
 		NumberOfBedrooms:              req.NumberOfBedrooms,
 		AdditionalServices:            req.AdditionalServices,
@@ -34,23 +218,197 @@ func (s *jobService) CreateJob(req models.CreateJobRequest) (*models.Job, error)
 		TruckSize:                     req.TruckSize,
 		PickupDateTime:                req.PickupDateTime,
 		DeliveryDateTime:              req.DeliveryDateTime,
-		CutAmount:                     req.CutAmount,
-		PaymentAmount:                 req.PaymentAmount,
+		PaymentAmount:                 payment,
+		PickupAddress:                 req.PickupAddress,
+		DeliveryAddress:               req.DeliveryAddress,
+		PromoCode:                     strings.TrimSpace(req.PromoCode),
 	}
-	return s.repo.CreateJob(job)
+	quote, err := s.fees.QuoteJob(job, payment)
+	if err != nil {
+		return nil, err
+	}
+	job.CutAmount = quote.BrokerCut
+	if err := s.locate(&job.PickupAddress); err != nil {
+		return nil, err
+	}
+	if err := s.locate(&job.DeliveryAddress); err != nil {
+		return nil, err
+	}
+	job.DistanceMiles = geo.HaversineMiles(
+		job.PickupAddress.Latitude, job.PickupAddress.Longitude,
+		job.DeliveryAddress.Latitude, job.DeliveryAddress.Longitude,
+	)
+	return job, nil
 }
 
-func (s *jobService) GetJobs(filter models.JobFilter, limit, offset int) ([]*models.Job, int, error) {
-	return s.repo.GetJobs(filter, limit, offset)
+func (s *jobService) locate(addr *models.Address) error {
+	if addr.HasCoordinates() {
+		return nil
+	}
+	if addr.ZIP == "" {
+		return ErrInvalidAddress
+	}
+	lat, lng, err := s.geocoder.Geocode(*addr)
+	if err != nil {
+		return mapGeoError(err)
+	}
+	addr.Latitude, addr.Longitude = lat, lng
+	return nil
+}
+
+func mapGeoError(err error) error {
+	if errors.Is(err, geo.ErrAddressNotFound) {
+		return ErrInvalidAddress
+	}
+	return err
+}
+
+func (s *jobService) DeleteJob(id string, userID int) error {
+	if err := s.repo.DeleteJob(id, userID, jobEvents(models.EventJobDeleted)); err != nil {
+		return mapJobError(err)
+	}
+	return nil
 }
 
-func (s *jobService) DeleteJob(id string) error {
-	err := s.repo.DeleteJob(id)
+// ClaimJob закрепляет работу за перевозчиком. Брать работы могут только
+// проверенные перевозчики с действующей страховкой
+func (s *jobService) ClaimJob(id string, carrierID int) (*models.Job, error) {
+	job, err := s.repo.GetJobByID(id)
+	if err != nil {
+		return nil, mapJobError(err)
+	}
+	verified, err := s.carrierRepo.IsCarrierVerified(carrierID)
+	if err != nil {
+		return nil, err
+	}
+	if !verified {
+		return nil, ErrCarrierNotVerified
+	}
+	own, err := canManageJob(s.repo, job, carrierID)
 	if err != nil {
-		if err.Error() == "job not found" {
-			return ErrJobNotFound
+		return nil, err
+	}
+	if own {
+		return nil, ErrCannotClaimOwnJob
+	}
+	if job.Status != models.JobStatusOpen {
+		return nil, ErrJobNotOpen
+	}
+	fees, err := s.fees.QuoteJob(job, job.PaymentAmount)
+	if err != nil {
+		return nil, err
+	}
+	job, err = s.repo.ClaimJob(id, carrierID, fees, jobEvents(models.EventJobClaimed))
+	if err != nil {
+		return nil, mapJobError(err)
+	}
+	return job, nil
+}
+
+// StartJob — перевозчик забрал груз и находится в пути
+func (s *jobService) StartJob(id string, userID int) (*models.Job, error) {
+	return s.transition(id, userID, models.JobStatusInTransit, s.isCarrier, nil)
+}
+
+// DeliverJob — перевозчик доставил груз
+func (s *jobService) DeliverJob(id string, userID int) (*models.Job, error) {
+	return s.transition(id, userID, models.JobStatusDelivered, s.isCarrier, nil)
+}
+
+// CompleteJob — заказчик подтверждает доставку. Вместе со сменой статуса
+// проводится долг перевозчику и выручка площадки
+func (s *jobService) CompleteJob(id string, userID int) (*models.Job, error) {
+	return s.transition(id, userID, models.JobStatusCompleted, s.isManager, s.ledger.CompletionJournal)
+}
+
+// CancelJob — заказчик отменяет работу, пока груз ещё не забран
+func (s *jobService) CancelJob(id string, userID int) (*models.Job, error) {
+	return s.transition(id, userID, models.JobStatusCancelled, s.isManager, nil)
+}
+
+func (s *jobService) isManager(job *models.Job, userID int) (bool, error) {
+	return canManageJob(s.repo, job, userID)
+}
+
+func (s *jobService) isCarrier(job *models.Job, userID int) (bool, error) {
+	return job.CarrierID != nil && *job.CarrierID == userID, nil
+}
+
+// canManageJob — пользователь автор работы либо владелец или диспетчер её компании
+func canManageJob(repo repository.JobRepository, job *models.Job, userID int) (bool, error) {
+	if job.PosterID == userID {
+		return true, nil
+	}
+	if job.CompanyID == nil {
+		return false, nil
+	}
+	return repo.CanManageJob(job.ID, userID)
+}
+
+// participantJob загружает работу, если пользователь управляет ею или назначен
+// на неё перевозчиком; остальным — ErrJobForbidden. Так открыты денежные данные работы
+func participantJob(repo repository.JobRepository, jobID string, userID int) (*models.Job, error) {
+	job, err := repo.GetJobByID(jobID)
+	if err != nil {
+		return nil, mapJobError(err)
+	}
+	if job.CarrierID != nil && *job.CarrierID == userID {
+		return job, nil
+	}
+	manager, err := canManageJob(repo, job, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !manager {
+		return nil, ErrJobForbidden
+	}
+	return job, nil
+}
+
+// transition меняет статус работы; postings (если задан) строит проводки,
+// которые записываются в той же транзакции
+func (s *jobService) transition(id string, userID int, to models.JobStatus, allowed func(*models.Job, int) (bool, error), postings func(*models.Job) ([]*models.JournalEntry, error)) (*models.Job, error) {
+	job, err := s.repo.GetJobByID(id)
+	if err != nil {
+		return nil, mapJobError(err)
+	}
+	ok, err := allowed(job, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrJobForbidden
+	}
+	if !canTransition(job.Status, to) {
+		return nil, ErrInvalidTransition
+	}
+	var entries []*models.JournalEntry
+	if postings != nil {
+		if entries, err = postings(job); err != nil {
+			return nil, err
 		}
+	}
+	job, err = s.repo.UpdateJobStatus(id, job.Status, to, entries, jobEvents(transitionEvents[to]))
+	if err != nil {
+		return nil, mapJobError(err)
+	}
+	return job, nil
+}
+
+// mapJobError переводит ошибки репозитория в ошибки сервиса
+func mapJobError(err error) error {
+	switch {
+	case errors.Is(err, repository.ErrJobNotFound):
+		return ErrJobNotFound
+	case errors.Is(err, repository.ErrJobForbidden):
+		return ErrJobForbidden
+	case errors.Is(err, repository.ErrJobNotOpen):
+		return ErrJobNotOpen
+	case errors.Is(err, repository.ErrJobStatusConflict):
+		return ErrInvalidTransition
+	case errors.Is(err, repository.ErrJobInProgress):
+		return ErrJobInProgress
+	default:
 		return err
 	}
-	return nil
 }
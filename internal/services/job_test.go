@@ -0,0 +1,402 @@
+package services
+
+import (
+	"moveshare/internal/geo"
+	"moveshare/internal/models"
+	"moveshare/internal/repository"
+	"slices"
+	"testing"
+	"time"
+)
+
+// fakeJobRepo хранит работы в памяти и повторяет условия SQL в jobRepository:
+// изменять и удалять можно только открытую работу, которой управляет пользователь.
+// managers — кто управляет работами компании, кроме автора
+type fakeJobRepo struct {
+	repository.JobRepository
+	jobs     map[string]*models.Job
+	managers map[int]bool
+	events   []*models.Event
+}
+
+func newFakeJobRepo(jobs ...*models.Job) *fakeJobRepo {
+	r := &fakeJobRepo{jobs: map[string]*models.Job{}, managers: map[int]bool{}}
+	for _, job := range jobs {
+		r.jobs[job.ID] = job
+	}
+	return r
+}
+
+func (r *fakeJobRepo) GetJobByID(id string) (*models.Job, error) {
+	job, ok := r.jobs[id]
+	if !ok {
+		return nil, repository.ErrJobNotFound
+	}
+	copied := *job
+	return &copied, nil
+}
+
+func (r *fakeJobRepo) CanManageJob(id string, userID int) (bool, error) {
+	job, ok := r.jobs[id]
+	return ok && (job.PosterID == userID || job.CompanyID != nil && r.managers[userID]), nil
+}
+
+func (r *fakeJobRepo) mutationError(id string, userID int) error {
+	if _, ok := r.jobs[id]; !ok {
+		return repository.ErrJobNotFound
+	}
+	if ok, _ := r.CanManageJob(id, userID); !ok {
+		return repository.ErrJobForbidden
+	}
+	return repository.ErrJobNotOpen
+}
+
+func (r *fakeJobRepo) UpdateJob(job *models.Job, userID int) (*models.Job, error) {
+	stored, ok := r.jobs[job.ID]
+	if manage, _ := r.CanManageJob(job.ID, userID); !ok || !manage || stored.Status != models.JobStatusOpen {
+		return nil, r.mutationError(job.ID, userID)
+	}
+	job.PosterID, job.CompanyID, job.Status = stored.PosterID, stored.CompanyID, stored.Status
+	r.jobs[job.ID] = job
+	return r.GetJobByID(job.ID)
+}
+
+func (r *fakeJobRepo) DeleteJob(id string, userID int, events repository.JobEvents) error {
+	job, ok := r.jobs[id]
+	if manage, _ := r.CanManageJob(id, userID); !ok || !manage || job.Status != models.JobStatusOpen {
+		return r.mutationError(id, userID)
+	}
+	delete(r.jobs, id)
+	r.events = append(r.events, events(job)...)
+	return nil
+}
+
+func (r *fakeJobRepo) UpdateJobStatus(id string, from, to models.JobStatus, journal []*models.JournalEntry, events repository.JobEvents) (*models.Job, error) {
+	job := r.jobs[id]
+	if job.Status != from {
+		return nil, repository.ErrJobStatusConflict
+	}
+	job.Status = to
+	r.events = append(r.events, events(job)...)
+	return r.GetJobByID(id)
+}
+
+func newTestJobService(jobs *fakeJobRepo) *jobService {
+	return NewJobService(jobs, newFakeUserRepo(), nil, verifiedCarriers{}, nil, flatFees{}, nil).(*jobService)
+}
+
+func openJob(id string, posterID int) *models.Job {
+	return &models.Job{
+		ID:              id,
+		PosterID:        posterID,
+		Status:          models.JobStatusOpen,
+		PaymentAmount:   usd(100000),
+		PickupAddress:   models.Address{ZIP: "10001", Latitude: 40.7506, Longitude: -73.9972},
+		DeliveryAddress: models.Address{ZIP: "19103", Latitude: 39.9522, Longitude: -75.1741},
+	}
+}
+
+func jobRequest(job *models.Job) models.CreateJobRequest {
+	return models.CreateJobRequest{
+		JobTitle:         "2 bedroom move",
+		PickupDateTime:   time.Now().Add(24 * time.Hour),
+		DeliveryDateTime: time.Now().Add(48 * time.Hour),
+		PaymentAmount:    job.PaymentAmount,
+		PickupAddress:    job.PickupAddress,
+		DeliveryAddress:  job.DeliveryAddress,
+	}
+}
+
+// Менять, удалять и отменять работу может только её автор или, если она
+// принадлежит компании, владелец или диспетчер компании
+func TestJobMutationRights(t *testing.T) {
+	const poster, dispatcher, stranger = 1, 2, 3
+	companyID := 10
+	actions := map[string]func(svc *jobService, id string, userID int) error{
+		"update": func(svc *jobService, id string, userID int) error {
+			_, err := svc.UpdateJob(id, userID, jobRequest(openJob(id, poster)))
+			return err
+		},
+		"delete": func(svc *jobService, id string, userID int) error {
+			return svc.DeleteJob(id, userID)
+		},
+		"cancel": func(svc *jobService, id string, userID int) error {
+			_, err := svc.CancelJob(id, userID)
+			return err
+		},
+	}
+	tests := []struct {
+		name    string
+		company bool
+		userID  int
+		want    error
+	}{
+		{"poster", false, poster, nil},
+		{"another user", false, stranger, ErrJobForbidden},
+		{"company dispatcher", true, dispatcher, nil},
+		{"dispatcher of a personal job", false, dispatcher, ErrJobForbidden},
+	}
+	for action, do := range actions {
+		for _, tt := range tests {
+			job := openJob("job", poster)
+			if tt.company {
+				job.CompanyID = &companyID
+			}
+			jobs := newFakeJobRepo(job)
+			jobs.managers[dispatcher] = true
+			if err := do(newTestJobService(jobs), "job", tt.userID); err != tt.want {
+				t.Errorf("%s by %s: err = %v, want %v", action, tt.name, err, tt.want)
+			}
+		}
+		if err := do(newTestJobService(newFakeJobRepo()), "missing", poster); err != ErrJobNotFound {
+			t.Errorf("%s of a missing job: err = %v, want ErrJobNotFound", action, err)
+		}
+	}
+}
+
+// Взятую работу автор уже не может ни изменить, ни удалить
+func TestJobMutationsOnlyWhileOpen(t *testing.T) {
+	job := openJob("job", 1)
+	job.Status = models.JobStatusClaimed
+	svc := newTestJobService(newFakeJobRepo(job))
+	if _, err := svc.UpdateJob("job", 1, jobRequest(job)); err != ErrJobNotOpen {
+		t.Errorf("update of a claimed job: err = %v, want ErrJobNotOpen", err)
+	}
+	if err := svc.DeleteJob("job", 1); err != ErrJobNotOpen {
+		t.Errorf("delete of a claimed job: err = %v, want ErrJobNotOpen", err)
+	}
+}
+
+func (r *fakeJobRepo) ClaimJob(id string, carrierID int, fees *models.FeeBreakdown, events repository.JobEvents) (*models.Job, error) {
+	job := r.jobs[id]
+	if job.Status != models.JobStatusOpen {
+		return nil, repository.ErrJobNotOpen
+	}
+	job.Status, job.CarrierID, job.CutAmount = models.JobStatusClaimed, &carrierID, fees.BrokerCut
+	r.events = append(r.events, events(job)...)
+	return r.GetJobByID(id)
+}
+
+func TestCanTransition(t *testing.T) {
+	statuses := []models.JobStatus{
+		models.JobStatusOpen, models.JobStatusClaimed, models.JobStatusInTransit,
+		models.JobStatusDelivered, models.JobStatusCompleted, models.JobStatusCancelled,
+	}
+	allowed := map[[2]models.JobStatus]bool{
+		{models.JobStatusOpen, models.JobStatusClaimed}:        true,
+		{models.JobStatusOpen, models.JobStatusCancelled}:      true,
+		{models.JobStatusClaimed, models.JobStatusInTransit}:   true,
+		{models.JobStatusClaimed, models.JobStatusCancelled}:   true,
+		{models.JobStatusInTransit, models.JobStatusDelivered}: true,
+		{models.JobStatusDelivered, models.JobStatusCompleted}: true,
+	}
+	for _, from := range statuses {
+		for _, to := range statuses {
+			if got := canTransition(from, to); got != allowed[[2]models.JobStatus{from, to}] {
+				t.Errorf("canTransition(%s, %s) = %v", from, to, got)
+			}
+		}
+	}
+}
+
+type unverifiedCarriers struct {
+	repository.CarrierRepository
+}
+
+func (unverifiedCarriers) IsCarrierVerified(carrierID int) (bool, error) {
+	return false, nil
+}
+
+func TestClaimJob(t *testing.T) {
+	const poster, carrier = 1, 5
+	claimed := openJob("job", poster)
+	claimed.Status = models.JobStatusClaimed
+	tests := []struct {
+		name      string
+		job       *models.Job
+		carrierID int
+		carriers  repository.CarrierRepository
+		want      error
+	}{
+		{"open job", openJob("job", poster), carrier, verifiedCarriers{}, nil},
+		{"own job", openJob("job", poster), poster, verifiedCarriers{}, ErrCannotClaimOwnJob},
+		{"unverified carrier", openJob("job", poster), carrier, unverifiedCarriers{}, ErrCarrierNotVerified},
+		{"already claimed", claimed, carrier, verifiedCarriers{}, ErrJobNotOpen},
+	}
+	for _, tt := range tests {
+		jobs := newFakeJobRepo(tt.job)
+		svc := NewJobService(jobs, newFakeUserRepo(), nil, tt.carriers, nil, flatFees{}, nil)
+		job, err := svc.ClaimJob("job", tt.carrierID)
+		if err != tt.want {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.want)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if job.Status != models.JobStatusClaimed || job.CarrierID == nil || *job.CarrierID != carrier || job.CutAmount != usd(10000) {
+			t.Errorf("%s: claimed job = %+v", tt.name, job)
+		}
+		if len(jobs.events) != 1 || jobs.events[0].Type != models.EventJobClaimed {
+			t.Errorf("%s: events = %+v", tt.name, jobs.events)
+		}
+	}
+}
+
+// Груз забирает и доставляет только назначенный перевозчик, отменяет — заказчик,
+// и только в порядке жизненного цикла
+func TestJobLifecycle(t *testing.T) {
+	const poster, carrier, other = 1, 5, 6
+	job := openJob("job", poster)
+	jobs := newFakeJobRepo(job)
+	svc := newTestJobService(jobs)
+	if _, err := svc.ClaimJob("job", carrier); err != nil {
+		t.Fatal(err)
+	}
+
+	steps := []struct {
+		name   string
+		do     func(id string, userID int) (*models.Job, error)
+		userID int
+		want   error
+		status models.JobStatus
+	}{
+		{"deliver before pickup", svc.DeliverJob, carrier, ErrInvalidTransition, models.JobStatusClaimed},
+		{"start by the poster", svc.StartJob, poster, ErrJobForbidden, models.JobStatusClaimed},
+		{"start by another carrier", svc.StartJob, other, ErrJobForbidden, models.JobStatusClaimed},
+		{"start", svc.StartJob, carrier, nil, models.JobStatusInTransit},
+		{"cancel in transit", svc.CancelJob, poster, ErrInvalidTransition, models.JobStatusInTransit},
+		{"deliver", svc.DeliverJob, carrier, nil, models.JobStatusDelivered},
+		{"start again", svc.StartJob, carrier, ErrInvalidTransition, models.JobStatusDelivered},
+	}
+	for _, step := range steps {
+		if _, err := step.do("job", step.userID); err != step.want {
+			t.Errorf("%s: err = %v, want %v", step.name, err, step.want)
+		}
+		if status := jobs.jobs["job"].Status; status != step.status {
+			t.Errorf("after %s: status = %s, want %s", step.name, status, step.status)
+		}
+	}
+
+	var types []models.EventType
+	for _, event := range jobs.events {
+		types = append(types, event.Type)
+	}
+	want := []models.EventType{models.EventJobClaimed, models.EventJobStarted, models.EventJobDelivered}
+	if !slices.Equal(types, want) {
+		t.Errorf("events = %v, want %v", types, want)
+	}
+}
+
+func TestCancelClaimedJob(t *testing.T) {
+	job := openJob("job", 1)
+	job.Status = models.JobStatusClaimed
+	jobs := newFakeJobRepo(job)
+	if _, err := newTestJobService(jobs).CancelJob("job", 1); err != nil {
+		t.Fatal(err)
+	}
+	if jobs.jobs["job"].Status != models.JobStatusCancelled || jobs.events[0].Type != models.EventJobCancelled {
+		t.Errorf("status = %s, events = %+v", jobs.jobs["job"].Status, jobs.events)
+	}
+}
+
+// Адреса без координат геокодируются по ZIP, расстояние перевозки считается по ним
+func TestJobAddressesAndDistance(t *testing.T) {
+	jobs := newFakeJobRepo(openJob("job", 1))
+	svc := NewJobService(jobs, newFakeUserRepo(), nil, verifiedCarriers{}, geo.NewZIPGeocoder(), flatFees{}, nil)
+
+	req := jobRequest(openJob("job", 1))
+	req.PickupAddress = models.Address{Street: "1 Main St", ZIP: "10001"}
+	req.DeliveryAddress = models.Address{Street: "2 Market St", ZIP: "19103-1234"}
+	job, err := svc.UpdateJob("job", 1, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.PickupAddress.Latitude != 40.7506 || job.DeliveryAddress.Longitude != -75.1740 {
+		t.Errorf("addresses were not geocoded: %+v, %+v", job.PickupAddress, job.DeliveryAddress)
+	}
+	if job.DistanceMiles < 75 || job.DistanceMiles > 85 {
+		t.Errorf("distance = %.1f miles, want about 80", job.DistanceMiles)
+	}
+
+	for name, addr := range map[string]models.Address{
+		"unknown zip":            {Street: "1 Main St", ZIP: "99999"},
+		"no zip, no coordinates": {Street: "1 Main St"},
+	} {
+		req.DeliveryAddress = addr
+		if _, err := svc.UpdateJob("job", 1, req); err != ErrInvalidAddress {
+			t.Errorf("%s: err = %v, want ErrInvalidAddress", name, err)
+		}
+	}
+}
+
+func TestResolveFilter(t *testing.T) {
+	svc := NewJobService(newFakeJobRepo(), newFakeUserRepo(), nil, verifiedCarriers{}, geo.NewZIPGeocoder(), flatFees{}, nil)
+	filter := models.JobFilter{
+		Origin:      &models.GeoRadius{ZIP: "60601", Miles: 50},
+		Destination: &models.GeoRadius{Latitude: 34.0614, Longitude: -118.2385, Miles: 25},
+	}
+	if err := svc.ResolveFilter(&filter); err != nil {
+		t.Fatal(err)
+	}
+	if filter.Origin.Latitude != 41.8858 || filter.Origin.Longitude != -87.6181 || filter.Destination.Latitude != 34.0614 {
+		t.Errorf("origin = %+v, destination = %+v", filter.Origin, filter.Destination)
+	}
+	filter.Origin = &models.GeoRadius{ZIP: "00000", Miles: 50}
+	if err := svc.ResolveFilter(&filter); err != ErrInvalidAddress {
+		t.Errorf("unknown zip: err = %v, want ErrInvalidAddress", err)
+	}
+}
+
+// backhaulJobs отдаёт заранее заданных кандидатов и запоминает фильтр
+type backhaulJobs struct {
+	*fakeJobRepo
+	candidates []*models.Job
+	filter     models.JobFilter
+}
+
+func (r *backhaulJobs) GetJobs(filter models.JobFilter, limit, offset int) ([]*models.Job, int, error) {
+	r.filter = filter
+	return r.candidates, len(r.candidates), nil
+}
+
+func TestGetBackhauls(t *testing.T) {
+	current := openJob("current", 1)
+	current.Status = models.JobStatusInTransit
+	current.DeliveryDateTime = time.Date(2026, 11, 2, 15, 0, 0, 0, time.UTC)
+	nearby := openJob("nearby", 2)
+	nearby.PickupAddress = models.Address{ZIP: "19103", Latitude: 39.9522, Longitude: -75.1741}
+	further := openJob("further", 3)
+	further.PickupAddress = models.Address{ZIP: "10001", Latitude: 40.7506, Longitude: -73.9972}
+	jobs := &backhaulJobs{fakeJobRepo: newFakeJobRepo(current), candidates: []*models.Job{nearby, further}}
+	svc := newTestJobService(jobs.fakeJobRepo)
+	svc.repo = jobs
+
+	backhauls, total, err := svc.GetBackhauls("current", 100, 20, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := jobs.filter
+	if f.Status != string(models.JobStatusOpen) || f.Sort != models.JobSortDeadhead || f.DateStart == nil || !f.DateStart.Equal(current.DeliveryDateTime) {
+		t.Errorf("filter = %+v", f)
+	}
+	if f.Origin == nil || f.Origin.Latitude != current.DeliveryAddress.Latitude || f.Origin.Longitude != current.DeliveryAddress.Longitude || f.Origin.Miles != 100 {
+		t.Errorf("origin = %+v, want delivery point of the current job", f.Origin)
+	}
+	if total != 2 || len(backhauls) != 2 {
+		t.Fatalf("total = %d, backhauls = %d", total, len(backhauls))
+	}
+	if backhauls[0].DeadheadMiles > 0.1 || backhauls[1].DeadheadMiles < 75 || backhauls[1].DeadheadMiles > 85 {
+		t.Errorf("deadhead = %.1f, %.1f", backhauls[0].DeadheadMiles, backhauls[1].DeadheadMiles)
+	}
+
+	noCoordinates := openJob("ungeocoded", 1)
+	noCoordinates.DeliveryAddress = models.Address{ZIP: "19103"}
+	jobs.jobs["ungeocoded"] = noCoordinates
+	if _, _, err := svc.GetBackhauls("ungeocoded", 100, 20, 0); err != ErrInvalidAddress {
+		t.Errorf("delivery without coordinates: err = %v, want ErrInvalidAddress", err)
+	}
+	if _, _, err := svc.GetBackhauls("missing", 100, 20, 0); err != ErrJobNotFound {
+		t.Errorf("missing job: err = %v, want ErrJobNotFound", err)
+	}
+}
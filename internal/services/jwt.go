@@ -2,68 +2,234 @@ package services
 
 import (
 	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/pem"
 	"errors"
+	"fmt"
+	"math/big"
+	"moveshare/internal/models"
 	"os"
+	"path/filepath"
+	"sort"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
+const (
+	// AccessTokenTTL — время жизни access-токена
+	AccessTokenTTL = time.Hour
+	// ChallengeTokenTTL — сколько времени есть на ввод второго фактора после пароля
+	ChallengeTokenTTL = 5 * time.Minute
+
+	challengePurpose = "2fa_challenge"
+)
+
+// AccessClaims — данные, извлечённые из проверенного access-токена
+type AccessClaims struct {
+	UserID int
+	Email  string
+	Role   models.UserRole
+	// CompanyID — активная компания; 0, если пользователь работает от своего имени
+	CompanyID int
+	JTI       string
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+}
+
 type JWTService interface {
-	GenerateToken(userID int, email string) (string, error)
-	ValidateToken(tokenString string) (int, error)
+	GenerateToken(user *models.User) (string, error)
+	ValidateToken(tokenString string) (*AccessClaims, error)
+	GenerateChallengeToken(userID int) (string, error)
+	ValidateChallengeToken(tokenString string) (*AccessClaims, error)
+	JWKS() models.JWKS
 }
 
-type jwtService struct {
+type signingKey struct {
+	kid        string
 	privateKey *rsa.PrivateKey
-	publicKey  *rsa.PublicKey
 }
 
-func NewJWTService(privateKeyPath string) (JWTService, error) {
-	keyBytes, err := os.ReadFile(privateKeyPath)
+// jwtService подписывает токены активным ключом, а проверяет любым из
+// загруженных — так ключи можно ротировать, не разлогинивая пользователей
+type jwtService struct {
+	active *signingKey
+	keys   map[string]*signingKey
+}
+
+// NewJWTService загружает все *.pem из keysDir. Токены подписываются ключом
+// из файла activeKey, а если он не задан — последним по алфавиту файлом
+func NewJWTService(keysDir, activeKey string) (JWTService, error) {
+	paths, err := filepath.Glob(filepath.Join(keysDir, "*.pem"))
 	if err != nil {
 		return nil, err
 	}
-	privKey, err := jwt.ParseRSAPrivateKeyFromPEM(keyBytes)
-	if err != nil {
-		return nil, err
+	sort.Strings(paths)
+
+	svc := &jwtService{keys: make(map[string]*signingKey)}
+	for _, path := range paths {
+		keyBytes, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		// public.pem и прочие публичные ключи пропускаем, а испорченный
+		// приватный ключ — ошибка: иначе сервер молча подпишет токены другим
+		if block, _ := pem.Decode(keyBytes); block != nil && (block.Type == "PUBLIC KEY" || block.Type == "RSA PUBLIC KEY") {
+			continue
+		}
+		privKey, err := jwt.ParseRSAPrivateKeyFromPEM(keyBytes)
+		if err != nil {
+			return nil, fmt.Errorf("parse RSA private key %s: %w", path, err)
+		}
+		key := &signingKey{kid: keyID(&privKey.PublicKey), privateKey: privKey}
+		svc.keys[key.kid] = key
+		if activeKey == "" || filepath.Base(path) == activeKey {
+			svc.active = key
+		}
+	}
+	if svc.active == nil {
+		return nil, fmt.Errorf("no active RSA private key found in %s", keysDir)
 	}
-	return &jwtService{
-		privateKey: privKey,
-		publicKey:  &privKey.PublicKey,
-	}, nil
+	return svc, nil
+}
+
+func (j *jwtService) GenerateToken(user *models.User) (string, error) {
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"user_id": user.ID,
+		"email":   user.Email,
+		"role":    string(user.Role),
+		"jti":     uuid.New().String(),
+		"exp":     now.Add(AccessTokenTTL).Unix(),
+		"iat":     now.Unix(),
+	}
+	if user.ActiveCompanyID != nil {
+		claims["company_id"] = *user.ActiveCompanyID
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = j.active.kid
+	return token.SignedString(j.active.privateKey)
 }
 
-func (j *jwtService) GenerateToken(userID int, email string) (string, error) {
+// GenerateChallengeToken выпускает короткоживущий токен, подтверждающий,
+// что пароль верен. Как access-токен он не принимается
+func (j *jwtService) GenerateChallengeToken(userID int) (string, error) {
+	now := time.Now()
 	claims := jwt.MapClaims{
 		"user_id": userID,
-		"email":   email,
-		"exp":     time.Now().Add(time.Hour * 1).Unix(),
-		"iat":     time.Now().Unix(),
+		"purpose": challengePurpose,
+		"jti":     uuid.New().String(),
+		"exp":     now.Add(ChallengeTokenTTL).Unix(),
+		"iat":     now.Unix(),
 	}
 	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
-	return token.SignedString(j.privateKey)
+	token.Header["kid"] = j.active.kid
+	return token.SignedString(j.active.privateKey)
 }
 
-// ValidateToken validates JWT, returns userID if ok
-func (j *jwtService) ValidateToken(tokenString string) (int, error) {
+// ValidateToken validates JWT, returns its claims if ok
+func (j *jwtService) ValidateToken(tokenString string) (*AccessClaims, error) {
+	return j.validate(tokenString, "")
+}
+
+func (j *jwtService) ValidateChallengeToken(tokenString string) (*AccessClaims, error) {
+	return j.validate(tokenString, challengePurpose)
+}
+
+// validate проверяет подпись и срок токена, а также что его назначение
+// (claim purpose) совпадает с ожидаемым; у access-токенов purpose нет
+func (j *jwtService) validate(tokenString, purpose string) (*AccessClaims, error) {
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
 		// Проверяем, что используется правильный signing method
 		if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
 			return nil, errors.New("unexpected signing method")
 		}
-		return j.publicKey, nil
+		kid, ok := token.Header["kid"].(string)
+		if !ok {
+			// Токены, выпущенные до появления kid, подписаны активным ключом
+			return &j.active.privateKey.PublicKey, nil
+		}
+		key, ok := j.keys[kid]
+		if !ok {
+			return nil, errors.New("unknown signing key")
+		}
+		return &key.privateKey.PublicKey, nil
 	})
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 
 	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
+		if p, _ := claims["purpose"].(string); p != purpose {
+			return nil, errors.New("unexpected token purpose")
+		}
 		uid, ok := claims["user_id"].(float64)
 		if !ok {
-			return 0, errors.New("user_id not found or invalid")
+			return nil, errors.New("user_id not found or invalid")
+		}
+		jti, ok := claims["jti"].(string)
+		if !ok {
+			return nil, errors.New("jti not found or invalid")
 		}
-		return int(uid), nil
+		exp, err := claims.GetExpirationTime()
+		if err != nil || exp == nil {
+			return nil, errors.New("exp not found or invalid")
+		}
+		iat, err := claims.GetIssuedAt()
+		if err != nil || iat == nil {
+			return nil, errors.New("iat not found or invalid")
+		}
+		email, _ := claims["email"].(string)
+		role, _ := claims["role"].(string)
+		companyID, _ := claims["company_id"].(float64)
+		return &AccessClaims{
+			UserID:    int(uid),
+			Email:     email,
+			Role:      models.UserRole(role),
+			CompanyID: int(companyID),
+			JTI:       jti,
+			IssuedAt:  iat.Time,
+			ExpiresAt: exp.Time,
+		}, nil
+	}
+	return nil, errors.New("invalid token")
+}
+
+// JWKS возвращает публичные части всех ключей, которыми могут быть подписаны действующие токены
+func (j *jwtService) JWKS() models.JWKS {
+	kids := make([]string, 0, len(j.keys))
+	for kid := range j.keys {
+		kids = append(kids, kid)
 	}
-	return 0, errors.New("invalid token")
+	sort.Strings(kids)
+
+	set := models.JWKS{Keys: make([]models.JWK, 0, len(kids))}
+	for _, kid := range kids {
+		pub := j.keys[kid].privateKey.PublicKey
+		n, e := rsaComponents(&pub)
+		set.Keys = append(set.Keys, models.JWK{
+			Kty: "RSA",
+			Use: "sig",
+			Alg: "RS256",
+			Kid: kid,
+			N:   n,
+			E:   e,
+		})
+	}
+	return set
+}
+
+// keyID — отпечаток публичного ключа по RFC 7638
+func keyID(pub *rsa.PublicKey) string {
+	n, e := rsaComponents(pub)
+	sum := sha256.Sum256([]byte(`{"e":"` + e + `","kty":"RSA","n":"` + n + `"}`))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func rsaComponents(pub *rsa.PublicKey) (n, e string) {
+	n = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+	e = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+	return n, e
 }
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"moveshare/internal/models"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeKeys генерирует RSA-ключ и кладёт в dir приватный ключ в file и,
+// если publicFile задан, публичный
+func writeKeys(t *testing.T, dir, file, publicFile string) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writePEM(t, filepath.Join(dir, file), "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(key))
+	if publicFile != "" {
+		public, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		writePEM(t, filepath.Join(dir, publicFile), "PUBLIC KEY", public)
+	}
+	return key
+}
+
+func writePEM(t *testing.T, path, blockType string, der []byte) {
+	t.Helper()
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNewJWTServiceSelectsActiveKey(t *testing.T) {
+	dir := t.TempDir()
+	older := writeKeys(t, dir, "2026-01.pem", "public.pem")
+	newer := writeKeys(t, dir, "2026-10.pem", "")
+
+	tests := []struct {
+		activeKey string
+		want      *rsa.PrivateKey
+	}{
+		{"", newer},
+		{"2026-01.pem", older},
+		{"2026-10.pem", newer},
+	}
+	for _, tt := range tests {
+		svc, err := NewJWTService(dir, tt.activeKey)
+		if err != nil {
+			t.Fatalf("active %q: %v", tt.activeKey, err)
+		}
+		s := svc.(*jwtService)
+		if s.active.kid != keyID(&tt.want.PublicKey) {
+			t.Errorf("active %q: signing with kid %s", tt.activeKey, s.active.kid)
+		}
+		// public.pem пропущен, оба приватных ключа опубликованы и принимаются
+		if jwks := svc.JWKS(); len(jwks.Keys) != 2 {
+			t.Errorf("active %q: jwks has %d keys, want 2", tt.activeKey, len(jwks.Keys))
+		}
+	}
+}
+
+// Токен, подписанный старым ключом, принимается и после смены активного
+func TestNewJWTServiceAcceptsRotatedKeys(t *testing.T) {
+	dir := t.TempDir()
+	writeKeys(t, dir, "2026-01.pem", "")
+	writeKeys(t, dir, "2026-10.pem", "")
+
+	old, err := NewJWTService(dir, "2026-01.pem")
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := old.GenerateToken(&models.User{ID: 7, Email: "carrier@example.com", Role: models.RoleCarrier})
+	if err != nil {
+		t.Fatal(err)
+	}
+	current, err := NewJWTService(dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := current.ValidateToken(token)
+	if err != nil || claims.UserID != 7 {
+		t.Errorf("token signed with the previous key: claims = %+v, err = %v", claims, err)
+	}
+}
+
+func TestNewJWTServiceErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		setup     func(t *testing.T, dir string)
+		activeKey string
+		wantInErr string
+	}{
+		{
+			name: "corrupt private key",
+			setup: func(t *testing.T, dir string) {
+				writeKeys(t, dir, "2026-01.pem", "")
+				writePEM(t, filepath.Join(dir, "2026-10.pem"), "RSA PRIVATE KEY", []byte("not a key"))
+			},
+			wantInErr: "2026-10.pem",
+		},
+		{
+			name: "not a pem file",
+			setup: func(t *testing.T, dir string) {
+				os.WriteFile(filepath.Join(dir, "broken.pem"), []byte("garbage"), 0o600)
+			},
+			wantInErr: "broken.pem",
+		},
+		{
+			name: "only a public key",
+			setup: func(t *testing.T, dir string) {
+				writeKeys(t, dir, "private.pem", "public.pem")
+				os.Remove(filepath.Join(dir, "private.pem"))
+			},
+			wantInErr: "no active RSA private key",
+		},
+		{
+			name: "unknown active key",
+			setup: func(t *testing.T, dir string) {
+				writeKeys(t, dir, "2026-01.pem", "")
+			},
+			activeKey: "2027-01.pem",
+			wantInErr: "no active RSA private key",
+		},
+	}
+	for _, tt := range tests {
+		dir := t.TempDir()
+		tt.setup(t, dir)
+		_, err := NewJWTService(dir, tt.activeKey)
+		if err == nil || !strings.Contains(err.Error(), tt.wantInErr) {
+			t.Errorf("%s: err = %v, want it to mention %q", tt.name, err, tt.wantInErr)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package services
+
+import (
+	"errors"
+	"moveshare/internal/models"
+	"moveshare/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidDateRange   = errors.New("invalid date range")
+	ErrInvalidAccountType = errors.New("invalid account type")
+)
+
+// LedgerService — учёт денег двойной записью: деньги заказчиков, долги
+// перевозчикам, выручка площадки и деньги на счёте эскроу
+type LedgerService interface {
+	// GetBalances — личные счета пользователя с остатками
+	GetBalances(userID int) ([]*models.LedgerAccount, error)
+	// GetStatement — выписка по счетам пользователя за [from, to)
+	GetStatement(userID int, from, to time.Time) (*models.Statement, error)
+	// GetAccounts — все счета типа accountType, для сверки
+	GetAccounts(accountType models.AccountType) ([]*models.LedgerAccount, error)
+	// CompletionJournal — проводки завершения работы: оплата заказчика становится
+	// долгом перевозчику и выручкой площадки. У работ без эскроу проводок нет.
+	// Пока оплата не списана, возвращает ErrEscrowNotFunded и работа не завершается
+	CompletionJournal(job *models.Job) ([]*models.JournalEntry, error)
+}
+
+type ledgerService struct {
+	repo        repository.LedgerRepository
+	paymentRepo repository.PaymentRepository
+}
+
+func NewLedgerService(repo repository.LedgerRepository, paymentRepo repository.PaymentRepository) LedgerService {
+	return &ledgerService{repo: repo, paymentRepo: paymentRepo}
+}
+
+func (s *ledgerService) GetBalances(userID int) ([]*models.LedgerAccount, error) {
+	return s.repo.GetUserAccounts(userID)
+}
+
+func (s *ledgerService) GetStatement(userID int, from, to time.Time) (*models.Statement, error) {
+	if !from.Before(to) {
+		return nil, ErrInvalidDateRange
+	}
+	return s.repo.GetStatement(userID, from.UTC(), to.UTC())
+}
+
+func (s *ledgerService) GetAccounts(accountType models.AccountType) ([]*models.LedgerAccount, error) {
+	switch accountType {
+	case models.AccountPosterBalance, models.AccountCarrierPayable, models.AccountPlatformRevenue, models.AccountEscrow:
+	default:
+		return nil, ErrInvalidAccountType
+	}
+	return s.repo.GetAccounts(accountType)
+}
+
+func (s *ledgerService) CompletionJournal(job *models.Job) ([]*models.JournalEntry, error) {
+	escrow, err := s.paymentRepo.GetEscrow(job.ID)
+	if errors.Is(err, repository.ErrEscrowNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	// Списание идёт асинхронно из outbox и могло ещё не пройти: без него
+	// выручка и долг перевозчику были бы проведены под несобранные деньги
+	if escrow.Status != models.EscrowFunded {
+		return nil, ErrEscrowNotFunded
+	}
+	earned, err := escrow.CarrierNet.Add(escrow.PlatformFee)
+	if err != nil {
+		return nil, err
+	}
+	// Доля брокера остаётся на балансе заказчика до выплаты
+	return journal(escrowJournal(models.JournalJobCompleted, escrow, "Job completed",
+		debit(models.AccountPosterBalance, &escrow.PayerID, earned),
+		credit(models.AccountCarrierPayable, &escrow.PayeeID, escrow.CarrierNet),
+		credit(models.AccountPlatformRevenue, nil, escrow.PlatformFee),
+	)), nil
+}
+
+// escrowJournal — проводка по эскроу. Каждая операция проводится по эскроу один
+// раз, поэтому ключ дедупликации — тип и эскроу. Движения с нулевой суммой
+// отбрасываются; если осталось меньше двух, проводки нет (nil)
+func escrowJournal(kind models.JournalEntryType, escrow *models.Escrow, description string, postings ...*models.Posting) *models.JournalEntry {
+	nonZero := []*models.Posting{}
+	for _, posting := range postings {
+		if !posting.Amount.IsZero() {
+			nonZero = append(nonZero, posting)
+		}
+	}
+	if len(nonZero) < 2 {
+		return nil
+	}
+	jobID := escrow.JobID
+	return &models.JournalEntry{
+		ID:          uuid.New().String(),
+		Type:        kind,
+		JobID:       &jobID,
+		Description: description,
+		DedupKey:    string(kind) + ":" + escrow.ID,
+		Postings:    nonZero,
+	}
+}
+
+// journal собирает непустые проводки
+func journal(entries ...*models.JournalEntry) []*models.JournalEntry {
+	result := []*models.JournalEntry{}
+	for _, entry := range entries {
+		if entry != nil {
+			result = append(result, entry)
+		}
+	}
+	return result
+}
+
+func debit(accountType models.AccountType, userID *int, amount models.Money) *models.Posting {
+	return &models.Posting{AccountType: accountType, UserID: userID, Amount: amount.WithDefaultCurrency()}
+}
+
+func credit(accountType models.AccountType, userID *int, amount models.Money) *models.Posting {
+	amount = amount.WithDefaultCurrency()
+	return &models.Posting{AccountType: accountType, UserID: userID, Amount: models.NewMoney(-amount.Amount, amount.Currency)}
+}
@@ -0,0 +1,50 @@
+package services
+
+import (
+	"moveshare/internal/models"
+	"testing"
+)
+
+func TestCompletionJournal(t *testing.T) {
+	tests := []struct {
+		status  models.EscrowStatus
+		wantErr error
+	}{
+		{models.EscrowPending, ErrEscrowNotFunded},
+		{models.EscrowCancelled, ErrEscrowNotFunded},
+		{models.EscrowRefunded, ErrEscrowNotFunded},
+		{models.EscrowFunded, nil},
+	}
+	for _, tt := range tests {
+		_, repo, _ := newTestPaymentService()
+		repo.escrow.Status = tt.status
+		entries, err := NewLedgerService(nil, repo).CompletionJournal(&models.Job{ID: "j1"})
+		if err != tt.wantErr {
+			t.Errorf("%s escrow: err = %v, want %v", tt.status, err, tt.wantErr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		// Заказчик отдаёт оплату без доли брокера: она остаётся у него до выплаты
+		balances := assertBalanced(t, entries)
+		want := map[models.AccountType]int64{
+			models.AccountPosterBalance:   95000,
+			models.AccountCarrierPayable:  -85000,
+			models.AccountPlatformRevenue: -10000,
+		}
+		for account, amount := range want {
+			if balances[account] != amount {
+				t.Errorf("%s = %d, want %d", account, balances[account], amount)
+			}
+		}
+	}
+}
+
+func TestCompletionJournalWithoutEscrow(t *testing.T) {
+	_, repo, _ := newTestPaymentService()
+	entries, err := NewLedgerService(nil, repo).CompletionJournal(&models.Job{ID: "other"})
+	if err != nil || len(entries) != 0 {
+		t.Errorf("job without escrow: entries = %v, err = %v", entries, err)
+	}
+}
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"errors"
+	"moveshare/internal/models"
+	"moveshare/internal/repository"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+const maxMessageLength = 4000
+
+var (
+	ErrThreadNotOpen   = errors.New("thread opens after the carrier bids on or claims the job")
+	ErrThreadForbidden = errors.New("not a participant of this thread")
+	ErrInvalidMessage  = errors.New("invalid message")
+)
+
+// MessageService — переписка заказчика с перевозчиками внутри работы.
+// Переписка с перевозчиком открывается после его ставки или взятия работы.
+type MessageService interface {
+	GetThreads(jobID string, userID int) ([]*models.MessageThread, error)
+	GetMessages(jobID string, carrierID, userID, limit, offset int) ([]*models.Message, int, error)
+	SendMessage(jobID string, carrierID, userID int, req models.SendMessageRequest) (*models.Message, error)
+	MarkRead(jobID string, carrierID, userID int) (int, error)
+}
+
+type messageService struct {
+	messageRepo repository.MessageRepository
+	jobRepo     repository.JobRepository
+}
+
+func NewMessageService(messageRepo repository.MessageRepository, jobRepo repository.JobRepository) MessageService {
+	return &messageService{messageRepo: messageRepo, jobRepo: jobRepo}
+}
+
+// GetThreads: заказчик видит все переписки по работе, перевозчик — только свою
+func (s *messageService) GetThreads(jobID string, userID int) ([]*models.MessageThread, error) {
+	job, err := s.jobRepo.GetJobByID(jobID)
+	if err != nil {
+		return nil, mapJobError(err)
+	}
+	manager, err := canManageJob(s.jobRepo, job, userID)
+	if err != nil {
+		return nil, err
+	}
+	if manager {
+		return s.messageRepo.GetThreadsByJob(jobID, 0, false)
+	}
+	return s.messageRepo.GetThreadsByJob(jobID, userID, true)
+}
+
+func (s *messageService) GetMessages(jobID string, carrierID, userID, limit, offset int) ([]*models.Message, int, error) {
+	isCarrier, err := s.participant(jobID, carrierID, userID)
+	if err != nil {
+		return nil, 0, err
+	}
+	thread, err := s.messageRepo.GetThread(jobID, carrierID, isCarrier)
+	if errors.Is(err, repository.ErrThreadNotFound) {
+		return []*models.Message{}, 0, nil
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	return s.messageRepo.GetMessages(thread.ID, limit, offset)
+}
+
+// SendMessage пишет в переписку работы jobID с перевозчиком carrierID,
+// создавая её при первом сообщении
+func (s *messageService) SendMessage(jobID string, carrierID, userID int, req models.SendMessageRequest) (*models.Message, error) {
+	body := strings.TrimSpace(req.Body)
+	if body == "" || len(body) > maxMessageLength {
+		return nil, ErrInvalidMessage
+	}
+	isCarrier, err := s.participant(jobID, carrierID, userID)
+	if err != nil {
+		return nil, err
+	}
+	canOpen, err := s.messageRepo.CanOpenThread(jobID, carrierID)
+	if err != nil {
+		return nil, err
+	}
+	if !canOpen {
+		return nil, ErrThreadNotOpen
+	}
+	thread, err := s.messageRepo.GetOrCreateThread(jobID, carrierID)
+	if err != nil {
+		return nil, err
+	}
+	return s.messageRepo.CreateMessage(&models.Message{
+		ID:          uuid.New().String(),
+		ThreadID:    thread.ID,
+		SenderID:    userID,
+		FromCarrier: isCarrier,
+		Body:        body,
+	})
+}
+
+// MarkRead отмечает прочитанными сообщения другой стороны; возвращает их число
+func (s *messageService) MarkRead(jobID string, carrierID, userID int) (int, error) {
+	isCarrier, err := s.participant(jobID, carrierID, userID)
+	if err != nil {
+		return 0, err
+	}
+	thread, err := s.messageRepo.GetThread(jobID, carrierID, isCarrier)
+	if errors.Is(err, repository.ErrThreadNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return s.messageRepo.MarkRead(thread.ID, isCarrier)
+}
+
+// participant проверяет, что userID участвует в переписке работы с перевозчиком
+// carrierID, и сообщает, на чьей он стороне (true — перевозчик)
+func (s *messageService) participant(jobID string, carrierID, userID int) (bool, error) {
+	job, err := s.jobRepo.GetJobByID(jobID)
+	if err != nil {
+		return false, mapJobError(err)
+	}
+	if userID == carrierID {
+		return true, nil
+	}
+	manager, err := canManageJob(s.jobRepo, job, userID)
+	if err != nil {
+		return false, err
+	}
+	if !manager {
+		return false, ErrThreadForbidden
+	}
+	return false, nil
+}
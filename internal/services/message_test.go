@@ -0,0 +1,164 @@
+package services
+
+import (
+	"moveshare/internal/models"
+	"moveshare/internal/repository"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeMessageRepo хранит переписки в памяти; открыть переписку можно с
+// перевозчиками из bidders
+type fakeMessageRepo struct {
+	repository.MessageRepository
+	bidders  map[int]bool
+	threads  map[int]*models.MessageThread
+	messages []*models.Message
+}
+
+func newFakeMessageRepo(bidders ...int) *fakeMessageRepo {
+	r := &fakeMessageRepo{bidders: map[int]bool{}, threads: map[int]*models.MessageThread{}}
+	for _, id := range bidders {
+		r.bidders[id] = true
+	}
+	return r
+}
+
+func (r *fakeMessageRepo) CanOpenThread(jobID string, carrierID int) (bool, error) {
+	return r.bidders[carrierID], nil
+}
+
+func (r *fakeMessageRepo) GetOrCreateThread(jobID string, carrierID int) (*models.MessageThread, error) {
+	if _, ok := r.threads[carrierID]; !ok {
+		r.threads[carrierID] = &models.MessageThread{ID: "thread-" + strconv.Itoa(carrierID), JobID: jobID, CarrierID: carrierID}
+	}
+	return r.threads[carrierID], nil
+}
+
+func (r *fakeMessageRepo) GetThread(jobID string, carrierID int, viewerIsCarrier bool) (*models.MessageThread, error) {
+	thread, ok := r.threads[carrierID]
+	if !ok {
+		return nil, repository.ErrThreadNotFound
+	}
+	return thread, nil
+}
+
+func (r *fakeMessageRepo) GetThreadsByJob(jobID string, carrierID int, viewerIsCarrier bool) ([]*models.MessageThread, error) {
+	var threads []*models.MessageThread
+	for id, thread := range r.threads {
+		if carrierID == 0 || id == carrierID {
+			threads = append(threads, thread)
+		}
+	}
+	return threads, nil
+}
+
+func (r *fakeMessageRepo) CreateMessage(msg *models.Message) (*models.Message, error) {
+	msg.CreatedAt = time.Now()
+	r.messages = append(r.messages, msg)
+	return msg, nil
+}
+
+func (r *fakeMessageRepo) GetMessages(threadID string, limit, offset int) ([]*models.Message, int, error) {
+	var msgs []*models.Message
+	for _, msg := range r.messages {
+		if msg.ThreadID == threadID {
+			msgs = append(msgs, msg)
+		}
+	}
+	return msgs, len(msgs), nil
+}
+
+func (r *fakeMessageRepo) MarkRead(threadID string, viewerIsCarrier bool) (int, error) {
+	n := 0
+	for _, msg := range r.messages {
+		if msg.ThreadID == threadID && msg.FromCarrier != viewerIsCarrier && msg.ReadAt == nil {
+			now := time.Now()
+			msg.ReadAt = &now
+			n++
+		}
+	}
+	return n, nil
+}
+
+const (
+	messagePoster  = 1
+	messageBidder  = 5
+	messageBidder2 = 6
+	messageOther   = 7
+)
+
+func newTestMessageService() (MessageService, *fakeMessageRepo) {
+	messages := newFakeMessageRepo(messageBidder, messageBidder2)
+	return NewMessageService(messages, newFakeJobRepo(openJob("job", messagePoster))), messages
+}
+
+func TestSendMessage(t *testing.T) {
+	tests := []struct {
+		name      string
+		carrierID int
+		senderID  int
+		body      string
+		want      error
+	}{
+		{"bidder writes to the poster", messageBidder, messageBidder, "Can pick up at 9am", nil},
+		{"poster replies", messageBidder, messagePoster, "Works for me", nil},
+		{"carrier without a bid", messageOther, messageOther, "Hello", ErrThreadNotOpen},
+		{"poster to a carrier without a bid", messageOther, messagePoster, "Hello", ErrThreadNotOpen},
+		{"bidder writes into another bidder's thread", messageBidder, messageBidder2, "Hello", ErrThreadForbidden},
+		{"empty body", messageBidder, messageBidder, "   ", ErrInvalidMessage},
+		{"body too long", messageBidder, messageBidder, strings.Repeat("a", maxMessageLength+1), ErrInvalidMessage},
+	}
+	for _, tt := range tests {
+		svc, _ := newTestMessageService()
+		if _, err := svc.SendMessage("job", tt.carrierID, tt.senderID, models.SendMessageRequest{Body: tt.body}); err != tt.want {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+	svc, _ := newTestMessageService()
+	if _, err := svc.SendMessage("missing", messageBidder, messageBidder, models.SendMessageRequest{Body: "Hello"}); err != ErrJobNotFound {
+		t.Errorf("missing job: err = %v, want ErrJobNotFound", err)
+	}
+}
+
+func TestMessageThreads(t *testing.T) {
+	svc, _ := newTestMessageService()
+	svc.SendMessage("job", messageBidder, messageBidder, models.SendMessageRequest{Body: " First "})
+	svc.SendMessage("job", messageBidder, messageBidder, models.SendMessageRequest{Body: "Second"})
+	svc.SendMessage("job", messageBidder2, messagePoster, models.SendMessageRequest{Body: "Are you available?"})
+
+	// Заказчик видит обе переписки, перевозчик — только свою
+	if threads, _ := svc.GetThreads("job", messagePoster); len(threads) != 2 {
+		t.Errorf("poster sees %d threads, want 2", len(threads))
+	}
+	if threads, _ := svc.GetThreads("job", messageBidder); len(threads) != 1 || threads[0].CarrierID != messageBidder {
+		t.Errorf("bidder sees %+v", threads)
+	}
+
+	msgs, total, err := svc.GetMessages("job", messageBidder, messagePoster, 20, 0)
+	if err != nil || total != 2 || msgs[0].Body != "First" || !msgs[0].FromCarrier {
+		t.Fatalf("messages = %+v, %d, %v", msgs, total, err)
+	}
+	if _, _, err := svc.GetMessages("job", messageBidder, messageBidder2, 20, 0); err != ErrThreadForbidden {
+		t.Errorf("another carrier reads the thread: err = %v, want ErrThreadForbidden", err)
+	}
+
+	// Свои сообщения прочитанными не отмечаются
+	if n, _ := svc.MarkRead("job", messageBidder, messageBidder); n != 0 {
+		t.Errorf("carrier marked %d own messages read", n)
+	}
+	if n, _ := svc.MarkRead("job", messageBidder, messagePoster); n != 2 {
+		t.Errorf("poster marked %d messages read, want 2", n)
+	}
+	if n, _ := svc.MarkRead("job", messageBidder, messagePoster); n != 0 {
+		t.Errorf("second mark read: %d, want 0", n)
+	}
+
+	// До первого сообщения переписка пуста, а не ошибка
+	msgs, total, err = svc.GetMessages("job", messageOther, messagePoster, 20, 0)
+	if err != nil || total != 0 || msgs == nil {
+		t.Errorf("thread without messages = %v, %d, %v", msgs, total, err)
+	}
+}
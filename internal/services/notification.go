@@ -0,0 +1,36 @@
+package services
+
+import (
+	"moveshare/internal/models"
+	"moveshare/internal/repository"
+)
+
+// NotificationService — уведомления пользователя в приложении
+type NotificationService interface {
+	GetNotifications(userID int, unreadOnly bool, limit, offset int) (*models.NotificationListResponse, error)
+	MarkAllRead(userID int) (int, error)
+}
+
+type notificationService struct {
+	repo repository.NotificationRepository
+}
+
+func NewNotificationService(repo repository.NotificationRepository) NotificationService {
+	return &notificationService{repo: repo}
+}
+
+func (s *notificationService) GetNotifications(userID int, unreadOnly bool, limit, offset int) (*models.NotificationListResponse, error) {
+	notifications, total, err := s.repo.GetNotifications(userID, unreadOnly, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	unread, err := s.repo.CountUnread(userID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.NotificationListResponse{Notifications: notifications, Total: total, Unread: unread}, nil
+}
+
+func (s *notificationService) MarkAllRead(userID int) (int, error) {
+	return s.repo.MarkAllRead(userID)
+}
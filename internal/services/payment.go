@@ -0,0 +1,188 @@
+package services
+
+import (
+	"errors"
+	"moveshare/internal/models"
+	"moveshare/internal/payments"
+	"moveshare/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrEscrowNotFound  = errors.New("escrow not found")
+	ErrEscrowNotFunded = errors.New("escrow is not funded yet")
+)
+
+// PaymentService проводит оплату работы через эскроу: при закреплении работы
+// деньги списываются с заказчика, при завершении выплачиваются перевозчику и
+// брокеру, при отмене возвращаются заказчику. Методы Fund, Release и Refund
+// вызываются из подписок на события работы и выдерживают повторы
+type PaymentService interface {
+	FundEscrow(job *models.Job) error
+	ReleaseEscrow(job *models.Job) error
+	RefundEscrow(job *models.Job) error
+	// GetEscrow — эскроу работы с журналом движений. Виден тем, кто управляет
+	// работой, и назначенному перевозчику
+	GetEscrow(jobID string, userID int) (*models.Escrow, error)
+}
+
+type paymentService struct {
+	repo     repository.PaymentRepository
+	jobRepo  repository.JobRepository
+	provider payments.Provider
+}
+
+func NewPaymentService(repo repository.PaymentRepository, jobRepo repository.JobRepository, provider payments.Provider) PaymentService {
+	return &paymentService{repo: repo, jobRepo: jobRepo, provider: provider}
+}
+
+// FundEscrow списывает оплату с заказчика. Уже списанное или отменённое эскроу не трогает
+func (s *paymentService) FundEscrow(job *models.Job) error {
+	return s.update(job.ID, func(escrow *models.Escrow) (*repository.EscrowChange, error) {
+		if escrow.Status != models.EscrowPending {
+			return nil, nil
+		}
+		charge, err := s.provider.Charge(payments.Charge{
+			IdempotencyKey: "escrow:" + escrow.ID + ":charge",
+			PayerID:        escrow.PayerID,
+			Amount:         escrow.Payment,
+			Description:    "MoveShare job " + escrow.JobID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		escrow.Status = models.EscrowFunded
+		escrow.ChargeRef = charge.Ref
+		return &repository.EscrowChange{
+			Movements: []*models.LedgerEntry{
+				s.entry(models.LedgerEscrowFunded, escrow.Payment, &escrow.PayerID, charge.Ref),
+			},
+			Journal: journal(escrowJournal(models.JournalEscrowFunded, escrow, "Escrow funded",
+				debit(models.AccountEscrow, nil, escrow.Payment),
+				credit(models.AccountPosterBalance, &escrow.PayerID, escrow.Payment),
+			)),
+		}, nil
+	})
+}
+
+// ReleaseEscrow выплачивает перевозчику его долю, а брокеру — долю брокера;
+// комиссия площадки остаётся на её счёте. Пока оплата не списана, возвращает
+// ErrEscrowNotFunded, и событие будет обработано повторно
+func (s *paymentService) ReleaseEscrow(job *models.Job) error {
+	return s.update(job.ID, func(escrow *models.Escrow) (*repository.EscrowChange, error) {
+		switch escrow.Status {
+		case models.EscrowPending:
+			return nil, ErrEscrowNotFunded
+		case models.EscrowFunded:
+		default:
+			return nil, nil
+		}
+		change := &repository.EscrowChange{Movements: []*models.LedgerEntry{}, Journal: []*models.JournalEntry{}}
+		payouts := []struct {
+			kind    models.LedgerEntryType
+			journal models.JournalEntryType
+			account models.AccountType
+			amount  models.Money
+			userID  int
+		}{
+			{models.LedgerCarrierPayout, models.JournalCarrierPayout, models.AccountCarrierPayable, escrow.CarrierNet, escrow.PayeeID},
+			{models.LedgerBrokerPayout, models.JournalBrokerPayout, models.AccountPosterBalance, escrow.BrokerCut, escrow.PayerID},
+		}
+		for _, p := range payouts {
+			if p.amount.IsZero() {
+				continue
+			}
+			payout, err := s.provider.Payout(payments.Payout{
+				IdempotencyKey: "escrow:" + escrow.ID + ":" + string(p.kind),
+				PayeeID:        p.userID,
+				Amount:         p.amount,
+				Description:    "MoveShare job " + escrow.JobID,
+			})
+			if err != nil {
+				return nil, err
+			}
+			userID := p.userID
+			change.Movements = append(change.Movements, s.entry(p.kind, p.amount, &userID, payout.Ref))
+			change.Journal = append(change.Journal, journal(escrowJournal(p.journal, escrow, "Payout "+payout.Ref,
+				debit(p.account, &userID, p.amount),
+				credit(models.AccountEscrow, nil, p.amount),
+			))...)
+		}
+		// Комиссия остаётся на счёте площадки: в двойной записи она уже стала
+		// выручкой при завершении работы, здесь только отметка в журнале движений
+		if !escrow.PlatformFee.IsZero() {
+			change.Movements = append(change.Movements, s.entry(models.LedgerPlatformFee, escrow.PlatformFee, nil, ""))
+		}
+		escrow.Status = models.EscrowReleased
+		return change, nil
+	})
+}
+
+// RefundEscrow возвращает заказчику списанную оплату. Если списать ещё не
+// успели, эскроу просто отменяется
+func (s *paymentService) RefundEscrow(job *models.Job) error {
+	return s.update(job.ID, func(escrow *models.Escrow) (*repository.EscrowChange, error) {
+		switch escrow.Status {
+		case models.EscrowPending:
+			escrow.Status = models.EscrowCancelled
+			return nil, nil
+		case models.EscrowFunded:
+		default:
+			return nil, nil
+		}
+		refund, err := s.provider.Refund(payments.Refund{
+			IdempotencyKey: "escrow:" + escrow.ID + ":refund",
+			ChargeRef:      escrow.ChargeRef,
+			Amount:         escrow.Payment,
+		})
+		if err != nil {
+			return nil, err
+		}
+		escrow.Status = models.EscrowRefunded
+		return &repository.EscrowChange{
+			Movements: []*models.LedgerEntry{
+				s.entry(models.LedgerEscrowRefunded, escrow.Payment, &escrow.PayerID, refund.Ref),
+			},
+			Journal: journal(escrowJournal(models.JournalEscrowRefunded, escrow, "Escrow refunded",
+				debit(models.AccountPosterBalance, &escrow.PayerID, escrow.Payment),
+				credit(models.AccountEscrow, nil, escrow.Payment),
+			)),
+		}, nil
+	})
+}
+
+func (s *paymentService) GetEscrow(jobID string, userID int) (*models.Escrow, error) {
+	if _, err := participantJob(s.jobRepo, jobID, userID); err != nil {
+		return nil, err
+	}
+	escrow, err := s.repo.GetEscrow(jobID)
+	if errors.Is(err, repository.ErrEscrowNotFound) {
+		return nil, ErrEscrowNotFound
+	}
+	return escrow, err
+}
+
+// update применяет переход к эскроу работы. Работы без эскроу (ещё открытые
+// или закреплённые до появления платежей) пропускаются
+func (s *paymentService) update(jobID string, transition repository.EscrowTransition) error {
+	_, err := s.repo.UpdateEscrow(jobID, transition)
+	if errors.Is(err, repository.ErrEscrowNotFound) {
+		return nil
+	}
+	return err
+}
+
+func (s *paymentService) entry(kind models.LedgerEntryType, amount models.Money, userID *int, ref string) *models.LedgerEntry {
+	entry := &models.LedgerEntry{
+		ID:          uuid.New().String(),
+		Type:        kind,
+		Amount:      amount,
+		UserID:      userID,
+		ProviderRef: ref,
+	}
+	if ref != "" {
+		entry.Provider = s.provider.Name()
+	}
+	return entry
+}
@@ -0,0 +1,281 @@
+package services
+
+import (
+	"errors"
+	"moveshare/internal/models"
+	"moveshare/internal/payments"
+	"moveshare/internal/repository"
+	"reflect"
+	"testing"
+)
+
+var errCommitFailed = errors.New("commit failed")
+
+// fakePaymentRepo — PaymentRepository в памяти. failCommit имитирует сбой
+// записи после того, как переход уже провёл деньги у провайдера
+type fakePaymentRepo struct {
+	escrow     *models.Escrow
+	journal    []*models.JournalEntry
+	dedupKeys  map[string]bool
+	failCommit bool
+}
+
+func (r *fakePaymentRepo) GetEscrow(jobID string) (*models.Escrow, error) {
+	if r.escrow == nil || r.escrow.JobID != jobID {
+		return nil, repository.ErrEscrowNotFound
+	}
+	copied := *r.escrow
+	return &copied, nil
+}
+
+func (r *fakePaymentRepo) UpdateEscrow(jobID string, transition repository.EscrowTransition) (*models.Escrow, error) {
+	escrow, err := r.GetEscrow(jobID)
+	if err != nil {
+		return nil, err
+	}
+	change, err := transition(escrow)
+	if err != nil {
+		return nil, err
+	}
+	if r.failCommit {
+		return nil, errCommitFailed
+	}
+	if change != nil {
+		for _, entry := range change.Movements {
+			entry.EscrowID, entry.JobID = escrow.ID, escrow.JobID
+			escrow.Ledger = append(escrow.Ledger, entry)
+		}
+		for _, entry := range change.Journal {
+			if !r.dedupKeys[entry.DedupKey] {
+				r.dedupKeys[entry.DedupKey] = true
+				r.journal = append(r.journal, entry)
+			}
+		}
+	}
+	r.escrow = escrow
+	copied := *escrow
+	return &copied, nil
+}
+
+// countingProvider считает обращения к провайдеру
+type countingProvider struct {
+	payments.Provider
+	charges, payouts, refunds int
+}
+
+func (p *countingProvider) Charge(req payments.Charge) (*payments.Transfer, error) {
+	p.charges++
+	return p.Provider.Charge(req)
+}
+
+func (p *countingProvider) Payout(req payments.Payout) (*payments.Transfer, error) {
+	p.payouts++
+	return p.Provider.Payout(req)
+}
+
+func (p *countingProvider) Refund(req payments.Refund) (*payments.Transfer, error) {
+	p.refunds++
+	return p.Provider.Refund(req)
+}
+
+func usd(amount int64) models.Money {
+	return models.NewMoney(amount, "USD")
+}
+
+func newTestPaymentService() (*paymentService, *fakePaymentRepo, *countingProvider) {
+	repo := &fakePaymentRepo{
+		escrow: &models.Escrow{
+			ID: "e1", JobID: "j1", Status: models.EscrowPending, PayerID: 1, PayeeID: 2,
+			Payment: usd(100000), PlatformFee: usd(10000), BrokerCut: usd(5000), CarrierNet: usd(85000),
+		},
+		dedupKeys: map[string]bool{},
+	}
+	provider := &countingProvider{Provider: payments.NewFakeProvider()}
+	return NewPaymentService(repo, nil, provider).(*paymentService), repo, provider
+}
+
+// assertBalanced проверяет, что каждая проводка сходится в ноль, и возвращает
+// остатки счетов (дебет — плюс)
+func assertBalanced(t *testing.T, journal []*models.JournalEntry) map[models.AccountType]int64 {
+	t.Helper()
+	balances := map[models.AccountType]int64{}
+	for _, entry := range journal {
+		var sum int64
+		for _, posting := range entry.Postings {
+			sum += posting.Amount.Amount
+			balances[posting.AccountType] += posting.Amount.Amount
+		}
+		if sum != 0 || len(entry.Postings) < 2 {
+			t.Errorf("journal entry %s is not balanced: %d", entry.Type, sum)
+		}
+	}
+	return balances
+}
+
+func ledgerTypes(escrow *models.Escrow) []models.LedgerEntryType {
+	types := []models.LedgerEntryType{}
+	for _, entry := range escrow.Ledger {
+		types = append(types, entry.Type)
+	}
+	return types
+}
+
+func TestEscrowFundAndRelease(t *testing.T) {
+	svc, repo, provider := newTestPaymentService()
+	job := &models.Job{ID: "j1"}
+
+	if err := svc.ReleaseEscrow(job); err != ErrEscrowNotFunded {
+		t.Fatalf("release before funding: err = %v, want ErrEscrowNotFunded", err)
+	}
+	if err := svc.FundEscrow(job); err != nil {
+		t.Fatal(err)
+	}
+	if repo.escrow.Status != models.EscrowFunded || repo.escrow.ChargeRef == "" {
+		t.Fatalf("after fund: %+v", repo.escrow)
+	}
+	// Повтор события не списывает второй раз
+	if err := svc.FundEscrow(job); err != nil {
+		t.Fatal(err)
+	}
+	if provider.charges != 1 || len(repo.escrow.Ledger) != 1 {
+		t.Errorf("replayed fund: charges = %d, ledger = %v", provider.charges, ledgerTypes(repo.escrow))
+	}
+
+	journal, err := NewLedgerService(nil, repo).CompletionJournal(job)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo.journal = append(repo.journal, journal...)
+
+	if err := svc.ReleaseEscrow(job); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.ReleaseEscrow(job); err != nil {
+		t.Fatal(err)
+	}
+	if repo.escrow.Status != models.EscrowReleased || provider.payouts != 2 {
+		t.Errorf("after release: status = %s, payouts = %d", repo.escrow.Status, provider.payouts)
+	}
+	want := []models.LedgerEntryType{models.LedgerEscrowFunded, models.LedgerCarrierPayout, models.LedgerBrokerPayout, models.LedgerPlatformFee}
+	if got := ledgerTypes(repo.escrow); !reflect.DeepEqual(got, want) {
+		t.Errorf("ledger = %v, want %v", got, want)
+	}
+	for _, entry := range repo.escrow.Ledger {
+		if entry.Type == models.LedgerCarrierPayout && entry.Amount != usd(85000) {
+			t.Errorf("carrier payout = %s, want 850.00 USD", entry.Amount)
+		}
+	}
+
+	// После выплат у площадки остаётся только комиссия, долгов нет
+	balances := assertBalanced(t, repo.journal)
+	wantBalances := map[models.AccountType]int64{
+		models.AccountEscrow:          10000,
+		models.AccountPlatformRevenue: -10000,
+		models.AccountPosterBalance:   0,
+		models.AccountCarrierPayable:  0,
+	}
+	for account, want := range wantBalances {
+		if balances[account] != want {
+			t.Errorf("%s balance = %d, want %d", account, balances[account], want)
+		}
+	}
+
+	if err := svc.RefundEscrow(job); err != nil || provider.refunds != 0 {
+		t.Errorf("refund after release: err = %v, refunds = %d", err, provider.refunds)
+	}
+}
+
+func TestEscrowRefund(t *testing.T) {
+	svc, repo, provider := newTestPaymentService()
+	job := &models.Job{ID: "j1"}
+
+	if err := svc.FundEscrow(job); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.RefundEscrow(job); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.RefundEscrow(job); err != nil {
+		t.Fatal(err)
+	}
+	if repo.escrow.Status != models.EscrowRefunded || provider.refunds != 1 {
+		t.Errorf("after refund: status = %s, refunds = %d", repo.escrow.Status, provider.refunds)
+	}
+	balances := assertBalanced(t, repo.journal)
+	for account, balance := range balances {
+		if balance != 0 {
+			t.Errorf("%s balance after refund = %d, want 0", account, balance)
+		}
+	}
+	if err := svc.ReleaseEscrow(job); err != nil || provider.payouts != 0 {
+		t.Errorf("release after refund: err = %v, payouts = %d", err, provider.payouts)
+	}
+}
+
+func TestEscrowCancelBeforeFunding(t *testing.T) {
+	svc, repo, provider := newTestPaymentService()
+	job := &models.Job{ID: "j1"}
+	if err := svc.RefundEscrow(job); err != nil {
+		t.Fatal(err)
+	}
+	if repo.escrow.Status != models.EscrowCancelled || provider.refunds != 0 || len(repo.escrow.Ledger) != 0 {
+		t.Errorf("cancel before funding: %+v, refunds = %d", repo.escrow, provider.refunds)
+	}
+	if err := svc.FundEscrow(job); err != nil || provider.charges != 0 {
+		t.Errorf("fund after cancel: err = %v, charges = %d", err, provider.charges)
+	}
+}
+
+// Если запись не удалась после списания, повтор идёт с тем же ключом
+// идемпотентности и получает то же списание, а не второе
+func TestEscrowRetryAfterFailedCommitReusesTransfer(t *testing.T) {
+	svc, repo, provider := newTestPaymentService()
+	job := &models.Job{ID: "j1"}
+
+	repo.failCommit = true
+	if err := svc.FundEscrow(job); err != errCommitFailed {
+		t.Fatalf("fund: err = %v, want errCommitFailed", err)
+	}
+	if repo.escrow.Status != models.EscrowPending {
+		t.Fatalf("failed commit changed escrow: %s", repo.escrow.Status)
+	}
+	repo.failCommit = false
+	if err := svc.FundEscrow(job); err != nil {
+		t.Fatal(err)
+	}
+	if provider.charges != 2 || len(repo.escrow.Ledger) != 1 {
+		t.Fatalf("charges = %d, ledger = %v", provider.charges, ledgerTypes(repo.escrow))
+	}
+
+	repo.failCommit = true
+	svc.ReleaseEscrow(job)
+	repo.failCommit = false
+	if err := svc.ReleaseEscrow(job); err != nil {
+		t.Fatal(err)
+	}
+	refs := map[string]bool{}
+	for _, entry := range repo.escrow.Ledger {
+		if entry.ProviderRef != "" {
+			if refs[entry.ProviderRef] {
+				t.Errorf("transfer %s recorded twice", entry.ProviderRef)
+			}
+			refs[entry.ProviderRef] = true
+		}
+	}
+	if len(refs) != 3 {
+		t.Errorf("distinct transfers = %d, want charge and two payouts", len(refs))
+	}
+}
+
+func TestEscrowWithoutEscrowIsSkipped(t *testing.T) {
+	svc, _, provider := newTestPaymentService()
+	job := &models.Job{ID: "other"}
+	for _, op := range []func(*models.Job) error{svc.FundEscrow, svc.ReleaseEscrow, svc.RefundEscrow} {
+		if err := op(job); err != nil {
+			t.Errorf("job without escrow: err = %v", err)
+		}
+	}
+	if provider.charges+provider.payouts+provider.refunds != 0 {
+		t.Errorf("provider was called for a job without escrow")
+	}
+}
@@ -0,0 +1,203 @@
+package services
+
+import (
+	"errors"
+	"moveshare/internal/models"
+	"moveshare/internal/repository"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// ReviewEditWindow — сколько времени отзыв можно исправить после публикации
+	ReviewEditWindow = 7 * 24 * time.Hour
+	maxReviewComment = 2000
+)
+
+var (
+	ErrJobNotCompleted = errors.New("job is not completed")
+	ErrReviewNotFound  = errors.New("review not found")
+	ErrReviewExists    = errors.New("review already left for this job")
+	ErrReviewLocked    = errors.New("review can no longer be edited")
+	ErrReviewForbidden = errors.New("not allowed to edit this review")
+	ErrInvalidReview   = errors.New("invalid review")
+)
+
+// ReviewService — взаимные отзывы заказчиков и перевозчиков и их репутация
+type ReviewService interface {
+	CreateReview(jobID string, userID int, req models.CreateReviewRequest) (*models.Review, error)
+	UpdateReview(id string, userID int, req models.CreateReviewRequest) (*models.Review, error)
+	GetJobReviews(jobID string) ([]*models.Review, error)
+	GetUserReviews(userID, limit, offset int) ([]*models.Review, int, error)
+	GetUserProfile(userID int) (*models.UserProfile, error)
+	GetCompanyProfile(companyID int) (*models.CompanyProfile, error)
+}
+
+type reviewService struct {
+	reviewRepo  repository.ReviewRepository
+	jobRepo     repository.JobRepository
+	userRepo    repository.UserRepository
+	companyRepo repository.CompanyRepository
+}
+
+func NewReviewService(reviewRepo repository.ReviewRepository, jobRepo repository.JobRepository, userRepo repository.UserRepository, companyRepo repository.CompanyRepository) ReviewService {
+	return &reviewService{
+		reviewRepo:  reviewRepo,
+		jobRepo:     jobRepo,
+		userRepo:    userRepo,
+		companyRepo: companyRepo,
+	}
+}
+
+// CreateReview оставляет отзыв о второй стороне завершённой работы:
+// перевозчик оценивает заказчика (и его компанию), заказчик — перевозчика
+func (s *reviewService) CreateReview(jobID string, userID int, req models.CreateReviewRequest) (*models.Review, error) {
+	req.Comment = strings.TrimSpace(req.Comment)
+	if err := validateReview(req); err != nil {
+		return nil, err
+	}
+	job, err := s.jobRepo.GetJobByID(jobID)
+	if err != nil {
+		return nil, mapJobError(err)
+	}
+	if job.Status != models.JobStatusCompleted {
+		return nil, ErrJobNotCompleted
+	}
+
+	review := &models.Review{
+		ID:         uuid.New().String(),
+		JobID:      job.ID,
+		ReviewerID: userID,
+		Rating:     req.Rating,
+		Comment:    req.Comment,
+	}
+	if job.CarrierID != nil && *job.CarrierID == userID {
+		review.ReviewerSide = models.ReviewByCarrier
+		review.RevieweeID = job.PosterID
+		review.RevieweeCompanyID = job.CompanyID
+	} else {
+		manager, err := canManageJob(s.jobRepo, job, userID)
+		if err != nil {
+			return nil, err
+		}
+		if !manager || job.CarrierID == nil {
+			return nil, ErrJobForbidden
+		}
+		review.ReviewerSide = models.ReviewByPoster
+		review.RevieweeID = *job.CarrierID
+	}
+
+	review, err = s.reviewRepo.CreateReview(review)
+	if err != nil {
+		return nil, mapReviewError(err)
+	}
+	return withEditWindow(review), nil
+}
+
+// UpdateReview правит собственный отзыв, пока не истекло окно редактирования
+func (s *reviewService) UpdateReview(id string, userID int, req models.CreateReviewRequest) (*models.Review, error) {
+	req.Comment = strings.TrimSpace(req.Comment)
+	if err := validateReview(req); err != nil {
+		return nil, err
+	}
+	review, err := s.reviewRepo.GetReviewByID(id)
+	if err != nil {
+		return nil, mapReviewError(err)
+	}
+	if review.ReviewerID != userID {
+		return nil, ErrReviewForbidden
+	}
+	review, err = s.reviewRepo.UpdateReview(id, req.Rating, req.Comment, time.Now().Add(-ReviewEditWindow))
+	if err != nil {
+		return nil, mapReviewError(err)
+	}
+	return withEditWindow(review), nil
+}
+
+func (s *reviewService) GetJobReviews(jobID string) ([]*models.Review, error) {
+	if _, err := s.jobRepo.GetJobByID(jobID); err != nil {
+		return nil, mapJobError(err)
+	}
+	reviews, err := s.reviewRepo.GetReviewsByJob(jobID)
+	if err != nil {
+		return nil, err
+	}
+	for _, review := range reviews {
+		withEditWindow(review)
+	}
+	return reviews, nil
+}
+
+func (s *reviewService) GetUserReviews(userID, limit, offset int) ([]*models.Review, int, error) {
+	reviews, total, err := s.reviewRepo.GetReviewsByUser(userID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, review := range reviews {
+		withEditWindow(review)
+	}
+	return reviews, total, nil
+}
+
+func (s *reviewService) GetUserProfile(userID int) (*models.UserProfile, error) {
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	summary, err := s.reviewRepo.GetUserRating(userID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.UserProfile{
+		ID:            user.ID,
+		Username:      user.Username,
+		Role:          user.Role,
+		RatingSummary: *summary,
+	}, nil
+}
+
+func (s *reviewService) GetCompanyProfile(companyID int) (*models.CompanyProfile, error) {
+	company, err := s.companyRepo.GetCompanyByID(companyID)
+	if err != nil {
+		return nil, mapCompanyError(err)
+	}
+	summary, err := s.reviewRepo.GetCompanyRating(companyID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.CompanyProfile{
+		ID:            company.ID,
+		Name:          company.Name,
+		RatingSummary: *summary,
+	}, nil
+}
+
+func validateReview(req models.CreateReviewRequest) error {
+	if req.Rating < 1 || req.Rating > 5 || len(req.Comment) > maxReviewComment {
+		return ErrInvalidReview
+	}
+	return nil
+}
+
+func withEditWindow(review *models.Review) *models.Review {
+	review.EditableUntil = review.CreatedAt.Add(ReviewEditWindow)
+	return review
+}
+
+func mapReviewError(err error) error {
+	switch {
+	case errors.Is(err, repository.ErrReviewNotFound):
+		return ErrReviewNotFound
+	case errors.Is(err, repository.ErrReviewExists):
+		return ErrReviewExists
+	case errors.Is(err, repository.ErrReviewLocked):
+		return ErrReviewLocked
+	default:
+		return err
+	}
+}
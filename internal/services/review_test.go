@@ -0,0 +1,146 @@
+package services
+
+import (
+	"moveshare/internal/models"
+	"moveshare/internal/repository"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeReviewRepo повторяет ограничения таблицы reviews: один отзыв от стороны
+// на работу, правка только пока отзыв создан позже createdAfter
+type fakeReviewRepo struct {
+	repository.ReviewRepository
+	reviews map[string]*models.Review
+}
+
+func (r *fakeReviewRepo) CreateReview(review *models.Review) (*models.Review, error) {
+	for _, existing := range r.reviews {
+		if existing.JobID == review.JobID && existing.ReviewerSide == review.ReviewerSide {
+			return nil, repository.ErrReviewExists
+		}
+	}
+	review.CreatedAt = time.Now()
+	r.reviews[review.ID] = review
+	copied := *review
+	return &copied, nil
+}
+
+func (r *fakeReviewRepo) GetReviewByID(id string) (*models.Review, error) {
+	review, ok := r.reviews[id]
+	if !ok {
+		return nil, repository.ErrReviewNotFound
+	}
+	copied := *review
+	return &copied, nil
+}
+
+func (r *fakeReviewRepo) UpdateReview(id string, rating int, comment string, createdAfter time.Time) (*models.Review, error) {
+	review := r.reviews[id]
+	if !review.CreatedAt.After(createdAfter) {
+		return nil, repository.ErrReviewLocked
+	}
+	review.Rating, review.Comment = rating, comment
+	return r.GetReviewByID(id)
+}
+
+func newTestReviewService(job *models.Job) (*reviewService, *fakeReviewRepo, *fakeJobRepo) {
+	reviews := &fakeReviewRepo{reviews: map[string]*models.Review{}}
+	jobs := newFakeJobRepo(job)
+	jobs.managers[2] = true
+	return NewReviewService(reviews, jobs, newFakeUserRepo(), nil).(*reviewService), reviews, jobs
+}
+
+func completedJob() *models.Job {
+	companyID, carrierID := 10, 5
+	job := openJob("job", 1)
+	job.Status, job.CompanyID, job.CarrierID = models.JobStatusCompleted, &companyID, &carrierID
+	return job
+}
+
+// Перевозчик оценивает заказчика и его компанию, заказчик или диспетчер компании —
+// перевозчика; посторонние отзывов не оставляют
+func TestCreateReview(t *testing.T) {
+	tests := []struct {
+		name         string
+		reviewerID   int
+		want         error
+		side         models.ReviewSide
+		revieweeID   int
+		revieweeComp bool
+	}{
+		{"carrier", 5, nil, models.ReviewByCarrier, 1, true},
+		{"poster", 1, nil, models.ReviewByPoster, 5, false},
+		{"company dispatcher", 2, nil, models.ReviewByPoster, 5, false},
+		{"another user", 7, ErrJobForbidden, "", 0, false},
+	}
+	for _, tt := range tests {
+		svc, _, _ := newTestReviewService(completedJob())
+		review, err := svc.CreateReview("job", tt.reviewerID, models.CreateReviewRequest{Rating: 5, Comment: "  On time  "})
+		if err != tt.want {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.want)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if review.ReviewerSide != tt.side || review.RevieweeID != tt.revieweeID || (review.RevieweeCompanyID != nil) != tt.revieweeComp {
+			t.Errorf("%s: review = %+v", tt.name, review)
+		}
+		if review.Comment != "On time" || !review.EditableUntil.Equal(review.CreatedAt.Add(ReviewEditWindow)) {
+			t.Errorf("%s: comment = %q, editable until %v", tt.name, review.Comment, review.EditableUntil)
+		}
+	}
+}
+
+func TestCreateReviewRejects(t *testing.T) {
+	delivered := completedJob()
+	delivered.Status = models.JobStatusDelivered
+	tests := []struct {
+		name string
+		job  *models.Job
+		req  models.CreateReviewRequest
+		want error
+	}{
+		{"rating 0", completedJob(), models.CreateReviewRequest{Rating: 0}, ErrInvalidReview},
+		{"rating 6", completedJob(), models.CreateReviewRequest{Rating: 6}, ErrInvalidReview},
+		{"comment too long", completedJob(), models.CreateReviewRequest{Rating: 4, Comment: strings.Repeat("a", maxReviewComment+1)}, ErrInvalidReview},
+		{"job not completed", delivered, models.CreateReviewRequest{Rating: 4}, ErrJobNotCompleted},
+	}
+	for _, tt := range tests {
+		svc, _, _ := newTestReviewService(tt.job)
+		if _, err := svc.CreateReview("job", 5, tt.req); err != tt.want {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+
+	svc, _, _ := newTestReviewService(completedJob())
+	svc.CreateReview("job", 1, models.CreateReviewRequest{Rating: 4})
+	if _, err := svc.CreateReview("job", 2, models.CreateReviewRequest{Rating: 3}); err != ErrReviewExists {
+		t.Errorf("second review from the poster side: err = %v, want ErrReviewExists", err)
+	}
+}
+
+func TestUpdateReview(t *testing.T) {
+	svc, reviews, _ := newTestReviewService(completedJob())
+	review, err := svc.CreateReview("job", 5, models.CreateReviewRequest{Rating: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.UpdateReview(review.ID, 1, models.CreateReviewRequest{Rating: 1}); err != ErrReviewForbidden {
+		t.Errorf("edit by another user: err = %v, want ErrReviewForbidden", err)
+	}
+	updated, err := svc.UpdateReview(review.ID, 5, models.CreateReviewRequest{Rating: 4, Comment: "Paid quickly"})
+	if err != nil || updated.Rating != 4 || updated.Comment != "Paid quickly" {
+		t.Errorf("edit = %+v, %v", updated, err)
+	}
+
+	reviews.reviews[review.ID].CreatedAt = time.Now().Add(-ReviewEditWindow - time.Minute)
+	if _, err := svc.UpdateReview(review.ID, 5, models.CreateReviewRequest{Rating: 5}); err != ErrReviewLocked {
+		t.Errorf("edit after the window: err = %v, want ErrReviewLocked", err)
+	}
+	if _, err := svc.UpdateReview("missing", 5, models.CreateReviewRequest{Rating: 5}); err != ErrReviewNotFound {
+		t.Errorf("missing review: err = %v, want ErrReviewNotFound", err)
+	}
+}
@@ -0,0 +1,205 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"moveshare/internal/feed"
+	"moveshare/internal/geo"
+	"moveshare/internal/models"
+	"moveshare/internal/notify"
+	"moveshare/internal/repository"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const maxSavedSearchNameLength = 100
+
+var (
+	ErrSavedSearchNotFound = errors.New("saved search not found")
+	ErrInvalidSavedSearch  = errors.New("invalid saved search")
+)
+
+// SavedSearchService — сохранённые поиски работ и уведомления о новых
+// работах, которые под них подходят
+type SavedSearchService interface {
+	CreateSavedSearch(userID int, req models.SavedSearchRequest) (*models.SavedSearch, error)
+	GetSavedSearches(userID int) ([]*models.SavedSearch, error)
+	UpdateSavedSearch(id string, userID int, req models.SavedSearchRequest) (*models.SavedSearch, error)
+	SetPaused(id string, userID int, paused bool) (*models.SavedSearch, error)
+	DeleteSavedSearch(id string, userID int) error
+	// NotifyNewJob уведомляет владельцев подходящих поисков о новой работе.
+	// Вызывается подписчиком events.Bus; ошибка — повторить позже. Ошибки
+	// отдельных уведомлений только логируются, чтобы повтор не дублировал остальные
+	NotifyNewJob(job *models.Job) error
+}
+
+type savedSearchService struct {
+	repo       repository.SavedSearchRepository
+	userRepo   repository.UserRepository
+	reviewRepo repository.ReviewRepository
+	geocoder   geo.Geocoder
+	dispatcher notify.Dispatcher
+	appBaseURL string
+}
+
+func NewSavedSearchService(repo repository.SavedSearchRepository, userRepo repository.UserRepository, reviewRepo repository.ReviewRepository, geocoder geo.Geocoder, dispatcher notify.Dispatcher, appBaseURL string) SavedSearchService {
+	return &savedSearchService{
+		repo:       repo,
+		userRepo:   userRepo,
+		reviewRepo: reviewRepo,
+		geocoder:   geocoder,
+		dispatcher: dispatcher,
+		appBaseURL: appBaseURL,
+	}
+}
+
+func (s *savedSearchService) CreateSavedSearch(userID int, req models.SavedSearchRequest) (*models.SavedSearch, error) {
+	search, err := s.fromRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	search.ID = uuid.New().String()
+	search.UserID = userID
+	return s.repo.CreateSavedSearch(search)
+}
+
+func (s *savedSearchService) GetSavedSearches(userID int) ([]*models.SavedSearch, error) {
+	return s.repo.GetSavedSearchesByUser(userID)
+}
+
+func (s *savedSearchService) UpdateSavedSearch(id string, userID int, req models.SavedSearchRequest) (*models.SavedSearch, error) {
+	search, err := s.fromRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	search.ID = id
+	search.UserID = userID
+	search, err = s.repo.UpdateSavedSearch(search)
+	if err != nil {
+		return nil, mapSavedSearchError(err)
+	}
+	return search, nil
+}
+
+func (s *savedSearchService) SetPaused(id string, userID int, paused bool) (*models.SavedSearch, error) {
+	search, err := s.repo.SetPaused(id, userID, paused)
+	if err != nil {
+		return nil, mapSavedSearchError(err)
+	}
+	return search, nil
+}
+
+func (s *savedSearchService) DeleteSavedSearch(id string, userID int) error {
+	return mapSavedSearchError(s.repo.DeleteSavedSearch(id, userID))
+}
+
+// fromRequest проверяет запрос и заранее геокодирует ZIP-коды фильтра,
+// чтобы при публикации каждой работы не обращаться к геокодеру
+func (s *savedSearchService) fromRequest(req models.SavedSearchRequest) (*models.SavedSearch, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len(name) > maxSavedSearchNameLength {
+		return nil, ErrInvalidSavedSearch
+	}
+
+	filter := req.Filter
+	filter.Sort = ""
+	for _, radius := range []*models.GeoRadius{filter.Origin, filter.Destination} {
+		if radius != nil && radius.Miles <= 0 {
+			return nil, ErrInvalidSavedSearch
+		}
+	}
+	if err := resolveFilter(s.geocoder, &filter); err != nil {
+		return nil, err
+	}
+
+	channels := []models.NotificationChannel{}
+	seen := map[models.NotificationChannel]bool{}
+	for _, channel := range req.Channels {
+		if !s.dispatcher.Supports(channel) {
+			return nil, ErrInvalidSavedSearch
+		}
+		if !seen[channel] {
+			seen[channel] = true
+			channels = append(channels, channel)
+		}
+	}
+	if len(channels) == 0 {
+		channels = append(channels, models.NotificationChannelInApp)
+	}
+
+	return &models.SavedSearch{Name: name, Filter: filter, Channels: channels}, nil
+}
+
+// NotifyNewJob проверяет работу всеми активными поисками. Подбор делается
+// в памяти тем же feed.Matches, что фильтрует ленту /jobs/stream
+func (s *savedSearchService) NotifyNewJob(job *models.Job) error {
+	searches, err := s.repo.GetActiveSavedSearches()
+	if err != nil {
+		return err
+	}
+
+	event := models.JobEvent{Type: models.JobEventCreated, Job: job, At: time.Now()}
+	if rating, err := s.reviewRepo.GetUserRating(job.PosterID); err == nil {
+		event.PosterRating = rating.Rating
+	}
+
+	for _, search := range searches {
+		if search.UserID == job.PosterID || !feed.Matches(search.Filter, event) {
+			continue
+		}
+		if err := s.notify(search, job); err != nil {
+			slog.Error("Failed to send saved search alert",
+				slog.String("saved_search_id", search.ID),
+				slog.String("job_id", job.ID),
+				slog.String("error", err.Error()))
+		}
+	}
+	return nil
+}
+
+func (s *savedSearchService) notify(search *models.SavedSearch, job *models.Job) error {
+	user, err := s.userRepo.GetUserByID(search.UserID)
+	if err != nil {
+		return err
+	}
+	jobID := job.ID
+	n := &models.Notification{
+		ID:     uuid.New().String(),
+		UserID: user.ID,
+		Type:   models.NotificationSavedSearchMatch,
+		Title:  fmt.Sprintf("New job for \"%s\": %s", search.Name, job.JobTitle),
+		Body: fmt.Sprintf(
+			"A new job matches your saved search \"%s\":\n\n%s\n%s → %s, pickup %s, payout %s\n\n%s",
+			search.Name, job.JobTitle,
+			placeName(job.PickupAddress), placeName(job.DeliveryAddress),
+			job.PickupDateTime.Format("Jan 2, 2006"), job.PaymentAmount.String(),
+			s.appBaseURL+"/jobs/"+job.ID,
+		),
+		JobID: &jobID,
+	}
+	if err := s.dispatcher.Send(search.Channels, user, n); err != nil {
+		return err
+	}
+	return s.repo.MarkNotified(search.ID)
+}
+
+// placeName — город и штат для текста уведомления, либо ZIP, если город не указан
+func placeName(addr models.Address) string {
+	if addr.City == "" {
+		return addr.ZIP
+	}
+	if addr.State == "" {
+		return addr.City
+	}
+	return addr.City + ", " + addr.State
+}
+
+func mapSavedSearchError(err error) error {
+	if errors.Is(err, repository.ErrSavedSearchNotFound) {
+		return ErrSavedSearchNotFound
+	}
+	return err
+}
@@ -0,0 +1,169 @@
+package services
+
+import (
+	"errors"
+	"moveshare/internal/geo"
+	"moveshare/internal/models"
+	"moveshare/internal/notify"
+	"moveshare/internal/repository"
+	"slices"
+	"strings"
+	"testing"
+)
+
+type fakeSavedSearchRepo struct {
+	repository.SavedSearchRepository
+	searches []*models.SavedSearch
+	notified []string
+}
+
+func (r *fakeSavedSearchRepo) CreateSavedSearch(search *models.SavedSearch) (*models.SavedSearch, error) {
+	r.searches = append(r.searches, search)
+	return search, nil
+}
+
+func (r *fakeSavedSearchRepo) GetActiveSavedSearches() ([]*models.SavedSearch, error) {
+	var active []*models.SavedSearch
+	for _, search := range r.searches {
+		if !search.Paused {
+			active = append(active, search)
+		}
+	}
+	return active, nil
+}
+
+func (r *fakeSavedSearchRepo) MarkNotified(id string) error {
+	r.notified = append(r.notified, id)
+	return nil
+}
+
+type fixedRatings struct {
+	repository.ReviewRepository
+}
+
+func (fixedRatings) GetUserRating(userID int) (*models.RatingSummary, error) {
+	return &models.RatingSummary{Rating: 4.5, ReviewCount: 2}, nil
+}
+
+// recordingNotifier запоминает, кому ушли уведомления, и может отказывать
+type recordingNotifier struct {
+	sent []int
+	err  error
+}
+
+func (n *recordingNotifier) Notify(user *models.User, msg *models.Notification) error {
+	if n.err != nil {
+		return n.err
+	}
+	n.sent = append(n.sent, user.ID)
+	return nil
+}
+
+func newTestSavedSearchService() (*savedSearchService, *fakeSavedSearchRepo, *recordingNotifier, *recordingNotifier) {
+	repo := &fakeSavedSearchRepo{}
+	inApp, email := &recordingNotifier{}, &recordingNotifier{}
+	dispatcher := notify.NewDispatcher(map[models.NotificationChannel]notify.Notifier{
+		models.NotificationChannelInApp: inApp,
+		models.NotificationChannelEmail: email,
+	})
+	users := newFakeUserRepo(
+		&models.User{ID: 1, Email: "poster@example.com"},
+		&models.User{ID: 5, Email: "carrier@example.com"},
+		&models.User{ID: 6, Email: "other@example.com"},
+	)
+	svc := NewSavedSearchService(repo, users, fixedRatings{}, geo.NewZIPGeocoder(), dispatcher, "https://app.example.com")
+	return svc.(*savedSearchService), repo, inApp, email
+}
+
+func TestCreateSavedSearch(t *testing.T) {
+	svc, _, _, _ := newTestSavedSearchService()
+	search, err := svc.CreateSavedSearch(5, models.SavedSearchRequest{
+		Name:     "  NYC pickups ",
+		Filter:   models.JobFilter{Origin: &models.GeoRadius{ZIP: "10001", Miles: 25}, Sort: models.JobSortDeadhead},
+		Channels: []models.NotificationChannel{models.NotificationChannelEmail, models.NotificationChannelEmail},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if search.Name != "NYC pickups" || search.Filter.Sort != "" || search.Filter.Origin.Latitude != 40.7506 {
+		t.Errorf("search = %+v, origin = %+v", search, search.Filter.Origin)
+	}
+	if !slices.Equal(search.Channels, []models.NotificationChannel{models.NotificationChannelEmail}) {
+		t.Errorf("channels = %v, want duplicates removed", search.Channels)
+	}
+	if search, _ := svc.CreateSavedSearch(5, models.SavedSearchRequest{Name: "Anything"}); !slices.Equal(search.Channels, []models.NotificationChannel{models.NotificationChannelInApp}) {
+		t.Errorf("default channels = %v, want in_app", search.Channels)
+	}
+
+	tests := []struct {
+		name string
+		req  models.SavedSearchRequest
+		want error
+	}{
+		{"empty name", models.SavedSearchRequest{Name: " "}, ErrInvalidSavedSearch},
+		{"name too long", models.SavedSearchRequest{Name: strings.Repeat("a", maxSavedSearchNameLength+1)}, ErrInvalidSavedSearch},
+		{"zero radius", models.SavedSearchRequest{Name: "a", Filter: models.JobFilter{Destination: &models.GeoRadius{ZIP: "10001"}}}, ErrInvalidSavedSearch},
+		{"unknown zip", models.SavedSearchRequest{Name: "a", Filter: models.JobFilter{Origin: &models.GeoRadius{ZIP: "00000", Miles: 5}}}, ErrInvalidAddress},
+		{"unsupported channel", models.SavedSearchRequest{Name: "a", Channels: []models.NotificationChannel{models.NotificationChannelWebhook}}, ErrInvalidSavedSearch},
+	}
+	for _, tt := range tests {
+		if _, err := svc.CreateSavedSearch(5, tt.req); err != tt.want {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestNotifyNewJob(t *testing.T) {
+	svc, repo, inApp, email := newTestSavedSearchService()
+	repo.searches = []*models.SavedSearch{
+		{ID: "near", UserID: 5, Filter: models.JobFilter{Origin: &models.GeoRadius{Latitude: 40.7128, Longitude: -74.0060, Miles: 25}}, Channels: []models.NotificationChannel{models.NotificationChannelInApp, models.NotificationChannelEmail}},
+		{ID: "far", UserID: 6, Filter: models.JobFilter{Origin: &models.GeoRadius{Latitude: 41.8858, Longitude: -87.6181, Miles: 25}}, Channels: []models.NotificationChannel{models.NotificationChannelInApp}},
+		{ID: "paused", UserID: 6, Paused: true, Channels: []models.NotificationChannel{models.NotificationChannelInApp}},
+		{ID: "own job", UserID: 1, Channels: []models.NotificationChannel{models.NotificationChannelInApp}},
+		{ID: "rating", UserID: 6, Filter: models.JobFilter{MinPosterRating: ptr(4.0)}, Channels: []models.NotificationChannel{models.NotificationChannelInApp}},
+	}
+	if err := svc.NotifyNewJob(openJob("job", 1)); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(repo.notified, []string{"near", "rating"}) {
+		t.Errorf("notified searches = %v, want near and rating", repo.notified)
+	}
+	if !slices.Equal(inApp.sent, []int{5, 6}) || !slices.Equal(email.sent, []int{5}) {
+		t.Errorf("in-app to %v, email to %v", inApp.sent, email.sent)
+	}
+}
+
+// Отказ одного канала не мешает остальным и не заставляет повторять рассылку
+func TestNotifyNewJobChannelFailure(t *testing.T) {
+	svc, repo, inApp, email := newTestSavedSearchService()
+	email.err = errors.New("smtp is down")
+	repo.searches = []*models.SavedSearch{
+		{ID: "both", UserID: 5, Channels: []models.NotificationChannel{models.NotificationChannelEmail, models.NotificationChannelInApp}},
+	}
+	if err := svc.NotifyNewJob(openJob("job", 1)); err != nil {
+		t.Errorf("err = %v, want nil", err)
+	}
+	if !slices.Equal(inApp.sent, []int{5}) || len(repo.notified) != 0 {
+		t.Errorf("in-app to %v, notified searches = %v", inApp.sent, repo.notified)
+	}
+}
+
+func TestPlaceName(t *testing.T) {
+	tests := []struct {
+		addr models.Address
+		want string
+	}{
+		{models.Address{City: "Boston", State: "MA", ZIP: "02108"}, "Boston, MA"},
+		{models.Address{City: "Boston", ZIP: "02108"}, "Boston"},
+		{models.Address{ZIP: "02108"}, "02108"},
+	}
+	for _, tt := range tests {
+		if got := placeName(tt.addr); got != tt.want {
+			t.Errorf("placeName(%+v) = %q, want %q", tt.addr, got, tt.want)
+		}
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
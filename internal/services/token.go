@@ -0,0 +1,162 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"moveshare/internal/models"
+	"moveshare/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RefreshTokenTTL — время жизни refresh-токена
+const RefreshTokenTTL = 30 * 24 * time.Hour
+
+var (
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token reuse detected")
+)
+
+// TokenService выдаёт пары access/refresh токенов, ротирует refresh-токены
+// и ведёт список отозванных access-токенов
+type TokenService interface {
+	IssueTokens(user *models.User) (*models.LoginResponse, error)
+	Refresh(refreshToken string) (*models.LoginResponse, error)
+	Logout(claims *AccessClaims, refreshToken string) error
+	// IsRevoked — токен отозван при выходе или выпущен до смены пароля или роли
+	IsRevoked(claims *AccessClaims) (bool, error)
+}
+
+type tokenService struct {
+	jwt       JWTService
+	tokenRepo repository.TokenRepository
+	userRepo  repository.UserRepository
+}
+
+func NewTokenService(jwt JWTService, tokenRepo repository.TokenRepository, userRepo repository.UserRepository) TokenService {
+	return &tokenService{jwt: jwt, tokenRepo: tokenRepo, userRepo: userRepo}
+}
+
+// IssueTokens начинает новую сессию (новое семейство refresh-токенов)
+func (s *tokenService) IssueTokens(user *models.User) (*models.LoginResponse, error) {
+	raw, stored, err := newRefreshToken(user.ID, uuid.New().String())
+	if err != nil {
+		return nil, err
+	}
+	if err := s.tokenRepo.CreateRefreshToken(stored); err != nil {
+		return nil, err
+	}
+	return s.response(user, raw)
+}
+
+// Refresh обменивает refresh-токен на новую пару токенов. Повторное
+// предъявление уже использованного токена означает, что он утёк, поэтому
+// отзывается всё семейство — и у злоумышленника, и у владельца.
+func (s *tokenService) Refresh(refreshToken string) (*models.LoginResponse, error) {
+	current, err := s.tokenRepo.GetRefreshTokenByHash(hashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, repository.ErrRefreshTokenNotFound) {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+	if current.RevokedAt != nil {
+		if current.ReplacedBy != nil {
+			if err := s.tokenRepo.RevokeFamily(current.FamilyID); err != nil {
+				return nil, err
+			}
+			return nil, ErrRefreshTokenReused
+		}
+		return nil, ErrInvalidRefreshToken
+	}
+	if time.Now().After(current.ExpiresAt) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	user, err := s.userRepo.GetUserByID(current.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, next, err := newRefreshToken(current.UserID, current.FamilyID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.tokenRepo.RotateRefreshToken(current.ID, next); err != nil {
+		if errors.Is(err, repository.ErrRefreshTokenRevoked) {
+			// Параллельный запрос успел использовать этот же токен
+			if err := s.tokenRepo.RevokeFamily(current.FamilyID); err != nil {
+				return nil, err
+			}
+			return nil, ErrRefreshTokenReused
+		}
+		return nil, err
+	}
+	return s.response(user, raw)
+}
+
+// Logout отзывает сессию refresh-токена и текущий access-токен
+func (s *tokenService) Logout(claims *AccessClaims, refreshToken string) error {
+	if refreshToken != "" {
+		current, err := s.tokenRepo.GetRefreshTokenByHash(hashToken(refreshToken))
+		switch {
+		case errors.Is(err, repository.ErrRefreshTokenNotFound):
+		case err != nil:
+			return err
+		case current.UserID == claims.UserID:
+			if err := s.tokenRepo.RevokeFamily(current.FamilyID); err != nil {
+				return err
+			}
+		}
+	}
+	return s.tokenRepo.RevokeAccessToken(claims.JTI, claims.ExpiresAt)
+}
+
+func (s *tokenService) IsRevoked(claims *AccessClaims) (bool, error) {
+	return s.tokenRepo.IsAccessTokenRevoked(claims.JTI, claims.UserID, claims.IssuedAt)
+}
+
+func (s *tokenService) response(user *models.User, refreshToken string) (*models.LoginResponse, error) {
+	accessToken, err := s.jwt.GenerateToken(user)
+	if err != nil {
+		return nil, err
+	}
+	return &models.LoginResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int(AccessTokenTTL.Seconds()),
+	}, nil
+}
+
+// newRefreshToken генерирует случайный токен и запись для хранения его хэша
+func newRefreshToken(userID int, familyID string) (string, *models.RefreshToken, error) {
+	raw, err := randomToken()
+	if err != nil {
+		return "", nil, err
+	}
+	return raw, &models.RefreshToken{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		FamilyID:  familyID,
+		TokenHash: hashToken(raw),
+		ExpiresAt: time.Now().Add(RefreshTokenTTL),
+	}, nil
+}
+
+// randomToken — 256 случайных бит в base64url
+func randomToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func hashToken(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,287 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"moveshare/internal/models"
+	"moveshare/internal/repository"
+	"testing"
+	"time"
+)
+
+// fakeTokenRepo — TokenRepository в памяти
+type fakeTokenRepo struct {
+	tokens  map[string]*models.RefreshToken
+	revoked map[string]bool
+	// users — для проверки времени смены пароля, как JOIN с users в базе
+	users *fakeUserRepo
+	// rotateConflict имитирует параллельный запрос, успевший ротировать тот же токен
+	rotateConflict bool
+}
+
+func newFakeTokenRepo() *fakeTokenRepo {
+	return &fakeTokenRepo{tokens: map[string]*models.RefreshToken{}, revoked: map[string]bool{}}
+}
+
+func (r *fakeTokenRepo) CreateRefreshToken(token *models.RefreshToken) error {
+	token.CreatedAt = time.Now()
+	copied := *token
+	r.tokens[token.ID] = &copied
+	return nil
+}
+
+func (r *fakeTokenRepo) GetRefreshTokenByHash(hash string) (*models.RefreshToken, error) {
+	for _, token := range r.tokens {
+		if token.TokenHash == hash {
+			copied := *token
+			return &copied, nil
+		}
+	}
+	return nil, repository.ErrRefreshTokenNotFound
+}
+
+func (r *fakeTokenRepo) RotateRefreshToken(oldID string, next *models.RefreshToken) error {
+	old := r.tokens[oldID]
+	if r.rotateConflict || old.RevokedAt != nil {
+		return repository.ErrRefreshTokenRevoked
+	}
+	now := time.Now()
+	old.RevokedAt, old.ReplacedBy = &now, &next.ID
+	return r.CreateRefreshToken(next)
+}
+
+func (r *fakeTokenRepo) RevokeFamily(familyID string) error {
+	r.revokeWhere(func(token *models.RefreshToken) bool { return token.FamilyID == familyID })
+	return nil
+}
+
+func (r *fakeTokenRepo) RevokeUserSessions(userID int) error {
+	r.revokeWhere(func(token *models.RefreshToken) bool { return token.UserID == userID })
+	return nil
+}
+
+func (r *fakeTokenRepo) revokeWhere(match func(*models.RefreshToken) bool) {
+	now := time.Now()
+	for _, token := range r.tokens {
+		if match(token) && token.RevokedAt == nil {
+			token.RevokedAt = &now
+		}
+	}
+}
+
+func (r *fakeTokenRepo) RevokeAccessToken(jti string, expiresAt time.Time) error {
+	r.revoked[jti] = true
+	return nil
+}
+
+func (r *fakeTokenRepo) IsAccessTokenRevoked(jti string, userID int, issuedAt time.Time) (bool, error) {
+	if r.revoked[jti] {
+		return true, nil
+	}
+	if r.users == nil {
+		return false, nil
+	}
+	changedAt := r.users.passwordChangedAt[userID]
+	if roleChangedAt := r.users.roleChangedAt[userID]; roleChangedAt.After(changedAt) {
+		changedAt = roleChangedAt
+	}
+	return changedAt.Truncate(time.Second).After(issuedAt), nil
+}
+
+// fakeUserRepo — пользователи в памяти; методы, которые не нужны тестам, не реализованы
+type fakeUserRepo struct {
+	repository.UserRepository
+	users             map[int]*models.User
+	recoveryCodes     map[string]bool
+	userTokens        map[string]*models.UserToken
+	passwordChangedAt map[int]time.Time
+	roleChangedAt     map[int]time.Time
+}
+
+func newFakeUserRepo(users ...*models.User) *fakeUserRepo {
+	repo := &fakeUserRepo{
+		users:             map[int]*models.User{},
+		passwordChangedAt: map[int]time.Time{},
+		roleChangedAt:     map[int]time.Time{},
+	}
+	for _, user := range users {
+		repo.users[user.ID] = user
+	}
+	return repo
+}
+
+func (r *fakeUserRepo) GetUserByID(id int) (*models.User, error) {
+	user, ok := r.users[id]
+	if !ok {
+		return nil, repository.ErrUserNotFound
+	}
+	copied := *user
+	return &copied, nil
+}
+
+// newTestJWTService — JWTService с одним сгенерированным ключом
+func newTestJWTService(t *testing.T) *jwtService {
+	t.Helper()
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := &signingKey{kid: keyID(&privateKey.PublicKey), privateKey: privateKey}
+	return &jwtService{active: key, keys: map[string]*signingKey{key.kid: key}}
+}
+
+func newTestTokenService(t *testing.T) (*tokenService, *fakeTokenRepo, *models.User) {
+	t.Helper()
+	user := &models.User{ID: 7, Email: "carrier@example.com", Role: models.RoleCarrier}
+	repo := newFakeTokenRepo()
+	return NewTokenService(newTestJWTService(t), repo, newFakeUserRepo(user)).(*tokenService), repo, user
+}
+
+func TestRefreshRotatesToken(t *testing.T) {
+	svc, repo, user := newTestTokenService(t)
+	first, err := svc.IssueTokens(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := svc.Refresh(first.RefreshToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.RefreshToken == first.RefreshToken || second.AccessToken == "" {
+		t.Fatalf("refresh did not issue a new pair: %+v", second)
+	}
+	if second.ExpiresIn != int(AccessTokenTTL.Seconds()) {
+		t.Errorf("expires_in = %d", second.ExpiresIn)
+	}
+	claims, err := svc.jwt.ValidateToken(second.AccessToken)
+	if err != nil || claims.UserID != user.ID || claims.Role != user.Role {
+		t.Errorf("access token claims = %+v, %v", claims, err)
+	}
+
+	old, _ := repo.GetRefreshTokenByHash(hashToken(first.RefreshToken))
+	next, _ := repo.GetRefreshTokenByHash(hashToken(second.RefreshToken))
+	if old.RevokedAt == nil || old.ReplacedBy == nil || *old.ReplacedBy != next.ID {
+		t.Errorf("used token was not replaced: %+v", old)
+	}
+	if next.FamilyID != old.FamilyID || next.RevokedAt != nil {
+		t.Errorf("rotated token: %+v, want active token in family %s", next, old.FamilyID)
+	}
+	// В хранилище только хэш
+	if next.TokenHash == second.RefreshToken {
+		t.Error("refresh token is stored in plain text")
+	}
+}
+
+// Повторное предъявление использованного токена отзывает всё семейство, но не
+// трогает другие сессии пользователя
+func TestRefreshReuseRevokesFamily(t *testing.T) {
+	svc, _, user := newTestTokenService(t)
+	stolen, _ := svc.IssueTokens(user)
+	otherSession, _ := svc.IssueTokens(user)
+
+	rotated, err := svc.Refresh(stolen.RefreshToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.Refresh(stolen.RefreshToken); err != ErrRefreshTokenReused {
+		t.Fatalf("reuse: err = %v, want ErrRefreshTokenReused", err)
+	}
+	if _, err := svc.Refresh(rotated.RefreshToken); err != ErrInvalidRefreshToken {
+		t.Errorf("latest token of the family: err = %v, want ErrInvalidRefreshToken", err)
+	}
+	if _, err := svc.Refresh(otherSession.RefreshToken); err != nil {
+		t.Errorf("other session: err = %v", err)
+	}
+}
+
+func TestRefreshRejects(t *testing.T) {
+	tests := []struct {
+		name    string
+		prepare func(svc *tokenService, repo *fakeTokenRepo, refreshToken string)
+		wantErr error
+	}{
+		{
+			name: "unknown token",
+			prepare: func(svc *tokenService, repo *fakeTokenRepo, refreshToken string) {
+				repo.tokens = map[string]*models.RefreshToken{}
+			},
+			wantErr: ErrInvalidRefreshToken,
+		},
+		{
+			name: "expired token",
+			prepare: func(svc *tokenService, repo *fakeTokenRepo, refreshToken string) {
+				for _, token := range repo.tokens {
+					token.ExpiresAt = time.Now().Add(-time.Second)
+				}
+			},
+			wantErr: ErrInvalidRefreshToken,
+		},
+		{
+			name: "session ended by logout",
+			prepare: func(svc *tokenService, repo *fakeTokenRepo, refreshToken string) {
+				svc.Logout(&AccessClaims{UserID: 7, JTI: "jti"}, refreshToken)
+			},
+			wantErr: ErrInvalidRefreshToken,
+		},
+		{
+			name: "parallel rotation of the same token",
+			prepare: func(svc *tokenService, repo *fakeTokenRepo, refreshToken string) {
+				repo.rotateConflict = true
+			},
+			wantErr: ErrRefreshTokenReused,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, repo, user := newTestTokenService(t)
+			issued, _ := svc.IssueTokens(user)
+			tt.prepare(svc, repo, issued.RefreshToken)
+			if _, err := svc.Refresh(issued.RefreshToken); err != tt.wantErr {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRefreshParallelRotationRevokesFamily(t *testing.T) {
+	svc, repo, user := newTestTokenService(t)
+	issued, _ := svc.IssueTokens(user)
+	repo.rotateConflict = true
+	svc.Refresh(issued.RefreshToken)
+	for _, token := range repo.tokens {
+		if token.RevokedAt == nil {
+			t.Errorf("token %s of the family is still active", token.ID)
+		}
+	}
+}
+
+func TestLogout(t *testing.T) {
+	svc, repo, user := newTestTokenService(t)
+	issued, _ := svc.IssueTokens(user)
+	claims, _ := svc.jwt.ValidateToken(issued.AccessToken)
+
+	// Чужой refresh-токен выход не отзывает
+	if err := svc.Logout(&AccessClaims{UserID: 8, JTI: "other"}, issued.RefreshToken); err != nil {
+		t.Fatal(err)
+	}
+	if revoked, _ := svc.IsRevoked(&AccessClaims{UserID: 8, JTI: "other"}); !revoked {
+		t.Error("access token of the caller was not revoked")
+	}
+	if _, err := svc.Refresh(issued.RefreshToken); err != nil {
+		t.Fatalf("another user's logout ended the session: %v", err)
+	}
+
+	issued, _ = svc.IssueTokens(user)
+	if err := svc.Logout(claims, issued.RefreshToken); err != nil {
+		t.Fatal(err)
+	}
+	if revoked, _ := svc.IsRevoked(claims); !revoked {
+		t.Error("access token was not revoked")
+	}
+	if _, err := svc.Refresh(issued.RefreshToken); err != ErrInvalidRefreshToken {
+		t.Errorf("refresh after logout: err = %v, want ErrInvalidRefreshToken", err)
+	}
+	if len(repo.revoked) != 2 {
+		t.Errorf("revoked access tokens = %v", repo.revoked)
+	}
+}
@@ -0,0 +1,212 @@
+package services
+
+import (
+	"crypto/rand"
+	"errors"
+	"moveshare/internal/models"
+	"moveshare/internal/repository"
+	"moveshare/internal/totp"
+	"strings"
+	"time"
+)
+
+const (
+	totpIssuer        = "MoveShare"
+	recoveryCodeCount = 10
+)
+
+var (
+	ErrTwoFactorEnabled     = errors.New("two-factor authentication already enabled")
+	ErrTwoFactorNotEnabled  = errors.New("two-factor authentication is not enabled")
+	ErrTwoFactorNotEnrolled = errors.New("two-factor enrollment not started")
+	ErrInvalidTwoFactorCode = errors.New("invalid two-factor code")
+	ErrInvalidChallenge     = errors.New("invalid or expired challenge token")
+)
+
+// TwoFactorService управляет TOTP-аутентификацией (RFC 6238) и резервными кодами
+type TwoFactorService interface {
+	Enroll(userID int) (*models.TOTPEnrollResponse, error)
+	Confirm(userID int, code string) ([]string, error)
+	Disable(userID int, code string) error
+	Challenge(user *models.User) (*models.LoginResponse, error)
+	CompleteLogin(req models.TwoFactorLoginRequest) (*models.LoginResponse, error)
+}
+
+type twoFactorService struct {
+	userRepo     repository.UserRepository
+	tokenRepo    repository.TokenRepository
+	jwt          JWTService
+	tokenService TokenService
+}
+
+func NewTwoFactorService(userRepo repository.UserRepository, tokenRepo repository.TokenRepository, jwt JWTService, tokenService TokenService) TwoFactorService {
+	return &twoFactorService{
+		userRepo:     userRepo,
+		tokenRepo:    tokenRepo,
+		jwt:          jwt,
+		tokenService: tokenService,
+	}
+}
+
+// Enroll генерирует новый секрет. 2FA включается только после Confirm
+func (s *twoFactorService) Enroll(userID int) (*models.TOTPEnrollResponse, error) {
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TOTPEnabled {
+		return nil, ErrTwoFactorEnabled
+	}
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.userRepo.SetPendingTOTPSecret(userID, secret); err != nil {
+		return nil, err
+	}
+	return &models.TOTPEnrollResponse{
+		Secret:     secret,
+		OTPAuthURI: totp.URI(totpIssuer, user.Email, secret),
+	}, nil
+}
+
+// Confirm проверяет первый код из приложения, включает 2FA и возвращает резервные коды
+func (s *twoFactorService) Confirm(userID int, code string) ([]string, error) {
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TOTPEnabled {
+		return nil, ErrTwoFactorEnabled
+	}
+	if user.TOTPSecret == "" {
+		return nil, ErrTwoFactorNotEnrolled
+	}
+	step, ok := totp.Validate(user.TOTPSecret, code, time.Now())
+	if !ok {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		if codes[i], err = newRecoveryCode(); err != nil {
+			return nil, err
+		}
+		hashes[i] = hashToken(codes[i])
+	}
+	if err := s.userRepo.EnableTOTP(userID, step, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// Disable выключает 2FA. Требуется действующий код из приложения
+func (s *twoFactorService) Disable(userID int, code string) error {
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		return err
+	}
+	if !user.TOTPEnabled {
+		return ErrTwoFactorNotEnabled
+	}
+	if err := s.checkTOTP(user, code); err != nil {
+		return err
+	}
+	return s.userRepo.DisableTOTP(userID)
+}
+
+// Challenge — первый шаг входа для пользователя с включённой 2FA
+func (s *twoFactorService) Challenge(user *models.User) (*models.LoginResponse, error) {
+	token, err := s.jwt.GenerateChallengeToken(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.LoginResponse{
+		TwoFactorRequired: true,
+		ChallengeToken:    token,
+	}, nil
+}
+
+// CompleteLogin — второй шаг входа. Неверный код сжигает challenge-токен,
+// так что перебор кодов требует каждый раз заново вводить пароль
+func (s *twoFactorService) CompleteLogin(req models.TwoFactorLoginRequest) (*models.LoginResponse, error) {
+	claims, err := s.jwt.ValidateChallengeToken(req.ChallengeToken)
+	if err != nil {
+		return nil, ErrInvalidChallenge
+	}
+	revoked, err := s.tokenRepo.IsAccessTokenRevoked(claims.JTI, claims.UserID, claims.IssuedAt)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, ErrInvalidChallenge
+	}
+	// challenge-токен одноразовый независимо от результата проверки
+	if err := s.tokenRepo.RevokeAccessToken(claims.JTI, claims.ExpiresAt); err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetUserByID(claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.TOTPEnabled {
+		return nil, ErrInvalidChallenge
+	}
+
+	if req.RecoveryCode != "" {
+		ok, err := s.userRepo.ConsumeRecoveryCode(user.ID, hashToken(normalizeRecoveryCode(req.RecoveryCode)))
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, ErrInvalidTwoFactorCode
+		}
+	} else if err := s.checkTOTP(user, req.Code); err != nil {
+		return nil, err
+	}
+
+	return s.tokenService.IssueTokens(user)
+}
+
+func (s *twoFactorService) checkTOTP(user *models.User, code string) error {
+	step, ok := totp.Validate(user.TOTPSecret, code, time.Now())
+	if !ok {
+		return ErrInvalidTwoFactorCode
+	}
+	if err := s.userRepo.UseTOTPStep(user.ID, step); err != nil {
+		if errors.Is(err, repository.ErrTOTPCodeReused) {
+			return ErrInvalidTwoFactorCode
+		}
+		return err
+	}
+	return nil
+}
+
+// recoveryAlphabet без похожих друг на друга символов (0/o, 1/l/i)
+const recoveryAlphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+
+// newRecoveryCode возвращает код вида xxxxx-xxxxx
+func newRecoveryCode() (string, error) {
+	buf := make([]byte, 10)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	for i, c := range buf {
+		if i == 5 {
+			b.WriteByte('-')
+		}
+		b.WriteByte(recoveryAlphabet[int(c)%len(recoveryAlphabet)])
+	}
+	return b.String(), nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), " ", ""))
+	if len(code) == 10 && !strings.Contains(code, "-") {
+		code = code[:5] + "-" + code[5:]
+	}
+	return code
+}
@@ -0,0 +1,214 @@
+package services
+
+import (
+	"moveshare/internal/models"
+	"moveshare/internal/repository"
+	"moveshare/internal/totp"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Хранение 2FA в fakeUserRepo повторяет условия SQL в userRepository
+
+func (r *fakeUserRepo) SetPendingTOTPSecret(userID int, secret string) error {
+	if user := r.users[userID]; !user.TOTPEnabled {
+		user.TOTPSecret, user.TOTPLastStep = secret, 0
+	}
+	return nil
+}
+
+func (r *fakeUserRepo) EnableTOTP(userID int, step int64, recoveryCodeHashes []string) error {
+	user := r.users[userID]
+	user.TOTPEnabled, user.TOTPLastStep = true, step
+	r.recoveryCodes = map[string]bool{}
+	for _, hash := range recoveryCodeHashes {
+		r.recoveryCodes[hash] = true
+	}
+	return nil
+}
+
+func (r *fakeUserRepo) DisableTOTP(userID int) error {
+	user := r.users[userID]
+	user.TOTPEnabled, user.TOTPSecret, user.TOTPLastStep = false, "", 0
+	r.recoveryCodes = nil
+	return nil
+}
+
+func (r *fakeUserRepo) UseTOTPStep(userID int, step int64) error {
+	user := r.users[userID]
+	if user.TOTPLastStep >= step {
+		return repository.ErrTOTPCodeReused
+	}
+	user.TOTPLastStep = step
+	return nil
+}
+
+func (r *fakeUserRepo) ConsumeRecoveryCode(userID int, codeHash string) (bool, error) {
+	if !r.recoveryCodes[codeHash] {
+		return false, nil
+	}
+	delete(r.recoveryCodes, codeHash)
+	return true, nil
+}
+
+func newTestTwoFactorService(t *testing.T) (*twoFactorService, *fakeUserRepo) {
+	t.Helper()
+	users := newFakeUserRepo(&models.User{ID: 7, Email: "carrier@example.com", Role: models.RoleCarrier})
+	tokens := newFakeTokenRepo()
+	jwt := newTestJWTService(t)
+	svc := NewTwoFactorService(users, tokens, jwt, NewTokenService(jwt, tokens, users)).(*twoFactorService)
+	return svc, users
+}
+
+// codeAt — код пользователя для текущего интервала со сдвигом offset
+func codeAt(t *testing.T, secret string, offset int64) string {
+	t.Helper()
+	code, err := totp.Code(secret, totp.Step(time.Now())+offset)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return code
+}
+
+// enableTwoFactor проходит Enroll и Confirm и возвращает секрет и резервные коды
+func enableTwoFactor(t *testing.T, svc *twoFactorService) (string, []string) {
+	t.Helper()
+	enroll, err := svc.Enroll(7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	codes, err := svc.Confirm(7, codeAt(t, enroll.Secret, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return enroll.Secret, codes
+}
+
+func TestTwoFactorEnrollment(t *testing.T) {
+	svc, users := newTestTwoFactorService(t)
+	if _, err := svc.Confirm(7, "123456"); err != ErrTwoFactorNotEnrolled {
+		t.Errorf("confirm before enroll: err = %v, want ErrTwoFactorNotEnrolled", err)
+	}
+	enroll, err := svc.Enroll(7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.Confirm(7, codeAt(t, enroll.Secret, 5)); err != ErrInvalidTwoFactorCode {
+		t.Errorf("confirm with a code outside the window: err = %v", err)
+	}
+	if users.users[7].TOTPEnabled {
+		t.Fatal("2FA enabled by a wrong code")
+	}
+
+	secret, codes := enableTwoFactor(t, svc)
+	if len(codes) != recoveryCodeCount || len(users.recoveryCodes) != recoveryCodeCount {
+		t.Errorf("recovery codes = %d, stored = %d", len(codes), len(users.recoveryCodes))
+	}
+	for _, code := range codes {
+		if users.recoveryCodes[code] {
+			t.Fatal("recovery code is stored in plain text")
+		}
+	}
+	if _, err := svc.Enroll(7); err != ErrTwoFactorEnabled {
+		t.Errorf("enroll when enabled: err = %v, want ErrTwoFactorEnabled", err)
+	}
+
+	// Код, которым подтвердили включение, второй раз не принимается
+	confirmed := users.users[7].TOTPLastStep
+	codeFor := func(step int64) string {
+		code, _ := totp.Code(secret, step)
+		return code
+	}
+	if err := svc.Disable(7, codeFor(confirmed)); err != ErrInvalidTwoFactorCode {
+		t.Errorf("disable with the confirmation code: err = %v, want ErrInvalidTwoFactorCode", err)
+	}
+	if err := svc.Disable(7, codeFor(confirmed+1)); err != nil {
+		t.Fatal(err)
+	}
+	if users.users[7].TOTPEnabled || users.users[7].TOTPSecret != "" {
+		t.Error("2FA is still enabled after disable")
+	}
+}
+
+func TestTwoFactorLogin(t *testing.T) {
+	svc, _ := newTestTwoFactorService(t)
+	secret, _ := enableTwoFactor(t, svc)
+	user, _ := svc.userRepo.GetUserByID(7)
+
+	challenge, err := svc.Challenge(user)
+	if err != nil || !challenge.TwoFactorRequired || challenge.AccessToken != "" {
+		t.Fatalf("challenge = %+v, %v", challenge, err)
+	}
+	// challenge-токен не годится как access-токен
+	if _, err := svc.jwt.ValidateToken(challenge.ChallengeToken); err == nil {
+		t.Error("challenge token accepted as an access token")
+	}
+
+	code := codeAt(t, secret, 1)
+	tokens, err := svc.CompleteLogin(models.TwoFactorLoginRequest{ChallengeToken: challenge.ChallengeToken, Code: code})
+	if err != nil || tokens.AccessToken == "" || tokens.RefreshToken == "" {
+		t.Fatalf("complete login = %+v, %v", tokens, err)
+	}
+	// challenge одноразовый
+	if _, err := svc.CompleteLogin(models.TwoFactorLoginRequest{ChallengeToken: challenge.ChallengeToken, Code: code}); err != ErrInvalidChallenge {
+		t.Errorf("reused challenge: err = %v, want ErrInvalidChallenge", err)
+	}
+}
+
+// Перехваченный код нельзя использовать повторно: ни тот же, ни код более
+// раннего интервала, хотя оба ещё в пределах допуска
+func TestTwoFactorLoginRejectsReplayedCode(t *testing.T) {
+	svc, _ := newTestTwoFactorService(t)
+	secret, _ := enableTwoFactor(t, svc)
+	user, _ := svc.userRepo.GetUserByID(7)
+
+	login := func(code string) error {
+		challenge, err := svc.Challenge(user)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = svc.CompleteLogin(models.TwoFactorLoginRequest{ChallengeToken: challenge.ChallengeToken, Code: code})
+		return err
+	}
+	if err := login(codeAt(t, secret, 1)); err != nil {
+		t.Fatal(err)
+	}
+	if err := login(codeAt(t, secret, 1)); err != ErrInvalidTwoFactorCode {
+		t.Errorf("replayed code: err = %v, want ErrInvalidTwoFactorCode", err)
+	}
+	if err := login(codeAt(t, secret, -1)); err != ErrInvalidTwoFactorCode {
+		t.Errorf("code of an earlier interval: err = %v, want ErrInvalidTwoFactorCode", err)
+	}
+}
+
+func TestTwoFactorLoginWithRecoveryCode(t *testing.T) {
+	svc, _ := newTestTwoFactorService(t)
+	_, codes := enableTwoFactor(t, svc)
+	user, _ := svc.userRepo.GetUserByID(7)
+
+	// Код можно ввести без дефиса и в верхнем регистре
+	typed := strings.ToUpper(codes[0][:5] + codes[0][6:])
+	for i, want := range []error{nil, ErrInvalidTwoFactorCode} {
+		challenge, _ := svc.Challenge(user)
+		_, err := svc.CompleteLogin(models.TwoFactorLoginRequest{ChallengeToken: challenge.ChallengeToken, RecoveryCode: " " + typed + " "})
+		if err != want {
+			t.Errorf("attempt %d with the same recovery code: err = %v, want %v", i+1, err, want)
+		}
+	}
+}
+
+func TestTwoFactorWrongCodeBurnsChallenge(t *testing.T) {
+	svc, _ := newTestTwoFactorService(t)
+	secret, _ := enableTwoFactor(t, svc)
+	user, _ := svc.userRepo.GetUserByID(7)
+
+	challenge, _ := svc.Challenge(user)
+	if _, err := svc.CompleteLogin(models.TwoFactorLoginRequest{ChallengeToken: challenge.ChallengeToken, Code: "000000"}); err != ErrInvalidTwoFactorCode {
+		t.Fatalf("wrong code: err = %v", err)
+	}
+	req := models.TwoFactorLoginRequest{ChallengeToken: challenge.ChallengeToken, Code: codeAt(t, secret, 1)}
+	if _, err := svc.CompleteLogin(req); err != ErrInvalidChallenge {
+		t.Errorf("valid code after a wrong one: err = %v, want ErrInvalidChallenge", err)
+	}
+}
@@ -0,0 +1,365 @@
+package services
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"moveshare/internal/models"
+	"moveshare/internal/repository"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// webhookBatchSize — сколько доставок забирать из очереди за раз
+	webhookBatchSize = 50
+	// webhookBaseBackoff — пауза перед второй попыткой; дальше она удваивается
+	webhookBaseBackoff = 30 * time.Second
+	webhookMaxBackoff  = 6 * time.Hour
+)
+
+// errBlockedAddress — адрес подписчика во внутренней сети: туда доставки не идут
+var errBlockedAddress = errors.New("webhook destination address is not allowed")
+
+// blockedNetworks — служебные диапазоны, которые не покрывают методы net.IP
+var blockedNetworks = []*net.IPNet{
+	mustParseCIDR("0.0.0.0/8"),
+	mustParseCIDR("100.64.0.0/10"),
+	mustParseCIDR("192.0.0.0/24"),
+	mustParseCIDR("198.18.0.0/15"),
+	mustParseCIDR("240.0.0.0/4"),
+	mustParseCIDR("64:ff9b::/96"),
+}
+
+var (
+	ErrWebhookNotFound  = errors.New("webhook not found")
+	ErrInvalidWebhook   = errors.New("invalid webhook")
+	ErrDeliveryNotFound = errors.New("webhook delivery not found")
+)
+
+// WebhookService — подписки на события и их доставка. События не отправляются
+// сразу: Enqueue пишет доставки в таблицу, а DeliverDue периодически
+// отправляет их с повторами, так что рестарт сервера их не теряет
+type WebhookService interface {
+	CreateWebhook(userID int, req models.CreateWebhookRequest) (*models.Webhook, error)
+	GetWebhooks(userID int) ([]*models.Webhook, error)
+	UpdateWebhook(id string, userID int, req models.UpdateWebhookRequest) (*models.Webhook, error)
+	DeleteWebhook(id string, userID int) error
+	GetDeliveries(id string, userID int, limit, offset int) ([]*models.WebhookDelivery, int, error)
+	Redeliver(id, deliveryID string, userID int) (*models.WebhookDelivery, error)
+
+	// Enqueue ставит событие в очередь доставки подпискам его аудитории. Повтор
+	// того же события (тот же ID) не создаёт новых доставок
+	Enqueue(event *models.Event) error
+	DeliverDue() error
+}
+
+type webhookService struct {
+	repo        repository.WebhookRepository
+	companyRepo repository.CompanyRepository
+	client      *http.Client
+	maxAttempts int
+}
+
+func NewWebhookService(repo repository.WebhookRepository, companyRepo repository.CompanyRepository, timeout time.Duration, maxAttempts int) WebhookService {
+	return &webhookService{
+		repo:        repo,
+		companyRepo: companyRepo,
+		client:      newWebhookClient(timeout),
+		maxAttempts: maxAttempts,
+	}
+}
+
+// CreateWebhook создаёт подписку и выдаёт секрет для проверки подписи.
+// Секрет показывается только в ответе на создание
+func (s *webhookService) CreateWebhook(userID int, req models.CreateWebhookRequest) (*models.Webhook, error) {
+	if err := validateWebhook(req.URL, req.EventTypes); err != nil {
+		return nil, err
+	}
+	if req.CompanyID != nil {
+		member, err := s.companyRepo.GetMember(*req.CompanyID, userID)
+		if err != nil {
+			if errors.Is(err, repository.ErrMemberNotFound) {
+				return nil, ErrCompanyForbidden
+			}
+			return nil, err
+		}
+		if !member.Role.CanManageJobs() {
+			return nil, ErrCompanyForbidden
+		}
+	}
+	secret, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.CreateWebhook(&models.Webhook{
+		ID:         uuid.New().String(),
+		UserID:     userID,
+		CompanyID:  req.CompanyID,
+		URL:        req.URL,
+		EventTypes: uniqueEventTypes(req.EventTypes),
+		Secret:     "whsec_" + secret,
+	})
+}
+
+func (s *webhookService) GetWebhooks(userID int) ([]*models.Webhook, error) {
+	return s.repo.GetWebhooks(userID)
+}
+
+func (s *webhookService) UpdateWebhook(id string, userID int, req models.UpdateWebhookRequest) (*models.Webhook, error) {
+	if err := validateWebhook(req.URL, req.EventTypes); err != nil {
+		return nil, err
+	}
+	webhook, err := s.repo.UpdateWebhook(&models.Webhook{
+		ID:         id,
+		URL:        req.URL,
+		EventTypes: uniqueEventTypes(req.EventTypes),
+		Active:     req.Active,
+	}, userID)
+	if err != nil {
+		return nil, mapWebhookError(err)
+	}
+	return webhook, nil
+}
+
+func (s *webhookService) DeleteWebhook(id string, userID int) error {
+	return mapWebhookError(s.repo.DeleteWebhook(id, userID))
+}
+
+func (s *webhookService) GetDeliveries(id string, userID int, limit, offset int) ([]*models.WebhookDelivery, int, error) {
+	if _, err := s.repo.GetWebhook(id, userID); err != nil {
+		return nil, 0, mapWebhookError(err)
+	}
+	return s.repo.GetDeliveries(id, limit, offset)
+}
+
+// Redeliver повторно ставит в очередь уже отправленное (или так и не доставленное) событие
+func (s *webhookService) Redeliver(id, deliveryID string, userID int) (*models.WebhookDelivery, error) {
+	if _, err := s.repo.GetWebhook(id, userID); err != nil {
+		return nil, mapWebhookError(err)
+	}
+	delivery, err := s.repo.Redeliver(id, deliveryID)
+	if err != nil {
+		return nil, mapWebhookError(err)
+	}
+	return delivery, nil
+}
+
+func (s *webhookService) Enqueue(event *models.Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = s.repo.EnqueueEvent(event, payload)
+	return err
+}
+
+// DeliverDue отправляет все доставки, срок которых подошёл
+func (s *webhookService) DeliverDue() error {
+	// Аренда должна пережить таймаут каждой отправки в пачке
+	lease := s.client.Timeout*webhookBatchSize + time.Minute
+	for {
+		due, err := s.repo.ClaimDueDeliveries(webhookBatchSize, lease)
+		if err != nil {
+			return err
+		}
+		for _, d := range due {
+			if err := s.deliver(d); err != nil {
+				slog.Error("Failed to record webhook delivery",
+					slog.String("delivery_id", d.Delivery.ID),
+					slog.String("error", err.Error()))
+			}
+		}
+		if len(due) < webhookBatchSize {
+			return nil
+		}
+	}
+}
+
+// deliver делает одну попытку и записывает результат. Ошибка — только если
+// не удалось записать результат
+func (s *webhookService) deliver(d *repository.DueDelivery) error {
+	timestamp := time.Now().Unix()
+	req, err := http.NewRequest(http.MethodPost, d.URL, bytes.NewReader(d.Delivery.Payload))
+	if err != nil {
+		return s.retryLater(d, nil, "invalid webhook url")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "MoveShare-Webhooks/1.0")
+	req.Header.Set("X-MoveShare-Event", string(d.Delivery.EventType))
+	req.Header.Set("X-MoveShare-Delivery", d.Delivery.ID)
+	req.Header.Set("X-MoveShare-Signature", fmt.Sprintf("t=%d,v1=%s", timestamp, signWebhookPayload(d.Secret, timestamp, d.Delivery.Payload)))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return s.retryLater(d, nil, deliveryErrorMessage(err))
+	}
+	// Тело ответа не сохраняется: журнал доставок виден владельцу подписки,
+	// и через него нельзя читать ответы чужих серверов
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return s.repo.RecordSuccess(d.Delivery.ID, resp.StatusCode)
+	}
+	status := resp.StatusCode
+	return s.retryLater(d, &status, "HTTP "+strconv.Itoa(status))
+}
+
+// deliveryErrorMessage — фиксированное описание сетевой ошибки для журнала, без
+// подробностей, по которым можно изучать внутреннюю сеть
+func deliveryErrorMessage(err error) string {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, errBlockedAddress):
+		return "destination address is not allowed"
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return "request timed out"
+	default:
+		return "request failed"
+	}
+}
+
+// newWebhookClient — HTTP-клиент доставок. Адрес проверяется при каждом
+// соединении, уже после разрешения имени, поэтому DNS rebinding не приводит
+// во внутреннюю сеть. Редиректы не выполняются, прокси из окружения не используется
+func newWebhookClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout, Control: blockInternalAddress}
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: timeout,
+			MaxIdleConns:        100,
+			IdleConnTimeout:     90 * time.Second,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// blockInternalAddress запрещает соединения с loopback, частными, link-local,
+// multicast и неуказанными адресами
+func blockInternalAddress(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return errBlockedAddress
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !publicIP(ip) {
+		return errBlockedAddress
+	}
+	return nil
+}
+
+func publicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() {
+		return false
+	}
+	for _, network := range blockedNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+func mustParseCIDR(cidr string) *net.IPNet {
+	_, network, err := net.ParseCIDR(cidr)
+	if err != nil {
+		panic(err)
+	}
+	return network
+}
+
+// retryLater записывает неудачу и назначает следующую попытку с экспоненциальной
+// паузой, пока не исчерпан лимит попыток
+func (s *webhookService) retryLater(d *repository.DueDelivery, statusCode *int, errMsg string) error {
+	attempt := d.Delivery.Attempts + 1
+	if attempt >= s.maxAttempts {
+		return s.repo.RecordFailure(d.Delivery.ID, statusCode, errMsg, nil)
+	}
+	next := time.Now().Add(webhookBackoff(attempt))
+	return s.repo.RecordFailure(d.Delivery.ID, statusCode, errMsg, &next)
+}
+
+// webhookBackoff — пауза после attempt-й неудачной попытки: 30s, 1m, 2m, 4m... но не больше 6h
+func webhookBackoff(attempt int) time.Duration {
+	backoff := time.Duration(float64(webhookBaseBackoff) * math.Pow(2, float64(attempt-1)))
+	if backoff <= 0 || backoff > webhookMaxBackoff {
+		return webhookMaxBackoff
+	}
+	return backoff
+}
+
+// signWebhookPayload — HMAC-SHA256 от "<timestamp>.<тело>" в hex. Метка времени
+// входит в подпись, чтобы подписчик мог отбрасывать переотправленные злоумышленником запросы
+func signWebhookPayload(secret string, timestamp int64, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func validateWebhook(rawURL string, eventTypes []models.EventType) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Hostname() == "" {
+		return ErrInvalidWebhook
+	}
+	// Явно внутренние адреса отклоняются сразу; имена проверяются при доставке
+	host := u.Hostname()
+	if strings.EqualFold(host, "localhost") || strings.HasSuffix(strings.ToLower(host), ".localhost") {
+		return ErrInvalidWebhook
+	}
+	if ip := net.ParseIP(host); ip != nil && !publicIP(ip) {
+		return ErrInvalidWebhook
+	}
+	if len(eventTypes) == 0 {
+		return ErrInvalidWebhook
+	}
+	for _, t := range eventTypes {
+		if !t.Valid() {
+			return ErrInvalidWebhook
+		}
+	}
+	return nil
+}
+
+func uniqueEventTypes(eventTypes []models.EventType) []models.EventType {
+	seen := map[models.EventType]bool{}
+	unique := make([]models.EventType, 0, len(eventTypes))
+	for _, t := range eventTypes {
+		if !seen[t] {
+			seen[t] = true
+			unique = append(unique, t)
+		}
+	}
+	return unique
+}
+
+func mapWebhookError(err error) error {
+	switch {
+	case errors.Is(err, repository.ErrWebhookNotFound):
+		return ErrWebhookNotFound
+	case errors.Is(err, repository.ErrDeliveryNotFound):
+		return ErrDeliveryNotFound
+	default:
+		return err
+	}
+}
@@ -0,0 +1,164 @@
+package services
+
+import (
+	"errors"
+	"moveshare/internal/models"
+	"moveshare/internal/repository"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSignWebhookPayload(t *testing.T) {
+	payload := []byte(`{"type":"job.created"}`)
+	// HMAC-SHA256("whsec_test", "1700000000." + тело), посчитан независимо
+	want := "a5ee29be81ab55f4b3fdb71e7653020d04c3f741faf12bc8fdb9cfd25defa69e"
+	if got := signWebhookPayload("whsec_test", 1700000000, payload); got != want {
+		t.Errorf("signature = %s, want %s", got, want)
+	}
+	// Метка времени входит в подпись
+	if signWebhookPayload("whsec_test", 1700000001, payload) == want {
+		t.Error("signature does not depend on the timestamp")
+	}
+	if signWebhookPayload("other", 1700000000, payload) == want {
+		t.Error("signature does not depend on the secret")
+	}
+}
+
+func TestPublicIP(t *testing.T) {
+	tests := []struct {
+		ip     string
+		public bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1:248:1893:25c8:1946", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"fe80::1", false},
+		{"fd00::1", false},
+		{"0.0.0.0", false},
+		{"::", false},
+		{"224.0.0.1", false},
+		{"100.64.0.1", false},
+		{"::ffff:127.0.0.1", false},
+		{"::ffff:169.254.169.254", false},
+		{"64:ff9b::a9fe:a9fe", false},
+	}
+	for _, tt := range tests {
+		if got := publicIP(net.ParseIP(tt.ip)); got != tt.public {
+			t.Errorf("publicIP(%s) = %v, want %v", tt.ip, got, tt.public)
+		}
+	}
+}
+
+func TestValidateWebhook(t *testing.T) {
+	events := []models.EventType{models.EventJobCreated}
+	tests := []struct {
+		url   string
+		valid bool
+	}{
+		{"https://hooks.example.com/moveshare", true},
+		{"http://hooks.example.com:8080/in", true},
+		{"ftp://hooks.example.com/", false},
+		{"https://", false},
+		{"http://localhost:5432/", false},
+		{"http://api.localhost/", false},
+		{"http://127.0.0.1/", false},
+		{"http://169.254.169.254/latest/meta-data/", false},
+		{"http://10.0.0.5/hook", false},
+		{"http://[::1]:8080/", false},
+	}
+	for _, tt := range tests {
+		if err := validateWebhook(tt.url, events); (err == nil) != tt.valid {
+			t.Errorf("validateWebhook(%q) err = %v, want valid %v", tt.url, err, tt.valid)
+		}
+	}
+	if err := validateWebhook("https://hooks.example.com/", nil); err != ErrInvalidWebhook {
+		t.Errorf("no event types: err = %v, want ErrInvalidWebhook", err)
+	}
+}
+
+// Имя, которое при регистрации выглядит внешним, всё равно не даст соединиться
+// с внутренним адресом: проверка делается при каждом соединении
+func TestWebhookClientRefusesInternalAddresses(t *testing.T) {
+	reached := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reached = true
+	}))
+	defer server.Close()
+
+	client := newWebhookClient(5 * time.Second)
+	_, err := client.Post(server.URL, "application/json", nil)
+	if !errors.Is(err, errBlockedAddress) {
+		t.Errorf("request to %s: err = %v, want errBlockedAddress", server.URL, err)
+	}
+	if reached {
+		t.Error("request reached a loopback server")
+	}
+	if got := deliveryErrorMessage(err); got != "destination address is not allowed" {
+		t.Errorf("delivery log message = %q", got)
+	}
+}
+
+func TestWebhookClientDoesNotFollowRedirects(t *testing.T) {
+	client := newWebhookClient(5 * time.Second)
+	if client.CheckRedirect == nil {
+		t.Fatal("client follows redirects")
+	}
+	if err := client.CheckRedirect(nil, nil); err != http.ErrUseLastResponse {
+		t.Errorf("CheckRedirect = %v, want http.ErrUseLastResponse", err)
+	}
+}
+
+func TestWebhookBackoff(t *testing.T) {
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{10, 30 * time.Second << 9},
+		{11, webhookMaxBackoff},
+		{200, webhookMaxBackoff},
+	}
+	for _, tt := range tests {
+		if got := webhookBackoff(tt.attempt); got != tt.want {
+			t.Errorf("webhookBackoff(%d) = %s, want %s", tt.attempt, got, tt.want)
+		}
+	}
+}
+
+// fakeWebhookRepo запоминает записанные неудачи; остальные методы не нужны
+type fakeWebhookRepo struct {
+	repository.WebhookRepository
+	nextAttempts []*time.Time
+}
+
+func (r *fakeWebhookRepo) RecordFailure(id string, statusCode *int, errMsg string, nextAttemptAt *time.Time) error {
+	r.nextAttempts = append(r.nextAttempts, nextAttemptAt)
+	return nil
+}
+
+func TestRetryLaterStopsAfterMaxAttempts(t *testing.T) {
+	repo := &fakeWebhookRepo{}
+	svc := &webhookService{repo: repo, maxAttempts: 3}
+	for attempts := 0; attempts < 3; attempts++ {
+		d := &repository.DueDelivery{Delivery: &models.WebhookDelivery{ID: "d1", Attempts: attempts}}
+		if err := svc.retryLater(d, nil, "request failed"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if repo.nextAttempts[0] == nil || repo.nextAttempts[1] == nil {
+		t.Errorf("attempts below the limit were not rescheduled: %v", repo.nextAttempts)
+	}
+	if repo.nextAttempts[2] != nil {
+		t.Errorf("last attempt rescheduled at %s", repo.nextAttempts[2])
+	}
+}
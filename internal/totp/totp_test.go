@@ -0,0 +1,135 @@
+package totp
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret — ключ тестовых векторов RFC 6238 для SHA1 ("12345678901234567890") в base32
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+// Векторы RFC 6238, приложение B. В RFC коды 8-значные, у нас 6 цифр —
+// младшие разряды того же значения
+func TestCodeRFC6238(t *testing.T) {
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},          // 94287082
+		{1111111109, "081804"},  // 07081804
+		{1111111111, "050471"},  // 14050471
+		{1234567890, "005924"},  // 89005924
+		{2000000000, "279037"},  // 69279037
+		{20000000000, "353130"}, // 65353130
+	}
+	for _, tt := range tests {
+		got, err := Code(rfcSecret, Step(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("Code at %d = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestCodeAcceptsLowercaseSecret(t *testing.T) {
+	got, err := Code(strings.ToLower(rfcSecret), Step(time.Unix(59, 0)))
+	if err != nil || got != "287082" {
+		t.Errorf("Code with lowercase secret = %s, %v", got, err)
+	}
+	if _, err := Code("not base32!", 1); err == nil {
+		t.Error("invalid secret accepted")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	// 1111111111 — вторая секунда интервала 37037037
+	now := time.Unix(1111111111, 0)
+	step := Step(now)
+	code := func(offset int64) string {
+		c, err := Code(rfcSecret, step+offset)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+	tests := []struct {
+		name     string
+		code     string
+		wantStep int64
+		ok       bool
+	}{
+		{"current interval", code(0), step, true},
+		{"previous interval within skew", code(-1), step - 1, true},
+		{"next interval within skew", code(1), step + 1, true},
+		{"two intervals behind", code(-2), 0, false},
+		{"two intervals ahead", code(2), 0, false},
+		{"surrounding spaces", " " + code(0) + " ", step, true},
+		{"wrong code", "000000", 0, false},
+		{"too short", code(0)[:5], 0, false},
+		{"too long", code(0) + "1", 0, false},
+		{"empty", "", 0, false},
+	}
+	for _, tt := range tests {
+		gotStep, ok := Validate(rfcSecret, tt.code, now)
+		if ok != tt.ok || gotStep != tt.wantStep {
+			t.Errorf("%s: Validate = %d, %v; want %d, %v", tt.name, gotStep, ok, tt.wantStep, tt.ok)
+		}
+	}
+	if _, ok := Validate("not base32!", code(0), now); ok {
+		t.Error("code accepted for an invalid secret")
+	}
+}
+
+// Границы интервала: код действует ровно Period, а с допуском — ещё по интервалу в обе стороны
+func TestValidateIntervalBoundaries(t *testing.T) {
+	start := time.Unix(1111111110, 0) // начало интервала 37037037
+	code, _ := Code(rfcSecret, Step(start))
+	for _, at := range []time.Time{start.Add(-Period), start, start.Add(2*Period - time.Second)} {
+		if _, ok := Validate(rfcSecret, code, at); !ok {
+			t.Errorf("code rejected at %s", at.UTC())
+		}
+	}
+	for _, at := range []time.Time{start.Add(-Period - time.Second), start.Add(2 * Period)} {
+		if _, ok := Validate(rfcSecret, code, at); ok {
+			t.Errorf("code accepted at %s", at.UTC())
+		}
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 160 бит — 32 символа base32 без выравнивания
+	if len(secret) != 32 {
+		t.Errorf("secret length = %d, want 32", len(secret))
+	}
+	other, _ := GenerateSecret()
+	if other == secret {
+		t.Error("two generated secrets are equal")
+	}
+	if _, err := Code(secret, 1); err != nil {
+		t.Errorf("generated secret is not usable: %v", err)
+	}
+}
+
+func TestURI(t *testing.T) {
+	uri, err := url.Parse(URI("MoveShare", "carrier@example.com", rfcSecret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uri.Scheme != "otpauth" || uri.Host != "totp" || uri.Path != "/MoveShare:carrier@example.com" {
+		t.Errorf("uri = %s", uri)
+	}
+	want := map[string]string{"secret": rfcSecret, "issuer": "MoveShare", "algorithm": "SHA1", "digits": "6", "period": "30"}
+	for key, value := range want {
+		if got := uri.Query().Get(key); got != value {
+			t.Errorf("%s = %q, want %q", key, got, value)
+		}
+	}
+}
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_jobs_poster_id;
+
+ALTER TABLE jobs DROP COLUMN IF EXISTS poster_id;
\ No newline at end of file
@@ -0,0 +1,3 @@
+ALTER TABLE jobs ADD COLUMN poster_id INTEGER REFERENCES users(id) ON DELETE CASCADE;
+
+CREATE INDEX idx_jobs_poster_id ON jobs(poster_id);
\ No newline at end of file
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_jobs_carrier_id;
+DROP INDEX IF EXISTS idx_jobs_status;
+
+ALTER TABLE jobs
+    DROP COLUMN IF EXISTS carrier_id,
+    DROP COLUMN IF EXISTS status;
\ No newline at end of file
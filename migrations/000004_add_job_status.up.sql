@@ -0,0 +1,7 @@
+ALTER TABLE jobs
+    ADD COLUMN status TEXT NOT NULL DEFAULT 'open'
+        CHECK (status IN ('open', 'claimed', 'in_transit', 'delivered', 'completed', 'cancelled')),
+    ADD COLUMN carrier_id INTEGER REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_jobs_status ON jobs(status);
+CREATE INDEX idx_jobs_carrier_id ON jobs(carrier_id);
\ No newline at end of file
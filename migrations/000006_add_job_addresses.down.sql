@@ -0,0 +1,17 @@
+DROP INDEX IF EXISTS idx_jobs_delivery_location;
+DROP INDEX IF EXISTS idx_jobs_pickup_location;
+
+ALTER TABLE jobs
+    DROP COLUMN IF EXISTS pickup_street,
+    DROP COLUMN IF EXISTS pickup_city,
+    DROP COLUMN IF EXISTS pickup_state,
+    DROP COLUMN IF EXISTS pickup_zip,
+    DROP COLUMN IF EXISTS pickup_lat,
+    DROP COLUMN IF EXISTS pickup_lng,
+    DROP COLUMN IF EXISTS delivery_street,
+    DROP COLUMN IF EXISTS delivery_city,
+    DROP COLUMN IF EXISTS delivery_state,
+    DROP COLUMN IF EXISTS delivery_zip,
+    DROP COLUMN IF EXISTS delivery_lat,
+    DROP COLUMN IF EXISTS delivery_lng,
+    DROP COLUMN IF EXISTS distance_miles;
\ No newline at end of file
@@ -0,0 +1,17 @@
+ALTER TABLE jobs
+    ADD COLUMN pickup_street TEXT NOT NULL DEFAULT '',
+    ADD COLUMN pickup_city TEXT NOT NULL DEFAULT '',
+    ADD COLUMN pickup_state TEXT NOT NULL DEFAULT '',
+    ADD COLUMN pickup_zip TEXT NOT NULL DEFAULT '',
+    ADD COLUMN pickup_lat DOUBLE PRECISION,
+    ADD COLUMN pickup_lng DOUBLE PRECISION,
+    ADD COLUMN delivery_street TEXT NOT NULL DEFAULT '',
+    ADD COLUMN delivery_city TEXT NOT NULL DEFAULT '',
+    ADD COLUMN delivery_state TEXT NOT NULL DEFAULT '',
+    ADD COLUMN delivery_zip TEXT NOT NULL DEFAULT '',
+    ADD COLUMN delivery_lat DOUBLE PRECISION,
+    ADD COLUMN delivery_lng DOUBLE PRECISION,
+    ADD COLUMN distance_miles DOUBLE PRECISION;
+
+CREATE INDEX idx_jobs_pickup_location ON jobs(pickup_lat, pickup_lng);
+CREATE INDEX idx_jobs_delivery_location ON jobs(delivery_lat, delivery_lng);
\ No newline at end of file
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS role_changes;
+
+ALTER TABLE users DROP COLUMN IF EXISTS role;
\ No newline at end of file
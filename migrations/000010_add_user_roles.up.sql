@@ -0,0 +1,14 @@
+ALTER TABLE users ADD COLUMN role TEXT NOT NULL DEFAULT 'shipper'
+    CHECK (role IN ('shipper', 'carrier', 'admin'));
+
+CREATE TABLE role_changes (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    old_role TEXT NOT NULL,
+    new_role TEXT NOT NULL,
+    changed_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_role_changes_user_id ON role_changes(user_id);
\ No newline at end of file
@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_jobs_company_id;
+
+ALTER TABLE jobs DROP COLUMN IF EXISTS company_id;
+
+ALTER TABLE users DROP COLUMN IF EXISTS active_company_id;
+
+DROP TABLE IF EXISTS company_invitations;
+DROP TABLE IF EXISTS company_members;
+DROP TABLE IF EXISTS companies;
\ No newline at end of file
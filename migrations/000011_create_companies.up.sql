@@ -0,0 +1,36 @@
+CREATE TABLE companies (
+    id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL,
+    created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE company_members (
+    company_id INTEGER NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role TEXT NOT NULL CHECK (role IN ('owner', 'dispatcher', 'driver')),
+    joined_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (company_id, user_id)
+);
+
+CREATE INDEX idx_company_members_user_id ON company_members(user_id);
+
+CREATE TABLE company_invitations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    company_id INTEGER NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    email VARCHAR(255) NOT NULL,
+    role TEXT NOT NULL CHECK (role IN ('owner', 'dispatcher', 'driver')),
+    token_hash TEXT UNIQUE NOT NULL,
+    invited_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    expires_at TIMESTAMP NOT NULL,
+    accepted_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_company_invitations_company_id ON company_invitations(company_id);
+
+ALTER TABLE users ADD COLUMN active_company_id INTEGER REFERENCES companies(id) ON DELETE SET NULL;
+
+ALTER TABLE jobs ADD COLUMN company_id INTEGER REFERENCES companies(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_jobs_company_id ON jobs(company_id);
\ No newline at end of file
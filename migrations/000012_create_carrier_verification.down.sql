@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS insurance_certificates;
+DROP TABLE IF EXISTS carrier_profiles;
\ No newline at end of file
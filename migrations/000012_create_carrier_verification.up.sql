@@ -0,0 +1,32 @@
+CREATE TABLE carrier_profiles (
+    user_id INTEGER PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    legal_name TEXT NOT NULL,
+    dot_number VARCHAR(8) NOT NULL UNIQUE,
+    mc_number VARCHAR(8),
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'rejected', 'suspended')),
+    review_note TEXT NOT NULL DEFAULT '',
+    reviewed_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    reviewed_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_carrier_profiles_status ON carrier_profiles(status);
+
+CREATE TABLE insurance_certificates (
+    id UUID PRIMARY KEY,
+    carrier_id INTEGER NOT NULL REFERENCES carrier_profiles(user_id) ON DELETE CASCADE,
+    file_path TEXT NOT NULL,
+    file_name TEXT NOT NULL,
+    content_type TEXT NOT NULL,
+    coverage_amount NUMERIC(14, 2) NOT NULL CHECK (coverage_amount > 0),
+    expires_at TIMESTAMP NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'rejected', 'expired')),
+    review_note TEXT NOT NULL DEFAULT '',
+    reviewed_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    reviewed_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_insurance_certificates_carrier_id ON insurance_certificates(carrier_id);
+CREATE INDEX idx_insurance_certificates_status_expires_at ON insurance_certificates(status, expires_at);
\ No newline at end of file
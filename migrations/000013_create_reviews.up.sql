@@ -0,0 +1,16 @@
+CREATE TABLE reviews (
+    id UUID PRIMARY KEY,
+    job_id UUID NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+    reviewer_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    reviewer_side TEXT NOT NULL CHECK (reviewer_side IN ('poster', 'carrier')),
+    reviewee_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    reviewee_company_id INTEGER REFERENCES companies(id) ON DELETE SET NULL,
+    rating SMALLINT NOT NULL CHECK (rating BETWEEN 1 AND 5),
+    comment TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (job_id, reviewer_side)
+);
+
+CREATE INDEX idx_reviews_reviewee_id ON reviews(reviewee_id);
+CREATE INDEX idx_reviews_reviewee_company_id ON reviews(reviewee_company_id);
\ No newline at end of file
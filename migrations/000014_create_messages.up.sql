@@ -0,0 +1,20 @@
+CREATE TABLE message_threads (
+    id UUID PRIMARY KEY,
+    job_id UUID NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+    carrier_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (job_id, carrier_id)
+);
+
+CREATE TABLE messages (
+    id UUID PRIMARY KEY,
+    thread_id UUID NOT NULL REFERENCES message_threads(id) ON DELETE CASCADE,
+    sender_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    from_carrier BOOLEAN NOT NULL,
+    body TEXT NOT NULL,
+    read_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_messages_thread_id_created_at ON messages(thread_id, created_at DESC);
+CREATE INDEX idx_messages_unread ON messages(thread_id, from_carrier) WHERE read_at IS NULL;
\ No newline at end of file
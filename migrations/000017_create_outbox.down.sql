@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_webhook_deliveries_event;
+ALTER TABLE webhook_deliveries DROP COLUMN IF EXISTS redelivered_from;
+DROP TABLE IF EXISTS outbox_consumers;
+DROP TABLE IF EXISTS outbox_events;
\ No newline at end of file
@@ -0,0 +1,15 @@
+DROP INDEX IF EXISTS idx_jobs_payment;
+
+ALTER TABLE bids
+    DROP COLUMN IF EXISTS currency,
+    ALTER COLUMN amount TYPE DOUBLE PRECISION USING amount / 100.0,
+    ALTER COLUMN counter_amount TYPE DOUBLE PRECISION USING counter_amount / 100.0;
+
+ALTER TABLE jobs
+    DROP COLUMN IF EXISTS currency,
+    ALTER COLUMN cut_amount DROP NOT NULL,
+    ALTER COLUMN cut_amount DROP DEFAULT,
+    ALTER COLUMN cut_amount TYPE DOUBLE PRECISION USING cut_amount / 100.0,
+    ALTER COLUMN payment_amount DROP NOT NULL,
+    ALTER COLUMN payment_amount DROP DEFAULT,
+    ALTER COLUMN payment_amount TYPE DOUBLE PRECISION USING payment_amount / 100.0;
\ No newline at end of file
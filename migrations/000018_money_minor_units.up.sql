@@ -0,0 +1,18 @@
+-- Суммы хранятся целым числом минимальных единиц валюты (центов), валюта — в currency
+ALTER TABLE jobs
+    ALTER COLUMN cut_amount TYPE BIGINT USING ROUND(COALESCE(cut_amount, 0) * 100)::BIGINT,
+    ALTER COLUMN payment_amount TYPE BIGINT USING ROUND(COALESCE(payment_amount, 0) * 100)::BIGINT,
+    ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'USD';
+
+ALTER TABLE jobs
+    ALTER COLUMN cut_amount SET DEFAULT 0,
+    ALTER COLUMN cut_amount SET NOT NULL,
+    ALTER COLUMN payment_amount SET DEFAULT 0,
+    ALTER COLUMN payment_amount SET NOT NULL;
+
+CREATE INDEX idx_jobs_payment ON jobs(currency, payment_amount);
+
+ALTER TABLE bids
+    ALTER COLUMN amount TYPE BIGINT USING ROUND(amount * 100)::BIGINT,
+    ALTER COLUMN counter_amount TYPE BIGINT USING ROUND(counter_amount * 100)::BIGINT,
+    ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'USD';
\ No newline at end of file
@@ -0,0 +1,3 @@
+ALTER TABLE jobs DROP COLUMN IF EXISTS promo_code;
+DROP TABLE IF EXISTS job_fees;
+DROP TABLE IF EXISTS fee_rule_sets;
\ No newline at end of file
@@ -0,0 +1,32 @@
+CREATE TABLE fee_rule_sets (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    version SERIAL NOT NULL UNIQUE,
+    name TEXT NOT NULL,
+    rules JSONB NOT NULL,
+    active_from TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_fee_rule_sets_active_from ON fee_rule_sets(active_from);
+
+-- Начальный набор правил: 10% площадке, брокер ничего не удерживает
+INSERT INTO fee_rule_sets (name, rules, active_from)
+VALUES ('Default', '[{"component": "platform_fee", "kind": "percentage", "description": "Platform fee", "bps": 1000}]', '2000-01-01');
+
+-- Расчёт, зафиксированный при закреплении работы за перевозчиком
+CREATE TABLE job_fees (
+    job_id UUID PRIMARY KEY REFERENCES jobs(id) ON DELETE CASCADE,
+    rule_set_id UUID NOT NULL REFERENCES fee_rule_sets(id),
+    rule_set_version INTEGER NOT NULL,
+    currency CHAR(3) NOT NULL,
+    payment BIGINT NOT NULL,
+    platform_fee BIGINT NOT NULL,
+    broker_cut BIGINT NOT NULL,
+    carrier_net BIGINT NOT NULL,
+    promo_code TEXT NOT NULL DEFAULT '',
+    lines JSONB NOT NULL,
+    calculated_at TIMESTAMP NOT NULL
+);
+
+ALTER TABLE jobs ADD COLUMN promo_code TEXT NOT NULL DEFAULT '';
\ No newline at end of file
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS payment_ledger;
+DROP FUNCTION IF EXISTS payment_ledger_append_only();
+DROP TABLE IF EXISTS escrows;
\ No newline at end of file
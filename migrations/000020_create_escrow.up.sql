@@ -0,0 +1,44 @@
+-- Деньги по работе, удерживаемые площадкой. Ссылки на работу и пользователей
+-- без внешних ключей: удаление работы админом не должно терять удержанные деньги
+CREATE TABLE escrows (
+    id UUID PRIMARY KEY,
+    job_id UUID NOT NULL UNIQUE,
+    status TEXT NOT NULL DEFAULT 'pending',
+    payer_id INTEGER NOT NULL,
+    payee_id INTEGER NOT NULL,
+    currency CHAR(3) NOT NULL,
+    payment BIGINT NOT NULL,
+    platform_fee BIGINT NOT NULL,
+    broker_cut BIGINT NOT NULL,
+    carrier_net BIGINT NOT NULL,
+    charge_ref TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE payment_ledger (
+    id UUID PRIMARY KEY,
+    escrow_id UUID NOT NULL REFERENCES escrows(id),
+    job_id UUID NOT NULL,
+    type TEXT NOT NULL,
+    amount BIGINT NOT NULL,
+    currency CHAR(3) NOT NULL,
+    user_id INTEGER,
+    provider TEXT NOT NULL DEFAULT '',
+    provider_ref TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_payment_ledger_escrow ON payment_ledger(escrow_id, created_at);
+CREATE INDEX idx_payment_ledger_user ON payment_ledger(user_id, created_at);
+
+-- Журнал только дополняется
+CREATE FUNCTION payment_ledger_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'payment_ledger is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER payment_ledger_append_only
+BEFORE UPDATE OR DELETE ON payment_ledger
+FOR EACH ROW EXECUTE FUNCTION payment_ledger_append_only();
\ No newline at end of file
@@ -0,0 +1,66 @@
+CREATE TABLE ledger_accounts (
+    id SERIAL PRIMARY KEY,
+    -- code — тип, пользователь и валюта, например carrier_payable:7:USD
+    code TEXT NOT NULL UNIQUE,
+    type TEXT NOT NULL,
+    user_id INTEGER,
+    currency CHAR(3) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_ledger_accounts_user ON ledger_accounts(user_id);
+
+CREATE TABLE journal_entries (
+    id UUID PRIMARY KEY,
+    type TEXT NOT NULL,
+    job_id UUID,
+    description TEXT NOT NULL DEFAULT '',
+    dedup_key TEXT NOT NULL UNIQUE,
+    posted_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE ledger_postings (
+    id BIGSERIAL PRIMARY KEY,
+    entry_id UUID NOT NULL REFERENCES journal_entries(id),
+    account_id INTEGER NOT NULL REFERENCES ledger_accounts(id),
+    -- Положительная сумма — дебет, отрицательная — кредит
+    amount BIGINT NOT NULL,
+    currency CHAR(3) NOT NULL
+);
+
+CREATE INDEX idx_ledger_postings_entry ON ledger_postings(entry_id);
+CREATE INDEX idx_ledger_postings_account ON ledger_postings(account_id);
+
+-- Сумма движений проводки в каждой валюте равна нулю. Проверяется при коммите,
+-- когда записаны все движения проводки
+CREATE FUNCTION ledger_entry_balanced() RETURNS trigger AS $$
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM ledger_postings WHERE entry_id = NEW.entry_id
+        GROUP BY currency HAVING SUM(amount) <> 0
+    ) THEN
+        RAISE EXCEPTION 'journal entry % is not balanced', NEW.entry_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE CONSTRAINT TRIGGER ledger_postings_balanced
+AFTER INSERT ON ledger_postings
+DEFERRABLE INITIALLY DEFERRED
+FOR EACH ROW EXECUTE FUNCTION ledger_entry_balanced();
+
+-- Проводки не меняются и не удаляются: ошибку исправляет обратная проводка
+CREATE FUNCTION ledger_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION '% is append-only', TG_TABLE_NAME;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER journal_entries_append_only
+BEFORE UPDATE OR DELETE ON journal_entries
+FOR EACH ROW EXECUTE FUNCTION ledger_append_only();
+
+CREATE TRIGGER ledger_postings_append_only
+BEFORE UPDATE OR DELETE ON ledger_postings
+FOR EACH ROW EXECUTE FUNCTION ledger_append_only();
\ No newline at end of file
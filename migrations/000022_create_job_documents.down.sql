@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS job_documents;
+DROP TABLE IF EXISTS document_sequences;
\ No newline at end of file
@@ -0,0 +1,21 @@
+-- Последний выданный номер документа каждого вида у каждого выставителя
+CREATE TABLE document_sequences (
+    issuer TEXT NOT NULL,
+    kind TEXT NOT NULL,
+    last_number INTEGER NOT NULL,
+    PRIMARY KEY (issuer, kind)
+);
+
+-- Выданные документы. Без внешнего ключа на jobs: бухгалтерские документы
+-- остаются и после удаления работы
+CREATE TABLE job_documents (
+    id UUID PRIMARY KEY,
+    job_id UUID NOT NULL,
+    kind TEXT NOT NULL,
+    issuer TEXT NOT NULL,
+    number INTEGER NOT NULL,
+    file_path TEXT NOT NULL DEFAULT '',
+    issued_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (job_id, kind),
+    UNIQUE (issuer, kind, number)
+);
\ No newline at end of file
@@ -0,0 +1,4 @@
+ALTER TABLE jobs DROP COLUMN IF EXISTS bol_hash;
+DROP TABLE IF EXISTS bol_signatures;
+DROP FUNCTION IF EXISTS bol_signatures_append_only();
+DROP TABLE IF EXISTS bills_of_lading;
\ No newline at end of file
@@ -0,0 +1,42 @@
+-- Транспортные накладные (bill of lading). Без внешнего ключа на jobs:
+-- подписанный документ хранится и после удаления работы. snapshot — данные
+-- работы и сторон на момент выдачи; document_hash — SHA-256 текущего PDF
+CREATE TABLE bills_of_lading (
+    id UUID PRIMARY KEY,
+    job_id UUID NOT NULL UNIQUE,
+    number TEXT NOT NULL,
+    snapshot JSONB NOT NULL,
+    document_hash TEXT NOT NULL,
+    file_path TEXT NOT NULL,
+    issued_at TIMESTAMP NOT NULL
+);
+
+-- Подписи накладной: отправитель при погрузке и получатель при доставке.
+-- document_hash — хеш PDF, получившегося после этой подписи
+CREATE TABLE bol_signatures (
+    id UUID PRIMARY KEY,
+    bol_id UUID NOT NULL REFERENCES bills_of_lading(id),
+    role TEXT NOT NULL,
+    signer_name TEXT NOT NULL,
+    image_path TEXT NOT NULL,
+    image_sha256 TEXT NOT NULL,
+    ip TEXT NOT NULL,
+    captured_by INTEGER,
+    document_hash TEXT NOT NULL,
+    signed_at TIMESTAMP NOT NULL,
+    UNIQUE (bol_id, role)
+);
+
+-- Подписи не меняются и не удаляются
+CREATE FUNCTION bol_signatures_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'bol_signatures is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER bol_signatures_append_only
+BEFORE UPDATE OR DELETE ON bol_signatures
+FOR EACH ROW EXECUTE FUNCTION bol_signatures_append_only();
+
+-- Хеш последней версии накладной рядом с работой
+ALTER TABLE jobs ADD COLUMN bol_hash TEXT NOT NULL DEFAULT '';
\ No newline at end of file
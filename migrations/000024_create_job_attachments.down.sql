@@ -0,0 +1 @@
+DROP TABLE IF EXISTS job_attachments;
\ No newline at end of file
@@ -0,0 +1,19 @@
+-- Файлы работ: фото описи от заказчика, фото доставки от перевозчика.
+-- Сами файлы лежат в хранилище (BLOB_DRIVER) по ключам blob_key и thumbnail_key
+CREATE TABLE job_attachments (
+    id UUID PRIMARY KEY,
+    job_id UUID NOT NULL,
+    uploaded_by INTEGER NOT NULL,
+    kind TEXT NOT NULL,
+    file_name TEXT NOT NULL,
+    content_type TEXT NOT NULL,
+    size BIGINT NOT NULL,
+    sha256 TEXT NOT NULL,
+    width INTEGER NOT NULL DEFAULT 0,
+    height INTEGER NOT NULL DEFAULT 0,
+    blob_key TEXT NOT NULL,
+    thumbnail_key TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_job_attachments_job ON job_attachments(job_id, created_at);
\ No newline at end of file
@@ -0,0 +1,3 @@
+ALTER TABLE insurance_certificates
+    DROP COLUMN IF EXISTS coverage_currency,
+    ALTER COLUMN coverage_amount TYPE NUMERIC(14, 2) USING coverage_amount / 100.0;
\ No newline at end of file
@@ -0,0 +1,4 @@
+-- Сумма покрытия страховки — целое число минимальных единиц валюты, как суммы работ
+ALTER TABLE insurance_certificates
+    ALTER COLUMN coverage_amount TYPE BIGINT USING ROUND(coverage_amount * 100)::BIGINT,
+    ADD COLUMN coverage_currency CHAR(3) NOT NULL DEFAULT 'USD';
\ No newline at end of file
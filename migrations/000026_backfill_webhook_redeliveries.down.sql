@@ -0,0 +1,2 @@
+-- Индекс принадлежит 000017, а проставленные пометки повторов неотличимы от
+-- новых, поэтому откатывать нечего
\ No newline at end of file
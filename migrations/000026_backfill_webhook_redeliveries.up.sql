@@ -0,0 +1,18 @@
+-- Повторные отправки, сделанные до 000017, — копии с тем же event_id.
+-- Исходной считается самая ранняя доставка, остальные помечаются повторами,
+-- после чего уникальный индекс создаётся заново
+DROP INDEX IF EXISTS idx_webhook_deliveries_event;
+
+UPDATE webhook_deliveries d
+SET redelivered_from = first.id
+FROM (
+    SELECT DISTINCT ON (webhook_id, event_id) id, webhook_id, event_id
+    FROM webhook_deliveries
+    ORDER BY webhook_id, event_id, created_at, id
+) first
+WHERE d.webhook_id = first.webhook_id
+  AND d.event_id = first.event_id
+  AND d.id <> first.id
+  AND d.redelivered_from IS NULL;
+
+CREATE UNIQUE INDEX idx_webhook_deliveries_event ON webhook_deliveries(webhook_id, event_id) WHERE redelivered_from IS NULL;
\ No newline at end of file